@@ -13,6 +13,7 @@ import (
 
 	ent "github.com/gurkanbulca/taskmaster/ent/generated"
 	"github.com/gurkanbulca/taskmaster/ent/generated/migrate"
+	"github.com/gurkanbulca/taskmaster/internal/database"
 )
 
 func main() {
@@ -52,6 +53,7 @@ func main() {
 		migrate.WithDropIndex(true),
 		migrate.WithDropColumn(true),
 		migrate.WithForeignKeys(true),
+		database.TaskSearchMigration(),
 	); err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
@@ -2,21 +2,33 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
 
 	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
 	taskv1 "github.com/gurkanbulca/taskmaster/api/proto/task/v1/generated"
@@ -24,11 +36,20 @@ import (
 	"github.com/gurkanbulca/taskmaster/ent/generated/migrate"
 	"github.com/gurkanbulca/taskmaster/internal/config"
 	"github.com/gurkanbulca/taskmaster/internal/database"
+	"github.com/gurkanbulca/taskmaster/internal/gateway"
+	"github.com/gurkanbulca/taskmaster/internal/healthcheck"
 	"github.com/gurkanbulca/taskmaster/internal/middleware"
 	"github.com/gurkanbulca/taskmaster/internal/repository"
 	"github.com/gurkanbulca/taskmaster/internal/service"
 	"github.com/gurkanbulca/taskmaster/pkg/auth"
 	"github.com/gurkanbulca/taskmaster/pkg/email"
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
+	"github.com/gurkanbulca/taskmaster/pkg/tracing"
+)
+
+const (
+	// shutdownTimeout bounds each graceful shutdown step before it is forced
+	shutdownTimeout = 30 * time.Second
 )
 
 func main() {
@@ -48,6 +69,22 @@ func main() {
 		log.Fatalf("Invalid configuration: %v", err)
 	}
 
+	// Initialize tracing before anything that creates spans
+	if cfg.Tracing.Enabled {
+		tracerProvider, err := tracing.NewProvider(context.Background(), cfg.ToTracingConfig())
+		if err != nil {
+			log.Fatalf("Failed to initialize tracing: %v", err)
+		}
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tracerProvider.Shutdown(shutdownCtx); err != nil {
+				log.Printf("Failed to flush traces: %v", err)
+			}
+		}()
+		log.Printf("Exporting traces to %s", cfg.Tracing.OTLPEndpoint)
+	}
+
 	// Connect to database with Ent
 	log.Println("Connecting to PostgreSQL with Ent...")
 	entClient, err := database.NewEntClient(database.Config{
@@ -58,6 +95,11 @@ func main() {
 		DBName:   cfg.Database.DBName,
 		SSLMode:  cfg.Database.SSLMode,
 		Debug:    cfg.IsDevelopment(),
+
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		QueryTimeout:    cfg.Database.QueryTimeout,
 	})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
@@ -68,6 +110,16 @@ func main() {
 		}
 	}()
 
+	// Structured JSON logger for request and service logs
+	logLevel := slog.LevelInfo
+	if cfg.Server.EnableDebugLogs {
+		logLevel = slog.LevelDebug
+	}
+	appLogger := logging.NewJSONLogger(os.Stdout, logLevel)
+
+	// Audit security-relevant user changes wherever they are made
+	service.RegisterSecurityHooks(entClient, appLogger)
+
 	// Run auto migration
 	if cfg.Server.AutoMigrate {
 		if err := runAutoMigration(context.Background(), entClient); err != nil {
@@ -82,34 +134,67 @@ func main() {
 		cfg.JWT.AccessTokenDuration,
 		cfg.JWT.RefreshTokenDuration,
 	)
+	tokenManager.SetIssuer(cfg.JWT.Issuer)
+	tokenManager.SetAudience(cfg.JWT.Audience)
+	tokenManager.SetLeeway(cfg.JWT.Leeway)
+	tokenManager.SetRefreshThreshold(cfg.JWT.RefreshThreshold)
+	tokenManager.SetRememberMeDuration(cfg.JWT.RememberMeDuration)
+	tokenBlacklist := auth.NewTokenBlacklist(entClient)
+	tokenManager.SetBlacklist(tokenBlacklist)
+	sessionStore := auth.NewSessionStore(entClient)
 
 	// Initialize email service
 	var emailService email.EmailService
+	var emailQueue *email.Queue
+	var smtpService *email.SMTPEmailService
 	if cfg.Email.TestingMode || cfg.IsDevelopment() {
 		log.Println("Using mock email service for development/testing")
 		emailService = email.NewMockEmailService()
 	} else {
 		log.Println("Using SMTP email service")
-		emailService = email.NewSMTPEmailService(cfg.ToEmailConfig())
+		smtpService, err = email.NewSMTPEmailService(cfg.ToEmailConfig())
+		if err != nil {
+			log.Fatalf("Failed to initialize email service: %v", err)
+		}
+		emailService = smtpService
 
 		// Test SMTP connection
-		if smtpService, ok := emailService.(*email.SMTPEmailService); ok {
-			if err := smtpService.TestConnection(context.Background()); err != nil {
-				log.Printf("Warning: SMTP connection test failed: %v", err)
-			} else {
-				log.Println("SMTP connection test successful")
-			}
+		if err := smtpService.TestConnection(context.Background()); err != nil {
+			log.Printf("Warning: SMTP connection test failed: %v", err)
+		} else {
+			log.Println("SMTP connection test successful")
 		}
+
+		// Deliver in the background so requests don't wait on SMTP
+		emailQueue = email.NewQueue(cfg.ToEmailQueueConfig())
+		emailService = email.NewQueuedEmailService(emailService, emailQueue)
 	}
 
 	// Initialize services
 	securityService := service.NewSecurityService(entClient)
 	securityLogger := service.NewSecurityLogger(securityService)
 
-	emailVerificationService := service.NewEmailVerificationService(entClient, emailService, securityLogger)
-	passwordResetService := service.NewPasswordResetService(entClient, emailService, auth.NewPasswordManager(), securityLogger)
+	emailVerificationService := service.NewEmailVerificationService(entClient, emailService, securityLogger, service.EmailVerificationConfig{
+		MaxAttempts: cfg.Security.MaxEmailVerificationAttempts,
+		ResendWait:  cfg.Security.EmailVerificationResendWait,
+	})
+	passwordManager := auth.NewPasswordManager()
+	passwordManager.SetMinScore(cfg.Validation.MinPasswordScore)
+	if err := passwordManager.SetHashAlgorithm(cfg.Security.PasswordHashAlgorithm, cfg.ToArgon2Params()); err != nil {
+		log.Fatalf("Failed to configure password hashing: %v", err)
+	}
+	if err := passwordManager.SetBcryptCost(cfg.Security.BcryptCost); err != nil {
+		log.Fatalf("Failed to configure password hashing: %v", err)
+	}
+	passwordResetService := service.NewPasswordResetService(entClient, emailService, passwordManager, securityLogger, service.PasswordResetConfig{
+		TokenDuration: cfg.Email.PasswordResetTokenDuration,
+		MaxAttempts:   cfg.Security.MaxPasswordResetAttempts,
+		RateLimit:     cfg.Security.PasswordResetRateLimit,
+	})
+	passwordResetService.SetPasswordHistorySize(cfg.Security.PasswordHistorySize)
 
 	taskRepo := repository.NewEntTaskRepository(entClient)
+	taskRepo.SetFullTextSearch(cfg.Database.TaskSearchMode == config.TaskSearchFullText)
 
 	// Pass security config to auth service
 	authService := service.NewAuthService(
@@ -120,23 +205,94 @@ func main() {
 		securityLogger,
 		cfg.Security, // Pass the security configuration
 	)
+	authService.SetEmailService(emailService)
+	// Previews render the real templates even when sending is mocked
+	emailPreviewer := smtpService
+	if emailPreviewer == nil {
+		emailPreviewer, err = email.NewSMTPEmailService(cfg.ToEmailConfig())
+		if err != nil {
+			log.Fatalf("Failed to load email templates: %v", err)
+		}
+	}
+	authService.SetEmailPreviewer(emailPreviewer)
+	authService.SetPasswordManager(passwordManager)
+	authService.SetLogger(appLogger)
+	authService.SetGetMeCacheTTL(cfg.Server.GetMeCacheTTL)
+	if cfg.OIDC.Enabled() {
+		authService.SetOIDCVerifier(auth.NewOIDCVerifier(cfg.OIDC.Issuer, cfg.OIDC.ClientID, cfg.OIDC.JWKSURL))
+		log.Printf("🔑 OIDC login enabled for issuer %s", cfg.OIDC.Issuer)
+	}
+	if cfg.Captcha.Enabled() {
+		authService.SetCaptchaVerifier(auth.NewRecaptchaVerifier(cfg.Captcha.SecretKey, cfg.Captcha.VerifyURL, cfg.Captcha.MinScore))
+		log.Println("🤖 CAPTCHA required for registration and password reset")
+	}
 
 	taskService := service.NewTaskService(taskRepo)
+	taskService.SetValidationConfig(cfg.ToValidationConfig())
+	taskService.SetEmailService(emailService)
+	taskService.SetLogger(appLogger)
+	taskService.SetIdempotencyKeyTTL(cfg.Server.IdempotencyKeyTTL)
+	taskService.SetTemplateSharing(cfg.Server.SharedTaskTemplates)
 
 	// Initialize middleware
 	metadataExtractor := middleware.NewMetadataExtractorInterceptor()
+	// The gateway proves it is this process with a secret that lives only in
+	// memory, so no other client can pass a forwarded client address
+	proxySecret := rand.Text()
+	metadataExtractor.SetProxySecret(proxySecret)
+	loginRateLimiter := middleware.NewLoginRateLimitInterceptor(cfg.ToLoginRateLimitConfig())
+	introspectionRateLimiter := middleware.NewMethodRateLimitInterceptor(
+		"/auth.v1.AuthService/IntrospectToken", cfg.Security.IntrospectionRateLimit, time.Minute)
 	authInterceptor := middleware.NewUpdatedAuthInterceptor(tokenManager)
+	authInterceptor.SetMethodRoles(cfg.Security.MethodRoles)
+	authInterceptor.SetAPIKeyStore(auth.NewAPIKeyStore(entClient), cfg.Security.APIKeyRateLimit)
+	authInterceptor.SetActivityTracker(auth.NewActivityTracker(entClient, cfg.Security.SessionTimeoutDuration))
 	validationInterceptor := middleware.NewEnhancedValidationInterceptor(cfg.ToValidationConfig())
+	metricsInterceptor := middleware.NewMetricsInterceptor(prometheus.DefaultRegisterer)
+	recoveryInterceptor := middleware.NewRecoveryInterceptor(appLogger, prometheus.DefaultRegisterer)
+	localizationInterceptor := middleware.NewLocalizationInterceptor()
+	tracingInterceptor := middleware.NewTracingInterceptor(otel.GetTracerProvider(), otel.GetTextMapPropagator())
+	deadlineInterceptor := middleware.NewDeadlineInterceptor(cfg.Server.RequestTimeout, cfg.Server.MethodTimeouts)
+
+	// The listener and the gateway's loopback connection use TLS when configured
+	var serverCreds, gatewayCreds credentials.TransportCredentials = insecure.NewCredentials(), insecure.NewCredentials()
+	if cfg.Server.TLSEnabled() {
+		serverTLS, err := loadServerTLS(cfg.Server)
+		if err != nil {
+			log.Fatalf("Failed to load TLS credentials: %v", err)
+		}
+		clientTLS, err := gatewayTLS(cfg.Server, serverTLS)
+		if err != nil {
+			log.Fatalf("Failed to load gateway TLS credentials: %v", err)
+		}
+		serverCreds = credentials.NewTLS(serverTLS)
+		gatewayCreds = credentials.NewTLS(clientTLS)
+		log.Printf("🔒 gRPC TLS enabled (client certificates required: %v)", cfg.Server.TLSClientCAFile != "")
+	}
 
 	// Create gRPC server with interceptors
 	grpcServer := grpc.NewServer(
+		grpc.Creds(serverCreds),
+		grpc.MaxRecvMsgSize(cfg.Server.MaxRecvMsgSize),
 		grpc.ChainUnaryInterceptor(
+			tracingInterceptor.Unary(),
+			metricsInterceptor.Unary(),      // First so rejected requests are counted too
+			localizationInterceptor.Unary(), // Outside recovery so its errors are translated too
+			recoveryInterceptor.Unary(),     // Inside metrics and tracing so recovered panics show up as Internal
+			deadlineInterceptor.Unary(),     // Bounds everything after it, including auth lookups
 			metadataExtractor.Unary(),
+			loginRateLimiter.Unary(),
+			introspectionRateLimiter.Unary(),
 			validationInterceptor.Unary(),
 			authInterceptor.Unary(),
-			loggingInterceptor,
+			middleware.RequirePermission(middleware.DefaultMethodPermissions()),
+			middleware.NewLoggingInterceptor(appLogger), // After auth so the user ID and role are in context
 		),
 		grpc.ChainStreamInterceptor(
+			tracingInterceptor.Stream(),
+			metricsInterceptor.Stream(),
+			localizationInterceptor.Stream(),
+			recoveryInterceptor.Stream(),
 			metadataExtractor.Stream(),
 			validationInterceptor.Stream(),
 			authInterceptor.Stream(),
@@ -151,9 +307,25 @@ func main() {
 	healthServer := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
 	// Set the health status for all services
-	healthServer.SetServingStatus("auth.v1.AuthService", grpc_health_v1.HealthCheckResponse_SERVING)
-	healthServer.SetServingStatus("task.v1.TaskService", grpc_health_v1.HealthCheckResponse_SERVING)
-	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING) // For overall health
+	// Readiness follows database connectivity, for all services and overall ("")
+	healthChecker := healthcheck.NewChecker(
+		healthServer,
+		cfg.Server.HealthCheckInterval,
+		cfg.Server.HealthCheckTimeout,
+		"auth.v1.AuthService", "task.v1.TaskService", "",
+	)
+	healthChecker.SetLogger(appLogger)
+	healthChecker.AddCheck("database", healthcheck.PingerFunc(func(ctx context.Context) error {
+		return database.Ping(ctx, entClient)
+	}))
+	// SMTP health is always published as "email"; it only affects readiness when critical
+	if smtpService != nil {
+		if cfg.Email.HealthCritical {
+			healthChecker.AddCheck("email", healthcheck.PingerFunc(smtpService.TestConnection))
+		} else {
+			healthChecker.AddOptionalCheck("email", healthcheck.PingerFunc(smtpService.TestConnection))
+		}
+	}
 
 	// Register reflection for development
 	if cfg.Server.EnableReflection {
@@ -167,8 +339,38 @@ func main() {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
+	// Root context, cancelled on SIGINT/SIGTERM to stop background jobs
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Start readiness probe updates
+	go healthChecker.Run(ctx)
+
 	// Start background cleanup job
-	go startCleanupJob(context.Background(), emailVerificationService, passwordResetService)
+	cleanupDone := make(chan struct{})
+	go func() {
+		defer close(cleanupDone)
+		runCleanupJob(ctx, cfg.Server.CleanupInterval, []cleanupTask{
+			{name: "expired email verification tokens", run: emailVerificationService.CleanupExpiredTokens},
+			{name: "expired password reset tokens", run: passwordResetService.CleanupExpiredTokens},
+			{name: "expired revoked tokens", run: func(ctx context.Context) error {
+				_, err := tokenBlacklist.CleanupExpired(ctx)
+				return err
+			}},
+			{name: "expired sessions", run: func(ctx context.Context) error {
+				_, err := sessionStore.CleanupExpired(ctx)
+				return err
+			}},
+			{name: "expired idempotency keys", run: func(ctx context.Context) error {
+				_, err := taskRepo.CleanupExpiredIdempotencyKeys(ctx)
+				return err
+			}},
+			{name: "old security events", run: func(ctx context.Context) error {
+				_, err := securityService.PurgeOldSecurityEvents(ctx, cfg.Security.SecurityEventRetention, cfg.Security.RetainUnresolvedHighSeverity)
+				return err
+			}},
+		})
+	}()
 
 	// Start server in goroutine
 	go func() {
@@ -178,13 +380,72 @@ func main() {
 		}
 	}()
 
+	// The REST gateway calls the gRPC server over loopback so requests pass
+	// through the same interceptors as native gRPC clients
+	gatewayConn, err := grpc.NewClient(
+		fmt.Sprintf("localhost:%s", cfg.Server.GRPCPort),
+		grpc.WithTransportCredentials(gatewayCreds),
+	)
+	if err != nil {
+		log.Fatalf("Failed to create gateway connection: %v", err)
+	}
+	defer gatewayConn.Close()
+
+	// Expose Prometheus metrics and the REST gateway over HTTP
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	restGateway := gateway.New(gatewayConn, cfg.Server.CORSAllowedOrigins)
+	restGateway.SetProxySecret(proxySecret)
+	mux.Handle("/v1/", restGateway)
+	httpServer := &http.Server{
+		Addr:              fmt.Sprintf(":%s", cfg.Server.HTTPPort),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		log.Printf("📈 Metrics available at http://localhost:%s/metrics", cfg.Server.HTTPPort)
+		log.Printf("🌐 REST gateway available at http://localhost:%s/v1/", cfg.Server.HTTPPort)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to serve HTTP: %v", err)
+		}
+	}()
+
 	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	<-ctx.Done()
+	stop()
 
 	log.Println("📴 Shutting down server...")
-	grpcServer.GracefulStop()
+	healthServer.Shutdown() // Report NOT_SERVING so no new traffic is routed here
+	<-cleanupDone
+
+	// Stop the gateway first so its in-flight calls finish before the gRPC
+	// server stops
+	httpShutdownCtx, cancelHTTP := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := httpServer.Shutdown(httpShutdownCtx); err != nil {
+		log.Printf("HTTP server did not shut down cleanly: %v", err)
+	}
+	cancelHTTP()
+
+	// Let in-flight RPCs finish, but don't wait forever on long-lived streams
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		log.Printf("Graceful stop did not finish within %v, forcing shutdown", shutdownTimeout)
+		grpcServer.Stop()
+	}
+
+	if emailQueue != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		if err := emailQueue.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Email queue did not drain before shutdown: %v", err)
+		}
+		cancel()
+	}
 	log.Println("✅ Server shutdown complete")
 }
 
@@ -196,6 +457,7 @@ func runAutoMigration(ctx context.Context, client *ent.Client) error {
 		migrate.WithDropIndex(true),
 		migrate.WithDropColumn(true),
 		migrate.WithForeignKeys(true),
+		database.TaskSearchMigration(),
 	)
 	if err != nil {
 		return fmt.Errorf("run auto migration: %w", err)
@@ -204,41 +466,93 @@ func runAutoMigration(ctx context.Context, client *ent.Client) error {
 	return nil
 }
 
-// startCleanupJob starts background cleanup jobs
-func startCleanupJob(ctx context.Context, emailVerificationService *service.EmailVerificationService, passwordResetService *service.PasswordResetService) {
-	ticker := time.NewTicker(1 * time.Hour)
+// loadServerTLS builds the gRPC listener's TLS config, requiring client
+// certificates when a client CA is configured
+func loadServerTLS(cfg config.ServerConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS key pair: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.TLSClientCAFile != "" {
+		caPEM, err := os.ReadFile(cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read TLS client CA: %w", err)
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// gatewayTLS builds the client side of the gateway's loopback connection. The
+// peer is this process, so it pins the server's own certificate rather than
+// verifying a chain and host name. Under mTLS it presents the gateway's own
+// client certificate.
+func gatewayTLS(cfg config.ServerConfig, serverTLS *tls.Config) (*tls.Config, error) {
+	serverCert := serverTLS.Certificates[0]
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true, // Replaced by the pin below
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 || !bytes.Equal(rawCerts[0], serverCert.Certificate[0]) {
+				return errors.New("unexpected gRPC server certificate")
+			}
+			return nil
+		},
+	}
+	if serverTLS.ClientCAs != nil {
+		clientCert, err := tls.LoadX509KeyPair(cfg.TLSGatewayCertFile, cfg.TLSGatewayKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load gateway TLS key pair: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	return tlsConfig, nil
+}
+
+// cleanupTask is one step of the periodic cleanup job
+type cleanupTask struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// runCleanupJob runs every task once on startup and then once per interval
+// until ctx is cancelled
+func runCleanupJob(ctx context.Context, interval time.Duration, tasks []cleanupTask) {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
-	log.Println("🧹 Starting background cleanup job (runs every hour)")
+	log.Printf("🧹 Starting background cleanup job (runs every %v)", interval)
+	runCleanupTasks(ctx, tasks)
 	for {
 		select {
 		case <-ctx.Done():
+			log.Println("🧹 Background cleanup job stopped")
 			return
 		case <-ticker.C:
-			if err := emailVerificationService.CleanupExpiredTokens(ctx); err != nil {
-				log.Printf("Failed to cleanup expired email verification tokens: %v", err)
-			}
-			if err := passwordResetService.CleanupExpiredTokens(ctx); err != nil {
-				log.Printf("Failed to cleanup expired password reset tokens: %v", err)
-			}
-			log.Println("🧹 Token cleanup completed")
+			runCleanupTasks(ctx, tasks)
 		}
 	}
 }
 
-// loggingInterceptor logs incoming requests
-func loggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	start := time.Now()
-	clientInfo := middleware.GetClientInfoFromContext(ctx)
-	resp, err := handler(ctx, req)
-	duration := time.Since(start)
-	logLevel := "INFO"
-	if err != nil {
-		logLevel = "ERROR"
-	}
-	log.Printf("[%s] %s completed in %v (user: %s, ip: %s)",
-		logLevel, info.FullMethod, duration, clientInfo.UserID, clientInfo.IPAddress)
-	if err != nil {
-		log.Printf("[ERROR] %s error: %v", info.FullMethod, err)
+// runCleanupTasks runs one cleanup pass, logging failures without stopping
+func runCleanupTasks(ctx context.Context, tasks []cleanupTask) {
+	for _, task := range tasks {
+		if err := task.run(ctx); err != nil {
+			log.Printf("Failed to cleanup %s: %v", task.name, err)
+		}
 	}
-	return resp, err
+	log.Println("🧹 Token cleanup completed")
 }
@@ -0,0 +1,274 @@
+// cmd/server/main_test.go
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/gurkanbulca/taskmaster/internal/config"
+)
+
+func TestRunCleanupJob_StopsWhenContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var runs atomic.Int32
+	tasks := []cleanupTask{
+		{name: "counting", run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		}},
+		{name: "failing", run: func(ctx context.Context) error {
+			return errors.New("database unavailable")
+		}},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		runCleanupJob(ctx, 10*time.Millisecond, tasks)
+		close(done)
+	}()
+
+	// Tasks run on each tick, and a failing task doesn't stop the job
+	assert.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("cleanup job did not stop after context was cancelled")
+	}
+}
+
+func TestRunCleanupJob_RunsImmediatelyOnStartup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	tasks := []cleanupTask{
+		{name: "counting", run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		}},
+	}
+
+	go runCleanupJob(ctx, time.Hour, tasks)
+
+	// The first pass doesn't wait for the hour-long interval
+	assert.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestCleanupInterval_ReadFromConfig(t *testing.T) {
+	t.Setenv("CLEANUP_INTERVAL", "15m")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, cfg.Server.CleanupInterval)
+	assert.NoError(t, cfg.ValidateConfig())
+
+	// Intervals under a minute are rejected
+	cfg.Server.CleanupInterval = 30 * time.Second
+	assert.EqualError(t, cfg.ValidateConfig(), "cleanup interval must be at least 1 minute")
+}
+
+// writeTestCert writes a self-signed certificate usable for server and client
+// auth, and as its own CA, returning the cert and key paths
+func writeTestCert(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "taskmaster-test"},
+		DNSNames:              []string{"taskmaster.internal"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+// issueTestCert writes a certificate for usage signed by the CA in caCertFile
+// and caKeyFile, returning the cert and key paths
+func issueTestCert(t *testing.T, caCertFile, caKeyFile string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+	t.Helper()
+
+	ca, err := tls.LoadX509KeyPair(caCertFile, caKeyFile)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "taskmaster-issued"},
+		DNSNames:     []string{"taskmaster.internal"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, ca.PrivateKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestLoadServerTLS_MissingFiles(t *testing.T) {
+	certFile, keyFile := writeTestCert(t)
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+
+	tests := []struct {
+		name string
+		cfg  config.ServerConfig
+	}{
+		{name: "missing cert", cfg: config.ServerConfig{TLSCertFile: missing, TLSKeyFile: keyFile}},
+		{name: "missing key", cfg: config.ServerConfig{TLSCertFile: certFile, TLSKeyFile: missing}},
+		{name: "missing client CA", cfg: config.ServerConfig{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSClientCAFile: missing}},
+		{name: "client CA without certificates", cfg: config.ServerConfig{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSClientCAFile: keyFile}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadServerTLS(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadServerTLS_ServesGRPC(t *testing.T) {
+	caCertFile, caKeyFile := writeTestCert(t)
+	// A typical server certificate, only valid for server auth
+	certFile, keyFile := issueTestCert(t, caCertFile, caKeyFile, 2, x509.ExtKeyUsageServerAuth)
+	gatewayCertFile, gatewayKeyFile := issueTestCert(t, caCertFile, caKeyFile, 3, x509.ExtKeyUsageClientAuth)
+
+	cfg := config.ServerConfig{
+		TLSCertFile:        certFile,
+		TLSKeyFile:         keyFile,
+		TLSClientCAFile:    caCertFile,
+		TLSGatewayCertFile: gatewayCertFile,
+		TLSGatewayKeyFile:  gatewayKeyFile,
+	}
+	serverTLS, err := loadServerTLS(cfg)
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(serverTLS)))
+	grpc_health_v1.RegisterHealthServer(grpcServer, health.NewServer())
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	check := func(creds credentials.TransportCredentials) (*grpc_health_v1.HealthCheckResponse, error) {
+		conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(creds))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	}
+
+	// The gateway's loopback credentials pass both sides of the handshake
+	clientTLS, err := gatewayTLS(cfg, serverTLS)
+	require.NoError(t, err)
+	resp, err := check(credentials.NewTLS(clientTLS))
+	require.NoError(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+
+	// The server certificate is not valid for client auth
+	serverCertAsClient, err := gatewayTLS(cfg, serverTLS)
+	require.NoError(t, err)
+	serverCertAsClient.Certificates = serverTLS.Certificates
+	_, err = check(credentials.NewTLS(serverCertAsClient))
+	assert.Error(t, err)
+
+	// Clients without a certificate are turned away
+	clientTLS.Certificates = nil
+	_, err = check(credentials.NewTLS(clientTLS))
+	assert.Error(t, err)
+
+	// A missing gateway certificate is reported up front
+	cfg.TLSGatewayCertFile = filepath.Join(t.TempDir(), "missing.pem")
+	_, err = gatewayTLS(cfg, serverTLS)
+	assert.Error(t, err)
+}
+
+func TestTLSConfig_Validation(t *testing.T) {
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	cfg.Server.TLSCertFile = "cert.pem"
+	assert.EqualError(t, cfg.ValidateConfig(), "TLS cert and key files must be set together")
+
+	cfg.Server.TLSCertFile = ""
+	cfg.Server.TLSClientCAFile = "ca.pem"
+	cfg.Server.TLSKeyFile = ""
+	assert.EqualError(t, cfg.ValidateConfig(), "TLS client CA requires a server certificate")
+
+	cfg.Server.TLSCertFile = "cert.pem"
+	cfg.Server.TLSKeyFile = "key.pem"
+	assert.EqualError(t, cfg.ValidateConfig(), "TLS client CA requires a gateway client certificate")
+
+	cfg.Server.TLSGatewayCertFile = "gateway.pem"
+	assert.EqualError(t, cfg.ValidateConfig(), "TLS gateway cert and key files must be set together")
+}
+
+func TestRequestTimeouts_ReadFromConfig(t *testing.T) {
+	t.Setenv("REQUEST_TIMEOUT", "10s")
+	t.Setenv("METHOD_TIMEOUTS", "/auth.v1.AuthService/ExportSecurityEvents=2m, /task.v1.TaskService/GetTask=bogus")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, cfg.Server.RequestTimeout)
+	// Malformed entries are dropped
+	assert.Equal(t, map[string]time.Duration{"/auth.v1.AuthService/ExportSecurityEvents": 2 * time.Minute}, cfg.Server.MethodTimeouts)
+	assert.NoError(t, cfg.ValidateConfig())
+
+	cfg.Server.MethodTimeouts["/task.v1.TaskService/GetTask"] = -time.Second
+	assert.EqualError(t, cfg.ValidateConfig(), "timeout for method /task.v1.TaskService/GetTask cannot be negative")
+}
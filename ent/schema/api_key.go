@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// ApiKey holds the schema definition for API keys used by non-interactive clients
+type ApiKey struct {
+	ent.Schema
+}
+
+// Fields of the ApiKey.
+func (ApiKey) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).
+			Default(uuid.New).
+			Immutable(),
+
+		field.UUID("user_id", uuid.UUID{}).
+			Immutable().
+			Comment("User the key acts as"),
+
+		field.String("name").
+			NotEmpty().
+			MaxLen(100).
+			Comment("Label chosen by the owner"),
+
+		field.String("prefix").
+			NotEmpty().
+			Immutable().
+			Comment("First characters of the key, shown so owners can tell keys apart"),
+
+		field.String("key_hash").
+			NotEmpty().
+			Unique().
+			Immutable().
+			Sensitive().
+			Comment("SHA-256 hash of the key; the key itself is only shown once"),
+
+		field.Strings("scopes").
+			Default([]string{}).
+			Comment("Scopes granted to the key, e.g. tasks:read"),
+
+		field.Time("expires_at").
+			Optional().
+			Nillable().
+			Comment("When the key stops working; never if unset"),
+
+		field.Time("last_used_at").
+			Optional().
+			Nillable().
+			Comment("When the key last authenticated a request"),
+
+		field.Time("revoked_at").
+			Optional().
+			Nillable().
+			Comment("When the key was revoked"),
+
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable(),
+	}
+}
+
+// Edges of the ApiKey.
+func (ApiKey) Edges() []ent.Edge {
+	return []ent.Edge{
+		// Key belongs to a user
+		edge.From("owner", User.Type).
+			Ref("api_keys").
+			Unique().
+			Required().
+			Immutable().
+			Field("user_id"),
+	}
+}
+
+// Indexes of the ApiKey.
+func (ApiKey) Indexes() []ent.Index {
+	return []ent.Index{
+		// Listing a user's keys and cleanup when a user is deleted
+		index.Fields("user_id"),
+	}
+}
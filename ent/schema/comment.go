@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// Comment holds the schema definition for comments on tasks
+type Comment struct {
+	ent.Schema
+}
+
+// Fields of the Comment.
+func (Comment) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).
+			Default(uuid.New).
+			Immutable(),
+
+		field.UUID("task_id", uuid.UUID{}).
+			Immutable().
+			Comment("Task the comment was posted on"),
+
+		field.UUID("author_id", uuid.UUID{}).
+			Immutable().
+			Comment("User who wrote the comment"),
+
+		field.Text("body").
+			NotEmpty().
+			Comment("Comment text"),
+
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable(),
+
+		field.Time("updated_at").
+			Default(time.Now).
+			UpdateDefault(time.Now),
+	}
+}
+
+// Edges of the Comment.
+func (Comment) Edges() []ent.Edge {
+	return []ent.Edge{
+		// Comment belongs to a task
+		edge.From("task", Task.Type).
+			Ref("comments").
+			Unique().
+			Required().
+			Immutable().
+			Field("task_id"),
+
+		// Comment is written by a user
+		edge.From("author", User.Type).
+			Ref("comments").
+			Unique().
+			Required().
+			Immutable().
+			Field("author_id"),
+	}
+}
+
+// Indexes of the Comment.
+func (Comment) Indexes() []ent.Index {
+	return []ent.Index{
+		// Listing a task's comments in chronological order
+		index.Fields("task_id", "created_at"),
+
+		// Index on author_id for cleanup when a user is deleted
+		index.Fields("author_id"),
+	}
+}
@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey holds the schema definition for processed CreateTask idempotency keys
+type IdempotencyKey struct {
+	ent.Schema
+}
+
+// Fields of the IdempotencyKey.
+func (IdempotencyKey) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).
+			Default(uuid.New).
+			Immutable(),
+
+		field.String("key").
+			NotEmpty().
+			MaxLen(255).
+			Immutable().
+			Comment("Client-supplied idempotency-key header value"),
+
+		field.UUID("user_id", uuid.UUID{}).
+			Immutable().
+			Comment("User who sent the request; keys are scoped per user"),
+
+		field.UUID("task_id", uuid.UUID{}).
+			Immutable().
+			Comment("Task created by the first request with this key"),
+
+		field.Time("expires_at").
+			Immutable().
+			Comment("After this the key may be reused and the row purged"),
+
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable(),
+	}
+}
+
+// Indexes of the IdempotencyKey.
+func (IdempotencyKey) Indexes() []ent.Index {
+	return []ent.Index{
+		// A key identifies one request per user
+		index.Fields("user_id", "key").Unique(),
+		// Index on expires_at for cleanup of expired entries
+		index.Fields("expires_at"),
+	}
+}
@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// Invite holds the schema definition for an admin-issued invitation. The
+// invited person registers through the emailed link and gets the invite's
+// role and a verified email.
+type Invite struct {
+	ent.Schema
+}
+
+// Fields of the Invite.
+func (Invite) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).
+			Default(uuid.New).
+			Immutable(),
+
+		field.String("email").
+			NotEmpty().
+			MaxLen(255).
+			Immutable().
+			Comment("Address the invite was sent to; the account is created with it"),
+
+		field.Enum("role").
+			Values("user", "admin", "manager").
+			Default("user").
+			Immutable().
+			Comment("Role given to the account created from the invite"),
+
+		field.String("token_hash").
+			NotEmpty().
+			Unique().
+			Sensitive().
+			Comment("SHA-256 hash of the invite token"),
+
+		field.UUID("invited_by", uuid.UUID{}).
+			Immutable().
+			Comment("Admin who created the invite"),
+
+		field.Time("expires_at").
+			Immutable().
+			Comment("When the invite stops being accepted"),
+
+		field.Bool("used").
+			Default(false).
+			Comment("Whether an account has been created from the invite"),
+
+		field.Time("used_at").
+			Optional().
+			Nillable().
+			Comment("When the invite was accepted"),
+
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("When the invite was created"),
+	}
+}
+
+// Indexes of the Invite.
+func (Invite) Indexes() []ent.Index {
+	return []ent.Index{
+		// Finding pending invites for an address
+		index.Fields("email", "used"),
+	}
+}
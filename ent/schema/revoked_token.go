@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// RevokedToken holds the schema definition for blacklisted JWTs
+type RevokedToken struct {
+	ent.Schema
+}
+
+// Fields of the RevokedToken.
+func (RevokedToken) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).
+			Default(uuid.New).
+			Immutable(),
+
+		field.String("jti").
+			NotEmpty().
+			Unique().
+			Immutable().
+			Comment("JWT ID claim of the revoked token"),
+
+		field.Time("expires_at").
+			Immutable().
+			Comment("When the revoked token would have expired; the row can be purged afterwards"),
+
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("When the token was revoked"),
+	}
+}
+
+// Indexes of the RevokedToken.
+func (RevokedToken) Indexes() []ent.Index {
+	return []ent.Index{
+		// Index on expires_at for cleanup of expired entries
+		index.Fields("expires_at"),
+	}
+}
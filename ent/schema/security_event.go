@@ -23,7 +23,8 @@ func (SecurityEvent) Fields() []ent.Field {
 			Immutable(),
 
 		field.UUID("user_id", uuid.UUID{}).
-			Comment("User who triggered the event"),
+			Optional().
+			Comment("User who triggered the event; unset for system events"),
 
 		field.Enum("event_type").
 			Values(
@@ -77,11 +78,10 @@ func (SecurityEvent) Fields() []ent.Field {
 // Edges of the SecurityEvent.
 func (SecurityEvent) Edges() []ent.Edge {
 	return []ent.Edge{
-		// Security event belongs to a user
+		// Security event belongs to a user, unless it is a system event
 		edge.From("user", User.Type).
 			Ref("security_events").
 			Unique().
-			Required().
 			Field("user_id"),
 	}
 }
@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// Session holds the schema definition for a signed-in device. Each session
+// owns one refresh token, which is rotated on every refresh.
+type Session struct {
+	ent.Schema
+}
+
+// Fields of the Session.
+func (Session) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).
+			Default(uuid.New).
+			Immutable(),
+
+		field.UUID("user_id", uuid.UUID{}).
+			Immutable().
+			Comment("User signed in by this session"),
+
+		field.String("refresh_token_hash").
+			NotEmpty().
+			Unique().
+			Sensitive().
+			Comment("SHA-256 hash of the session's current refresh token"),
+
+		field.String("ip_address").
+			Optional().
+			Comment("IP address the session was created from"),
+
+		field.String("user_agent").
+			Optional().
+			Comment("User agent the session was created from"),
+
+		field.Time("expires_at").
+			Comment("When the current refresh token expires"),
+
+		field.Time("last_used_at").
+			Default(time.Now).
+			Comment("When the session was created or last refreshed"),
+
+		field.Time("last_activity_at").
+			Optional().
+			Nillable().
+			Comment("Last authenticated request, for the idle session timeout"),
+
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable().
+			Comment("When the user signed in"),
+	}
+}
+
+// Edges of the Session.
+func (Session) Edges() []ent.Edge {
+	return []ent.Edge{
+		// Session belongs to a user
+		edge.From("owner", User.Type).
+			Ref("sessions").
+			Unique().
+			Required().
+			Immutable().
+			Field("user_id"),
+	}
+}
+
+// Indexes of the Session.
+func (Session) Indexes() []ent.Index {
+	return []ent.Index{
+		// Listing and revoking a user's sessions
+		index.Fields("user_id"),
+
+		// Cleanup of expired sessions
+		index.Fields("expires_at"),
+	}
+}
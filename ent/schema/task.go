@@ -61,6 +61,16 @@ func (Task) Fields() []ent.Field {
 			Default(map[string]interface{}{}).
 			Comment("Additional metadata for the task"),
 
+		field.UUID("parent_id", uuid.UUID{}).
+			Optional().
+			Nillable().
+			Comment("Parent task when this task is a subtask"),
+
+		field.Int("version").
+			Default(1).
+			Positive().
+			Comment("Incremented on every update for optimistic concurrency control"),
+
 		field.Time("created_at").
 			Default(time.Now).
 			Immutable().
@@ -70,6 +80,11 @@ func (Task) Fields() []ent.Field {
 			Default(time.Now).
 			UpdateDefault(time.Now).
 			Comment("When the task was last updated"),
+
+		field.Time("deleted_at").
+			Optional().
+			Nillable().
+			Comment("When the task was soft-deleted; nil for active tasks"),
 	}
 }
 
@@ -92,7 +107,16 @@ func (Task) Edges() []ent.Edge {
 		edge.To("subtasks", Task.Type).
 			From("parent").
 			Unique().
+			Field("parent_id").
 			Comment("Subtasks of this task"),
+
+		// Comments posted on this task
+		edge.To("comments", Comment.Type).
+			Comment("Comments on this task"),
+
+		// Change history of this task
+		edge.To("activities", TaskActivity.Type).
+			Comment("Activity log entries for this task"),
 	}
 }
 
@@ -116,5 +140,11 @@ func (Task) Indexes() []ent.Index {
 
 		// Index on due_date for deadline queries
 		index.Fields("due_date"),
+
+		// Index on deleted_at to exclude soft-deleted tasks
+		index.Fields("deleted_at"),
+
+		// Index on parent_id for listing subtasks
+		index.Fields("parent_id"),
 	}
 }
@@ -0,0 +1,84 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// TaskActivity holds the schema definition for the task change history
+type TaskActivity struct {
+	ent.Schema
+}
+
+// Fields of the TaskActivity.
+func (TaskActivity) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).
+			Default(uuid.New).
+			Immutable(),
+
+		field.UUID("task_id", uuid.UUID{}).
+			Immutable().
+			Comment("Task that changed"),
+
+		field.UUID("actor_id", uuid.UUID{}).
+			Optional().
+			Nillable().
+			Comment("User who made the change; cleared if the user is deleted"),
+
+		field.Enum("action").
+			Values("created", "updated", "deleted", "restored").
+			Immutable(),
+
+		field.String("changed_field").
+			StorageKey("field").
+			Optional().
+			Immutable().
+			Comment("Changed field for update entries"),
+
+		field.Text("old_value").
+			Optional().
+			Immutable(),
+
+		field.Text("new_value").
+			Optional().
+			Immutable(),
+
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable(),
+	}
+}
+
+// Edges of the TaskActivity.
+func (TaskActivity) Edges() []ent.Edge {
+	return []ent.Edge{
+		// Activity belongs to a task
+		edge.From("task", Task.Type).
+			Ref("activities").
+			Unique().
+			Required().
+			Immutable().
+			Field("task_id"),
+
+		// Activity was performed by a user
+		edge.From("actor", User.Type).
+			Ref("task_activities").
+			Unique().
+			Field("actor_id"),
+	}
+}
+
+// Indexes of the TaskActivity.
+func (TaskActivity) Indexes() []ent.Index {
+	return []ent.Index{
+		// History is read per task in chronological order
+		index.Fields("task_id", "created_at"),
+		index.Fields("actor_id"),
+	}
+}
@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+	"github.com/google/uuid"
+)
+
+// TaskTemplate holds the defaults used to pre-fill new tasks
+type TaskTemplate struct {
+	ent.Schema
+}
+
+// Fields of the TaskTemplate.
+func (TaskTemplate) Fields() []ent.Field {
+	return []ent.Field{
+		field.UUID("id", uuid.UUID{}).
+			Default(uuid.New).
+			Immutable(),
+
+		field.UUID("owner_id", uuid.UUID{}).
+			Immutable().
+			Comment("User who created the template"),
+
+		field.String("name").
+			NotEmpty().
+			Comment("Display name of the template"),
+
+		field.String("title_pattern").
+			NotEmpty().
+			Comment("Title for new tasks; {placeholders} are filled in when a task is created"),
+
+		field.Text("description").
+			Optional().
+			Default("").
+			Comment("Default description for new tasks"),
+
+		field.Enum("priority").
+			Values("low", "medium", "high", "critical").
+			Default("medium").
+			Comment("Default priority for new tasks"),
+
+		field.JSON("tags", []string{}).
+			Optional().
+			Default([]string{}).
+			Comment("Default tags for new tasks"),
+
+		field.Bool("shared").
+			Default(false).
+			Comment("Whether other users may view and use the template"),
+
+		field.Time("created_at").
+			Default(time.Now).
+			Immutable(),
+
+		field.Time("updated_at").
+			Default(time.Now).
+			UpdateDefault(time.Now),
+	}
+}
+
+// Edges of the TaskTemplate.
+func (TaskTemplate) Edges() []ent.Edge {
+	return []ent.Edge{
+		// Template belongs to the user who created it
+		edge.From("owner", User.Type).
+			Ref("task_templates").
+			Unique().
+			Required().
+			Immutable().
+			Field("owner_id"),
+	}
+}
+
+// Indexes of the TaskTemplate.
+func (TaskTemplate) Indexes() []ent.Index {
+	return []ent.Index{
+		// Listing a user's templates by name
+		index.Fields("owner_id", "name"),
+
+		// Listing shared templates
+		index.Fields("shared"),
+	}
+}
@@ -36,9 +36,9 @@ func (User) Fields() []ent.Field {
 			Comment("Unique username"),
 
 		field.String("password_hash").
-			NotEmpty().
+			Optional().
 			Sensitive(). // Won't be included in logs
-			Comment("Hashed password"),
+			Comment("Hashed password; empty for accounts created through OIDC login"),
 
 		field.String("first_name").
 			Optional().
@@ -69,7 +69,7 @@ func (User) Fields() []ent.Field {
 		field.String("email_verification_token").
 			Optional().
 			Sensitive().
-			Comment("Token for email verification"),
+			Comment("SHA-256 hash of the email verification token"),
 
 		field.Time("email_verification_expires_at").
 			Optional().
@@ -80,11 +80,27 @@ func (User) Fields() []ent.Field {
 			Default(0).
 			Comment("Number of email verification attempts"),
 
+		// Email Change
+		field.String("pending_email").
+			Optional().
+			Nillable().
+			Comment("New email address awaiting confirmation"),
+
+		field.String("email_change_token").
+			Optional().
+			Sensitive().
+			Comment("SHA-256 hash of the token confirming the pending email change"),
+
+		field.Time("email_change_expires_at").
+			Optional().
+			Nillable().
+			Comment("Email change token expiration"),
+
 		// Password Reset - Phase 2
 		field.String("password_reset_token").
 			Optional().
 			Sensitive().
-			Comment("Token for password reset"),
+			Comment("SHA-256 hash of the password reset token"),
 
 		field.Time("password_reset_expires_at").
 			Optional().
@@ -105,6 +121,11 @@ func (User) Fields() []ent.Field {
 			Default(0).
 			Comment("Number of consecutive failed login attempts"),
 
+		field.Time("last_failed_login_at").
+			Optional().
+			Nillable().
+			Comment("When the most recent failed login attempt happened"),
+
 		field.Time("account_locked_until").
 			Optional().
 			Nillable().
@@ -119,21 +140,45 @@ func (User) Fields() []ent.Field {
 			Optional().
 			Comment("IP address of last login"),
 
+		field.Strings("recent_login_ips").
+			Optional().
+			Comment("IP addresses of recent successful logins, most recent first"),
+
 		field.Time("password_changed_at").
 			Optional().
 			Nillable().
 			Comment("When password was last changed"),
 
-		// JWT Tokens
-		field.String("refresh_token").
+		field.Strings("password_history").
 			Optional().
 			Sensitive().
-			Comment("Current refresh token"),
+			Comment("Hashes of previous passwords, most recent first"),
 
-		field.Time("refresh_token_expires_at").
+		// OIDC Login
+		field.String("oidc_issuer").
 			Optional().
 			Nillable().
-			Comment("Refresh token expiration"),
+			Comment("Issuer of the linked OIDC identity"),
+
+		field.String("oidc_subject").
+			Optional().
+			Nillable().
+			Comment("Subject of the linked OIDC identity at its issuer"),
+
+		// Two-Factor Authentication
+		field.String("totp_secret").
+			Optional().
+			Sensitive().
+			Comment("Base32-encoded TOTP shared secret"),
+
+		field.Bool("totp_enabled").
+			Default(false).
+			Comment("Whether TOTP two-factor authentication is enabled"),
+
+		field.Strings("totp_backup_codes").
+			Optional().
+			Sensitive().
+			Comment("Hashes of unused TOTP backup codes"),
 
 		// User Preferences
 		field.JSON("preferences", map[string]interface{}{}).
@@ -155,6 +200,12 @@ func (User) Fields() []ent.Field {
 			Default(map[string]interface{}{}).
 			Comment("Detailed notification preferences"),
 
+		field.String("locale").
+			Optional().
+			Default("").
+			MaxLen(35).
+			Comment("Preferred BCP 47 language tag for emails; empty means English"),
+
 		// Timestamps
 		field.Time("created_at").
 			Default(time.Now).
@@ -182,6 +233,26 @@ func (User) Edges() []ent.Edge {
 		// Security events - Phase 2
 		edge.To("security_events", SecurityEvent.Type).
 			Comment("Security events related to this user"),
+
+		// A user can comment on many tasks
+		edge.To("comments", Comment.Type).
+			Comment("Comments written by this user"),
+
+		// Task changes made by this user
+		edge.To("task_activities", TaskActivity.Type).
+			Comment("Task activity log entries recorded for this user"),
+
+		// API keys for non-interactive clients
+		edge.To("api_keys", ApiKey.Type).
+			Comment("API keys that authenticate as this user"),
+
+		// Signed-in devices, each with its own refresh token
+		edge.To("sessions", Session.Type).
+			Comment("Sessions in which this user is signed in"),
+
+		// Reusable defaults for creating tasks
+		edge.To("task_templates", TaskTemplate.Type).
+			Comment("Task templates owned by this user"),
 	}
 }
 
@@ -210,6 +281,14 @@ func (User) Indexes() []ent.Index {
 		index.Fields("password_reset_token").
 			Unique(),
 
+		// Index for email change confirmation
+		index.Fields("email_change_token").
+			Unique(),
+
+		// One account per OIDC identity
+		index.Fields("oidc_issuer", "oidc_subject").
+			Unique(),
+
 		// Index for account security
 		index.Fields("account_locked_until"),
 
@@ -3,12 +3,17 @@ package config
 
 import (
 	"fmt"
+	"net/mail"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gurkanbulca/taskmaster/internal/middleware"
+	"github.com/gurkanbulca/taskmaster/pkg/auth"
 	"github.com/gurkanbulca/taskmaster/pkg/email"
+	"github.com/gurkanbulca/taskmaster/pkg/tracing"
 )
 
 type Config struct {
@@ -18,6 +23,9 @@ type Config struct {
 	Email      EmailConfig      // Phase 2
 	Security   SecurityConfig   // Phase 2
 	Validation ValidationConfig // Phase 2
+	Tracing    TracingConfig
+	OIDC       OIDCConfig
+	Captcha    CaptchaConfig
 }
 
 type ServerConfig struct {
@@ -28,6 +36,30 @@ type ServerConfig struct {
 	AutoMigrate      bool
 	EnableReflection bool
 	EnableDebugLogs  bool
+	MaxRecvMsgSize   int // Largest gRPC message the server accepts, in bytes
+
+	RequestTimeout    time.Duration            // Default time limit for unary RPCs; 0 disables it
+	MethodTimeouts    map[string]time.Duration // Full gRPC method name -> time limit overriding RequestTimeout
+	IdempotencyKeyTTL time.Duration            // How long a CreateTask idempotency key is honored
+	GetMeCacheTTL     time.Duration            // How long GetMe responses are cached per user; 0 disables the cache
+	CleanupInterval   time.Duration            // How often the background cleanup job runs
+
+	SharedTaskTemplates bool // Whether users may share task templates with everyone
+
+	CORSAllowedOrigins []string // Browser origins allowed to call the HTTP gateway; "*" allows any
+
+	// TLS for the gRPC listener, enabled when TLSCertFile is set
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string // When set, clients must present a certificate signed by this CA (mTLS)
+
+	// Client certificate the REST gateway presents to the gRPC listener under mTLS
+	TLSGatewayCertFile string
+	TLSGatewayKeyFile  string
+
+	// Readiness probe settings
+	HealthCheckInterval time.Duration
+	HealthCheckTimeout  time.Duration
 }
 
 type DatabaseConfig struct {
@@ -37,6 +69,13 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	QueryTimeout    time.Duration // Default per-statement timeout; 0 disables it
+
+	TaskSearchMode string // How task search matches: contains or fulltext
 }
 
 type JWTConfig struct {
@@ -44,6 +83,45 @@ type JWTConfig struct {
 	RefreshSecret        string
 	AccessTokenDuration  time.Duration
 	RefreshTokenDuration time.Duration
+	RememberMeDuration   time.Duration // Refresh token lifetime of logins with remember_me
+	Issuer               string        // "iss" claim of issued tokens
+	Audience             string        // "aud" claim of issued tokens
+	Leeway               time.Duration // Clock skew tolerated on the "exp" and "nbf" claims
+	RefreshThreshold     time.Duration // Remaining lifetime below which responses carry x-token-expiring; 0 disables
+}
+
+// OIDCConfig configures LoginWithOIDC; it is disabled when ClientID is empty
+type OIDCConfig struct {
+	Issuer   string // Expected "iss" claim of ID tokens
+	ClientID string // Expected "aud" claim of ID tokens
+	JWKSURL  string // Where the provider publishes its signing keys
+}
+
+// Enabled reports whether OIDC login is configured
+func (c OIDCConfig) Enabled() bool {
+	return c.ClientID != ""
+}
+
+// CaptchaConfig configures reCAPTCHA checks on Register and
+// RequestPasswordReset; they are skipped when SecretKey is empty
+type CaptchaConfig struct {
+	SecretKey string  // reCAPTCHA secret key of the site
+	VerifyURL string  // Token verification endpoint
+	MinScore  float64 // Lowest reCAPTCHA v3 score accepted
+}
+
+// Enabled reports whether CAPTCHA verification is configured
+func (c CaptchaConfig) Enabled() bool {
+	return c.SecretKey != ""
+}
+
+// TracingConfig controls OpenTelemetry span export
+type TracingConfig struct {
+	Enabled      bool
+	OTLPEndpoint string // OTLP/gRPC collector address
+	OTLPInsecure bool   // Connect to the collector without TLS
+	ServiceName  string
+	SampleRatio  float64 // Fraction of new traces to sample, 0 to 1
 }
 
 // Phase 2: Email Configuration
@@ -58,25 +136,73 @@ type EmailConfig struct {
 	AppName      string
 	SupportEmail string
 	TestingMode  bool
+	TemplateDir  string
+	BCC          []string
 
 	// Email token settings
 	VerificationTokenDuration  time.Duration
 	PasswordResetTokenDuration time.Duration
 	RateLimitPerHour           int
+
+	// Delivery retry settings
+	SendMaxAttempts    int
+	SendRetryBaseDelay time.Duration
+	SendRetryMaxDelay  time.Duration
+
+	// Background delivery queue settings
+	QueueWorkers int
+	QueueSize    int
+
+	HealthCritical bool // Report the server NOT_SERVING while SMTP is unreachable
 }
 
 // Phase 2: Security Configuration
 type SecurityConfig struct {
 	MaxLoginAttempts             int           // Max failed login attempts before lockout
 	AccountLockoutDuration       time.Duration // How long to lock the account
-	MaxEmailVerificationAttempts int
+	MaxEmailVerificationAttempts int           // Verification emails sent before further sends are refused
+	EmailVerificationResendWait  time.Duration // Minimum time between verification emails
 	MaxPasswordResetAttempts     int
 	PasswordResetRateLimit       time.Duration
 	EnableSecurityNotifications  bool
 	RequireEmailVerification     bool
 	SessionTimeoutDuration       time.Duration
+	LoginRateLimitAttempts       int           // Max login attempts per IP within the rate limit window
+	LoginRateLimitWindow         time.Duration // Sliding window for per-IP login rate limiting
+	APIKeyRateLimit              int           // Max requests per minute for each API key
+	IntrospectionRateLimit       int           // Max IntrospectToken calls per minute per IP
+	PasswordHistorySize          int           // Previous passwords that cannot be reused, besides the current one
+	MaxPasswordAge               time.Duration // Passwords older than this are flagged as expired on login (0 disables)
+	HideRegistrationConflicts    bool          // Don't reveal through Register whether an email is registered
+	FailedLoginWindow            time.Duration // Failed attempts further apart than this start a new count (0 counts until a successful login)
+	DetectNewLoginIP             bool          // Record a suspicious activity event when a login comes from an unfamiliar IP
+	LoginIPHistorySize           int           // Recent login IPs remembered per user
+	PasswordHashAlgorithm        string        // Algorithm for new hashes: bcrypt or argon2id
+	BcryptCost                   int           // Work factor for bcrypt hashes
+	Argon2Memory                 uint32        // Argon2id memory in KiB
+	Argon2Iterations             uint32
+	Argon2Parallelism            uint8
+	DeletedUserTaskPolicy        string              // What happens to a deleted user's tasks: delete or orphan
+	MethodRoles                  map[string][]string // Full gRPC method name -> roles allowed to call it
+	SecurityEventRetention       time.Duration       // Security events older than this are purged by the cleanup job
+	RetainUnresolvedHighSeverity bool                // Keep unresolved high/critical events past the retention window
 }
 
+// validRoles lists the roles accepted in MethodRoles
+var validRoles = map[string]bool{"user": true, "manager": true, "admin": true}
+
+// Policies for tasks created by a user who deletes their account
+const (
+	DeletedUserTasksDelete = "delete"
+	DeletedUserTasksOrphan = "orphan"
+)
+
+// Task search modes: substring matching, or PostgreSQL full-text search
+const (
+	TaskSearchContains = "contains"
+	TaskSearchFullText = "fulltext"
+)
+
 // Phase 2: Validation Configuration
 type ValidationConfig struct {
 	MinPasswordLength      int
@@ -84,12 +210,16 @@ type ValidationConfig struct {
 	RequirePasswordLower   bool
 	RequirePasswordNumber  bool
 	RequirePasswordSpecial bool
+	MinPasswordScore       int
 	MinUsernameLength      int
 	MaxUsernameLength      int
 	MaxEmailLength         int
 	MaxNameLength          int
 	MaxDescriptionLength   int
 	MaxTitleLength         int
+	MaxTagsBytes           int
+	MaxMetadataBytes       int
+	MaxCommentLength       int
 }
 
 func Load() (*Config, error) {
@@ -102,6 +232,27 @@ func Load() (*Config, error) {
 			AutoMigrate:      getEnvAsBool("AUTO_MIGRATE", true),
 			EnableReflection: getEnvAsBool("ENABLE_REFLECTION", true),
 			EnableDebugLogs:  getEnvAsBool("ENABLE_DEBUG_LOGS", true),
+			MaxRecvMsgSize:   getEnvAsInt("GRPC_MAX_RECV_MSG_SIZE", 1024*1024),
+
+			RequestTimeout:    getEnvAsDuration("REQUEST_TIMEOUT", 30*time.Second),
+			MethodTimeouts:    getEnvAsMethodDurations("METHOD_TIMEOUTS"),
+			IdempotencyKeyTTL: getEnvAsDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+			GetMeCacheTTL:     getEnvAsDuration("GETME_CACHE_TTL", 0),
+			CleanupInterval:   getEnvAsDuration("CLEANUP_INTERVAL", 1*time.Hour),
+
+			SharedTaskTemplates: getEnvAsBool("SHARED_TASK_TEMPLATES", true),
+
+			CORSAllowedOrigins: getEnvAsSlice("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
+
+			TLSCertFile:     getEnv("GRPC_TLS_CERT_FILE", ""),
+			TLSKeyFile:      getEnv("GRPC_TLS_KEY_FILE", ""),
+			TLSClientCAFile: getEnv("GRPC_TLS_CLIENT_CA_FILE", ""),
+
+			TLSGatewayCertFile: getEnv("GRPC_TLS_GATEWAY_CERT_FILE", ""),
+			TLSGatewayKeyFile:  getEnv("GRPC_TLS_GATEWAY_KEY_FILE", ""),
+
+			HealthCheckInterval: getEnvAsDuration("HEALTH_CHECK_INTERVAL", 10*time.Second),
+			HealthCheckTimeout:  getEnvAsDuration("HEALTH_CHECK_TIMEOUT", 2*time.Second),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -110,12 +261,24 @@ func Load() (*Config, error) {
 			Password: getEnv("DB_PASSWORD", "postgres"),
 			DBName:   getEnv("DB_NAME", "taskmaster"),
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+
+			MaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+			QueryTimeout:    getEnvAsDuration("DB_QUERY_TIMEOUT", 30*time.Second),
+
+			TaskSearchMode: getEnv("TASK_SEARCH_MODE", TaskSearchContains),
 		},
 		JWT: JWTConfig{
 			AccessSecret:         getEnv("JWT_ACCESS_SECRET", getEnv("JWT_SECRET", "dev-access-secret-change-in-production")),
 			RefreshSecret:        getEnv("JWT_REFRESH_SECRET", getEnv("JWT_SECRET", "dev-refresh-secret-change-in-production")),
 			AccessTokenDuration:  getEnvAsDuration("JWT_ACCESS_TOKEN_DURATION", 15*time.Minute),
 			RefreshTokenDuration: getEnvAsDuration("JWT_REFRESH_TOKEN_DURATION", 7*24*time.Hour),
+			RememberMeDuration:   getEnvAsDuration("JWT_REMEMBER_ME_DURATION", 30*24*time.Hour),
+			Issuer:               getEnv("JWT_ISSUER", auth.DefaultTokenIssuer),
+			Audience:             getEnv("JWT_AUDIENCE", auth.DefaultTokenAudience),
+			Leeway:               getEnvAsDuration("JWT_LEEWAY", auth.DefaultTokenLeeway),
+			RefreshThreshold:     getEnvAsDuration("JWT_REFRESH_THRESHOLD", auth.DefaultRefreshThreshold),
 		},
 		// Phase 2: Email Configuration
 		Email: EmailConfig{
@@ -129,21 +292,52 @@ func Load() (*Config, error) {
 			AppName:      getEnv("APP_NAME", "TaskMaster"),
 			SupportEmail: getEnv("SUPPORT_EMAIL", "support@taskmaster.com"),
 			TestingMode:  getEnvAsBool("EMAIL_TESTING_MODE", false),
+			TemplateDir:  getEnv("EMAIL_TEMPLATE_DIR", ""),
+			BCC:          getEnvAsSlice("EMAIL_BCC", nil),
 
 			VerificationTokenDuration:  getEnvAsDuration("EMAIL_VERIFICATION_TOKEN_DURATION", 24*time.Hour),
 			PasswordResetTokenDuration: getEnvAsDuration("PASSWORD_RESET_TOKEN_DURATION", 1*time.Hour),
 			RateLimitPerHour:           getEnvAsInt("EMAIL_RATE_LIMIT_PER_HOUR", 5),
+
+			SendMaxAttempts:    getEnvAsInt("EMAIL_SEND_MAX_ATTEMPTS", 3),
+			SendRetryBaseDelay: getEnvAsDuration("EMAIL_SEND_RETRY_BASE_DELAY", 1*time.Second),
+			SendRetryMaxDelay:  getEnvAsDuration("EMAIL_SEND_RETRY_MAX_DELAY", 30*time.Second),
+
+			QueueWorkers: getEnvAsInt("EMAIL_QUEUE_WORKERS", 4),
+			QueueSize:    getEnvAsInt("EMAIL_QUEUE_SIZE", 100),
+
+			HealthCritical: getEnvAsBool("EMAIL_HEALTH_CRITICAL", false),
 		},
 		// Phase 2: Security Configuration with configurable failed attempts and lockout duration
 		Security: SecurityConfig{
 			MaxLoginAttempts:             getEnvAsInt("MAX_LOGIN_ATTEMPTS", 5),
 			AccountLockoutDuration:       getEnvAsDuration("ACCOUNT_LOCKOUT_DURATION", 15*time.Minute),
 			MaxEmailVerificationAttempts: getEnvAsInt("MAX_EMAIL_VERIFICATION_ATTEMPTS", 5),
+			EmailVerificationResendWait:  getEnvAsDuration("EMAIL_VERIFICATION_RESEND_WAIT", time.Hour),
 			MaxPasswordResetAttempts:     getEnvAsInt("MAX_PASSWORD_RESET_ATTEMPTS", 5),
 			PasswordResetRateLimit:       getEnvAsDuration("PASSWORD_RESET_RATE_LIMIT", 15*time.Minute),
 			EnableSecurityNotifications:  getEnvAsBool("ENABLE_SECURITY_NOTIFICATIONS", true),
 			RequireEmailVerification:     getEnvAsBool("REQUIRE_EMAIL_VERIFICATION", false),
 			SessionTimeoutDuration:       getEnvAsDuration("SESSION_TIMEOUT_DURATION", 30*24*time.Hour),
+			LoginRateLimitAttempts:       getEnvAsInt("LOGIN_RATE_LIMIT_ATTEMPTS", 10),
+			LoginRateLimitWindow:         getEnvAsDuration("LOGIN_RATE_LIMIT_WINDOW", 1*time.Minute),
+			APIKeyRateLimit:              getEnvAsInt("API_KEY_RATE_LIMIT", 600),
+			IntrospectionRateLimit:       getEnvAsInt("INTROSPECTION_RATE_LIMIT", 300),
+			PasswordHistorySize:          getEnvAsInt("PASSWORD_HISTORY_SIZE", 5),
+			MaxPasswordAge:               getEnvAsDuration("MAX_PASSWORD_AGE", 0),
+			FailedLoginWindow:            getEnvAsDuration("FAILED_LOGIN_WINDOW", time.Hour),
+			HideRegistrationConflicts:    getEnvAsBool("HIDE_REGISTRATION_CONFLICTS", false),
+			DetectNewLoginIP:             getEnvAsBool("DETECT_NEW_LOGIN_IP", true),
+			LoginIPHistorySize:           getEnvAsInt("LOGIN_IP_HISTORY_SIZE", 5),
+			PasswordHashAlgorithm:        getEnv("PASSWORD_HASH_ALGORITHM", auth.HashAlgorithmBcrypt),
+			BcryptCost:                   getEnvAsInt("BCRYPT_COST", auth.DefaultBcryptCost),
+			Argon2Memory:                 uint32(getEnvAsInt("ARGON2_MEMORY_KIB", 64*1024)),
+			Argon2Iterations:             uint32(getEnvAsInt("ARGON2_ITERATIONS", 3)),
+			Argon2Parallelism:            uint8(getEnvAsInt("ARGON2_PARALLELISM", 2)),
+			DeletedUserTaskPolicy:        getEnv("DELETED_USER_TASK_POLICY", DeletedUserTasksOrphan),
+			MethodRoles:                  getEnvAsMethodRoles("METHOD_ROLES"),
+			SecurityEventRetention:       getEnvAsDuration("SECURITY_EVENT_RETENTION", 90*24*time.Hour),
+			RetainUnresolvedHighSeverity: getEnvAsBool("RETAIN_UNRESOLVED_HIGH_SEVERITY_EVENTS", true),
 		},
 		// Phase 2: Validation Configuration
 		Validation: ValidationConfig{
@@ -152,12 +346,33 @@ func Load() (*Config, error) {
 			RequirePasswordLower:   getEnvAsBool("REQUIRE_PASSWORD_LOWER", true),
 			RequirePasswordNumber:  getEnvAsBool("REQUIRE_PASSWORD_NUMBER", true),
 			RequirePasswordSpecial: getEnvAsBool("REQUIRE_PASSWORD_SPECIAL", false),
+			MinPasswordScore:       getEnvAsInt("MIN_PASSWORD_SCORE", 2),
 			MinUsernameLength:      getEnvAsInt("MIN_USERNAME_LENGTH", 3),
 			MaxUsernameLength:      getEnvAsInt("MAX_USERNAME_LENGTH", 50),
 			MaxEmailLength:         getEnvAsInt("MAX_EMAIL_LENGTH", 255),
 			MaxNameLength:          getEnvAsInt("MAX_NAME_LENGTH", 100),
 			MaxDescriptionLength:   getEnvAsInt("MAX_DESCRIPTION_LENGTH", 5000),
 			MaxTitleLength:         getEnvAsInt("MAX_TITLE_LENGTH", 200),
+			MaxTagsBytes:           getEnvAsInt("MAX_TAGS_BYTES", 1024),
+			MaxMetadataBytes:       getEnvAsInt("MAX_METADATA_BYTES", 16*1024),
+			MaxCommentLength:       getEnvAsInt("MAX_COMMENT_LENGTH", 2000),
+		},
+		Tracing: TracingConfig{
+			Enabled:      getEnvAsBool("TRACING_ENABLED", false),
+			OTLPEndpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"),
+			OTLPInsecure: getEnvAsBool("OTEL_EXPORTER_OTLP_INSECURE", true),
+			ServiceName:  getEnv("OTEL_SERVICE_NAME", "taskmaster"),
+			SampleRatio:  getEnvAsFloat("TRACING_SAMPLE_RATIO", 1.0),
+		},
+		OIDC: OIDCConfig{
+			Issuer:   getEnv("OIDC_ISSUER", "https://accounts.google.com"),
+			ClientID: getEnv("OIDC_CLIENT_ID", ""),
+			JWKSURL:  getEnv("OIDC_JWKS_URL", "https://www.googleapis.com/oauth2/v3/certs"),
+		},
+		Captcha: CaptchaConfig{
+			SecretKey: getEnv("RECAPTCHA_SECRET_KEY", ""),
+			VerifyURL: getEnv("RECAPTCHA_VERIFY_URL", auth.DefaultRecaptchaVerifyURL),
+			MinScore:  getEnvAsFloat("RECAPTCHA_MIN_SCORE", 0.5),
 		},
 	}, nil
 }
@@ -174,9 +389,24 @@ func (c *Config) ToEmailConfig() *email.Config {
 		BaseURL:      c.Email.BaseURL,
 		AppName:      c.Email.AppName,
 		SupportEmail: c.Email.SupportEmail,
+		TemplateDir:  c.Email.TemplateDir,
+		BCC:          c.Email.BCC,
+
+		SendMaxAttempts:    c.Email.SendMaxAttempts,
+		SendRetryBaseDelay: c.Email.SendRetryBaseDelay,
+		SendRetryMaxDelay:  c.Email.SendRetryMaxDelay,
 	}
 }
 
+// ToArgon2Params converts config to Argon2id hashing parameters
+func (c *Config) ToArgon2Params() auth.Argon2Params {
+	params := auth.DefaultArgon2Params()
+	params.Memory = c.Security.Argon2Memory
+	params.Iterations = c.Security.Argon2Iterations
+	params.Parallelism = c.Security.Argon2Parallelism
+	return params
+}
+
 // ToValidationConfig converts config to validation middleware config
 func (c *Config) ToValidationConfig() *middleware.ValidationConfig {
 	return &middleware.ValidationConfig{
@@ -185,12 +415,42 @@ func (c *Config) ToValidationConfig() *middleware.ValidationConfig {
 		RequirePasswordLower:   c.Validation.RequirePasswordLower,
 		RequirePasswordNumber:  c.Validation.RequirePasswordNumber,
 		RequirePasswordSpecial: c.Validation.RequirePasswordSpecial,
+		MinPasswordScore:       c.Validation.MinPasswordScore,
 		MinUsernameLength:      c.Validation.MinUsernameLength,
 		MaxUsernameLength:      c.Validation.MaxUsernameLength,
 		MaxEmailLength:         c.Validation.MaxEmailLength,
 		MaxNameLength:          c.Validation.MaxNameLength,
 		MaxDescriptionLength:   c.Validation.MaxDescriptionLength,
 		MaxTitleLength:         c.Validation.MaxTitleLength,
+		MaxTagsBytes:           c.Validation.MaxTagsBytes,
+		MaxMetadataBytes:       c.Validation.MaxMetadataBytes,
+		MaxCommentLength:       c.Validation.MaxCommentLength,
+	}
+}
+
+// ToEmailQueueConfig converts config to email queue config
+func (c *Config) ToEmailQueueConfig() *email.QueueConfig {
+	queueConfig := email.DefaultQueueConfig()
+	queueConfig.Workers = c.Email.QueueWorkers
+	queueConfig.BufferSize = c.Email.QueueSize
+	return queueConfig
+}
+
+// ToLoginRateLimitConfig converts config to login rate limit middleware config
+func (c *Config) ToLoginRateLimitConfig() *middleware.LoginRateLimitConfig {
+	return &middleware.LoginRateLimitConfig{
+		MaxAttempts: c.Security.LoginRateLimitAttempts,
+		Window:      c.Security.LoginRateLimitWindow,
+	}
+}
+
+// ToTracingConfig converts config to tracer provider config
+func (c *Config) ToTracingConfig() tracing.Config {
+	return tracing.Config{
+		Endpoint:    c.Tracing.OTLPEndpoint,
+		Insecure:    c.Tracing.OTLPInsecure,
+		ServiceName: c.Tracing.ServiceName,
+		SampleRatio: c.Tracing.SampleRatio,
 	}
 }
 
@@ -204,6 +464,11 @@ func (c *Config) IsProduction() bool {
 	return c.Server.Environment == "production"
 }
 
+// TLSEnabled reports whether the gRPC listener serves TLS
+func (c ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != ""
+}
+
 // ValidateConfig validates the configuration
 func (c *Config) ValidateConfig() error {
 	if c.IsProduction() {
@@ -220,13 +485,109 @@ func (c *Config) ValidateConfig() error {
 		if c.Database.SSLMode != "require" {
 			return fmt.Errorf("database SSL must be required in production")
 		}
+
+		if slices.Contains(c.Server.CORSAllowedOrigins, "*") {
+			return fmt.Errorf("CORS must not allow every origin in production")
+		}
+
+		if !c.Server.TLSEnabled() {
+			return fmt.Errorf("gRPC TLS must be enabled in production")
+		}
 	}
 
 	// General validation
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		return fmt.Errorf("TLS cert and key files must be set together")
+	}
+
+	if c.Server.TLSClientCAFile != "" && !c.Server.TLSEnabled() {
+		return fmt.Errorf("TLS client CA requires a server certificate")
+	}
+
+	if (c.Server.TLSGatewayCertFile == "") != (c.Server.TLSGatewayKeyFile == "") {
+		return fmt.Errorf("TLS gateway cert and key files must be set together")
+	}
+
+	if c.Server.TLSClientCAFile != "" && c.Server.TLSGatewayCertFile == "" {
+		return fmt.Errorf("TLS client CA requires a gateway client certificate")
+	}
+
+	if c.JWT.Issuer == "" || c.JWT.Audience == "" {
+		return fmt.Errorf("JWT issuer and audience must be set")
+	}
+
+	if c.JWT.Leeway < 0 || c.JWT.Leeway > 5*time.Minute {
+		return fmt.Errorf("JWT leeway must be between 0 and 5 minutes")
+	}
+
+	if c.JWT.RefreshThreshold < 0 || c.JWT.RefreshThreshold >= c.JWT.AccessTokenDuration {
+		return fmt.Errorf("JWT refresh threshold must be between 0 and the access token duration")
+	}
+
+	if c.Server.HealthCheckInterval < 1*time.Second {
+		return fmt.Errorf("health check interval must be at least 1 second")
+	}
+
+	if c.Server.HealthCheckTimeout <= 0 || c.Server.HealthCheckTimeout > c.Server.HealthCheckInterval {
+		return fmt.Errorf("health check timeout must be positive and no longer than the interval")
+	}
+
+	if c.Database.MaxOpenConns < 1 {
+		return fmt.Errorf("database max open connections must be at least 1")
+	}
+
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		return fmt.Errorf("database max idle connections must be between 0 and max open connections")
+	}
+
+	if c.Database.ConnMaxLifetime < 0 || c.Database.QueryTimeout < 0 {
+		return fmt.Errorf("database connection lifetime and query timeout cannot be negative")
+	}
+
+	switch c.Database.TaskSearchMode {
+	case TaskSearchContains, TaskSearchFullText:
+	default:
+		return fmt.Errorf("task search mode must be %q or %q", TaskSearchContains, TaskSearchFullText)
+	}
+
 	if c.Validation.MinPasswordLength < 6 {
 		return fmt.Errorf("minimum password length cannot be less than 6")
 	}
 
+	if c.Server.MaxRecvMsgSize < 1024 {
+		return fmt.Errorf("gRPC max receive message size must be at least 1024 bytes")
+	}
+
+	if c.Server.RequestTimeout < 0 {
+		return fmt.Errorf("request timeout cannot be negative")
+	}
+
+	for method, timeout := range c.Server.MethodTimeouts {
+		if timeout < 0 {
+			return fmt.Errorf("timeout for method %s cannot be negative", method)
+		}
+	}
+
+	if c.Server.IdempotencyKeyTTL < 1*time.Minute {
+		return fmt.Errorf("idempotency key TTL must be at least 1 minute")
+	}
+
+	if c.Server.CleanupInterval < 1*time.Minute {
+		return fmt.Errorf("cleanup interval must be at least 1 minute")
+	}
+
+	if c.Validation.MaxTagsBytes < 0 || c.Validation.MaxMetadataBytes < 0 {
+		return fmt.Errorf("tags and metadata size limits cannot be negative")
+	}
+
+	if c.Validation.MaxCommentLength < 1 {
+		return fmt.Errorf("max comment length must be at least 1")
+	}
+
+	if c.Validation.MinPasswordScore < 0 || c.Validation.MinPasswordScore > 4 {
+		return fmt.Errorf("minimum password score must be between 0 and 4")
+	}
+
 	if c.Security.MaxLoginAttempts < 1 {
 		return fmt.Errorf("max login attempts must be at least 1")
 	}
@@ -235,6 +596,126 @@ func (c *Config) ValidateConfig() error {
 		return fmt.Errorf("account lockout duration must be at least 1 minute")
 	}
 
+	if c.Security.LoginRateLimitAttempts < 1 {
+		return fmt.Errorf("login rate limit attempts must be at least 1")
+	}
+
+	if c.Security.LoginRateLimitWindow < 1*time.Second {
+		return fmt.Errorf("login rate limit window must be at least 1 second")
+	}
+
+	if c.Security.APIKeyRateLimit < 1 {
+		return fmt.Errorf("API key rate limit must be at least 1 request per minute")
+	}
+
+	if c.Security.IntrospectionRateLimit < 1 {
+		return fmt.Errorf("introspection rate limit must be at least 1 request per minute")
+	}
+
+	if c.Security.PasswordHistorySize < 0 || c.Security.PasswordHistorySize > 24 {
+		return fmt.Errorf("password history size must be between 0 and 24")
+	}
+
+	if c.Security.MaxEmailVerificationAttempts < 1 {
+		return fmt.Errorf("max email verification attempts must be at least 1")
+	}
+
+	if c.Security.EmailVerificationResendWait <= 0 {
+		return fmt.Errorf("email verification resend wait must be positive")
+	}
+
+	if c.Security.FailedLoginWindow < 0 {
+		return fmt.Errorf("failed login window cannot be negative")
+	}
+
+	if c.Security.MaxPasswordAge < 0 {
+		return fmt.Errorf("max password age cannot be negative")
+	}
+
+	if c.Security.LoginIPHistorySize < 1 || c.Security.LoginIPHistorySize > 50 {
+		return fmt.Errorf("login IP history size must be between 1 and 50")
+	}
+
+	switch c.Security.PasswordHashAlgorithm {
+	case auth.HashAlgorithmBcrypt, auth.HashAlgorithmArgon2id:
+	default:
+		return fmt.Errorf("password hash algorithm must be %q or %q", auth.HashAlgorithmBcrypt, auth.HashAlgorithmArgon2id)
+	}
+
+	if err := auth.ValidateBcryptCost(c.Security.BcryptCost); err != nil {
+		return err
+	}
+
+	if c.Security.Argon2Memory < 8*1024 {
+		return fmt.Errorf("argon2 memory must be at least 8192 KiB")
+	}
+
+	if c.Security.Argon2Iterations < 1 {
+		return fmt.Errorf("argon2 iterations must be at least 1")
+	}
+
+	if c.Security.Argon2Parallelism < 1 {
+		return fmt.Errorf("argon2 parallelism must be at least 1")
+	}
+
+	if c.OIDC.Enabled() && (c.OIDC.Issuer == "" || c.OIDC.JWKSURL == "") {
+		return fmt.Errorf("OIDC issuer and JWKS URL are required when OIDC_CLIENT_ID is set")
+	}
+
+	if c.Captcha.MinScore < 0 || c.Captcha.MinScore > 1 {
+		return fmt.Errorf("reCAPTCHA minimum score must be between 0 and 1")
+	}
+
+	if c.Security.SecurityEventRetention < 24*time.Hour {
+		return fmt.Errorf("security event retention must be at least 24 hours")
+	}
+
+	switch c.Security.DeletedUserTaskPolicy {
+	case DeletedUserTasksDelete, DeletedUserTasksOrphan:
+	default:
+		return fmt.Errorf("deleted user task policy must be %q or %q", DeletedUserTasksDelete, DeletedUserTasksOrphan)
+	}
+
+	for method, roles := range c.Security.MethodRoles {
+		if len(roles) == 0 {
+			return fmt.Errorf("method %s must list at least one role", method)
+		}
+		for _, role := range roles {
+			if !validRoles[role] {
+				return fmt.Errorf("method %s has unknown role %q", method, role)
+			}
+		}
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be between 0 and 1")
+	}
+
+	for i, addr := range c.Email.BCC {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return fmt.Errorf("invalid email BCC address %q: %w", addr, err)
+		}
+		// The envelope takes a bare address, not "Name <addr>"
+		c.Email.BCC[i] = parsed.Address
+	}
+
+	if c.Email.SendMaxAttempts < 1 {
+		return fmt.Errorf("email send max attempts must be at least 1")
+	}
+
+	if c.Email.SendRetryMaxDelay < c.Email.SendRetryBaseDelay {
+		return fmt.Errorf("email send retry max delay cannot be less than the base delay")
+	}
+
+	if c.Email.QueueWorkers < 1 {
+		return fmt.Errorf("email queue workers must be at least 1")
+	}
+
+	if c.Email.QueueSize < 0 {
+		return fmt.Errorf("email queue size cannot be negative")
+	}
+
 	return nil
 }
 
@@ -255,6 +736,14 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 func getEnvAsBool(key string, defaultValue bool) bool {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
@@ -281,3 +770,60 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 
 	return defaultValue
 }
+
+// getEnvAsSlice parses a comma-separated list, dropping empty entries
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	var values []string
+	for _, value := range strings.Split(valueStr, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvAsMethodDurations parses "method=duration,method=duration" into a map,
+// e.g. "/task.v1.TaskService/ListTasks=1m", dropping malformed entries
+func getEnvAsMethodDurations(key string) map[string]time.Duration {
+	methodDurations := make(map[string]time.Duration)
+
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		method, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || method == "" {
+			continue
+		}
+		if duration, err := time.ParseDuration(strings.TrimSpace(value)); err == nil {
+			methodDurations[method] = duration
+		}
+	}
+
+	return methodDurations
+}
+
+// getEnvAsMethodRoles parses "method=role|role,method=role" into a map, e.g.
+// "/auth.v1.AuthService/UnlockAccount=admin,/task.v1.TaskService/ListTasks=admin|manager"
+func getEnvAsMethodRoles(key string) map[string][]string {
+	methodRoles := make(map[string][]string)
+
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		method, roles, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || method == "" {
+			continue
+		}
+
+		var parsed []string
+		for _, role := range strings.Split(roles, "|") {
+			if role = strings.TrimSpace(role); role != "" {
+				parsed = append(parsed, role)
+			}
+		}
+		methodRoles[method] = parsed
+	}
+
+	return methodRoles
+}
@@ -26,10 +26,7 @@ func NewEntClient(cfg Config) (*ent.Client, error) {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
 
-	// Configure connection pool
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	configurePool(db, cfg)
 
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -39,8 +36,11 @@ func NewEntClient(cfg Config) (*ent.Client, error) {
 		return nil, fmt.Errorf("ping database: %w", err)
 	}
 
-	// Create Ent driver
-	drv := entsql.OpenDB(dialect.Postgres, db)
+	// Create Ent driver, bounding statements that have no deadline of their own
+	var drv dialect.Driver = entsql.OpenDB(dialect.Postgres, db)
+	if cfg.QueryTimeout > 0 {
+		drv = newTimeoutDriver(drv, cfg.QueryTimeout)
+	}
 
 	// Create Ent client with debug logging in development
 	opts := []ent.Option{ent.Driver(drv)}
@@ -54,6 +54,21 @@ func NewEntClient(cfg Config) (*ent.Client, error) {
 	return client, nil
 }
 
+// configurePool applies the connection pool settings to db
+func configurePool(db *sql.DB, cfg Config) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+}
+
+// Ping checks that the database answers a trivial query
+func Ping(ctx context.Context, client *ent.Client) error {
+	if _, err := client.User.Query().Limit(1).Exist(ctx); err != nil {
+		return fmt.Errorf("ping database: %w", err)
+	}
+	return nil
+}
+
 // Config for database connection
 type Config struct {
 	Host     string
@@ -63,4 +78,11 @@ type Config struct {
 	DBName   string
 	SSLMode  string
 	Debug    bool
+
+	// Connection pool settings
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration // 0 keeps connections open indefinitely
+
+	QueryTimeout time.Duration // Applied to statements without a deadline; 0 disables it
 }
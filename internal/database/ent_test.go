@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestConfigurePool(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:pool?mode=memory&cache=shared")
+	require.NoError(t, err)
+	defer db.Close()
+
+	configurePool(db, Config{MaxOpenConns: 3, MaxIdleConns: 1, ConnMaxLifetime: time.Minute})
+	assert.Equal(t, 3, db.Stats().MaxOpenConnections)
+
+	// Check out every allowed connection, then return them; only one stays idle
+	ctx := context.Background()
+	var conns []*sql.Conn
+	for range 3 {
+		conn, err := db.Conn(ctx)
+		require.NoError(t, err)
+		conns = append(conns, conn)
+	}
+	assert.Equal(t, 3, db.Stats().OpenConnections)
+
+	for _, conn := range conns {
+		require.NoError(t, conn.Close())
+	}
+	stats := db.Stats()
+	assert.Equal(t, 1, stats.Idle)
+	assert.Equal(t, int64(2), stats.MaxIdleClosed)
+}
+
+// recordingDriver captures the contexts statements run with
+type recordingDriver struct {
+	dialect.Driver
+	ctxs []context.Context
+}
+
+func (d *recordingDriver) Exec(ctx context.Context, query string, args, v any) error {
+	d.ctxs = append(d.ctxs, ctx)
+	return nil
+}
+
+func (d *recordingDriver) Query(ctx context.Context, query string, args, v any) error {
+	d.ctxs = append(d.ctxs, ctx)
+	v.(*entsql.Rows).ColumnScanner = emptyRows{}
+	return nil
+}
+
+// emptyRows is a result set with no rows
+type emptyRows struct{ entsql.ColumnScanner }
+
+func (emptyRows) Close() error { return nil }
+
+func TestTimeoutDriver(t *testing.T) {
+	inner := &recordingDriver{}
+	drv := newTimeoutDriver(inner, time.Minute)
+
+	t.Run("exec gets the default timeout", func(t *testing.T) {
+		require.NoError(t, drv.Exec(context.Background(), "UPDATE", []any{}, nil))
+		deadline, ok := inner.ctxs[len(inner.ctxs)-1].Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+	})
+
+	t.Run("existing deadline is kept", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		require.NoError(t, drv.Exec(ctx, "UPDATE", []any{}, nil))
+		assert.Equal(t, ctx, inner.ctxs[len(inner.ctxs)-1])
+	})
+
+	t.Run("query timeout lasts until rows are closed", func(t *testing.T) {
+		rows := &entsql.Rows{}
+		require.NoError(t, drv.Query(context.Background(), "SELECT", []any{}, rows))
+		ctx := inner.ctxs[len(inner.ctxs)-1]
+		_, ok := ctx.Deadline()
+		require.True(t, ok)
+		assert.NoError(t, ctx.Err())
+
+		require.NoError(t, rows.Close())
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+}
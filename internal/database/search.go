@@ -0,0 +1,109 @@
+package database
+
+import (
+	"ariga.io/atlas/sql/postgres"
+	atlas "ariga.io/atlas/sql/schema"
+	entschema "entgo.io/ent/dialect/sql/schema"
+)
+
+// Task full-text search is backed by a generated tsvector column and a GIN
+// index that the Ent schema does not describe, so auto-migration has to be
+// told to create them and to leave them in place afterwards
+const (
+	TaskSearchColumn = "search_vector"
+	taskSearchIndex  = "tasks_search_vector_idx"
+	tasksTable       = "tasks"
+)
+
+// taskSearchExpr ranks title matches above description matches
+const taskSearchExpr = "setweight(to_tsvector('english', coalesce(title, '')), 'A') || " +
+	"setweight(to_tsvector('english', coalesce(description, '')), 'B')"
+
+// TaskSearchMigration returns a PostgreSQL migrate option that adds the task
+// search column and index when missing and keeps them from being dropped
+func TaskSearchMigration() entschema.MigrateOption {
+	return entschema.WithDiffHook(func(next entschema.Differ) entschema.Differ {
+		return entschema.DiffFunc(func(current, desired *atlas.Schema) ([]atlas.Change, error) {
+			changes, err := next.Diff(current, desired)
+			if err != nil {
+				return nil, err
+			}
+			return taskSearchChanges(current, changes), nil
+		})
+	})
+}
+
+// taskSearchChanges adjusts a migration plan for the task search column
+func taskSearchChanges(current *atlas.Schema, changes []atlas.Change) []atlas.Change {
+	existing, ok := current.Table(tasksTable)
+	if ok {
+		if _, ok := existing.Column(TaskSearchColumn); ok {
+			return keepTaskSearch(changes)
+		}
+	}
+
+	column := atlas.NewNullColumn(TaskSearchColumn).
+		SetType(&postgres.TextSearchType{T: postgres.TypeTSVector}).
+		SetGeneratedExpr(&atlas.GeneratedExpr{Expr: taskSearchExpr, Type: "STORED"})
+	index := atlas.NewIndex(taskSearchIndex).
+		AddColumns(column).
+		AddAttrs(&postgres.IndexType{T: postgres.IndexTypeGIN})
+
+	for _, change := range changes {
+		switch c := change.(type) {
+		case *atlas.AddTable:
+			if c.T.Name == tasksTable {
+				c.T.AddColumns(column).AddIndexes(index)
+				return changes
+			}
+		case *atlas.ModifyTable:
+			if c.T.Name == tasksTable {
+				index.Table = c.T
+				c.Changes = append(c.Changes, &atlas.AddColumn{C: column}, &atlas.AddIndex{I: index})
+				return changes
+			}
+		}
+	}
+
+	if !ok {
+		return changes
+	}
+	index.Table = existing
+	return append(changes, &atlas.ModifyTable{
+		T:       existing,
+		Changes: []atlas.Change{&atlas.AddColumn{C: column}, &atlas.AddIndex{I: index}},
+	})
+}
+
+// keepTaskSearch removes the drops auto-migration plans for the search
+// column and index, since they are absent from the Ent schema
+func keepTaskSearch(changes []atlas.Change) []atlas.Change {
+	kept := changes[:0]
+	for _, change := range changes {
+		modify, ok := change.(*atlas.ModifyTable)
+		if !ok || modify.T.Name != tasksTable {
+			kept = append(kept, change)
+			continue
+		}
+
+		tableChanges := modify.Changes[:0]
+		for _, tc := range modify.Changes {
+			switch c := tc.(type) {
+			case *atlas.DropColumn:
+				if c.C.Name == TaskSearchColumn {
+					continue
+				}
+			case *atlas.DropIndex:
+				if c.I.Name == taskSearchIndex {
+					continue
+				}
+			}
+			tableChanges = append(tableChanges, tc)
+		}
+		modify.Changes = tableChanges
+		if len(modify.Changes) > 0 {
+			kept = append(kept, modify)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ariga.io/atlas/sql/postgres"
+	atlas "ariga.io/atlas/sql/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// planSQL renders changes as the PostgreSQL statements they would run
+func planSQL(t *testing.T, changes []atlas.Change) string {
+	t.Helper()
+	plan, err := postgres.DefaultPlan.PlanChanges(context.Background(), "test", changes)
+	require.NoError(t, err)
+
+	var stmts []string
+	for _, c := range plan.Changes {
+		stmts = append(stmts, c.Cmd)
+	}
+	return strings.Join(stmts, ";\n")
+}
+
+func tasksTableWith(columns ...*atlas.Column) *atlas.Table {
+	t := atlas.NewTable(tasksTable).AddColumns(
+		atlas.NewColumn("title").SetType(&atlas.StringType{T: "character varying"}),
+		atlas.NewNullColumn("description").SetType(&atlas.StringType{T: "text"}),
+	)
+	return t.AddColumns(columns...)
+}
+
+func TestTaskSearchChanges(t *testing.T) {
+	t.Run("new database creates column and index with the table", func(t *testing.T) {
+		table := tasksTableWith()
+		changes := taskSearchChanges(atlas.New("public"), []atlas.Change{&atlas.AddTable{T: table}})
+
+		require.Len(t, changes, 1)
+		_, ok := table.Column(TaskSearchColumn)
+		assert.True(t, ok)
+
+		ddl := planSQL(t, changes)
+		assert.Contains(t, ddl, `"search_vector" tsvector NULL GENERATED ALWAYS AS`)
+		assert.Contains(t, ddl, `CREATE INDEX "tasks_search_vector_idx" ON "tasks" USING GIN ("search_vector")`)
+	})
+
+	t.Run("existing table without the column gets it added", func(t *testing.T) {
+		current := atlas.New("public").AddTables(tasksTableWith())
+		changes := taskSearchChanges(current, nil)
+
+		require.Len(t, changes, 1)
+		ddl := planSQL(t, changes)
+		assert.Contains(t, ddl, `ADD COLUMN "search_vector" tsvector NULL GENERATED ALWAYS AS`)
+		assert.Contains(t, ddl, "USING GIN")
+	})
+
+	t.Run("existing column and index are kept", func(t *testing.T) {
+		column := atlas.NewNullColumn(TaskSearchColumn).SetType(&postgres.TextSearchType{T: postgres.TypeTSVector})
+		table := tasksTableWith(column)
+		index := atlas.NewIndex(taskSearchIndex).AddColumns(column)
+		table.AddIndexes(index)
+		current := atlas.New("public").AddTables(table)
+
+		other := atlas.NewIndex("task_status")
+		changes := taskSearchChanges(current, []atlas.Change{
+			&atlas.ModifyTable{T: table, Changes: []atlas.Change{
+				&atlas.DropIndex{I: index},
+				&atlas.DropColumn{C: column},
+			}},
+			&atlas.ModifyTable{T: table, Changes: []atlas.Change{&atlas.DropIndex{I: other}}},
+		})
+
+		require.Len(t, changes, 1)
+		modify := changes[0].(*atlas.ModifyTable)
+		assert.Equal(t, []atlas.Change{&atlas.DropIndex{I: other}}, modify.Changes)
+	})
+}
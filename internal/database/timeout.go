@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+)
+
+// timeoutDriver bounds every statement whose context has no deadline of its
+// own, so a stuck query can't hold a pooled connection indefinitely
+type timeoutDriver struct {
+	dialect.Driver
+	timeout time.Duration
+}
+
+// newTimeoutDriver wraps drv so statements time out after timeout
+func newTimeoutDriver(drv dialect.Driver, timeout time.Duration) dialect.Driver {
+	return &timeoutDriver{Driver: drv, timeout: timeout}
+}
+
+// Exec implements dialect.Driver
+func (d *timeoutDriver) Exec(ctx context.Context, query string, args, v any) error {
+	return execWithTimeout(ctx, d.Driver, d.timeout, query, args, v)
+}
+
+// Query implements dialect.Driver
+func (d *timeoutDriver) Query(ctx context.Context, query string, args, v any) error {
+	return queryWithTimeout(ctx, d.Driver, d.timeout, query, args, v)
+}
+
+// Tx implements dialect.Driver. The transaction itself is not bounded, only
+// the statements run inside it.
+func (d *timeoutDriver) Tx(ctx context.Context) (dialect.Tx, error) {
+	tx, err := d.Driver.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &timeoutTx{Tx: tx, timeout: d.timeout}, nil
+}
+
+// timeoutTx applies the statement timeout inside a transaction
+type timeoutTx struct {
+	dialect.Tx
+	timeout time.Duration
+}
+
+// Exec implements dialect.Tx
+func (t *timeoutTx) Exec(ctx context.Context, query string, args, v any) error {
+	return execWithTimeout(ctx, t.Tx, t.timeout, query, args, v)
+}
+
+// Query implements dialect.Tx
+func (t *timeoutTx) Query(ctx context.Context, query string, args, v any) error {
+	return queryWithTimeout(ctx, t.Tx, t.timeout, query, args, v)
+}
+
+func execWithTimeout(ctx context.Context, eq dialect.ExecQuerier, timeout time.Duration, query string, args, v any) error {
+	if _, ok := ctx.Deadline(); ok {
+		return eq.Exec(ctx, query, args, v)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return eq.Exec(ctx, query, args, v)
+}
+
+// queryWithTimeout keeps the timeout running until the returned rows are
+// closed, since cancelling earlier would abort reading them
+func queryWithTimeout(ctx context.Context, eq dialect.ExecQuerier, timeout time.Duration, query string, args, v any) error {
+	rows, ok := v.(*entsql.Rows)
+	if _, hasDeadline := ctx.Deadline(); hasDeadline || !ok {
+		return eq.Query(ctx, query, args, v)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	if err := eq.Query(ctx, query, args, v); err != nil {
+		cancel()
+		return err
+	}
+	rows.ColumnScanner = cancelOnClose{ColumnScanner: rows.ColumnScanner, cancel: cancel}
+	return nil
+}
+
+// cancelOnClose releases a query's timeout when its rows are closed
+type cancelOnClose struct {
+	entsql.ColumnScanner
+	cancel context.CancelFunc
+}
+
+// Close implements entsql.ColumnScanner
+func (r cancelOnClose) Close() error {
+	err := r.ColumnScanner.Close()
+	r.cancel()
+	return err
+}
@@ -0,0 +1,332 @@
+// internal/gateway/gateway.go
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gurkanbulca/taskmaster/internal/middleware"
+)
+
+// timestampName identifies google.protobuf.Timestamp fields in query parameters
+var timestampName = (&timestamppb.Timestamp{}).ProtoReflect().Descriptor().FullName()
+
+// maxBodyBytes caps the JSON request body, matching the gRPC default receive limit
+const maxBodyBytes = 1024 * 1024
+
+// forwardedHeaders are copied from HTTP requests into gRPC metadata
+var forwardedHeaders = []string{
+	"authorization",
+	middleware.APIKeyHeader,
+	middleware.IdempotencyKeyHeader,
+	middleware.CaptchaTokenHeader,
+	middleware.AcceptLanguageHeader,
+}
+
+// Gateway serves the unary Auth and Task RPCs as REST/JSON. Requests are
+// forwarded over a gRPC connection so they pass through the same interceptors
+// as native gRPC clients.
+type Gateway struct {
+	conn           grpc.ClientConnInterface
+	mux            *http.ServeMux
+	allowedOrigins map[string]bool
+	allowAll       bool
+	proxySecret    string
+}
+
+// New creates a gateway that calls the gRPC server behind conn. Browsers on
+// allowedOrigins may call it cross-origin; "*" allows any origin.
+func New(conn grpc.ClientConnInterface, allowedOrigins []string) *Gateway {
+	g := &Gateway{
+		conn:           conn,
+		mux:            http.NewServeMux(),
+		allowedOrigins: make(map[string]bool),
+	}
+
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			g.allowAll = true
+		}
+		g.allowedOrigins[origin] = true
+	}
+
+	for _, rt := range routes {
+		g.mux.Handle(rt.pattern, g.handler(rt))
+	}
+
+	return g
+}
+
+// SetProxySecret sends secret with every call so the gRPC server trusts the
+// client address the gateway forwards, see middleware.ProxySecretHeader
+func (g *Gateway) SetProxySecret(secret string) {
+	g.proxySecret = secret
+}
+
+// ServeHTTP applies CORS and dispatches to the mapped RPC
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && (g.allowAll || g.allowedOrigins[origin]) {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Token-Expiring")
+
+		// Answer preflight requests without touching the RPC
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-API-Key, Idempotency-Key, X-Captcha-Token")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	g.mux.ServeHTTP(w, r)
+}
+
+// handler decodes the request for rt, invokes the RPC and encodes its response
+func (g *Gateway) handler(rt route) http.Handler {
+	params := pathParams(rt.pattern)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := rt.request.ProtoReflect().New().Interface()
+		if err := decodeRequest(r, req, params); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := rt.response.ProtoReflect().New().Interface()
+		var trailer metadata.MD
+		if err := g.conn.Invoke(g.outgoingContext(r), rt.method, req, resp, grpc.Trailer(&trailer)); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		if expiring := trailer.Get(middleware.TokenExpiringTrailer); len(expiring) > 0 {
+			w.Header().Set("X-Token-Expiring", expiring[0])
+		}
+
+		body, err := protojson.Marshal(resp)
+		if err != nil {
+			writeError(w, status.Error(codes.Internal, "failed to encode response"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+}
+
+// decodeRequest fills req from the JSON body, query string and path wildcards.
+// Path wildcards are applied last so the body cannot override them.
+func decodeRequest(r *http.Request, req proto.Message, params []string) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "failed to read request body")
+	}
+	if len(body) > maxBodyBytes {
+		return status.Error(codes.InvalidArgument, "request body too large")
+	}
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, req); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid request body: %v", err)
+		}
+	}
+
+	for name, values := range r.URL.Query() {
+		fd := findField(req, name)
+		if fd == nil {
+			continue // Ignore unrelated parameters such as cache busters
+		}
+		for _, value := range values {
+			if err := setField(req, fd, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range params {
+		fd := findField(req, name)
+		if fd == nil {
+			return status.Errorf(codes.Internal, "route parameter %q has no matching field", name)
+		}
+		if err := setField(req, fd, r.PathValue(name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// outgoingContext forwards auth headers and the client's address and user
+// agent as gRPC metadata
+func (g *Gateway) outgoingContext(r *http.Request) context.Context {
+	md := metadata.MD{}
+	for _, header := range forwardedHeaders {
+		if value := r.Header.Get(header); value != "" {
+			md.Set(header, value)
+		}
+	}
+
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		md.Set(middleware.ForwardedForHeader, host)
+	}
+	if userAgent := r.UserAgent(); userAgent != "" {
+		md.Set("x-user-agent", userAgent)
+	}
+	if g.proxySecret != "" {
+		md.Set(middleware.ProxySecretHeader, g.proxySecret)
+	}
+
+	return metadata.NewOutgoingContext(r.Context(), md)
+}
+
+// pathParams returns the wildcard names in a ServeMux pattern
+func pathParams(pattern string) []string {
+	var params []string
+	for _, segment := range strings.Split(pattern, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params = append(params, strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}"))
+		}
+	}
+	return params
+}
+
+// findField looks a field up by its proto or JSON name
+func findField(msg proto.Message, name string) protoreflect.FieldDescriptor {
+	fields := msg.ProtoReflect().Descriptor().Fields()
+	if fd := fields.ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return fields.ByJSONName(name)
+}
+
+// setField parses value into a scalar, repeated scalar or timestamp field
+func setField(msg proto.Message, fd protoreflect.FieldDescriptor, value string) error {
+	// Timestamps are accepted as RFC 3339 strings, as in JSON bodies
+	if !fd.IsList() && fd.Kind() == protoreflect.MessageKind && fd.Message().FullName() == timestampName {
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid value for %q: must be an RFC 3339 timestamp", fd.Name())
+		}
+		msg.ProtoReflect().Set(fd, protoreflect.ValueOfMessage(timestamppb.New(t).ProtoReflect()))
+		return nil
+	}
+
+	if fd.IsMap() || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		return status.Errorf(codes.InvalidArgument, "parameter %q must be sent in the request body", fd.Name())
+	}
+
+	v, err := parseScalar(fd, value)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid value for %q: %v", fd.Name(), err)
+	}
+
+	m := msg.ProtoReflect()
+	if fd.IsList() {
+		m.Mutable(fd).List().Append(v)
+		return nil
+	}
+	m.Set(fd, v)
+	return nil
+}
+
+func parseScalar(fd protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(value)), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		i, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(i)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		i, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(i), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		u, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(u)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		u, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(u), err
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(value, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(value, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(value)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		n, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), err
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field type %s", fd.Kind())
+	}
+}
+
+// errorBody is the JSON shape of a failed call
+type errorBody struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	body, _ := json.Marshal(errorBody{Code: int(st.Code()), Message: st.Message()})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(HTTPStatusFromCode(st.Code()))
+	w.Write(body)
+}
+
+// HTTPStatusFromCode maps a gRPC status code to the equivalent HTTP status
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // Client Closed Request
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,198 @@
+// internal/gateway/gateway_test.go
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
+	taskv1 "github.com/gurkanbulca/taskmaster/api/proto/task/v1/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/enttest"
+	"github.com/gurkanbulca/taskmaster/internal/config"
+	"github.com/gurkanbulca/taskmaster/internal/middleware"
+	"github.com/gurkanbulca/taskmaster/internal/repository"
+	"github.com/gurkanbulca/taskmaster/internal/service"
+	"github.com/gurkanbulca/taskmaster/pkg/auth"
+	"github.com/gurkanbulca/taskmaster/pkg/email"
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newTestGateway serves the gateway over HTTP, backed by an in-memory gRPC
+// server running the real services and auth interceptor
+func newTestGateway(t *testing.T, allowedOrigins ...string) *httptest.Server {
+	t.Helper()
+
+	client := enttest.Open(t, "sqlite3", "file:gateway?mode=memory&cache=shared&_fk=1")
+	t.Cleanup(func() { client.Close() })
+	service.RegisterSecurityHooks(client, logging.Default())
+
+	tokenManager := auth.NewTokenManager("test-access-secret", "test-refresh-secret", 15*time.Minute, 7*24*time.Hour)
+	mockEmailService := email.NewMockEmailService()
+	securityLogger := service.NewSecurityLogger(service.NewSecurityService(client))
+	securityConfig := config.SecurityConfig{MaxLoginAttempts: 3, AccountLockoutDuration: 15 * time.Minute, MaxEmailVerificationAttempts: 5}
+	authService := service.NewAuthService(
+		client,
+		tokenManager,
+		service.NewEmailVerificationService(client, mockEmailService, securityLogger, service.EmailVerificationConfig{}),
+		service.NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, service.PasswordResetConfig{}),
+		securityLogger,
+		securityConfig,
+	)
+	taskService := service.NewTaskService(repository.NewEntTaskRepository(client))
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			middleware.NewMetadataExtractorInterceptor().Unary(),
+			middleware.NewEnhancedValidationInterceptor(middleware.DefaultValidationConfig()).Unary(),
+			middleware.NewUpdatedAuthInterceptor(tokenManager).Unary(),
+		),
+	)
+	authv1.RegisterAuthServiceServer(grpcServer, authService)
+	taskv1.RegisterTaskServiceServer(grpcServer, taskService)
+
+	listener := bufconn.Listen(1024 * 1024)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	server := httptest.NewServer(New(conn, allowedOrigins))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// doJSON sends body as JSON and decodes the JSON response into a map
+func doJSON(t *testing.T, method, url, token, body string) (int, map[string]interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	decoded := map[string]interface{}{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	return resp.StatusCode, decoded
+}
+
+func TestGateway_RegisterAndListTasks(t *testing.T) {
+	server := newTestGateway(t)
+
+	code, registered := doJSON(t, http.MethodPost, server.URL+"/v1/auth/register", "",
+		`{"email": "gateway@example.com", "username": "gatewayuser", "password": "TestPass123!"}`)
+	require.Equal(t, http.StatusOK, code, registered)
+	token, _ := registered["accessToken"].(string)
+	require.NotEmpty(t, token)
+
+	code, created := doJSON(t, http.MethodPost, server.URL+"/v1/tasks", token,
+		`{"title": "Call the API over HTTP", "priority": "PRIORITY_HIGH"}`)
+	require.Equal(t, http.StatusOK, code, created)
+	task := created["task"].(map[string]interface{})
+	taskID := task["id"].(string)
+
+	code, listed := doJSON(t, http.MethodGet, server.URL+"/v1/tasks?pageSize=5", token, "")
+	require.Equal(t, http.StatusOK, code, listed)
+	tasks := listed["tasks"].([]interface{})
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Call the API over HTTP", tasks[0].(map[string]interface{})["title"])
+
+	// Path wildcards fill the request ID
+	code, fetched := doJSON(t, http.MethodGet, server.URL+"/v1/tasks/"+taskID, token, "")
+	require.Equal(t, http.StatusOK, code, fetched)
+	assert.Equal(t, taskID, fetched["task"].(map[string]interface{})["id"])
+
+	// Timestamp query parameters take RFC 3339 values
+	code, created = doJSON(t, http.MethodPost, server.URL+"/v1/tasks", token,
+		`{"title": "Plan the offsite", "dueDate": "2030-01-02T09:00:00Z"}`)
+	require.Equal(t, http.StatusOK, code, created)
+
+	code, listed = doJSON(t, http.MethodGet,
+		server.URL+"/v1/tasks?dueDateFrom=2030-01-02T00:00:00Z&dueDateTo=2030-01-02T23:59:59Z", token, "")
+	require.Equal(t, http.StatusOK, code, listed)
+	tasks = listed["tasks"].([]interface{})
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Plan the offsite", tasks[0].(map[string]interface{})["title"])
+
+	code, listed = doJSON(t, http.MethodGet, server.URL+"/v1/tasks?dueDateFrom=tomorrow", token, "")
+	assert.Equal(t, http.StatusBadRequest, code, listed)
+}
+
+func TestGateway_Errors(t *testing.T) {
+	server := newTestGateway(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   codes.Code
+	}{
+		{name: "missing token", method: http.MethodGet, path: "/v1/tasks", wantStatus: http.StatusUnauthorized, wantCode: codes.Unauthenticated},
+		{name: "malformed body", method: http.MethodPost, path: "/v1/auth/login", body: `{"email":`, wantStatus: http.StatusBadRequest, wantCode: codes.InvalidArgument},
+		{name: "wrong credentials", method: http.MethodPost, path: "/v1/auth/login", body: `{"email": "nobody@example.com", "password": "TestPass123!"}`, wantStatus: http.StatusUnauthorized, wantCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := doJSON(t, tt.method, server.URL+tt.path, "", tt.body)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, float64(tt.wantCode), body["code"])
+			assert.NotEmpty(t, body["message"])
+		})
+	}
+}
+
+func TestGateway_CORS(t *testing.T) {
+	server := newTestGateway(t, "https://app.example.com")
+
+	preflight := func(origin string) *http.Response {
+		req, err := http.NewRequest(http.MethodOptions, server.URL+"/v1/tasks", nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	allowed := preflight("https://app.example.com")
+	assert.Equal(t, http.StatusNoContent, allowed.StatusCode)
+	assert.Equal(t, "https://app.example.com", allowed.Header.Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, allowed.Header.Get("Access-Control-Allow-Headers"), "Authorization")
+
+	denied := preflight("https://evil.example.com")
+	assert.Empty(t, denied.Header.Get("Access-Control-Allow-Origin"))
+}
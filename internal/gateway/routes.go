@@ -0,0 +1,122 @@
+// internal/gateway/routes.go
+package gateway
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
+	taskv1 "github.com/gurkanbulca/taskmaster/api/proto/task/v1/generated"
+)
+
+// route maps an HTTP endpoint to a unary gRPC method. Wildcards in the
+// pattern name the request field they fill, e.g. {task_id}.
+type route struct {
+	pattern  string
+	method   string
+	request  proto.Message
+	response proto.Message
+}
+
+const (
+	authService = "/auth.v1.AuthService/"
+	taskService = "/task.v1.TaskService/"
+)
+
+// routes lists the REST mapping. Streaming RPCs such as WatchTasks are only
+// available over gRPC.
+var routes = []route{
+	// Authentication
+	{"POST /v1/auth/register", authService + "Register", &authv1.RegisterRequest{}, &authv1.RegisterResponse{}},
+	{"POST /v1/auth/login", authService + "Login", &authv1.LoginRequest{}, &authv1.LoginResponse{}},
+	{"POST /v1/auth/login/oidc", authService + "LoginWithOIDC", &authv1.LoginWithOIDCRequest{}, &authv1.LoginResponse{}},
+	{"POST /v1/auth/login/totp", authService + "VerifyTOTPLogin", &authv1.VerifyTOTPLoginRequest{}, &authv1.LoginResponse{}},
+	{"POST /v1/auth/invites/accept", authService + "AcceptInvite", &authv1.AcceptInviteRequest{}, &authv1.LoginResponse{}},
+	{"POST /v1/auth/refresh", authService + "RefreshToken", &authv1.RefreshTokenRequest{}, &authv1.RefreshTokenResponse{}},
+	{"POST /v1/auth/logout", authService + "Logout", &authv1.LogoutRequest{}, &emptypb.Empty{}},
+	{"POST /v1/auth/introspect", authService + "IntrospectToken", &authv1.IntrospectTokenRequest{}, &authv1.IntrospectTokenResponse{}},
+
+	// Current user
+	{"GET /v1/auth/me", authService + "GetMe", &emptypb.Empty{}, &authv1.GetMeResponse{}},
+	{"PATCH /v1/auth/me", authService + "UpdateProfile", &authv1.UpdateProfileRequest{}, &authv1.UpdateProfileResponse{}},
+	{"DELETE /v1/auth/me", authService + "DeleteAccount", &authv1.DeleteAccountRequest{}, &emptypb.Empty{}},
+	{"GET /v1/auth/me/notifications", authService + "GetNotificationPreferences", &authv1.GetNotificationPreferencesRequest{}, &authv1.GetNotificationPreferencesResponse{}},
+	{"PATCH /v1/auth/me/notifications", authService + "UpdateNotificationPreferences", &authv1.UpdateNotificationPreferencesRequest{}, &authv1.UpdateNotificationPreferencesResponse{}},
+	{"POST /v1/auth/me/deactivate", authService + "DeactivateAccount", &authv1.DeactivateAccountRequest{}, &emptypb.Empty{}},
+	{"POST /v1/auth/password", authService + "ChangePassword", &authv1.ChangePasswordRequest{}, &emptypb.Empty{}},
+
+	// Two-factor authentication
+	{"POST /v1/auth/totp/enable", authService + "EnableTOTP", &authv1.EnableTOTPRequest{}, &authv1.EnableTOTPResponse{}},
+	{"POST /v1/auth/totp/confirm", authService + "ConfirmTOTP", &authv1.ConfirmTOTPRequest{}, &authv1.ConfirmTOTPResponse{}},
+	{"POST /v1/auth/totp/disable", authService + "DisableTOTP", &authv1.DisableTOTPRequest{}, &emptypb.Empty{}},
+
+	// API keys
+	{"POST /v1/auth/api-keys", authService + "CreateAPIKey", &authv1.CreateAPIKeyRequest{}, &authv1.CreateAPIKeyResponse{}},
+	{"GET /v1/auth/api-keys", authService + "ListAPIKeys", &authv1.ListAPIKeysRequest{}, &authv1.ListAPIKeysResponse{}},
+	{"DELETE /v1/auth/api-keys/{id}", authService + "RevokeAPIKey", &authv1.RevokeAPIKeyRequest{}, &emptypb.Empty{}},
+
+	// Sessions
+	{"GET /v1/auth/sessions", authService + "ListSessions", &authv1.ListSessionsRequest{}, &authv1.ListSessionsResponse{}},
+	{"DELETE /v1/auth/sessions/{session_id}", authService + "RevokeSession", &authv1.RevokeSessionRequest{}, &emptypb.Empty{}},
+
+	// Email verification and change
+	{"GET /v1/auth/email/verification", authService + "GetVerificationStatus", &authv1.GetVerificationStatusRequest{}, &authv1.GetVerificationStatusResponse{}},
+	{"POST /v1/auth/email/verification", authService + "SendVerificationEmail", &authv1.SendVerificationEmailRequest{}, &emptypb.Empty{}},
+	{"POST /v1/auth/email/verification/resend", authService + "ResendVerificationEmail", &authv1.ResendVerificationEmailRequest{}, &emptypb.Empty{}},
+	{"POST /v1/auth/email/verify", authService + "VerifyEmail", &authv1.VerifyEmailRequest{}, &emptypb.Empty{}},
+	{"POST /v1/auth/email/change", authService + "RequestEmailChange", &authv1.RequestEmailChangeRequest{}, &emptypb.Empty{}},
+	{"POST /v1/auth/email/change/confirm", authService + "ConfirmEmailChange", &authv1.ConfirmEmailChangeRequest{}, &emptypb.Empty{}},
+	{"POST /v1/auth/email/preview", authService + "PreviewEmail", &authv1.PreviewEmailRequest{}, &authv1.PreviewEmailResponse{}},
+
+	// Password reset
+	{"POST /v1/auth/password/reset", authService + "RequestPasswordReset", &authv1.RequestPasswordResetRequest{}, &emptypb.Empty{}},
+	{"POST /v1/auth/password/reset/verify", authService + "VerifyPasswordResetToken", &authv1.VerifyPasswordResetTokenRequest{}, &authv1.VerifyPasswordResetTokenResponse{}},
+	{"POST /v1/auth/password/reset/confirm", authService + "ResetPassword", &authv1.ResetPasswordRequest{}, &emptypb.Empty{}},
+
+	// Security
+	{"GET /v1/auth/security/events", authService + "GetSecurityEvents", &authv1.GetSecurityEventsRequest{}, &authv1.GetSecurityEventsResponse{}},
+	{"GET /v1/auth/security/events/export", authService + "ExportSecurityEvents", &authv1.ExportSecurityEventsRequest{}, &authv1.ExportSecurityEventsResponse{}},
+	{"GET /v1/auth/security/stats", authService + "GetSecurityStats", &authv1.GetSecurityStatsRequest{}, &authv1.GetSecurityStatsResponse{}},
+	{"POST /v1/auth/security/events/resolve", authService + "BulkResolveSecurityEvents", &authv1.BulkResolveSecurityEventsRequest{}, &authv1.BulkResolveSecurityEventsResponse{}},
+	{"POST /v1/auth/security/events/delete", authService + "DeleteSecurityEvents", &authv1.DeleteSecurityEventsRequest{}, &authv1.DeleteSecurityEventsResponse{}},
+
+	// User administration
+	{"GET /v1/users", authService + "ListUsers", &authv1.ListUsersRequest{}, &authv1.ListUsersResponse{}},
+	{"POST /v1/users/import", authService + "BulkImportUsers", &authv1.BulkImportUsersRequest{}, &authv1.BulkImportUsersResponse{}},
+	{"POST /v1/users/invites", authService + "CreateInvite", &authv1.CreateInviteRequest{}, &authv1.CreateInviteResponse{}},
+	{"GET /v1/users/locked", authService + "ListLockedAccounts", &authv1.ListLockedAccountsRequest{}, &authv1.ListLockedAccountsResponse{}},
+	{"GET /v1/users/{user_id}", authService + "GetUserByID", &authv1.GetUserByIDRequest{}, &authv1.GetUserByIDResponse{}},
+	{"POST /v1/users/{user_id}/unlock", authService + "UnlockAccount", &authv1.UnlockAccountRequest{}, &emptypb.Empty{}},
+	{"PUT /v1/users/{user_id}/active", authService + "SetUserActive", &authv1.SetUserActiveRequest{}, &emptypb.Empty{}},
+	{"PUT /v1/users/{user_id}/role", authService + "UpdateUserRole", &authv1.UpdateUserRoleRequest{}, &authv1.UpdateUserRoleResponse{}},
+
+	// Tasks
+	{"POST /v1/tasks", taskService + "CreateTask", &taskv1.CreateTaskRequest{}, &taskv1.CreateTaskResponse{}},
+	{"POST /v1/tasks/batch", taskService + "BatchCreateTasks", &taskv1.BatchCreateTasksRequest{}, &taskv1.BatchCreateTasksResponse{}},
+	{"GET /v1/tasks", taskService + "ListTasks", &taskv1.ListTasksRequest{}, &taskv1.ListTasksResponse{}},
+	{"GET /v1/tasks/statistics", taskService + "GetTaskStatistics", &taskv1.GetTaskStatisticsRequest{}, &taskv1.GetTaskStatisticsResponse{}},
+	{"GET /v1/tasks/upcoming", taskService + "ListUpcomingTasks", &taskv1.ListUpcomingTasksRequest{}, &taskv1.ListUpcomingTasksResponse{}},
+	{"GET /v1/tasks/mine", taskService + "ListMyTasks", &taskv1.ListMyTasksRequest{}, &taskv1.ListMyTasksResponse{}},
+	{"POST /v1/tasks/batch-get", taskService + "BatchGetTasks", &taskv1.BatchGetTasksRequest{}, &taskv1.BatchGetTasksResponse{}},
+	{"POST /v1/tasks/batch-delete", taskService + "BatchDeleteTasks", &taskv1.BatchDeleteTasksRequest{}, &taskv1.BatchDeleteTasksResponse{}},
+	{"PATCH /v1/tasks/status", taskService + "BatchUpdateTaskStatus", &taskv1.BatchUpdateTaskStatusRequest{}, &taskv1.BatchUpdateTaskStatusResponse{}},
+	{"GET /v1/tasks/{id}", taskService + "GetTask", &taskv1.GetTaskRequest{}, &taskv1.GetTaskResponse{}},
+	{"PATCH /v1/tasks/{id}", taskService + "UpdateTask", &taskv1.UpdateTaskRequest{}, &taskv1.UpdateTaskResponse{}},
+	{"DELETE /v1/tasks/{id}", taskService + "DeleteTask", &taskv1.DeleteTaskRequest{}, &emptypb.Empty{}},
+	{"POST /v1/tasks/{id}/restore", taskService + "RestoreTask", &taskv1.RestoreTaskRequest{}, &taskv1.RestoreTaskResponse{}},
+	{"GET /v1/tasks/{parent_id}/subtasks", taskService + "ListSubtasks", &taskv1.ListSubtasksRequest{}, &taskv1.ListSubtasksResponse{}},
+	{"GET /v1/tasks/{task_id}/history", taskService + "GetTaskHistory", &taskv1.GetTaskHistoryRequest{}, &taskv1.GetTaskHistoryResponse{}},
+
+	// Task templates
+	{"POST /v1/task-templates", taskService + "CreateTaskTemplate", &taskv1.CreateTaskTemplateRequest{}, &taskv1.CreateTaskTemplateResponse{}},
+	{"GET /v1/task-templates", taskService + "ListTaskTemplates", &taskv1.ListTaskTemplatesRequest{}, &taskv1.ListTaskTemplatesResponse{}},
+	{"GET /v1/task-templates/{id}", taskService + "GetTaskTemplate", &taskv1.GetTaskTemplateRequest{}, &taskv1.GetTaskTemplateResponse{}},
+	{"PATCH /v1/task-templates/{id}", taskService + "UpdateTaskTemplate", &taskv1.UpdateTaskTemplateRequest{}, &taskv1.UpdateTaskTemplateResponse{}},
+	{"DELETE /v1/task-templates/{id}", taskService + "DeleteTaskTemplate", &taskv1.DeleteTaskTemplateRequest{}, &emptypb.Empty{}},
+	{"POST /v1/task-templates/{template_id}/tasks", taskService + "CreateTaskFromTemplate", &taskv1.CreateTaskFromTemplateRequest{}, &taskv1.CreateTaskFromTemplateResponse{}},
+
+	// Comments
+	{"POST /v1/tasks/{task_id}/comments", taskService + "AddComment", &taskv1.AddCommentRequest{}, &taskv1.AddCommentResponse{}},
+	{"GET /v1/tasks/{task_id}/comments", taskService + "ListComments", &taskv1.ListCommentsRequest{}, &taskv1.ListCommentsResponse{}},
+	{"DELETE /v1/comments/{id}", taskService + "DeleteComment", &taskv1.DeleteCommentRequest{}, &emptypb.Empty{}},
+}
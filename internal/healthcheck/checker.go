@@ -0,0 +1,137 @@
+// internal/healthcheck/checker.go
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
+)
+
+// Pinger reports whether a dependency is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingerFunc adapts a function to the Pinger interface
+type PingerFunc func(ctx context.Context) error
+
+// Ping calls f(ctx)
+func (f PingerFunc) Ping(ctx context.Context) error {
+	return f(ctx)
+}
+
+// StatusSetter receives serving status updates; *health.Server satisfies it
+type StatusSetter interface {
+	SetServingStatus(service string, servingStatus grpc_health_v1.HealthCheckResponse_ServingStatus)
+}
+
+// check is a registered dependency. Only critical checks affect the listed services.
+type check struct {
+	pinger   Pinger
+	critical bool
+}
+
+// Checker periodically pings dependencies and reports the listed services
+// as NOT_SERVING while any critical one is unreachable. Each dependency's own
+// status is also published under its name.
+type Checker struct {
+	server   StatusSetter
+	services []string
+	interval time.Duration
+	timeout  time.Duration
+	logger   logging.Logger
+
+	mu     sync.Mutex
+	checks map[string]check
+	status grpc_health_v1.HealthCheckResponse_ServingStatus
+}
+
+// NewChecker creates a checker that updates services on server. The empty
+// service name is the overall server health.
+func NewChecker(server StatusSetter, interval, timeout time.Duration, services ...string) *Checker {
+	return &Checker{
+		server:   server,
+		services: services,
+		interval: interval,
+		timeout:  timeout,
+		logger:   logging.Default(),
+		checks:   make(map[string]check),
+		status:   grpc_health_v1.HealthCheckResponse_UNKNOWN,
+	}
+}
+
+// SetLogger replaces the default logger
+func (c *Checker) SetLogger(logger logging.Logger) {
+	c.logger = logger
+}
+
+// AddCheck registers a named dependency the listed services need to serve
+func (c *Checker) AddCheck(name string, pinger Pinger) {
+	c.addCheck(name, check{pinger: pinger, critical: true})
+}
+
+// AddOptionalCheck registers a named dependency whose outage is reported
+// under its own name only, leaving the listed services serving
+func (c *Checker) AddOptionalCheck(name string, pinger Pinger) {
+	c.addCheck(name, check{pinger: pinger})
+}
+
+func (c *Checker) addCheck(name string, chk check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = chk
+}
+
+// Run checks immediately and then once per interval until ctx is cancelled
+func (c *Checker) Run(ctx context.Context) {
+	c.Check(ctx)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Check(ctx)
+		}
+	}
+}
+
+// Check pings every dependency once, publishes the resulting status and returns it
+func (c *Checker) Check(ctx context.Context) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := grpc_health_v1.HealthCheckResponse_SERVING
+	for name, chk := range c.checks {
+		pingCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		err := chk.pinger.Ping(pingCtx)
+		cancel()
+
+		checkStatus := grpc_health_v1.HealthCheckResponse_SERVING
+		if err != nil {
+			c.logger.Warn("health check failed", "check", name, "critical", chk.critical, "error", err)
+			checkStatus = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+			if chk.critical {
+				status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+			}
+		}
+		c.server.SetServingStatus(name, checkStatus)
+	}
+
+	if status != c.status {
+		c.logger.Info("serving status changed", "from", c.status.String(), "to", status.String())
+		c.status = status
+	}
+
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, status)
+	}
+
+	return status
+}
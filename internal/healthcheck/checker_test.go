@@ -0,0 +1,181 @@
+// internal/healthcheck/checker_test.go
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/gurkanbulca/taskmaster/pkg/email"
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
+)
+
+// togglePinger fails while down is set
+type togglePinger struct {
+	down  atomic.Bool
+	calls atomic.Int32
+}
+
+func (p *togglePinger) Ping(ctx context.Context) error {
+	p.calls.Add(1)
+	if p.down.Load() {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// fakeTransport is an SMTP transport whose server is unreachable while down is set
+type fakeTransport struct {
+	down atomic.Bool
+}
+
+func (f *fakeTransport) SendMail(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	return nil
+}
+
+func (f *fakeTransport) Verify(ctx context.Context, addr string, auth smtp.Auth) error {
+	if f.down.Load() {
+		return errors.New("dial SMTP server: connection refused")
+	}
+	return nil
+}
+
+func servingStatus(t *testing.T, server *health.Server, service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := server.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.Status
+}
+
+func TestChecker_StatusTransitions(t *testing.T) {
+	server := health.NewServer()
+	checker := NewChecker(server, time.Minute, time.Second, "", "task.v1.TaskService")
+	capture := logging.NewCaptureLogger()
+	checker.SetLogger(capture)
+
+	db := &togglePinger{}
+	checker.AddCheck("database", db)
+
+	// Healthy database
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, checker.Check(context.Background()))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus(t, server, ""))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus(t, server, "task.v1.TaskService"))
+
+	// Database goes down
+	db.down.Store(true)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, checker.Check(context.Background()))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, ""))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, "task.v1.TaskService"))
+
+	// Database recovers
+	db.down.Store(false)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, checker.Check(context.Background()))
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus(t, server, ""))
+
+	var transitions []string
+	for _, entry := range capture.Entries() {
+		if entry.Message == "serving status changed" {
+			transitions = append(transitions, entry.Fields["to"].(string))
+		}
+	}
+	assert.Equal(t, []string{"SERVING", "NOT_SERVING", "SERVING"}, transitions)
+}
+
+func TestChecker_RunPingsPeriodically(t *testing.T) {
+	server := health.NewServer()
+	checker := NewChecker(server, 10*time.Millisecond, time.Second, "")
+	checker.SetLogger(logging.NewCaptureLogger())
+
+	db := &togglePinger{}
+	db.down.Store(true)
+	checker.AddCheck("database", db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		checker.Run(ctx)
+		close(done)
+	}()
+
+	// The first check runs immediately
+	assert.Eventually(t, func() bool {
+		return servingStatus(t, server, "") == grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	db.down.Store(false)
+	assert.Eventually(t, func() bool {
+		return servingStatus(t, server, "") == grpc_health_v1.HealthCheckResponse_SERVING
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("checker did not stop after context was cancelled")
+	}
+	assert.GreaterOrEqual(t, db.calls.Load(), int32(2))
+}
+
+func TestChecker_EmailCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		critical     bool
+		wantWhenDown grpc_health_v1.HealthCheckResponse_ServingStatus
+	}{
+		{
+			name:         "optional email leaves server serving",
+			critical:     false,
+			wantWhenDown: grpc_health_v1.HealthCheckResponse_SERVING,
+		},
+		{
+			name:         "critical email degrades readiness",
+			critical:     true,
+			wantWhenDown: grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &fakeTransport{}
+			smtpService, err := email.NewSMTPEmailService(&email.Config{SMTPHost: "localhost", SMTPPort: 2525})
+			require.NoError(t, err)
+			smtpService.SetSender(transport)
+
+			server := health.NewServer()
+			checker := NewChecker(server, time.Minute, time.Second, "")
+			checker.SetLogger(logging.NewCaptureLogger())
+			checker.AddCheck("database", &togglePinger{})
+			if tt.critical {
+				checker.AddCheck("email", PingerFunc(smtpService.TestConnection))
+			} else {
+				checker.AddOptionalCheck("email", PingerFunc(smtpService.TestConnection))
+			}
+
+			// SMTP reachable
+			checker.Check(context.Background())
+			assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus(t, server, "email"))
+			assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus(t, server, ""))
+
+			// SMTP goes down
+			transport.down.Store(true)
+			assert.Equal(t, tt.wantWhenDown, checker.Check(context.Background()))
+			assert.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, "email"))
+			assert.Equal(t, tt.wantWhenDown, servingStatus(t, server, ""))
+			assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus(t, server, "database"))
+
+			// SMTP recovers
+			transport.down.Store(false)
+			checker.Check(context.Background())
+			assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus(t, server, "email"))
+			assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus(t, server, ""))
+		})
+	}
+}
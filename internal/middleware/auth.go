@@ -3,7 +3,13 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -12,10 +18,22 @@ import (
 	"github.com/gurkanbulca/taskmaster/pkg/auth"
 )
 
+// APIKeyHeader is the metadata header carrying an API key, accepted instead
+// of a Bearer token
+const APIKeyHeader = "x-api-key"
+
+// TokenExpiringTrailer is set on responses to requests whose access token is
+// about to expire, with the seconds left, as a hint to refresh it now
+const TokenExpiringTrailer = "x-token-expiring"
+
 // UpdatedAuthInterceptor provides authentication middleware with metadata extraction
 type UpdatedAuthInterceptor struct {
 	tokenManager  *auth.TokenManager
 	publicMethods map[string]bool
+	methodRoles   map[string][]string
+	apiKeys       *auth.APIKeyStore
+	apiKeyLimiter *slidingWindow
+	activity      *auth.ActivityTracker
 }
 
 // NewUpdatedAuthInterceptor creates a new auth interceptor
@@ -24,10 +42,15 @@ func NewUpdatedAuthInterceptor(tokenManager *auth.TokenManager) *UpdatedAuthInte
 	publicMethods := map[string]bool{
 		"/auth.v1.AuthService/Register":             true,
 		"/auth.v1.AuthService/Login":                true,
+		"/auth.v1.AuthService/LoginWithOIDC":        true,
 		"/auth.v1.AuthService/RefreshToken":         true,
 		"/auth.v1.AuthService/VerifyEmail":          true,
+		"/auth.v1.AuthService/ConfirmEmailChange":   true,
 		"/auth.v1.AuthService/RequestPasswordReset": true,
 		"/auth.v1.AuthService/ResetPassword":        true,
+		"/auth.v1.AuthService/VerifyTOTPLogin":      true,
+		"/auth.v1.AuthService/AcceptInvite":         true,
+		"/auth.v1.AuthService/IntrospectToken":      true, // Rate limited per IP instead
 		"/grpc.health.v1.Health/Check":              true,
 		"/grpc.health.v1.Health/Watch":              true,
 	}
@@ -35,9 +58,31 @@ func NewUpdatedAuthInterceptor(tokenManager *auth.TokenManager) *UpdatedAuthInte
 	return &UpdatedAuthInterceptor{
 		tokenManager:  tokenManager,
 		publicMethods: publicMethods,
+		methodRoles:   make(map[string][]string),
 	}
 }
 
+// SetMethodRoles restricts authenticated methods to callers holding one of
+// the listed roles. Methods not in the map only require authentication.
+func (a *UpdatedAuthInterceptor) SetMethodRoles(methodRoles map[string][]string) {
+	a.methodRoles = methodRoles
+}
+
+// SetAPIKeyStore enables authentication with the x-api-key header. Each key
+// may make at most requestsPerMinute requests.
+func (a *UpdatedAuthInterceptor) SetAPIKeyStore(store *auth.APIKeyStore, requestsPerMinute int) {
+	a.apiKeys = store
+	a.apiKeyLimiter = newSlidingWindow(requestsPerMinute, time.Minute)
+}
+
+// SetActivityTracker enables the idle session timeout: token-authenticated
+// requests from sessions idle for longer than the tracker's timeout, or
+// already ended, are rejected, and the others are recorded as activity on
+// their session. API keys are exempt.
+func (a *UpdatedAuthInterceptor) SetActivityTracker(tracker *auth.ActivityTracker) {
+	a.activity = tracker
+}
+
 // Unary returns a unary server interceptor for authentication
 func (a *UpdatedAuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 	return func(
@@ -57,6 +102,10 @@ func (a *UpdatedAuthInterceptor) Unary() grpc.UnaryServerInterceptor {
 			return nil, err
 		}
 
+		if err := a.authorize(newCtx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
 		return handler(newCtx, req)
 	}
 }
@@ -80,6 +129,10 @@ func (a *UpdatedAuthInterceptor) Stream() grpc.StreamServerInterceptor {
 			return err
 		}
 
+		if err := a.authorize(newCtx, info.FullMethod); err != nil {
+			return err
+		}
+
 		// Wrap the stream with authenticated context
 		wrappedStream := &authenticatedServerStream{
 			ServerStream: stream,
@@ -90,13 +143,17 @@ func (a *UpdatedAuthInterceptor) Stream() grpc.StreamServerInterceptor {
 	}
 }
 
-// authenticate extracts and validates the JWT token from metadata
+// authenticate extracts and validates the JWT token or API key from metadata
 func (a *UpdatedAuthInterceptor) authenticate(ctx context.Context) (context.Context, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "missing metadata")
 	}
 
+	if apiKeys := md.Get(APIKeyHeader); len(apiKeys) > 0 && a.apiKeys != nil {
+		return a.authenticateAPIKey(ctx, apiKeys[0])
+	}
+
 	// Extract authorization header
 	authHeaders := md.Get("authorization")
 	if len(authHeaders) == 0 {
@@ -110,19 +167,138 @@ func (a *UpdatedAuthInterceptor) authenticate(ctx context.Context) (context.Cont
 	}
 
 	// Validate token
-	claims, err := a.tokenManager.ValidateAccessToken(token)
+	claims, expiringSoon, err := a.tokenManager.ValidateAndCheckRefresh(ctx, token)
 	if err != nil {
 		return nil, status.Error(codes.Unauthenticated, "invalid token")
 	}
 
-	// Add user information to context using new context keys
+	if err := a.checkActivity(ctx, claims.SessionID); err != nil {
+		return nil, err
+	}
+
+	if expiringSoon {
+		// Best effort: fails only outside a real gRPC call
+		secondsLeft := max(int(time.Until(claims.ExpiresAt.Time).Seconds()), 0)
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(TokenExpiringTrailer, strconv.Itoa(secondsLeft)))
+	}
+
+	// Always write the typed keys read by the Get*FromContext helpers
 	ctx = context.WithValue(ctx, ContextKeyUserID, claims.UserID)
 	ctx = context.WithValue(ctx, ContextKeyUserEmail, claims.Email)
 	ctx = context.WithValue(ctx, ContextKeyUserRole, claims.Role)
+	ctx = context.WithValue(ctx, ContextKeyTokenID, claims.ID)
+	if claims.ExpiresAt != nil {
+		ctx = context.WithValue(ctx, ContextKeyTokenExp, claims.ExpiresAt.Time)
+	}
+
+	return ctx, nil
+}
+
+// checkActivity enforces the idle session timeout of the token's session, if
+// enabled. Tokens not bound to a session are rejected.
+func (a *UpdatedAuthInterceptor) checkActivity(ctx context.Context, sessionID string) error {
+	if a.activity == nil {
+		return nil
+	}
+
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	if err := a.activity.Touch(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrIdleSession):
+			return status.Error(codes.Unauthenticated, "session has timed out, please login again")
+		case errors.Is(err, auth.ErrInvalidSession):
+			return status.Error(codes.Unauthenticated, "invalid token")
+		default:
+			return status.Error(codes.Internal, "failed to check session activity")
+		}
+	}
+	return nil
+}
+
+// authenticateAPIKey resolves an API key to its owner and scopes
+func (a *UpdatedAuthInterceptor) authenticateAPIKey(ctx context.Context, rawKey string) (context.Context, error) {
+	key, err := a.apiKeys.Authenticate(ctx, rawKey)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrRevokedAPIKey), errors.Is(err, auth.ErrExpiredAPIKey):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, auth.ErrInvalidAPIKey):
+			return nil, status.Error(codes.Unauthenticated, "invalid API key")
+		default:
+			return nil, status.Error(codes.Internal, "failed to authenticate API key")
+		}
+	}
+
+	if !a.apiKeyLimiter.allow(key.ID.String()) {
+		return nil, status.Error(codes.ResourceExhausted, "API key rate limit exceeded, please try again later")
+	}
+
+	owner := key.Edges.Owner
+	ctx = context.WithValue(ctx, ContextKeyUserID, owner.ID.String())
+	ctx = context.WithValue(ctx, ContextKeyUserEmail, owner.Email)
+	ctx = context.WithValue(ctx, ContextKeyUserRole, string(owner.Role))
+	ctx = context.WithValue(ctx, ContextKeyAPIKeyID, key.ID.String())
+	ctx = context.WithValue(ctx, ContextKeyAPIScopes, key.Scopes)
 
 	return ctx, nil
 }
 
+// apiKeyScope returns the scope an API key needs to call method, or "" if
+// API keys may not call it at all. Only the task service is open to keys;
+// account management requires an interactive login.
+func apiKeyScope(method string) string {
+	service, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	if service != "task.v1.TaskService" {
+		return ""
+	}
+
+	for _, prefix := range []string{"Get", "List", "Watch", "BatchGet"} {
+		if strings.HasPrefix(name, prefix) {
+			return auth.ScopeTasksRead
+		}
+	}
+	return auth.ScopeTasksWrite
+}
+
+// authorize enforces API key scopes and the roles configured for the method
+func (a *UpdatedAuthInterceptor) authorize(ctx context.Context, method string) error {
+	if scopes, ok := ctx.Value(ContextKeyAPIScopes).([]string); ok {
+		required := apiKeyScope(method)
+		if required == "" {
+			return status.Error(codes.PermissionDenied, "method is not available to API keys")
+		}
+		if !slices.Contains(scopes, required) {
+			return status.Errorf(codes.PermissionDenied, "API key scope %s required", required)
+		}
+	}
+
+	roles, ok := a.methodRoles[method]
+	if !ok {
+		return nil
+	}
+	return RequireRole(ctx, roles...)
+}
+
+// RequireRole returns a PermissionDenied error unless the caller's role is one of roles
+func RequireRole(ctx context.Context, roles ...string) error {
+	userRole, ok := GetUserRoleFromContext(ctx)
+	if !ok {
+		return status.Error(codes.PermissionDenied, "role required")
+	}
+
+	for _, role := range roles {
+		if userRole == role {
+			return nil
+		}
+	}
+
+	return status.Errorf(codes.PermissionDenied, "one of roles %v required", roles)
+}
+
 // authenticatedServerStream wraps grpc.ServerStream with authenticated context
 type authenticatedServerStream struct {
 	grpc.ServerStream
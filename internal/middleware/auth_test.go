@@ -0,0 +1,239 @@
+// internal/middleware/auth_test.go
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/gurkanbulca/taskmaster/ent/generated/enttest"
+	"github.com/gurkanbulca/taskmaster/pkg/auth"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestAuthInterceptor() (*UpdatedAuthInterceptor, *auth.TokenManager) {
+	tokenManager := auth.NewTokenManager("test-access-secret", "test-refresh-secret", 15*time.Minute, 7*24*time.Hour)
+	interceptor := NewUpdatedAuthInterceptor(tokenManager)
+	interceptor.SetMethodRoles(map[string][]string{
+		"/auth.v1.AuthService/UnlockAccount": {"admin"},
+		"/task.v1.TaskService/ListTasks":     {"admin", "manager"},
+	})
+	return interceptor, tokenManager
+}
+
+func callWithToken(interceptor *UpdatedAuthInterceptor, method, token string) (bool, error) {
+	ctx := context.Background()
+	if token != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return "ok", nil
+	}
+
+	_, err := interceptor.Unary()(ctx, nil, info, handler)
+	return called, err
+}
+
+func TestUpdatedAuthInterceptor_MethodRoles(t *testing.T) {
+	interceptor, tokenManager := newTestAuthInterceptor()
+
+	tokenFor := func(role string) string {
+		accessToken, _, _, err := tokenManager.GenerateTokenPair("user-1", "user@example.com", "user", role)
+		require.NoError(t, err)
+		return accessToken
+	}
+
+	tests := []struct {
+		name         string
+		method       string
+		token        string
+		expectedCode codes.Code
+	}{
+		{name: "admin method allows admin", method: "/auth.v1.AuthService/UnlockAccount", token: tokenFor("admin"), expectedCode: codes.OK},
+		{name: "admin method denies user", method: "/auth.v1.AuthService/UnlockAccount", token: tokenFor("user"), expectedCode: codes.PermissionDenied},
+		{name: "admin method denies manager", method: "/auth.v1.AuthService/UnlockAccount", token: tokenFor("manager"), expectedCode: codes.PermissionDenied},
+		{name: "admin method still requires a token", method: "/auth.v1.AuthService/UnlockAccount", token: "", expectedCode: codes.Unauthenticated},
+		{name: "multi-role method allows manager", method: "/task.v1.TaskService/ListTasks", token: tokenFor("manager"), expectedCode: codes.OK},
+		{name: "public method needs no token", method: "/auth.v1.AuthService/Login", token: "", expectedCode: codes.OK},
+		{name: "default-protected method allows any role", method: "/auth.v1.AuthService/GetMe", token: tokenFor("user"), expectedCode: codes.OK},
+		{name: "default-protected method requires a token", method: "/auth.v1.AuthService/GetMe", token: "", expectedCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called, err := callWithToken(interceptor, tt.method, tt.token)
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.Equal(t, tt.expectedCode == codes.OK, called)
+		})
+	}
+}
+
+// trailerStream records the trailer an interceptor sets on a unary call
+type trailerStream struct {
+	trailer metadata.MD
+}
+
+func (s *trailerStream) Method() string               { return "" }
+func (s *trailerStream) SetHeader(metadata.MD) error  { return nil }
+func (s *trailerStream) SendHeader(metadata.MD) error { return nil }
+func (s *trailerStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+func TestUpdatedAuthInterceptor_TokenExpiringTrailer(t *testing.T) {
+	tests := []struct {
+		name     string
+		lifetime time.Duration
+		expiring bool
+	}{
+		{name: "fresh token", lifetime: 15 * time.Minute, expiring: false},
+		{name: "token about to expire", lifetime: time.Minute, expiring: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenManager := auth.NewTokenManager("test-access-secret", "test-refresh-secret", tt.lifetime, time.Hour)
+			tokenManager.SetRefreshThreshold(2 * time.Minute)
+			interceptor := NewUpdatedAuthInterceptor(tokenManager)
+
+			accessToken, _, _, err := tokenManager.GenerateTokenPair("user-1", "user@example.com", "user", "user")
+			require.NoError(t, err)
+
+			stream := &trailerStream{}
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+accessToken))
+			ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+			info := &grpc.UnaryServerInfo{FullMethod: "/auth.v1.AuthService/GetMe"}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "ok", nil
+			}
+
+			_, err = interceptor.Unary()(ctx, nil, info, handler)
+			require.NoError(t, err)
+
+			values := stream.trailer.Get(TokenExpiringTrailer)
+			if !tt.expiring {
+				assert.Empty(t, values)
+				return
+			}
+			require.Len(t, values, 1)
+			secondsLeft, err := strconv.Atoi(values[0])
+			require.NoError(t, err)
+			assert.InDelta(t, 60, secondsLeft, 5)
+		})
+	}
+}
+
+func TestUpdatedAuthInterceptor_IdleTimeout(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:idle?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	interceptor, tokenManager := newTestAuthInterceptor()
+	interceptor.SetActivityTracker(auth.NewActivityTracker(client, time.Hour))
+
+	tests := []struct {
+		name         string
+		lastActivity time.Time
+		expectedCode codes.Code
+	}{
+		{name: "active session allowed", lastActivity: time.Now().Add(-5 * time.Minute), expectedCode: codes.OK},
+		{name: "idle session rejected", lastActivity: time.Now().Add(-2 * time.Hour), expectedCode: codes.Unauthenticated},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := client.User.Create().
+				SetEmail(fmt.Sprintf("idle%d@example.com", i)).
+				SetUsername(fmt.Sprintf("idleuser%d", i)).
+				SetPasswordHash("hash").
+				SaveX(context.Background())
+			sess := client.Session.Create().
+				SetUserID(u.ID).
+				SetRefreshTokenHash(fmt.Sprintf("idle%d", i)).
+				SetExpiresAt(time.Now().Add(24 * time.Hour)).
+				SetLastActivityAt(tt.lastActivity).
+				SaveX(context.Background())
+
+			accessToken, _, _, err := tokenManager.GenerateTokenPairFor(sess.ID.String(), u.ID.String(), u.Email, u.Username, "user", false)
+			require.NoError(t, err)
+
+			called, err := callWithToken(interceptor, "/auth.v1.AuthService/GetMe", accessToken)
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			assert.Equal(t, tt.expectedCode == codes.OK, called)
+
+			// Only allowed requests count as activity
+			reloaded := client.Session.GetX(context.Background(), sess.ID)
+			if tt.expectedCode == codes.OK {
+				assert.WithinDuration(t, time.Now(), *reloaded.LastActivityAt, time.Minute)
+			} else {
+				assert.WithinDuration(t, tt.lastActivity, *reloaded.LastActivityAt, time.Second)
+			}
+		})
+	}
+
+	t.Run("token without session rejected", func(t *testing.T) {
+		accessToken, _, _, err := tokenManager.GenerateTokenPair(uuid.New().String(), "nosession@example.com", "nosession", "user")
+		require.NoError(t, err)
+
+		called, err := callWithToken(interceptor, "/auth.v1.AuthService/GetMe", accessToken)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.False(t, called)
+	})
+
+	t.Run("ended session rejected", func(t *testing.T) {
+		accessToken, _, _, err := tokenManager.GenerateTokenPairFor(uuid.New().String(), uuid.New().String(), "ended@example.com", "ended", "user", false)
+		require.NoError(t, err)
+
+		called, err := callWithToken(interceptor, "/auth.v1.AuthService/GetMe", accessToken)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.False(t, called)
+	})
+}
+
+func TestRequireRole(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ContextKeyUserRole, "manager")
+
+	assert.NoError(t, RequireRole(ctx, "admin", "manager"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(RequireRole(ctx, "admin")))
+	assert.Equal(t, codes.PermissionDenied, status.Code(RequireRole(context.Background(), "user")))
+}
+
+func TestAPIKeyScope(t *testing.T) {
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{method: "/task.v1.TaskService/GetTask", want: auth.ScopeTasksRead},
+		{method: "/task.v1.TaskService/ListTasks", want: auth.ScopeTasksRead},
+		{method: "/task.v1.TaskService/WatchTasks", want: auth.ScopeTasksRead},
+		{method: "/task.v1.TaskService/BatchGetTasks", want: auth.ScopeTasksRead},
+		{method: "/task.v1.TaskService/BatchUpdateTaskStatus", want: auth.ScopeTasksWrite},
+		{method: "/task.v1.TaskService/BatchDeleteTasks", want: auth.ScopeTasksWrite},
+		{method: "/task.v1.TaskService/CreateTask", want: auth.ScopeTasksWrite},
+		{method: "/task.v1.TaskService/DeleteTask", want: auth.ScopeTasksWrite},
+		{method: "/auth.v1.AuthService/GetMe", want: ""},
+		{method: "/auth.v1.AuthService/CreateAPIKey", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			assert.Equal(t, tt.want, apiKeyScope(tt.method))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// internal/middleware/authz.go
+package middleware
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gurkanbulca/taskmaster/pkg/authz"
+)
+
+// DefaultMethodPermissions returns the permissions required by admin-only methods
+func DefaultMethodPermissions() map[string]authz.Permission {
+	return map[string]authz.Permission{
+		"/auth.v1.AuthService/UnlockAccount":      authz.AccountUnlock,
+		"/auth.v1.AuthService/ListLockedAccounts": authz.AccountUnlock,
+		"/auth.v1.AuthService/SetUserActive":      authz.UserManage,
+		"/auth.v1.AuthService/ListUsers":          authz.UserManage,
+		"/auth.v1.AuthService/GetUserByID":        authz.UserManage,
+		"/auth.v1.AuthService/UpdateUserRole":     authz.UserManage,
+		"/auth.v1.AuthService/BulkImportUsers":    authz.UserManage,
+		"/auth.v1.AuthService/CreateInvite":       authz.UserManage,
+		"/auth.v1.AuthService/PreviewEmail":       authz.UserManage,
+	}
+}
+
+// RequirePermission returns a unary server interceptor that rejects calls to
+// the mapped methods unless the caller's role grants the permission. It must
+// run after the auth interceptor has put the role into the context.
+func RequirePermission(methodPermissions map[string]authz.Permission) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		permission, ok := methodPermissions[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		role, _ := GetUserRoleFromContext(ctx)
+		if !authz.Can(role, permission) {
+			return nil, status.Errorf(codes.PermissionDenied, "permission %s required", permission)
+		}
+
+		return handler(ctx, req)
+	}
+}
@@ -3,11 +3,16 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
 	"net"
+	"strings"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+
+	"github.com/gurkanbulca/taskmaster/pkg/i18n"
 )
 
 // ContextKeys for storing request metadata
@@ -19,16 +24,29 @@ const (
 	ContextKeyUserID    ContextKey = "user_id"
 	ContextKeyUserEmail ContextKey = "user_email"
 	ContextKeyUserRole  ContextKey = "user_role"
+	ContextKeyTokenID   ContextKey = "token_id"
+	ContextKeyTokenExp  ContextKey = "token_expires_at"
+	ContextKeyAPIKeyID  ContextKey = "api_key_id"
+	ContextKeyAPIScopes ContextKey = "api_key_scopes"
+	ContextKeyLocale    ContextKey = "locale"
 )
 
 // MetadataExtractorInterceptor extracts client metadata and adds it to context
-type MetadataExtractorInterceptor struct{}
+type MetadataExtractorInterceptor struct {
+	proxySecret string
+}
 
 // NewMetadataExtractorInterceptor creates a new metadata extractor interceptor
 func NewMetadataExtractorInterceptor() *MetadataExtractorInterceptor {
 	return &MetadataExtractorInterceptor{}
 }
 
+// SetProxySecret trusts forwarded-for metadata on requests that carry secret
+// in ProxySecretHeader. Without it the peer address is always used.
+func (m *MetadataExtractorInterceptor) SetProxySecret(secret string) {
+	m.proxySecret = secret
+}
+
 // Unary returns a unary server interceptor for metadata extraction
 func (m *MetadataExtractorInterceptor) Unary() grpc.UnaryServerInterceptor {
 	return func(
@@ -64,10 +82,15 @@ func (m *MetadataExtractorInterceptor) Stream() grpc.StreamServerInterceptor {
 	}
 }
 
-// enrichContext extracts IP address and user agent from the context
+// enrichContext extracts IP address, user agent and preferred locale from the context
 func (m *MetadataExtractorInterceptor) enrichContext(ctx context.Context) context.Context {
-	// Extract IP address from peer info
+	// Extract IP address from peer info, or from the gateway's forwarded-for
 	ipAddress := extractIPAddress(ctx)
+	if m.fromProxy(ctx) {
+		if forwarded := forwardedFor(ctx); forwarded != "" {
+			ipAddress = forwarded
+		}
+	}
 	if ipAddress != "" {
 		ctx = context.WithValue(ctx, ContextKeyIPAddress, ipAddress)
 	}
@@ -78,9 +101,38 @@ func (m *MetadataExtractorInterceptor) enrichContext(ctx context.Context) contex
 		ctx = context.WithValue(ctx, ContextKeyUserAgent, userAgent)
 	}
 
+	// Extract the most preferred language from Accept-Language
+	if locales := extractLocales(ctx); len(locales) > 0 {
+		ctx = context.WithValue(ctx, ContextKeyLocale, locales[0])
+	}
+
 	return ctx
 }
 
+// ForwardedForHeader carries the original client address for requests relayed
+// by the HTTP gateway. It is only trusted alongside ProxySecretHeader, since
+// any other client could set it to dodge per-IP limits.
+const ForwardedForHeader = "x-forwarded-for"
+
+// ProxySecretHeader carries the per-process secret the HTTP gateway uses to
+// show that its forwarded-for metadata can be trusted
+const ProxySecretHeader = "x-proxy-secret"
+
+// fromProxy reports whether the request carries the configured proxy secret
+func (m *MetadataExtractorInterceptor) fromProxy(ctx context.Context) bool {
+	if m.proxySecret == "" {
+		return false
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	values := md.Get(ProxySecretHeader)
+	return len(values) == 1 && subtle.ConstantTimeCompare([]byte(values[0]), []byte(m.proxySecret)) == 1
+}
+
 // extractIPAddress extracts the client IP address from the context
 func extractIPAddress(ctx context.Context) string {
 	// Get peer information
@@ -106,6 +158,22 @@ func extractIPAddress(ctx context.Context) string {
 	return host
 }
 
+// forwardedFor returns the first address in the forwarded-for metadata
+func forwardedFor(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	values := md.Get(ForwardedForHeader)
+	if len(values) == 0 {
+		return ""
+	}
+
+	first, _, _ := strings.Cut(values[0], ",")
+	return strings.TrimSpace(first)
+}
+
 // extractUserAgent extracts the user agent from gRPC metadata
 func extractUserAgent(ctx context.Context) string {
 	md, ok := metadata.FromIncomingContext(ctx)
@@ -113,11 +181,13 @@ func extractUserAgent(ctx context.Context) string {
 		return ""
 	}
 
-	// Check common user agent headers
+	// Check common user agent headers. x-user-agent comes first because
+	// proxies such as the HTTP gateway set it for the original client, while
+	// user-agent then names the proxy's own gRPC client.
 	userAgentHeaders := []string{
+		"x-user-agent",
 		"user-agent",
 		"grpc-user-agent",
-		"x-user-agent",
 	}
 
 	for _, header := range userAgentHeaders {
@@ -129,6 +199,20 @@ func extractUserAgent(ctx context.Context) string {
 	return ""
 }
 
+// AcceptLanguageHeader lists the client's preferred languages
+const AcceptLanguageHeader = "accept-language"
+
+// extractLocales returns the Accept-Language tags from gRPC metadata, most
+// preferred first
+func extractLocales(ctx context.Context) []string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	return i18n.ParseAcceptLanguage(strings.Join(md.Get(AcceptLanguageHeader), ","))
+}
+
 // enrichedServerStream wraps grpc.ServerStream with enriched context
 type enrichedServerStream struct {
 	grpc.ServerStream
@@ -157,38 +241,104 @@ func GetUserAgentFromContext(ctx context.Context) string {
 	return ""
 }
 
-// GetUserIDFromContext extracts user ID from context (updated to use new key)
-func GetUserIDFromContext(ctx context.Context) (string, bool) {
-	if userID, ok := ctx.Value(ContextKeyUserID).(string); ok {
-		return userID, true
+// GetLocaleFromContext extracts the client's preferred locale from context
+func GetLocaleFromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(ContextKeyLocale).(string); ok {
+		return locale
 	}
-	// Fallback to old method for backward compatibility
-	if userID, ok := ctx.Value("user_id").(string); ok {
-		return userID, true
+	return ""
+}
+
+// stringFromContext reads a string stored under the typed key. Values stored
+// under the equivalent raw string key by older code are still honored, so
+// readers and writers cannot silently disagree about which key is in use.
+func stringFromContext(ctx context.Context, key ContextKey) (string, bool) {
+	if value, ok := ctx.Value(key).(string); ok {
+		return value, true
+	}
+	// Fallback to the untyped key for backward compatibility
+	if value, ok := ctx.Value(string(key)).(string); ok {
+		return value, true
 	}
 	return "", false
 }
 
-// GetUserRoleFromContext extracts user role from context (updated to use new key)
+// GetUserIDFromContext extracts user ID from context
+func GetUserIDFromContext(ctx context.Context) (string, bool) {
+	return stringFromContext(ctx, ContextKeyUserID)
+}
+
+// GetUserRoleFromContext extracts user role from context
 func GetUserRoleFromContext(ctx context.Context) (string, bool) {
-	if role, ok := ctx.Value(ContextKeyUserRole).(string); ok {
-		return role, true
+	return stringFromContext(ctx, ContextKeyUserRole)
+}
+
+// GetUserEmailFromContext extracts user email from context
+func GetUserEmailFromContext(ctx context.Context) (string, bool) {
+	return stringFromContext(ctx, ContextKeyUserEmail)
+}
+
+// GetTokenIDFromContext extracts the access token ID (jti) from context
+func GetTokenIDFromContext(ctx context.Context) (string, bool) {
+	if jti, ok := ctx.Value(ContextKeyTokenID).(string); ok && jti != "" {
+		return jti, true
 	}
-	// Fallback to old method for backward compatibility
-	if role, ok := ctx.Value("user_role").(string); ok {
-		return role, true
+	return "", false
+}
+
+// GetTokenExpiresAtFromContext extracts the access token expiry from context
+func GetTokenExpiresAtFromContext(ctx context.Context) (time.Time, bool) {
+	if exp, ok := ctx.Value(ContextKeyTokenExp).(time.Time); ok {
+		return exp, true
+	}
+	return time.Time{}, false
+}
+
+// GetAPIKeyIDFromContext extracts the ID of the API key that authenticated
+// the request; ok is false for requests authenticated with a Bearer token
+func GetAPIKeyIDFromContext(ctx context.Context) (string, bool) {
+	if id, ok := ctx.Value(ContextKeyAPIKeyID).(string); ok && id != "" {
+		return id, true
 	}
 	return "", false
 }
 
-// GetUserEmailFromContext extracts user email from context
-func GetUserEmailFromContext(ctx context.Context) (string, bool) {
-	if email, ok := ctx.Value(ContextKeyUserEmail).(string); ok {
-		return email, true
+// IdempotencyKeyHeader is the metadata header clients set to make retries safe
+const IdempotencyKeyHeader = "idempotency-key"
+
+// MaxIdempotencyKeyLength is the longest idempotency key accepted
+const MaxIdempotencyKeyLength = 255
+
+// GetIdempotencyKeyFromContext extracts the idempotency key from incoming metadata
+func GetIdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	if values := md.Get(IdempotencyKeyHeader); len(values) > 0 {
+		if key := strings.TrimSpace(values[0]); key != "" {
+			return key, true
+		}
 	}
-	// Fallback to old method for backward compatibility
-	if email, ok := ctx.Value("user_email").(string); ok {
-		return email, true
+	return "", false
+}
+
+// CaptchaTokenHeader is the metadata header carrying a solved CAPTCHA token,
+// for clients that cannot set the request field
+const CaptchaTokenHeader = "x-captcha-token"
+
+// GetCaptchaTokenFromContext extracts the CAPTCHA token from incoming metadata
+func GetCaptchaTokenFromContext(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	if values := md.Get(CaptchaTokenHeader); len(values) > 0 {
+		if token := strings.TrimSpace(values[0]); token != "" {
+			return token, true
+		}
 	}
 	return "", false
 }
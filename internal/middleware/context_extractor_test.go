@@ -0,0 +1,111 @@
+// internal/middleware/context_extractor_test.go
+package middleware
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestGetClientInfoFromContext_AfterAuthInterceptor(t *testing.T) {
+	interceptor, tokenManager := newTestAuthInterceptor()
+
+	accessToken, _, _, err := tokenManager.GenerateTokenPair("user-42", "user42@example.com", "user42", "manager")
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+accessToken))
+	info := &grpc.UnaryServerInfo{FullMethod: "/auth.v1.AuthService/GetMe"}
+
+	var clientInfo *ClientInfo
+	var userID string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		clientInfo = GetClientInfoFromContext(ctx)
+		userID, _ = GetUserIDFromContext(ctx)
+		return "ok", nil
+	}
+
+	_, err = interceptor.Unary()(ctx, nil, info, handler)
+	require.NoError(t, err)
+
+	require.NotNil(t, clientInfo)
+	assert.Equal(t, "user-42", clientInfo.UserID)
+	assert.Equal(t, "user42@example.com", clientInfo.UserEmail)
+	assert.Equal(t, "manager", clientInfo.UserRole)
+	assert.Equal(t, clientInfo.UserID, userID)
+}
+
+func TestMetadataExtractor_ForwardedFor(t *testing.T) {
+	extractor := NewMetadataExtractorInterceptor()
+	extractor.SetProxySecret("gateway-secret")
+
+	ipFor := func(pairs ...string) string {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 5000}})
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(pairs...))
+		var ip string
+		_, err := extractor.Unary()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			ip = GetIPAddressFromContext(ctx)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		return ip
+	}
+
+	assert.Equal(t, "203.0.113.7", ipFor(ForwardedForHeader, "203.0.113.7, 10.0.0.1", ProxySecretHeader, "gateway-secret"))
+
+	// Loopback peers are not trusted on their own, e.g. behind a sidecar
+	assert.Equal(t, "127.0.0.1", ipFor(ForwardedForHeader, "203.0.113.7"))
+	assert.Equal(t, "127.0.0.1", ipFor(ForwardedForHeader, "203.0.113.7", ProxySecretHeader, "guess"))
+
+	// Without a configured secret the header is never trusted
+	extractor.SetProxySecret("")
+	assert.Equal(t, "127.0.0.1", ipFor(ForwardedForHeader, "203.0.113.7", ProxySecretHeader, ""))
+}
+
+func TestGetUserIDFromContext_LegacyStringKey(t *testing.T) {
+	//nolint:staticcheck // simulates values written by older code with raw string keys
+	ctx := context.WithValue(context.Background(), "user_id", "legacy-user")
+
+	userID, ok := GetUserIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "legacy-user", userID)
+	assert.Equal(t, "legacy-user", GetClientInfoFromContext(ctx).UserID)
+
+	// The typed key wins when both are present
+	ctx = context.WithValue(ctx, ContextKeyUserID, "typed-user")
+	userID, ok = GetUserIDFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "typed-user", userID)
+
+	_, ok = GetUserIDFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestGetIdempotencyKeyFromContext(t *testing.T) {
+	_, ok := GetIdempotencyKeyFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyHeader, "  retry-123 "))
+	key, ok := GetIdempotencyKeyFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "retry-123", key)
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(IdempotencyKeyHeader, "   "))
+	_, ok = GetIdempotencyKeyFromContext(ctx)
+	assert.False(t, ok)
+}
+
+func TestGetCaptchaTokenFromContext(t *testing.T) {
+	_, ok := GetCaptchaTokenFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(CaptchaTokenHeader, " solved "))
+	token, ok := GetCaptchaTokenFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "solved", token)
+}
@@ -0,0 +1,62 @@
+// internal/middleware/deadline.go
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeadlineInterceptor bounds how long unary handlers may run, so a stuck
+// database call is cancelled instead of hanging the request. Streams such as
+// WatchTasks are long-lived by design and are not affected.
+type DeadlineInterceptor struct {
+	defaultTimeout time.Duration
+	methodTimeouts map[string]time.Duration
+}
+
+// NewDeadlineInterceptor applies defaultTimeout to every unary method except
+// those listed in methodTimeouts. A timeout of 0 leaves the method unbounded.
+func NewDeadlineInterceptor(defaultTimeout time.Duration, methodTimeouts map[string]time.Duration) *DeadlineInterceptor {
+	return &DeadlineInterceptor{
+		defaultTimeout: defaultTimeout,
+		methodTimeouts: methodTimeouts,
+	}
+}
+
+// Unary returns a unary server interceptor that enforces the timeout. A
+// shorter deadline set by the client still wins.
+func (d *DeadlineInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		timeout := d.timeoutFor(info.FullMethod)
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		resp, err := handler(ctx, req)
+		// Handlers usually wrap the cancelled DB call as Internal; report the real cause
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, status.Error(codes.DeadlineExceeded, "request timed out")
+		}
+		return resp, err
+	}
+}
+
+func (d *DeadlineInterceptor) timeoutFor(method string) time.Duration {
+	if timeout, ok := d.methodTimeouts[method]; ok {
+		return timeout
+	}
+	return d.defaultTimeout
+}
@@ -0,0 +1,104 @@
+// internal/middleware/deadline_test.go
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// slowHandler stands in for a handler stuck on a database call that honors
+// cancellation, reporting whether it was cancelled
+func slowHandler(cancelled chan<- bool) grpc.UnaryHandler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			cancelled <- true
+			return nil, status.Error(codes.Internal, "failed to query tasks")
+		case <-time.After(time.Second):
+			cancelled <- false
+			return "done", nil
+		}
+	}
+}
+
+func TestDeadlineInterceptor_CancelsSlowHandler(t *testing.T) {
+	interceptor := NewDeadlineInterceptor(20*time.Millisecond, nil).Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: "/task.v1.TaskService/ListTasks"}
+
+	cancelled := make(chan bool, 1)
+	start := time.Now()
+	resp, err := interceptor(context.Background(), nil, info, slowHandler(cancelled))
+
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.True(t, <-cancelled)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestDeadlineInterceptor_MethodTimeouts(t *testing.T) {
+	interceptor := NewDeadlineInterceptor(20*time.Millisecond, map[string]time.Duration{
+		"/task.v1.TaskService/ListTasks":            time.Minute,
+		"/auth.v1.AuthService/ExportSecurityEvents": 0,
+	}).Unary()
+
+	tests := []struct {
+		name         string
+		method       string
+		ctx          func() (context.Context, context.CancelFunc)
+		wantDeadline time.Duration
+	}{
+		{
+			name:         "default timeout",
+			method:       "/task.v1.TaskService/GetTask",
+			wantDeadline: 20 * time.Millisecond,
+		},
+		{
+			name:         "per-method override",
+			method:       "/task.v1.TaskService/ListTasks",
+			wantDeadline: time.Minute,
+		},
+		{
+			name:   "disabled for method",
+			method: "/auth.v1.AuthService/ExportSecurityEvents",
+		},
+		{
+			name:   "shorter client deadline wins",
+			method: "/task.v1.TaskService/ListTasks",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 5*time.Second)
+			},
+			wantDeadline: 5 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.ctx != nil {
+				var cancel context.CancelFunc
+				ctx, cancel = tt.ctx()
+				defer cancel()
+			}
+
+			info := &grpc.UnaryServerInfo{FullMethod: tt.method}
+			_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				deadline, ok := ctx.Deadline()
+				if tt.wantDeadline == 0 {
+					assert.False(t, ok)
+				} else {
+					require.True(t, ok)
+					assert.WithinDuration(t, time.Now().Add(tt.wantDeadline), deadline, 10*time.Millisecond)
+				}
+				return "ok", nil
+			})
+			require.NoError(t, err)
+		})
+	}
+}
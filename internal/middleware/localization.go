@@ -0,0 +1,75 @@
+// internal/middleware/localization.go
+package middleware
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/gurkanbulca/taskmaster/pkg/i18n"
+)
+
+// LocalizationInterceptor translates the messages of errors returned to
+// clients into the languages listed in their Accept-Language metadata.
+// Codes and details are left as they are, so clients should keep matching
+// on those rather than on message text.
+type LocalizationInterceptor struct{}
+
+// NewLocalizationInterceptor creates a new localization interceptor
+func NewLocalizationInterceptor() *LocalizationInterceptor {
+	return &LocalizationInterceptor{}
+}
+
+// Unary returns a unary server interceptor that localizes returned errors
+func (l *LocalizationInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		return resp, localizeError(ctx, err)
+	}
+}
+
+// Stream returns a stream server interceptor that localizes returned errors
+func (l *LocalizationInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return localizeError(stream.Context(), handler(srv, stream))
+	}
+}
+
+// localizeError replaces the message of a status error with its translation
+// for the request's preferred locales, if the catalog has one
+func localizeError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	locales := extractLocales(ctx)
+	if len(locales) == 0 {
+		return err
+	}
+
+	translated := i18n.Translate(st.Message(), locales...)
+	if translated == st.Message() {
+		return err
+	}
+
+	// Rebuild from the proto so details survive
+	p := st.Proto()
+	p.Message = translated
+	return status.ErrorProto(p)
+}
@@ -0,0 +1,88 @@
+// internal/middleware/localization_test.go
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestLocalizationInterceptor_Unary(t *testing.T) {
+	interceptor := NewLocalizationInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/task.v1.TaskService/GetTask"}
+
+	call := func(acceptLanguage string, handlerErr error) error {
+		ctx := context.Background()
+		if acceptLanguage != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(AcceptLanguageHeader, acceptLanguage))
+		}
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, handlerErr
+		}
+		_, err := interceptor.Unary()(ctx, nil, info, handler)
+		return err
+	}
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		wantMessage    string
+	}{
+		{name: "translated", acceptLanguage: "de-DE,de;q=0.9,en;q=0.8", wantMessage: "Aufgabe nicht gefunden"},
+		{name: "weighted preference", acceptLanguage: "de;q=0.5, es", wantMessage: "Tarea no encontrada"},
+		{name: "unsupported locale", acceptLanguage: "fr-FR", wantMessage: "task not found"},
+		{name: "no header", wantMessage: "task not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := call(tt.acceptLanguage, status.Error(codes.NotFound, "task not found"))
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, codes.NotFound, st.Code())
+			assert.Equal(t, tt.wantMessage, st.Message())
+		})
+	}
+
+	t.Run("details are preserved", func(t *testing.T) {
+		st, err := status.New(codes.Unauthenticated, "invalid credentials").
+			WithDetails(wrapperspb.String("INVALID_CREDENTIALS"))
+		require.NoError(t, err)
+
+		localized, ok := status.FromError(call("es", st.Err()))
+		require.True(t, ok)
+		assert.Equal(t, "Credenciales no válidas", localized.Message())
+		require.Len(t, localized.Details(), 1)
+		assert.Equal(t, "INVALID_CREDENTIALS", localized.Details()[0].(*wrapperspb.StringValue).Value)
+	})
+
+	t.Run("non-status errors pass through", func(t *testing.T) {
+		plain := errors.New("task not found")
+		assert.Same(t, plain, call("de", plain))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		assert.NoError(t, call("de", nil))
+	})
+}
+
+func TestMetadataExtractor_Locale(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(AcceptLanguageHeader, "en;q=0.4, pt-BR"))
+
+	var locale string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		locale = GetLocaleFromContext(ctx)
+		return "ok", nil
+	}
+	_, err := NewMetadataExtractorInterceptor().Unary()(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "pt-br", locale)
+}
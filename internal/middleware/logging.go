@@ -0,0 +1,46 @@
+// internal/middleware/logging.go
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
+)
+
+// NewLoggingInterceptor returns a unary server interceptor that writes one
+// structured entry per request. It must run after the metadata extractor and
+// auth interceptors so ClientInfo carries the IP address, user ID and role.
+func NewLoggingInterceptor(logger logging.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		start := time.Now()
+		clientInfo := GetClientInfoFromContext(ctx)
+
+		resp, err := handler(ctx, req)
+
+		fields := []any{
+			"method", info.FullMethod,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"user_id", clientInfo.UserID,
+			"role", clientInfo.UserRole,
+			"ip", clientInfo.IPAddress,
+			"code", status.Code(err).String(),
+		}
+
+		if err != nil {
+			logger.Error("request failed", append(fields, "error", err.Error())...)
+		} else {
+			logger.Info("request completed", fields...)
+		}
+
+		return resp, err
+	}
+}
@@ -0,0 +1,104 @@
+// internal/middleware/logging_test.go
+package middleware
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
+)
+
+// chainUnary composes interceptors in the same order as grpc.ChainUnaryInterceptor
+func chainUnary(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+func TestServerChain_PopulatesClientInfo(t *testing.T) {
+	authInterceptor, tokenManager := newTestAuthInterceptor()
+
+	accessToken, _, _, err := tokenManager.GenerateTokenPair("user-7", "user7@example.com", "user7", "admin")
+	require.NoError(t, err)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+accessToken))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.1.2.3"), Port: 5000}})
+
+	// Same order as the server: extractor, auth, then logging
+	chain := chainUnary(
+		NewMetadataExtractorInterceptor().Unary(),
+		authInterceptor.Unary(),
+		NewLoggingInterceptor(logging.NewCaptureLogger()),
+	)
+
+	var clientInfo *ClientInfo
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		clientInfo = GetClientInfoFromContext(ctx)
+		return "ok", nil
+	}
+
+	_, err = chain(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/auth.v1.AuthService/GetMe"}, handler)
+	require.NoError(t, err)
+
+	require.NotNil(t, clientInfo)
+	assert.Equal(t, "user-7", clientInfo.UserID)
+	assert.Equal(t, "admin", clientInfo.UserRole)
+	assert.Equal(t, "10.1.2.3", clientInfo.IPAddress)
+}
+
+func TestLoggingInterceptor_Fields(t *testing.T) {
+	capture := logging.NewCaptureLogger()
+	interceptor := NewLoggingInterceptor(capture)
+
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, ContextKeyUserID, "user-9")
+	ctx = context.WithValue(ctx, ContextKeyUserRole, "user")
+	ctx = context.WithValue(ctx, ContextKeyIPAddress, "10.0.0.9")
+	info := &grpc.UnaryServerInfo{FullMethod: "/task.v1.TaskService/GetTask"}
+
+	// Successful request
+	_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+
+	// Failed request
+	_, err = interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "task not found")
+	})
+	require.Error(t, err)
+
+	entries := capture.Entries()
+	require.Len(t, entries, 2)
+
+	success := entries[0]
+	assert.Equal(t, "INFO", success.Level)
+	assert.Equal(t, "/task.v1.TaskService/GetTask", success.Fields["method"])
+	assert.Equal(t, "user-9", success.Fields["user_id"])
+	assert.Equal(t, "10.0.0.9", success.Fields["ip"])
+	assert.Equal(t, "OK", success.Fields["code"])
+	assert.Contains(t, success.Fields, "duration_ms")
+	assert.NotContains(t, success.Fields, "error")
+
+	failure := entries[1]
+	assert.Equal(t, "ERROR", failure.Level)
+	assert.Equal(t, "NotFound", failure.Fields["code"])
+	assert.Equal(t, "rpc error: code = NotFound desc = task not found", failure.Fields["error"])
+	assert.Equal(t, "user-9", failure.Fields["user_id"])
+}
@@ -0,0 +1,80 @@
+// internal/middleware/metrics.go
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsInterceptor records Prometheus request metrics for every RPC
+type MetricsInterceptor struct {
+	requests      *prometheus.CounterVec
+	latency       *prometheus.HistogramVec
+	activeStreams *prometheus.GaugeVec
+}
+
+// NewMetricsInterceptor creates the collectors and registers them with reg
+func NewMetricsInterceptor(reg prometheus.Registerer) *MetricsInterceptor {
+	m := &MetricsInterceptor{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_requests_total",
+			Help: "Total number of RPCs completed, by method and gRPC status code.",
+		}, []string{"method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_server_request_duration_seconds",
+			Help:    "RPC latency in seconds, by method. Streams are measured until they close.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		activeStreams: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "grpc_server_active_streams",
+			Help: "Number of open server streams, such as WatchTasks, by method.",
+		}, []string{"method"}),
+	}
+
+	reg.MustRegister(m.requests, m.latency, m.activeStreams)
+	return m
+}
+
+// Unary returns a unary server interceptor that records request metrics
+func (m *MetricsInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observe(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// Stream returns a stream server interceptor that records request metrics
+// and tracks the number of open streams
+func (m *MetricsInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		active := m.activeStreams.WithLabelValues(info.FullMethod)
+		active.Inc()
+		defer active.Dec()
+
+		start := time.Now()
+		err := handler(srv, stream)
+		m.observe(info.FullMethod, start, err)
+		return err
+	}
+}
+
+func (m *MetricsInterceptor) observe(method string, start time.Time, err error) {
+	m.requests.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.latency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,90 @@
+// internal/middleware/metrics_test.go
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeServerStream is a minimal grpc.ServerStream for stream interceptor tests
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestMetricsInterceptor_Unary(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics := NewMetricsInterceptor(reg)
+	interceptor := metrics.Unary()
+
+	const method = "/task.v1.TaskService/GetTask"
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	for i := 0; i < 2; i++ {
+		_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+		require.NoError(t, err)
+	}
+
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "task not found")
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.requests.WithLabelValues(method, "OK")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues(method, "NotFound")))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.latency, "grpc_server_request_duration_seconds"))
+
+	// All collectors are exposed through the registry
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	names := make([]string, 0, len(families))
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	assert.Contains(t, names, "grpc_server_requests_total")
+	assert.Contains(t, names, "grpc_server_request_duration_seconds")
+}
+
+func TestMetricsInterceptor_StreamTracksActiveStreams(t *testing.T) {
+	metrics := NewMetricsInterceptor(prometheus.NewRegistry())
+	interceptor := metrics.Stream()
+
+	const method = "/task.v1.TaskService/WatchTasks"
+	info := &grpc.StreamServerInfo{FullMethod: method, IsServerStream: true}
+	active := metrics.activeStreams.WithLabelValues(method)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 1)
+
+	go func() {
+		done <- interceptor(nil, &fakeServerStream{ctx: context.Background()}, info, func(srv interface{}, stream grpc.ServerStream) error {
+			close(started)
+			<-release
+			return status.Error(codes.Canceled, "client went away")
+		})
+	}()
+
+	<-started
+	assert.Equal(t, 1.0, testutil.ToFloat64(active))
+
+	close(release)
+	require.Error(t, <-done)
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(active))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues(method, "Canceled")))
+}
@@ -0,0 +1,122 @@
+// internal/middleware/rate_limit_test.go
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestLoginRateLimiter(maxAttempts int, window time.Duration, clock *time.Time) *LoginRateLimitInterceptor {
+	limiter := NewLoginRateLimitInterceptor(&LoginRateLimitConfig{
+		MaxAttempts: maxAttempts,
+		Window:      window,
+	})
+	limiter.now = func() time.Time { return *clock }
+	return limiter
+}
+
+func callWithIP(limiter *LoginRateLimitInterceptor, method, ip string) error {
+	ctx := context.WithValue(context.Background(), ContextKeyIPAddress, ip)
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	_, err := limiter.Unary()(ctx, nil, info, handler)
+	return err
+}
+
+func TestLoginRateLimitInterceptor_SameIP(t *testing.T) {
+	clock := time.Now()
+	limiter := newTestLoginRateLimiter(3, time.Minute, &clock)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, callWithIP(limiter, loginMethod, "10.0.0.1"))
+	}
+
+	err := callWithIP(limiter, loginMethod, "10.0.0.1")
+	require.Error(t, err)
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+
+	// Once the earliest attempts slide out of the window, the IP may retry
+	clock = clock.Add(time.Minute + time.Second)
+	assert.NoError(t, callWithIP(limiter, loginMethod, "10.0.0.1"))
+}
+
+func TestLoginRateLimitInterceptor_DifferentIPs(t *testing.T) {
+	clock := time.Now()
+	limiter := newTestLoginRateLimiter(2, time.Minute, &clock)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, callWithIP(limiter, loginMethod, "10.0.0.1"))
+	}
+	assert.Error(t, callWithIP(limiter, loginMethod, "10.0.0.1"))
+
+	// Other clients are tracked independently
+	assert.NoError(t, callWithIP(limiter, loginMethod, "10.0.0.2"))
+	assert.NoError(t, callWithIP(limiter, loginMethod, "10.0.0.3"))
+}
+
+func TestLoginRateLimitInterceptor_OtherMethodsUnaffected(t *testing.T) {
+	clock := time.Now()
+	limiter := newTestLoginRateLimiter(1, time.Minute, &clock)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, callWithIP(limiter, "/auth.v1.AuthService/Register", "10.0.0.1"))
+	}
+
+	require.NoError(t, callWithIP(limiter, loginMethod, "10.0.0.1"))
+	assert.Error(t, callWithIP(limiter, loginMethod, "10.0.0.1"))
+}
+
+func TestLoginRateLimitInterceptor_EvictsStaleBuckets(t *testing.T) {
+	clock := time.Now()
+	limiter := newTestLoginRateLimiter(5, time.Minute, &clock)
+
+	require.NoError(t, callWithIP(limiter, loginMethod, "10.0.0.1"))
+	require.NoError(t, callWithIP(limiter, loginMethod, "10.0.0.2"))
+	assert.Len(t, limiter.attempts, 2)
+
+	clock = clock.Add(2 * time.Minute)
+	require.NoError(t, callWithIP(limiter, loginMethod, "10.0.0.3"))
+
+	assert.Len(t, limiter.attempts, 1)
+	assert.Contains(t, limiter.attempts, "10.0.0.3")
+}
+
+func TestMethodRateLimitInterceptor(t *testing.T) {
+	clock := time.Now()
+	limiter := NewMethodRateLimitInterceptor("/auth.v1.AuthService/IntrospectToken", 2, time.Minute)
+	limiter.now = func() time.Time { return clock }
+
+	call := func(method, ip string) error {
+		ctx := context.WithValue(context.Background(), ContextKeyIPAddress, ip)
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		}
+		_, err := limiter.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, call("/auth.v1.AuthService/IntrospectToken", "10.0.0.1"))
+	}
+	err := call("/auth.v1.AuthService/IntrospectToken", "10.0.0.1")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Other clients and methods are unaffected
+	assert.NoError(t, call("/auth.v1.AuthService/IntrospectToken", "10.0.0.2"))
+	assert.NoError(t, call("/auth.v1.AuthService/GetMe", "10.0.0.1"))
+
+	clock = clock.Add(time.Minute + time.Second)
+	assert.NoError(t, call("/auth.v1.AuthService/IntrospectToken", "10.0.0.1"))
+}
@@ -0,0 +1,82 @@
+// internal/middleware/recovery.go
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
+)
+
+// RecoveryInterceptor turns a panicking handler into a codes.Internal error
+// so one bad request can't take the whole server down
+type RecoveryInterceptor struct {
+	logger logging.Logger
+	panics *prometheus.CounterVec
+}
+
+// NewRecoveryInterceptor creates the panic counter and registers it with reg
+func NewRecoveryInterceptor(logger logging.Logger, reg prometheus.Registerer) *RecoveryInterceptor {
+	r := &RecoveryInterceptor{
+		logger: logger,
+		panics: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_server_panics_total",
+			Help: "Total number of handler panics recovered, by method.",
+		}, []string{"method"}),
+	}
+
+	reg.MustRegister(r.panics)
+	return r
+}
+
+// Unary returns a unary server interceptor that recovers from panics
+func (r *RecoveryInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				resp, err = nil, r.recovered(info.FullMethod, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns a stream server interceptor that recovers from panics
+func (r *RecoveryInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = r.recovered(info.FullMethod, p)
+			}
+		}()
+		return handler(srv, stream)
+	}
+}
+
+// recovered logs the panic with its stack and returns the error sent to the
+// client, which deliberately carries none of the panic details
+func (r *RecoveryInterceptor) recovered(method string, p any) error {
+	r.panics.WithLabelValues(method).Inc()
+	r.logger.Error("recovered from panic",
+		"method", method,
+		"panic", fmt.Sprint(p),
+		"stack", string(debug.Stack()),
+	)
+	return status.Error(codes.Internal, "internal server error")
+}
@@ -0,0 +1,100 @@
+// internal/middleware/recovery_test.go
+package middleware
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
+)
+
+// panickingHealthServer panics in every handler
+type panickingHealthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (panickingHealthServer) Check(context.Context, *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	panic("database handle is nil")
+}
+
+func (panickingHealthServer) Watch(*grpc_health_v1.HealthCheckRequest, grpc_health_v1.Health_WatchServer) error {
+	panic("database handle is nil")
+}
+
+func TestRecoveryInterceptor_ServerSurvivesPanics(t *testing.T) {
+	capture := logging.NewCaptureLogger()
+	recovery := NewRecoveryInterceptor(capture, prometheus.NewRegistry())
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(recovery.Unary()),
+		grpc.ChainStreamInterceptor(recovery.Stream()),
+	)
+	grpc_health_v1.RegisterHealthServer(grpcServer, panickingHealthServer{})
+	listener := bufconn.Listen(1024 * 1024)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	// Repeated panics each come back as a bare Internal error
+	for i := 0; i < 2; i++ {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.Internal, st.Code())
+		assert.Equal(t, "internal server error", st.Message())
+	}
+
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	const checkMethod = "/grpc.health.v1.Health/Check"
+	assert.Equal(t, 2.0, testutil.ToFloat64(recovery.panics.WithLabelValues(checkMethod)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(recovery.panics.WithLabelValues("/grpc.health.v1.Health/Watch")))
+
+	entries := capture.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, "ERROR", entries[0].Level)
+	assert.Equal(t, checkMethod, entries[0].Fields["method"])
+	assert.Equal(t, "database handle is nil", entries[0].Fields["panic"])
+	assert.Contains(t, entries[0].Fields["stack"], "panickingHealthServer.Check")
+}
+
+func TestRecoveryInterceptor_PassesThroughResults(t *testing.T) {
+	recovery := NewRecoveryInterceptor(logging.NewCaptureLogger(), prometheus.NewRegistry())
+	info := &grpc.UnaryServerInfo{FullMethod: "/task.v1.TaskService/GetTask"}
+
+	resp, err := recovery.Unary()(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = recovery.Unary()(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "task not found")
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, 0.0, testutil.ToFloat64(recovery.panics.WithLabelValues(info.FullMethod)))
+}
@@ -0,0 +1,116 @@
+// internal/middleware/tracing.go
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tracerName identifies spans created by the tracing interceptor
+const tracerName = "github.com/gurkanbulca/taskmaster/internal/middleware"
+
+// TracingInterceptor starts a server span per RPC, continuing any trace
+// context propagated by the client in gRPC metadata
+type TracingInterceptor struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// NewTracingInterceptor creates a new tracing interceptor
+func NewTracingInterceptor(provider trace.TracerProvider, propagator propagation.TextMapPropagator) *TracingInterceptor {
+	return &TracingInterceptor{
+		tracer:     provider.Tracer(tracerName),
+		propagator: propagator,
+	}
+}
+
+// Unary returns a unary server interceptor that traces each call
+func (t *TracingInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		ctx, span := t.startSpan(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		endSpan(span, err)
+		return resp, err
+	}
+}
+
+// Stream returns a stream server interceptor that traces each stream for its lifetime
+func (t *TracingInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		ctx, span := t.startSpan(stream.Context(), info.FullMethod)
+		err := handler(srv, &enrichedServerStream{ServerStream: stream, ctx: ctx})
+		endSpan(span, err)
+		return err
+	}
+}
+
+func (t *TracingInterceptor) startSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = t.propagator.Extract(ctx, metadataCarrier(md))
+	}
+
+	name := strings.TrimPrefix(fullMethod, "/")
+	service, method, _ := strings.Cut(name, "/")
+
+	return t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", service),
+			attribute.String("rpc.method", method),
+		),
+	)
+}
+
+// endSpan records the gRPC status code on the span and ends it
+func endSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if code != codes.OK {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, code.String())
+	}
+	span.End()
+}
+
+// metadataCarrier adapts incoming gRPC metadata to a propagation carrier
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,105 @@
+// internal/middleware/tracing_test.go
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestTracingInterceptor() (*TracingInterceptor, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return NewTracingInterceptor(provider, propagation.TraceContext{}), exporter
+}
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracingInterceptor_Unary(t *testing.T) {
+	interceptor, exporter := newTestTracingInterceptor()
+	unary := interceptor.Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: "/task.v1.TaskService/GetTask"}
+
+	var handlerSpan trace.SpanContext
+	_, err := unary(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return "ok", nil
+	})
+	require.NoError(t, err)
+
+	_, err = unary(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "task not found")
+	})
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	success := spans[0]
+	assert.Equal(t, "task.v1.TaskService/GetTask", success.Name)
+	assert.Equal(t, trace.SpanKindServer, success.SpanKind)
+	assert.Equal(t, handlerSpan.SpanID(), success.SpanContext.SpanID(), "handler should see the RPC span")
+	attrs := spanAttributes(success)
+	assert.Equal(t, "grpc", attrs["rpc.system"].AsString())
+	assert.Equal(t, "task.v1.TaskService", attrs["rpc.service"].AsString())
+	assert.Equal(t, "GetTask", attrs["rpc.method"].AsString())
+	assert.Equal(t, int64(codes.OK), attrs["rpc.grpc.status_code"].AsInt64())
+	assert.Equal(t, otelcodes.Unset, success.Status.Code)
+
+	failure := spans[1]
+	assert.Equal(t, int64(codes.NotFound), spanAttributes(failure)["rpc.grpc.status_code"].AsInt64())
+	assert.Equal(t, otelcodes.Error, failure.Status.Code)
+}
+
+func TestTracingInterceptor_ContinuesIncomingTrace(t *testing.T) {
+	interceptor, exporter := newTestTracingInterceptor()
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("traceparent", traceparent))
+	info := &grpc.UnaryServerInfo{FullMethod: "/auth.v1.AuthService/GetMe"}
+
+	_, err := interceptor.Unary()(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	assert.True(t, spans[0].Parent.IsRemote())
+}
+
+func TestTracingInterceptor_Stream(t *testing.T) {
+	interceptor, exporter := newTestTracingInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/task.v1.TaskService/WatchTasks", IsServerStream: true}
+
+	err := interceptor.Stream()(nil, &fakeServerStream{ctx: context.Background()}, info, func(srv interface{}, stream grpc.ServerStream) error {
+		assert.True(t, trace.SpanContextFromContext(stream.Context()).IsValid())
+		return status.Error(codes.Canceled, "client went away")
+	})
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "task.v1.TaskService/WatchTasks", spans[0].Name)
+	assert.Equal(t, int64(codes.Canceled), spanAttributes(spans[0])["rpc.grpc.status_code"].AsInt64())
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/mail"
 	"regexp"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -15,6 +16,8 @@ import (
 
 	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
 	taskv1 "github.com/gurkanbulca/taskmaster/api/proto/task/v1/generated"
+	"github.com/gurkanbulca/taskmaster/pkg/auth"
+	"github.com/gurkanbulca/taskmaster/pkg/i18n"
 )
 
 // ValidationConfig holds validation configuration
@@ -24,12 +27,16 @@ type ValidationConfig struct {
 	RequirePasswordLower   bool
 	RequirePasswordNumber  bool
 	RequirePasswordSpecial bool
+	MinPasswordScore       int // Minimum auth.ScorePassword result, 0 disables the check
 	MinUsernameLength      int
 	MaxUsernameLength      int
 	MaxEmailLength         int
 	MaxNameLength          int
 	MaxDescriptionLength   int
 	MaxTitleLength         int
+	MaxTagsBytes           int // Combined size of all tags, 0 disables the check
+	MaxMetadataBytes       int // Combined size of all metadata keys and values, 0 disables the check
+	MaxCommentLength       int
 }
 
 // DefaultValidationConfig returns default validation configuration
@@ -40,15 +47,27 @@ func DefaultValidationConfig() *ValidationConfig {
 		RequirePasswordLower:   true,
 		RequirePasswordNumber:  true,
 		RequirePasswordSpecial: false,
+		MinPasswordScore:       auth.DefaultMinPasswordScore,
 		MinUsernameLength:      3,
 		MaxUsernameLength:      50,
 		MaxEmailLength:         255,
 		MaxNameLength:          100,
 		MaxDescriptionLength:   5000,
 		MaxTitleLength:         200,
+		MaxTagsBytes:           1024,
+		MaxMetadataBytes:       16 * 1024,
+		MaxCommentLength:       2000,
 	}
 }
 
+// Task metadata limits
+const (
+	maxMetadataEntries     = 50
+	maxMetadataKeyLength   = 100
+	maxMetadataValueLength = 1000
+	reservedMetadataPrefix = "_" // Keys with this prefix are reserved for internal use
+)
+
 // EnhancedValidationInterceptor provides comprehensive request validation
 type EnhancedValidationInterceptor struct {
 	config *ValidationConfig
@@ -89,12 +108,35 @@ func (v *EnhancedValidationInterceptor) Stream() grpc.StreamServerInterceptor {
 		info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler,
 	) error {
-		// For streaming endpoints, we typically validate the initial request
-		// Since WatchTasks doesn't have complex validation needs, we just pass through
-		// If you need to validate streaming requests, you would wrap the stream here
+		// Validate the initial request as the handler receives it, so a bad
+		// request is rejected before the service method runs
+		return handler(srv, &validatingServerStream{
+			ServerStream: stream,
+			validator:    v,
+			method:       info.FullMethod,
+		})
+	}
+}
 
-		return handler(srv, stream)
+// validatingServerStream validates the first message received on a stream
+type validatingServerStream struct {
+	grpc.ServerStream
+	validator *EnhancedValidationInterceptor
+	method    string
+	received  bool
+}
+
+func (s *validatingServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
 	}
+
+	if !s.received {
+		s.received = true
+		return s.validator.validateRequest(m, s.method)
+	}
+
+	return nil
 }
 
 // validateRequest validates different request types
@@ -104,6 +146,8 @@ func (v *EnhancedValidationInterceptor) validateRequest(req interface{}, method
 		return v.validateRegisterRequest(r)
 	case *authv1.LoginRequest:
 		return v.validateLoginRequest(r)
+	case *authv1.LoginWithOIDCRequest:
+		return v.validateLoginWithOIDCRequest(r)
 	case *authv1.ChangePasswordRequest:
 		return v.validateChangePasswordRequest(r)
 	case *authv1.UpdateProfileRequest:
@@ -114,6 +158,18 @@ func (v *EnhancedValidationInterceptor) validateRequest(req interface{}, method
 		return v.validateResetPasswordRequest(r)
 	case *authv1.VerifyEmailRequest:
 		return v.validateVerifyEmailRequest(r)
+	case *authv1.RequestEmailChangeRequest:
+		return v.validateRequestEmailChangeRequest(r)
+	case *authv1.ConfirmEmailChangeRequest:
+		return v.validateConfirmEmailChangeRequest(r)
+	case *authv1.IntrospectTokenRequest:
+		return v.validateIntrospectTokenRequest(r)
+	case *authv1.CreateAPIKeyRequest:
+		return v.validateCreateAPIKeyRequest(r)
+	case *authv1.RevokeAPIKeyRequest:
+		return v.validateRevokeAPIKeyRequest(r)
+	case *authv1.RevokeSessionRequest:
+		return v.validateRevokeSessionRequest(r)
 	case *taskv1.CreateTaskRequest:
 		return v.validateCreateTaskRequest(r)
 	case *taskv1.UpdateTaskRequest:
@@ -124,6 +180,26 @@ func (v *EnhancedValidationInterceptor) validateRequest(req interface{}, method
 		return v.validateDeleteTaskRequest(r)
 	case *taskv1.ListTasksRequest:
 		return v.validateListTasksRequest(r)
+	case *taskv1.ListMyTasksRequest:
+		return v.validateListMyTasksRequest(r)
+	case *taskv1.WatchTasksRequest:
+		return v.validateWatchTasksRequest(r)
+	case *taskv1.ListSubtasksRequest:
+		return v.validateListSubtasksRequest(r)
+	case *taskv1.GetTaskHistoryRequest:
+		return v.validateGetTaskHistoryRequest(r)
+	case *taskv1.AddCommentRequest:
+		return v.validateAddCommentRequest(r)
+	case *taskv1.ListCommentsRequest:
+		return v.validateListCommentsRequest(r)
+	case *taskv1.DeleteCommentRequest:
+		return v.validateDeleteCommentRequest(r)
+	case *taskv1.CreateTaskTemplateRequest:
+		return v.validateCreateTaskTemplateRequest(r)
+	case *taskv1.UpdateTaskTemplateRequest:
+		return v.validateUpdateTaskTemplateRequest(r)
+	case *taskv1.CreateTaskFromTemplateRequest:
+		return v.validateCreateTaskFromTemplateRequest(r)
 	}
 
 	return nil
@@ -145,7 +221,7 @@ func (v *EnhancedValidationInterceptor) validateRegisterRequest(req *authv1.Regi
 	}
 
 	// Password validation
-	if err := v.validatePassword(req.Password); err != nil {
+	if err := v.validatePassword(req.Password, req.Username, req.Email, req.FirstName, req.LastName); err != nil {
 		errors = append(errors, fmt.Sprintf("password: %s", err.Error()))
 	}
 
@@ -246,6 +322,10 @@ func (v *EnhancedValidationInterceptor) validateUpdateProfileRequest(req *authv1
 		}
 	}
 
+	if req.Locale != "" && i18n.Normalize(req.Locale) == "" {
+		errors = append(errors, "locale must be a BCP 47 language tag such as en or pt-BR")
+	}
+
 	if len(errors) > 0 {
 		return status.Error(codes.InvalidArgument, strings.Join(errors, "; "))
 	}
@@ -292,8 +372,76 @@ func (v *EnhancedValidationInterceptor) validateVerifyEmailRequest(req *authv1.V
 	return nil
 }
 
+func (v *EnhancedValidationInterceptor) validateLoginWithOIDCRequest(req *authv1.LoginWithOIDCRequest) error {
+	if req.IdToken == "" {
+		return status.Error(codes.InvalidArgument, "ID token is required")
+	}
+	if len(req.IdToken) > 16*1024 {
+		return status.Error(codes.InvalidArgument, "ID token too long")
+	}
+	return nil
+}
+
+func (v *EnhancedValidationInterceptor) validateRequestEmailChangeRequest(req *authv1.RequestEmailChangeRequest) error {
+	if err := v.validateEmail(req.NewEmail); err != nil {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("new_email: %s", err.Error()))
+	}
+	return nil
+}
+
+func (v *EnhancedValidationInterceptor) validateConfirmEmailChangeRequest(req *authv1.ConfirmEmailChangeRequest) error {
+	if req.Token == "" {
+		return status.Error(codes.InvalidArgument, "email change token is required")
+	}
+	if len(req.Token) < 32 || len(req.Token) > 128 {
+		return status.Error(codes.InvalidArgument, "invalid email change token format")
+	}
+	return nil
+}
+
+func (v *EnhancedValidationInterceptor) validateIntrospectTokenRequest(req *authv1.IntrospectTokenRequest) error {
+	if req.Token == "" {
+		return status.Error(codes.InvalidArgument, "token is required")
+	}
+	if len(req.Token) > 8*1024 {
+		return status.Error(codes.InvalidArgument, "token too long")
+	}
+	return nil
+}
+
+func (v *EnhancedValidationInterceptor) validateCreateAPIKeyRequest(req *authv1.CreateAPIKeyRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return status.Error(codes.InvalidArgument, "API key name is required")
+	}
+	if len(name) > 100 {
+		return status.Error(codes.InvalidArgument, "API key name too long (max 100 characters)")
+	}
+	return nil
+}
+
+func (v *EnhancedValidationInterceptor) validateRevokeAPIKeyRequest(req *authv1.RevokeAPIKeyRequest) error {
+	if !isValidUUID(req.Id) {
+		return status.Error(codes.InvalidArgument, "invalid API key ID format")
+	}
+	return nil
+}
+
+func (v *EnhancedValidationInterceptor) validateRevokeSessionRequest(req *authv1.RevokeSessionRequest) error {
+	if !isValidUUID(req.SessionId) {
+		return status.Error(codes.InvalidArgument, "invalid session ID format")
+	}
+	return nil
+}
+
 // Task service validations
 
+// ValidateCreateTaskRequest validates a single task creation request.
+// It is exported so batch handlers can validate items individually.
+func (v *EnhancedValidationInterceptor) ValidateCreateTaskRequest(req *taskv1.CreateTaskRequest) error {
+	return v.validateCreateTaskRequest(req)
+}
+
 func (v *EnhancedValidationInterceptor) validateCreateTaskRequest(req *taskv1.CreateTaskRequest) error {
 	var errors []string
 
@@ -329,6 +477,17 @@ func (v *EnhancedValidationInterceptor) validateCreateTaskRequest(req *taskv1.Cr
 		}
 	}
 
+	// Parent validation
+	if req.ParentId != "" && !isValidUUID(req.ParentId) {
+		errors = append(errors, "invalid parent task ID format")
+	}
+
+	// Metadata validation
+	errors = append(errors, validateTaskMetadata(req.Metadata)...)
+
+	// Aggregate size validation
+	errors = append(errors, v.validateAggregateSizes(req.Tags, req.Metadata)...)
+
 	// AssignedTo validation
 	if req.AssignedTo != "" && len(req.AssignedTo) > v.config.MaxEmailLength {
 		errors = append(errors, fmt.Sprintf("assigned_to too long (max %d characters)", v.config.MaxEmailLength))
@@ -375,6 +534,22 @@ func (v *EnhancedValidationInterceptor) validateUpdateTaskRequest(req *taskv1.Up
 		}
 	}
 
+	// Parent validation (if provided)
+	if req.ParentId != "" && !isValidUUID(req.ParentId) {
+		errors = append(errors, "invalid parent task ID format")
+	}
+
+	// Version validation (0 means unconditional)
+	if req.ExpectedVersion < 0 {
+		errors = append(errors, "expected version cannot be negative")
+	}
+
+	// Metadata validation (if provided)
+	errors = append(errors, validateTaskMetadata(req.Metadata)...)
+
+	// Aggregate size validation (if provided)
+	errors = append(errors, v.validateAggregateSizes(req.Tags, req.Metadata)...)
+
 	// AssignedTo validation (if provided)
 	if req.AssignedTo != "" && len(req.AssignedTo) > v.config.MaxEmailLength {
 		errors = append(errors, fmt.Sprintf("assigned_to too long (max %d characters)", v.config.MaxEmailLength))
@@ -420,6 +595,100 @@ func (v *EnhancedValidationInterceptor) validateListTasksRequest(req *taskv1.Lis
 	return nil
 }
 
+func (v *EnhancedValidationInterceptor) validateListMyTasksRequest(req *taskv1.ListMyTasksRequest) error {
+	if req.PageSize < 0 {
+		return status.Error(codes.InvalidArgument, "page size cannot be negative")
+	}
+	if req.PageSize > 100 {
+		return status.Error(codes.InvalidArgument, "page size cannot exceed 100")
+	}
+	return nil
+}
+
+func (v *EnhancedValidationInterceptor) validateListSubtasksRequest(req *taskv1.ListSubtasksRequest) error {
+	if req.ParentId == "" {
+		return status.Error(codes.InvalidArgument, "parent task ID is required")
+	}
+	if !isValidUUID(req.ParentId) {
+		return status.Error(codes.InvalidArgument, "invalid parent task ID format")
+	}
+	return nil
+}
+
+func (v *EnhancedValidationInterceptor) validateGetTaskHistoryRequest(req *taskv1.GetTaskHistoryRequest) error {
+	if req.TaskId == "" {
+		return status.Error(codes.InvalidArgument, "task ID is required")
+	}
+	if !isValidUUID(req.TaskId) {
+		return status.Error(codes.InvalidArgument, "invalid task ID format")
+	}
+	return nil
+}
+
+func (v *EnhancedValidationInterceptor) validateAddCommentRequest(req *taskv1.AddCommentRequest) error {
+	if req.TaskId == "" {
+		return status.Error(codes.InvalidArgument, "task ID is required")
+	}
+	if !isValidUUID(req.TaskId) {
+		return status.Error(codes.InvalidArgument, "invalid task ID format")
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		return status.Error(codes.InvalidArgument, "comment body is required")
+	}
+	if len(req.Body) > v.config.MaxCommentLength {
+		return status.Errorf(codes.InvalidArgument, "comment too long (max %d characters)", v.config.MaxCommentLength)
+	}
+	return nil
+}
+
+func (v *EnhancedValidationInterceptor) validateListCommentsRequest(req *taskv1.ListCommentsRequest) error {
+	if req.TaskId == "" {
+		return status.Error(codes.InvalidArgument, "task ID is required")
+	}
+	if !isValidUUID(req.TaskId) {
+		return status.Error(codes.InvalidArgument, "invalid task ID format")
+	}
+	return nil
+}
+
+func (v *EnhancedValidationInterceptor) validateDeleteCommentRequest(req *taskv1.DeleteCommentRequest) error {
+	if req.Id == "" {
+		return status.Error(codes.InvalidArgument, "comment ID is required")
+	}
+	if !isValidUUID(req.Id) {
+		return status.Error(codes.InvalidArgument, "invalid comment ID format")
+	}
+	return nil
+}
+
+func (v *EnhancedValidationInterceptor) validateWatchTasksRequest(req *taskv1.WatchTasksRequest) error {
+	var errors []string
+
+	if req.CreatorId != "" && !isValidUUID(req.CreatorId) {
+		errors = append(errors, "invalid creator ID format")
+	}
+
+	if len(req.AssignedTo) > v.config.MaxEmailLength {
+		errors = append(errors, fmt.Sprintf("assigned_to too long (max %d characters)", v.config.MaxEmailLength))
+	}
+
+	if _, ok := taskv1.TaskStatus_name[int32(req.Status)]; !ok {
+		errors = append(errors, fmt.Sprintf("invalid status filter %d", req.Status))
+	}
+
+	for _, eventType := range req.EventTypes {
+		if _, ok := taskv1.TaskEvent_EventType_name[int32(eventType)]; !ok || eventType == taskv1.TaskEvent_EVENT_TYPE_UNSPECIFIED {
+			errors = append(errors, fmt.Sprintf("invalid event type filter %d", eventType))
+		}
+	}
+
+	if len(errors) > 0 {
+		return status.Error(codes.InvalidArgument, strings.Join(errors, "; "))
+	}
+
+	return nil
+}
+
 // Helper validation functions
 
 func (v *EnhancedValidationInterceptor) validateEmail(email string) error {
@@ -462,7 +731,9 @@ func (v *EnhancedValidationInterceptor) validateUsername(username string) error
 	return nil
 }
 
-func (v *EnhancedValidationInterceptor) validatePassword(password string) error {
+// validatePassword checks length, character classes and strength. userInputs
+// such as the username and email must not appear in the password.
+func (v *EnhancedValidationInterceptor) validatePassword(password string, userInputs ...string) error {
 	if password == "" {
 		return fmt.Errorf("password is required")
 	}
@@ -510,6 +781,14 @@ func (v *EnhancedValidationInterceptor) validatePassword(password string) error
 		return fmt.Errorf("password must contain at least one %s", strings.Join(requirements, ", "))
 	}
 
+	strength := auth.ScorePassword(password, userInputs...)
+	if strength.Score < v.config.MinPasswordScore {
+		if len(strength.Warnings) > 0 {
+			return fmt.Errorf("password is too weak: %s", strength.Warnings[0])
+		}
+		return fmt.Errorf("password is too weak (score %d, minimum %d)", strength.Score, v.config.MinPasswordScore)
+	}
+
 	return nil
 }
 
@@ -527,6 +806,160 @@ func (v *EnhancedValidationInterceptor) validateName(name, fieldName string) err
 	return nil
 }
 
+// validateCreateTaskTemplateRequest requires a name and title pattern
+func (v *EnhancedValidationInterceptor) validateCreateTaskTemplateRequest(req *taskv1.CreateTaskTemplateRequest) error {
+	var errors []string
+
+	if strings.TrimSpace(req.Name) == "" {
+		errors = append(errors, "template name is required")
+	}
+	if strings.TrimSpace(req.TitlePattern) == "" {
+		errors = append(errors, "title pattern is required")
+	}
+	errors = append(errors, v.validateTemplateFields(req.Name, req.TitlePattern, req.Description, req.Tags)...)
+
+	if len(errors) > 0 {
+		return status.Error(codes.InvalidArgument, strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+// validateUpdateTaskTemplateRequest checks the template ID and any changed fields
+func (v *EnhancedValidationInterceptor) validateUpdateTaskTemplateRequest(req *taskv1.UpdateTaskTemplateRequest) error {
+	var errors []string
+
+	if req.Id == "" {
+		errors = append(errors, "template ID is required")
+	} else if !isValidUUID(req.Id) {
+		errors = append(errors, "invalid template ID format")
+	}
+	errors = append(errors, v.validateTemplateFields(req.Name, req.TitlePattern, req.Description, req.Tags)...)
+
+	if len(errors) > 0 {
+		return status.Error(codes.InvalidArgument, strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+// validateTemplateFields applies the task title, description and tag limits
+// to a template's defaults
+func (v *EnhancedValidationInterceptor) validateTemplateFields(name, titlePattern, description string, tags []string) []string {
+	var errors []string
+
+	if len(name) > v.config.MaxTitleLength {
+		errors = append(errors, fmt.Sprintf("template name too long (max %d characters)", v.config.MaxTitleLength))
+	}
+	if len(titlePattern) > v.config.MaxTitleLength {
+		errors = append(errors, fmt.Sprintf("title pattern too long (max %d characters)", v.config.MaxTitleLength))
+	}
+	if len(description) > v.config.MaxDescriptionLength {
+		errors = append(errors, fmt.Sprintf("description too long (max %d characters)", v.config.MaxDescriptionLength))
+	}
+
+	if len(tags) > 20 {
+		errors = append(errors, "too many tags (max 20)")
+	}
+	for _, tag := range tags {
+		if len(tag) > 50 {
+			errors = append(errors, "tag too long (max 50 characters)")
+		}
+		if strings.TrimSpace(tag) == "" {
+			errors = append(errors, "empty tags are not allowed")
+		}
+	}
+
+	return errors
+}
+
+// validateCreateTaskFromTemplateRequest checks the template and parent task IDs
+func (v *EnhancedValidationInterceptor) validateCreateTaskFromTemplateRequest(req *taskv1.CreateTaskFromTemplateRequest) error {
+	if req.TemplateId == "" {
+		return status.Error(codes.InvalidArgument, "template ID is required")
+	}
+	if !isValidUUID(req.TemplateId) {
+		return status.Error(codes.InvalidArgument, "invalid template ID format")
+	}
+	if req.ParentId != "" && !isValidUUID(req.ParentId) {
+		return status.Error(codes.InvalidArgument, "invalid parent task ID format")
+	}
+	return nil
+}
+
+// validateTaskMetadata checks the number of entries, key and value lengths,
+// reserved keys and control characters
+func validateTaskMetadata(metadata map[string]string) []string {
+	var errors []string
+
+	if len(metadata) > maxMetadataEntries {
+		errors = append(errors, fmt.Sprintf("too many metadata entries (max %d)", maxMetadataEntries))
+	}
+
+	// Sorted so error messages are stable
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := metadata[key]
+
+		switch {
+		case strings.TrimSpace(key) == "":
+			errors = append(errors, "empty metadata keys are not allowed")
+			continue
+		case strings.HasPrefix(key, reservedMetadataPrefix):
+			errors = append(errors, fmt.Sprintf("metadata key '%s' is reserved (keys cannot start with '%s')", key, reservedMetadataPrefix))
+		case len(key) > maxMetadataKeyLength:
+			errors = append(errors, fmt.Sprintf("metadata key '%s' too long (max %d characters)", key, maxMetadataKeyLength))
+		case containsControlChars(key):
+			errors = append(errors, fmt.Sprintf("metadata key %q contains control characters", key))
+		}
+
+		if len(value) > maxMetadataValueLength {
+			errors = append(errors, fmt.Sprintf("metadata value for '%s' too long (max %d characters)", key, maxMetadataValueLength))
+		}
+		if containsControlChars(value) {
+			errors = append(errors, fmt.Sprintf("metadata value for %q contains control characters", key))
+		}
+	}
+
+	return errors
+}
+
+// containsControlChars reports whether s contains any Unicode control character
+func containsControlChars(s string) bool {
+	return strings.IndexFunc(s, unicode.IsControl) >= 0
+}
+
+// validateAggregateSizes caps the combined size of tags and metadata, which
+// per-field limits alone do not bound
+func (v *EnhancedValidationInterceptor) validateAggregateSizes(tags []string, metadata map[string]string) []string {
+	var errors []string
+
+	if v.config.MaxTagsBytes > 0 {
+		size := 0
+		for _, tag := range tags {
+			size += len(tag)
+		}
+		if size > v.config.MaxTagsBytes {
+			errors = append(errors, fmt.Sprintf("tags too large (max %d bytes in total)", v.config.MaxTagsBytes))
+		}
+	}
+
+	if v.config.MaxMetadataBytes > 0 {
+		size := 0
+		for key, value := range metadata {
+			size += len(key) + len(value)
+		}
+		if size > v.config.MaxMetadataBytes {
+			errors = append(errors, fmt.Sprintf("metadata too large (max %d bytes in total)", v.config.MaxMetadataBytes))
+		}
+	}
+
+	return errors
+}
+
 // isValidUUID checks if a string is a valid UUID format
 func isValidUUID(s string) bool {
 	uuidRegex := regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
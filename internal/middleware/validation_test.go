@@ -0,0 +1,246 @@
+// internal/middleware/validation_test.go
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	taskv1 "github.com/gurkanbulca/taskmaster/api/proto/task/v1/generated"
+)
+
+// recvServerStream delivers a single preset request to RecvMsg
+type recvServerStream struct {
+	fakeServerStream
+	req proto.Message
+}
+
+func (s *recvServerStream) RecvMsg(m interface{}) error {
+	proto.Merge(m.(proto.Message), s.req)
+	return nil
+}
+
+// watchTasksHandler mimics the generated WatchTasks handler, which receives
+// the request before calling the service method
+func watchTasksHandler(called *bool) grpc.StreamHandler {
+	return func(srv interface{}, stream grpc.ServerStream) error {
+		req := new(taskv1.WatchTasksRequest)
+		if err := stream.RecvMsg(req); err != nil {
+			return err
+		}
+		*called = true
+		return nil
+	}
+}
+
+func TestEnhancedValidationInterceptor_StreamValidatesWatchTasks(t *testing.T) {
+	interceptor := NewEnhancedValidationInterceptor(nil).Stream()
+	info := &grpc.StreamServerInfo{FullMethod: "/task.v1.TaskService/WatchTasks", IsServerStream: true}
+
+	tests := []struct {
+		name    string
+		req     *taskv1.WatchTasksRequest
+		wantErr string
+	}{
+		{
+			name: "valid filters",
+			req: &taskv1.WatchTasksRequest{
+				CreatorId:  "6f1c2a9e-8a5b-4d7e-9c3f-2b1a0e9d8c7b",
+				Status:     taskv1.TaskStatus_TASK_STATUS_PENDING,
+				EventTypes: []taskv1.TaskEvent_EventType{taskv1.TaskEvent_EVENT_TYPE_CREATED},
+			},
+		},
+		{
+			name: "no filters",
+			req:  &taskv1.WatchTasksRequest{},
+		},
+		{
+			name:    "malformed creator ID",
+			req:     &taskv1.WatchTasksRequest{CreatorId: "not-a-uuid"},
+			wantErr: "invalid creator ID format",
+		},
+		{
+			name:    "unknown status",
+			req:     &taskv1.WatchTasksRequest{Status: taskv1.TaskStatus(99)},
+			wantErr: "invalid status filter 99",
+		},
+		{
+			name:    "unknown event type",
+			req:     &taskv1.WatchTasksRequest{EventTypes: []taskv1.TaskEvent_EventType{taskv1.TaskEvent_EventType(42)}},
+			wantErr: "invalid event type filter 42",
+		},
+		{
+			name:    "unspecified event type",
+			req:     &taskv1.WatchTasksRequest{EventTypes: []taskv1.TaskEvent_EventType{taskv1.TaskEvent_EVENT_TYPE_UNSPECIFIED}},
+			wantErr: "invalid event type filter 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &recvServerStream{fakeServerStream: fakeServerStream{ctx: context.Background()}, req: tt.req}
+
+			var called bool
+			err := interceptor(nil, stream, info, watchTasksHandler(&called))
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.True(t, called)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.False(t, called, "service method should not run")
+		})
+	}
+}
+
+func TestEnhancedValidationInterceptor_AggregateSizeLimits(t *testing.T) {
+	config := DefaultValidationConfig()
+	config.MaxMetadataBytes = 1024
+	config.MaxTagsBytes = 100
+	interceptor := NewEnhancedValidationInterceptor(config).Unary()
+
+	// Each entry is small, but 40 of them add up to over 1 KiB
+	bigMetadata := make(map[string]string, 40)
+	for i := 0; i < 40; i++ {
+		bigMetadata[fmt.Sprintf("key-%02d", i)] = strings.Repeat("v", 30)
+	}
+
+	smallMetadata := map[string]string{"team": "platform", "sprint": "42"}
+
+	// Each tag is within the 50 character limit, but together they exceed 100 bytes
+	bigTags := []string{strings.Repeat("a", 40), strings.Repeat("b", 40), strings.Repeat("c", 40)}
+
+	tests := []struct {
+		name    string
+		method  string
+		req     interface{}
+		wantErr string
+	}{
+		{
+			name:   "create within limits",
+			method: "/task.v1.TaskService/CreateTask",
+			req:    &taskv1.CreateTaskRequest{Title: "Ship it", Tags: []string{"release"}, Metadata: smallMetadata},
+		},
+		{
+			name:    "create with oversized metadata",
+			method:  "/task.v1.TaskService/CreateTask",
+			req:     &taskv1.CreateTaskRequest{Title: "Ship it", Metadata: bigMetadata},
+			wantErr: "metadata too large (max 1024 bytes in total)",
+		},
+		{
+			name:    "create with oversized tags",
+			method:  "/task.v1.TaskService/CreateTask",
+			req:     &taskv1.CreateTaskRequest{Title: "Ship it", Tags: bigTags},
+			wantErr: "tags too large (max 100 bytes in total)",
+		},
+		{
+			name:    "update with oversized metadata",
+			method:  "/task.v1.TaskService/UpdateTask",
+			req:     &taskv1.UpdateTaskRequest{Id: "6f1c2a9e-8a5b-4d7e-9c3f-2b1a0e9d8c7b", Metadata: bigMetadata},
+			wantErr: "metadata too large",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			_, err := interceptor(context.Background(), tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					called = true
+					return "ok", nil
+				})
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.True(t, called)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.False(t, called)
+		})
+	}
+}
+
+func TestEnhancedValidationInterceptor_TaskMetadata(t *testing.T) {
+	interceptor := NewEnhancedValidationInterceptor(nil).Unary()
+	info := &grpc.UnaryServerInfo{FullMethod: "/task.v1.TaskService/CreateTask"}
+
+	tooMany := make(map[string]string, maxMetadataEntries+1)
+	for i := 0; i <= maxMetadataEntries; i++ {
+		tooMany[fmt.Sprintf("k%d", i)] = "v"
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		wantErr  string
+	}{
+		{
+			name:     "valid metadata",
+			metadata: map[string]string{"team": "platform", "ticket": "OPS-42"},
+		},
+		{
+			name:     "too many keys",
+			metadata: tooMany,
+			wantErr:  "too many metadata entries (max 50)",
+		},
+		{
+			name:     "oversized value",
+			metadata: map[string]string{"notes": strings.Repeat("x", maxMetadataValueLength+1)},
+			wantErr:  "metadata value for 'notes' too long (max 1000 characters)",
+		},
+		{
+			name:     "oversized key",
+			metadata: map[string]string{strings.Repeat("k", maxMetadataKeyLength+1): "v"},
+			wantErr:  "too long (max 100 characters)",
+		},
+		{
+			name:     "reserved key",
+			metadata: map[string]string{"_internal": "v"},
+			wantErr:  "metadata key '_internal' is reserved",
+		},
+		{
+			name:     "empty key",
+			metadata: map[string]string{" ": "v"},
+			wantErr:  "empty metadata keys are not allowed",
+		},
+		{
+			name:     "control characters in value",
+			metadata: map[string]string{"notes": "line\x00break"},
+			wantErr:  `metadata value for "notes" contains control characters`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &taskv1.CreateTaskRequest{Title: "Ship it", Metadata: tt.metadata}
+			_, err := interceptor(context.Background(), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "ok", nil
+			})
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// internal/repository/ent_comment_repository.go
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/comment"
+	"github.com/gurkanbulca/taskmaster/pkg/tracing"
+)
+
+type EntCommentRepository struct {
+	client *ent.Client
+}
+
+func NewEntCommentRepository(client *ent.Client) *EntCommentRepository {
+	return &EntCommentRepository{
+		client: client,
+	}
+}
+
+// Create adds a comment to a task and returns it with its author loaded
+func (r *EntCommentRepository) Create(ctx context.Context, taskID, authorID uuid.UUID, body string) (created *ent.Comment, err error) {
+	ctx, span := tracing.Start(ctx, "EntCommentRepository.Create")
+	defer func() { tracing.End(span, err) }()
+
+	created, err = r.client.Comment.
+		Create().
+		SetTaskID(taskID).
+		SetAuthorID(authorID).
+		SetBody(body).
+		Save(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, created.ID)
+}
+
+// GetByID loads a comment with its author
+func (r *EntCommentRepository) GetByID(ctx context.Context, id uuid.UUID) (found *ent.Comment, err error) {
+	ctx, span := tracing.Start(ctx, "EntCommentRepository.GetByID")
+	defer func() { tracing.End(span, err) }()
+
+	return r.client.Comment.
+		Query().
+		Where(comment.ID(id)).
+		WithAuthor().
+		Only(ctx)
+}
+
+// ListByTask returns a task's comments, oldest first
+func (r *EntCommentRepository) ListByTask(ctx context.Context, taskID uuid.UUID) (comments []*ent.Comment, err error) {
+	ctx, span := tracing.Start(ctx, "EntCommentRepository.ListByTask")
+	defer func() { tracing.End(span, err) }()
+
+	return r.client.Comment.
+		Query().
+		Where(comment.TaskIDEQ(taskID)).
+		WithAuthor().
+		Order(ent.Asc(comment.FieldCreatedAt), ent.Asc(comment.FieldID)).
+		All(ctx)
+}
+
+// Delete removes a comment
+func (r *EntCommentRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "EntCommentRepository.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	return r.client.Comment.DeleteOneID(id).Exec(ctx)
+}
@@ -3,20 +3,37 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"entgo.io/ent/dialect"
 	"entgo.io/ent/dialect/sql"
 	"github.com/google/uuid"
 
 	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/idempotencykey"
 	"github.com/gurkanbulca/taskmaster/ent/generated/predicate"
 	"github.com/gurkanbulca/taskmaster/ent/generated/task"
+	"github.com/gurkanbulca/taskmaster/ent/generated/taskactivity"
 	"github.com/gurkanbulca/taskmaster/ent/generated/user"
+	"github.com/gurkanbulca/taskmaster/internal/database"
+	"github.com/gurkanbulca/taskmaster/pkg/tracing"
 )
 
+// ErrVersionConflict is returned when an update's expected version does not
+// match the stored task, meaning someone else modified it first
+var ErrVersionConflict = errors.New("task version conflict")
+
+// ErrTaskCycle is returned when re-parenting a task would make it its own ancestor
+var ErrTaskCycle = errors.New("task cannot be its own ancestor")
+
+// maxTaskDepth bounds the ancestor walk used for cycle detection
+const maxTaskDepth = 100
+
 type EntTaskRepository struct {
-	client *ent.Client
+	client         *ent.Client
+	fullTextSearch bool
 }
 
 func NewEntTaskRepository(client *ent.Client) *EntTaskRepository {
@@ -25,7 +42,21 @@ func NewEntTaskRepository(client *ent.Client) *EntTaskRepository {
 	}
 }
 
-func (r *EntTaskRepository) Create(ctx context.Context, t *TaskInput) (*ent.Task, error) {
+// SetFullTextSearch switches task search from substring matching to
+// PostgreSQL full-text search; other dialects keep substring matching
+func (r *EntTaskRepository) SetFullTextSearch(enabled bool) {
+	r.fullTextSearch = enabled
+}
+
+// Client returns the underlying Ent client, for repositories of related entities
+func (r *EntTaskRepository) Client() *ent.Client {
+	return r.client
+}
+
+func (r *EntTaskRepository) Create(ctx context.Context, t *TaskInput) (created *ent.Task, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.Create")
+	defer func() { tracing.End(span, err) }()
+
 	create := r.client.Task.
 		Create().
 		SetTitle(t.Title).
@@ -70,13 +101,155 @@ func (r *EntTaskRepository) Create(ctx context.Context, t *TaskInput) (*ent.Task
 	return create.Save(ctx)
 }
 
-func (r *EntTaskRepository) CreateWithCreator(ctx context.Context, t *TaskInput, creatorID string) (*ent.Task, error) {
+func (r *EntTaskRepository) CreateWithCreator(ctx context.Context, t *TaskInput, creatorID string) (created *ent.Task, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.CreateWithCreator")
+	defer func() { tracing.End(span, err) }()
+
 	creatorUUID, err := uuid.Parse(creatorID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid creator ID: %w", err)
 	}
 
-	create := r.client.Task.
+	tx, err := r.client.Tx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+
+	create, err := newTaskCreate(tx.Task, t, creatorUUID)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+
+	newTask, err := create.Save(ctx)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+
+	if err := recordActivity(ctx, tx, newTask.ID, &creatorUUID, taskactivity.ActionCreated, nil); err != nil {
+		return nil, rollback(tx, fmt.Errorf("record task activity: %w", err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return newTask, nil
+}
+
+// CreateIdempotent creates a task unless the creator already used key within
+// its TTL, in which case the task created by that first request is returned.
+// The bool reports whether a new task was created.
+func (r *EntTaskRepository) CreateIdempotent(ctx context.Context, t *TaskInput, creatorID, key string, ttl time.Duration) (created *ent.Task, isNew bool, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.CreateIdempotent")
+	defer func() { tracing.End(span, err) }()
+
+	creatorUUID, err := uuid.Parse(creatorID)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid creator ID: %w", err)
+	}
+
+	if existing, err := r.findByIdempotencyKey(ctx, creatorUUID, key); err != nil || existing != nil {
+		return existing, false, err
+	}
+
+	// An expired row would otherwise block reuse of the key
+	_, err = r.client.IdempotencyKey.Delete().
+		Where(
+			idempotencykey.UserIDEQ(creatorUUID),
+			idempotencykey.KeyEQ(key),
+			idempotencykey.ExpiresAtLTE(time.Now()),
+		).
+		Exec(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("delete expired idempotency key: %w", err)
+	}
+
+	tx, err := r.client.Tx(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("starting transaction: %w", err)
+	}
+
+	create, err := newTaskCreate(tx.Task, t, creatorUUID)
+	if err != nil {
+		return nil, false, rollback(tx, err)
+	}
+
+	newTask, err := create.Save(ctx)
+	if err != nil {
+		return nil, false, rollback(tx, fmt.Errorf("create task: %w", err))
+	}
+
+	if err := recordActivity(ctx, tx, newTask.ID, &creatorUUID, taskactivity.ActionCreated, nil); err != nil {
+		return nil, false, rollback(tx, fmt.Errorf("record task activity: %w", err))
+	}
+
+	err = tx.IdempotencyKey.Create().
+		SetKey(key).
+		SetUserID(creatorUUID).
+		SetTaskID(newTask.ID).
+		SetExpiresAt(time.Now().Add(ttl)).
+		Exec(ctx)
+	if err != nil {
+		rerr := rollback(tx, fmt.Errorf("record idempotency key: %w", err))
+		if !ent.IsConstraintError(err) {
+			return nil, false, rerr
+		}
+		// A concurrent request with the same key won the race
+		existing, ferr := r.findByIdempotencyKey(ctx, creatorUUID, key)
+		if ferr != nil || existing == nil {
+			return nil, false, rerr
+		}
+		return existing, false, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return newTask, true, nil
+}
+
+// findByIdempotencyKey returns the task recorded for a live key, or nil
+func (r *EntTaskRepository) findByIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) (*ent.Task, error) {
+	record, err := r.client.IdempotencyKey.Query().
+		Where(
+			idempotencykey.UserIDEQ(userID),
+			idempotencykey.KeyEQ(key),
+			idempotencykey.ExpiresAtGT(time.Now()),
+		).
+		Only(ctx)
+	if ent.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query idempotency key: %w", err)
+	}
+
+	existing, err := r.client.Task.Get(ctx, record.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("load task for idempotency key: %w", err)
+	}
+	return existing, nil
+}
+
+// CleanupExpiredIdempotencyKeys removes idempotency keys past their TTL
+func (r *EntTaskRepository) CleanupExpiredIdempotencyKeys(ctx context.Context) (deleted int, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.CleanupExpiredIdempotencyKeys")
+	defer func() { tracing.End(span, err) }()
+
+	deleted, err = r.client.IdempotencyKey.Delete().
+		Where(idempotencykey.ExpiresAtLT(time.Now())).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("cleanup idempotency keys: %w", err)
+	}
+	return deleted, nil
+}
+
+// newTaskCreate builds a create for the task input on the given client,
+// which may be transactional
+func newTaskCreate(client *ent.TaskClient, t *TaskInput, creatorUUID uuid.UUID) (*ent.TaskCreate, error) {
+	create := client.
 		Create().
 		SetTitle(t.Title).
 		SetDescription(t.Description).
@@ -84,6 +257,7 @@ func (r *EntTaskRepository) CreateWithCreator(ctx context.Context, t *TaskInput,
 		SetPriority(task.Priority(t.Priority)).
 		SetNillableAssignedTo(t.AssignedTo).
 		SetNillableDueDate(t.DueDate).
+		SetNillableParentID(t.ParentID).
 		SetCreatorID(creatorUUID)
 
 	// Handle tags - ensure it's not nil
@@ -109,74 +283,69 @@ func (r *EntTaskRepository) CreateWithCreator(ctx context.Context, t *TaskInput,
 		create = create.SetAssigneeID(assigneeUUID)
 	}
 
-	return create.Save(ctx)
+	return create, nil
 }
 
-func (r *EntTaskRepository) GetByID(ctx context.Context, id uuid.UUID) (*ent.Task, error) {
+func (r *EntTaskRepository) GetByID(ctx context.Context, id uuid.UUID) (found *ent.Task, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.GetByID")
+	defer func() { tracing.End(span, err) }()
+
 	return r.client.Task.
 		Query().
-		Where(task.ID(id)).
+		Where(task.ID(id), task.DeletedAtIsNil()).
 		Only(ctx)
 }
 
-func (r *EntTaskRepository) GetByIDWithCreator(ctx context.Context, id uuid.UUID) (*ent.Task, error) {
+func (r *EntTaskRepository) GetByIDWithCreator(ctx context.Context, id uuid.UUID) (found *ent.Task, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.GetByIDWithCreator")
+	defer func() { tracing.End(span, err) }()
+
 	return r.client.Task.
 		Query().
-		Where(task.ID(id)).
+		Where(task.ID(id), task.DeletedAtIsNil()).
 		WithCreator().
 		WithAssignee().
 		Only(ctx)
 }
 
-func (r *EntTaskRepository) List(ctx context.Context, filter ListFilter) ([]*ent.Task, int, error) {
-	query := r.client.Task.Query()
-
-	// Apply filters
-	var predicates []predicate.Task
-
-	if filter.Status != nil {
-		predicates = append(predicates, task.StatusEQ(task.Status(*filter.Status)))
-	}
+// GetByIDs loads the live tasks among ids with relations in a single query.
+// IDs that don't match a task are skipped, and the order is unspecified.
+func (r *EntTaskRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) (tasks []*ent.Task, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.GetByIDs")
+	defer func() { tracing.End(span, err) }()
 
-	if filter.Priority != nil {
-		predicates = append(predicates, task.PriorityEQ(task.Priority(*filter.Priority)))
-	}
+	return r.client.Task.
+		Query().
+		Where(task.IDIn(ids...), task.DeletedAtIsNil()).
+		WithCreator().
+		WithAssignee().
+		All(ctx)
+}
 
-	if filter.AssignedTo != nil {
-		predicates = append(predicates, task.AssignedToEQ(*filter.AssignedTo))
-	}
+// GetByIDIncludingDeleted loads a task with relations even if it was soft-deleted
+func (r *EntTaskRepository) GetByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (found *ent.Task, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.GetByIDIncludingDeleted")
+	defer func() { tracing.End(span, err) }()
 
-	// Filter by user ID (either creator or assignee)
-	if filter.UserID != nil {
-		userUUID, err := uuid.Parse(*filter.UserID)
-		if err != nil {
-			return nil, 0, fmt.Errorf("invalid user ID: %w", err)
-		}
+	return r.client.Task.
+		Query().
+		Where(task.ID(id)).
+		WithCreator().
+		WithAssignee().
+		Only(ctx)
+}
 
-		predicates = append(predicates, task.Or(
-			task.HasCreatorWith(user.ID(userUUID)),
-			task.HasAssigneeWith(user.ID(userUUID)),
-		))
-	}
+func (r *EntTaskRepository) List(ctx context.Context, filter ListFilter) (tasks []*ent.Task, total int, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.List")
+	defer func() { tracing.End(span, err) }()
 
-	// Filter by creator ID specifically
-	if filter.CreatorID != nil {
-		creatorUUID, err := uuid.Parse(*filter.CreatorID)
-		if err != nil {
-			return nil, 0, fmt.Errorf("invalid creator ID: %w", err)
-		}
-		predicates = append(predicates, task.HasCreatorWith(user.ID(creatorUUID)))
-	}
+	query := r.client.Task.Query()
 
-	if filter.Search != "" {
-		// Search in title and description
-		predicates = append(predicates, task.Or(
-			task.TitleContainsFold(filter.Search),
-			task.DescriptionContainsFold(filter.Search),
-		))
+	// Apply filters
+	predicates, err := buildTaskPredicates(filter, r.fullTextSearch)
+	if err != nil {
+		return nil, 0, err
 	}
-
-	// Apply predicates
 	if len(predicates) > 0 {
 		query = query.Where(predicates...)
 	}
@@ -208,16 +377,29 @@ func (r *EntTaskRepository) List(ctx context.Context, filter ListFilter) ([]*ent
 			query = query.Order(ent.Desc(task.FieldDueDate))
 		}
 	case "priority":
-		// Custom order for priority
+		// Custom order for priority: descending puts critical first
+		direction := ""
+		if filter.SortOrder == "asc" {
+			direction = " DESC"
+		}
 		query = query.Order(func(s *sql.Selector) {
 			s.OrderExpr(sql.ExprP(
-				"CASE priority WHEN 'critical' THEN 1 WHEN 'high' THEN 2 WHEN 'medium' THEN 3 WHEN 'low' THEN 4 END",
+				"CASE priority WHEN 'critical' THEN 1 WHEN 'high' THEN 2 WHEN 'medium' THEN 3 WHEN 'low' THEN 4 END" + direction,
 			))
 		})
+	case "relevance":
+		// Rank full-text matches, falling back to newest first
+		if r.fullTextSearch && filter.Search != "" {
+			query = query.Order(orderBySearchRank(filter.Search))
+		}
+		query = query.Order(ent.Desc(task.FieldCreatedAt))
 	default:
 		query = query.Order(ent.Desc(task.FieldCreatedAt))
 	}
 
+	// Break ties on ID so offset pagination is deterministic
+	query = query.Order(ent.Desc(task.FieldID))
+
 	// Apply pagination
 	if filter.Limit > 0 {
 		query = query.Limit(filter.Limit)
@@ -232,7 +414,7 @@ func (r *EntTaskRepository) List(ctx context.Context, filter ListFilter) ([]*ent
 	}
 
 	// Execute query
-	tasks, err := query.All(ctx)
+	tasks, err = query.All(ctx)
 	if err != nil {
 		return nil, 0, fmt.Errorf("query tasks: %w", err)
 	}
@@ -240,8 +422,215 @@ func (r *EntTaskRepository) List(ctx context.Context, filter ListFilter) ([]*ent
 	return tasks, totalCount, nil
 }
 
-func (r *EntTaskRepository) Update(ctx context.Context, id uuid.UUID, input *TaskUpdateInput) (*ent.Task, error) {
-	update := r.client.Task.UpdateOneID(id)
+// buildTaskPredicates translates a ListFilter into query predicates
+func buildTaskPredicates(filter ListFilter, fullTextSearch bool) ([]predicate.Task, error) {
+	var predicates []predicate.Task
+
+	if !filter.IncludeDeleted {
+		predicates = append(predicates, task.DeletedAtIsNil())
+	}
+
+	if filter.Status != nil {
+		predicates = append(predicates, task.StatusEQ(task.Status(*filter.Status)))
+	}
+
+	if filter.Priority != nil {
+		predicates = append(predicates, task.PriorityEQ(task.Priority(*filter.Priority)))
+	}
+
+	if len(filter.ExcludeStatuses) > 0 {
+		statuses := make([]task.Status, len(filter.ExcludeStatuses))
+		for i, st := range filter.ExcludeStatuses {
+			statuses[i] = task.Status(st)
+		}
+		predicates = append(predicates, task.StatusNotIn(statuses...))
+	}
+
+	// Due date bounds never match tasks without a due date
+	if filter.DueAfter != nil {
+		predicates = append(predicates, task.DueDateGTE(*filter.DueAfter))
+	}
+	if filter.DueBefore != nil {
+		predicates = append(predicates, task.DueDateLTE(*filter.DueBefore))
+	}
+
+	if filter.AssignedTo != nil {
+		predicates = append(predicates, task.AssignedToEQ(*filter.AssignedTo))
+	}
+
+	// Filter by user ID (either creator or assignee)
+	if filter.UserID != nil {
+		userUUID, err := uuid.Parse(*filter.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID: %w", err)
+		}
+
+		predicates = append(predicates, task.Or(
+			task.HasCreatorWith(user.ID(userUUID)),
+			task.HasAssigneeWith(user.ID(userUUID)),
+		))
+	}
+
+	// Filter by assignee through the edge, so only existing users match
+	if filter.AssigneeID != nil {
+		assigneeUUID, err := uuid.Parse(*filter.AssigneeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid assignee ID: %w", err)
+		}
+		predicates = append(predicates, task.HasAssigneeWith(user.ID(assigneeUUID)))
+	}
+
+	// Filter by creator ID specifically
+	if filter.CreatorID != nil {
+		creatorUUID, err := uuid.Parse(*filter.CreatorID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid creator ID: %w", err)
+		}
+		predicates = append(predicates, task.HasCreatorWith(user.ID(creatorUUID)))
+	}
+
+	if filter.Search != "" {
+		predicates = append(predicates, taskSearchPredicate(filter.Search, fullTextSearch))
+	}
+
+	return predicates, nil
+}
+
+// taskSearchPredicate matches search against title and description, using
+// the generated tsvector column when full-text search runs on PostgreSQL
+func taskSearchPredicate(search string, fullTextSearch bool) predicate.Task {
+	contains := task.Or(
+		task.TitleContainsFold(search),
+		task.DescriptionContainsFold(search),
+	)
+	if !fullTextSearch {
+		return contains
+	}
+
+	return func(s *sql.Selector) {
+		if s.Dialect() != dialect.Postgres {
+			contains(s)
+			return
+		}
+		s.Where(sql.P(func(b *sql.Builder) {
+			b.Ident(s.C(database.TaskSearchColumn)).
+				WriteString(" @@ websearch_to_tsquery('english', ").
+				Arg(search).
+				WriteByte(')')
+		}))
+	}
+}
+
+// orderBySearchRank puts the best full-text matches first on PostgreSQL and
+// leaves the order untouched elsewhere
+func orderBySearchRank(search string) func(*sql.Selector) {
+	return func(s *sql.Selector) {
+		if s.Dialect() != dialect.Postgres {
+			return
+		}
+		s.OrderExpr(sql.ExprFunc(func(b *sql.Builder) {
+			b.WriteString("ts_rank(").
+				Ident(s.C(database.TaskSearchColumn)).
+				WriteString(", websearch_to_tsquery('english', ").
+				Arg(search).
+				WriteString(")) DESC")
+		}))
+	}
+}
+
+// Stats aggregates task counts by status and priority, plus the number of
+// overdue tasks (past due and neither completed nor cancelled)
+func (r *EntTaskRepository) Stats(ctx context.Context, filter ListFilter) (stats *TaskStats, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.Stats")
+	defer func() { tracing.End(span, err) }()
+
+	predicates, err := buildTaskPredicates(filter, r.fullTextSearch)
+	if err != nil {
+		return nil, err
+	}
+
+	stats = &TaskStats{
+		ByStatus:   make(map[string]int),
+		ByPriority: make(map[string]int),
+	}
+
+	var statusRows []struct {
+		Status string `json:"status"`
+		Count  int    `json:"count"`
+	}
+	err = r.client.Task.Query().
+		Where(predicates...).
+		GroupBy(task.FieldStatus).
+		Aggregate(ent.Count()).
+		Scan(ctx, &statusRows)
+	if err != nil {
+		return nil, fmt.Errorf("count tasks by status: %w", err)
+	}
+	for _, row := range statusRows {
+		stats.ByStatus[row.Status] = row.Count
+		stats.Total += row.Count
+	}
+
+	var priorityRows []struct {
+		Priority string `json:"priority"`
+		Count    int    `json:"count"`
+	}
+	err = r.client.Task.Query().
+		Where(predicates...).
+		GroupBy(task.FieldPriority).
+		Aggregate(ent.Count()).
+		Scan(ctx, &priorityRows)
+	if err != nil {
+		return nil, fmt.Errorf("count tasks by priority: %w", err)
+	}
+	for _, row := range priorityRows {
+		stats.ByPriority[row.Priority] = row.Count
+	}
+
+	stats.Overdue, err = r.client.Task.Query().
+		Where(predicates...).
+		Where(
+			task.DueDateLT(time.Now()),
+			task.StatusNotIn(task.StatusCompleted, task.StatusCancelled),
+		).
+		Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("count overdue tasks: %w", err)
+	}
+
+	return stats, nil
+}
+
+// Update applies the input and records each changed field in the task's
+// history within the same transaction
+func (r *EntTaskRepository) Update(ctx context.Context, id uuid.UUID, input *TaskUpdateInput) (updated *ent.Task, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.Update")
+	defer func() { tracing.End(span, err) }()
+
+	if input.ParentID != nil {
+		if err := r.checkParentCycle(ctx, id, *input.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := r.client.Tx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+
+	before, err := tx.Task.Query().Where(task.ID(id), task.DeletedAtIsNil()).Only(ctx)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+	if input.ExpectedVersion != nil && before.Version != *input.ExpectedVersion {
+		return nil, rollback(tx, ErrVersionConflict)
+	}
+
+	update := tx.Task.UpdateOneID(id).Where(task.DeletedAtIsNil()).AddVersion(1)
+
+	if input.ExpectedVersion != nil {
+		update = update.Where(task.VersionEQ(*input.ExpectedVersion))
+	}
 
 	if input.Title != nil {
 		update = update.SetTitle(*input.Title)
@@ -275,27 +664,147 @@ func (r *EntTaskRepository) Update(ctx context.Context, id uuid.UUID, input *Tas
 	if input.Metadata != nil {
 		update = update.SetMetadata(input.Metadata)
 	}
+	if input.ParentID != nil {
+		update = update.SetParentID(*input.ParentID)
+	}
+
+	updated, err = update.Save(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) && input.ExpectedVersion != nil {
+			// Someone else updated the task after it was loaded above
+			err = ErrVersionConflict
+		}
+		return nil, rollback(tx, err)
+	}
+
+	if changes := diffTasks(before, updated); len(changes) > 0 {
+		if err := recordActivity(ctx, tx, id, input.ActorID, taskactivity.ActionUpdated, changes); err != nil {
+			return nil, rollback(tx, fmt.Errorf("record task activity: %w", err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
 
-	return update.Save(ctx)
+	return updated, nil
 }
 
-func (r *EntTaskRepository) Delete(ctx context.Context, id uuid.UUID) error {
+// ListSubtasks returns the active direct subtasks of a task, oldest first
+func (r *EntTaskRepository) ListSubtasks(ctx context.Context, parentID uuid.UUID) (subtasks []*ent.Task, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.ListSubtasks")
+	defer func() { tracing.End(span, err) }()
+
 	return r.client.Task.
-		DeleteOneID(id).
+		Query().
+		Where(task.ParentIDEQ(parentID), task.DeletedAtIsNil()).
+		WithCreator().
+		WithAssignee().
+		Order(ent.Asc(task.FieldCreatedAt), ent.Asc(task.FieldID)).
+		All(ctx)
+}
+
+// checkParentCycle walks up from parentID and fails if it reaches id
+func (r *EntTaskRepository) checkParentCycle(ctx context.Context, id, parentID uuid.UUID) error {
+	current := parentID
+	for depth := 0; depth < maxTaskDepth; depth++ {
+		if current == id {
+			return ErrTaskCycle
+		}
+
+		ancestor, err := r.client.Task.Query().
+			Where(task.ID(current)).
+			Select(task.FieldParentID).
+			Only(ctx)
+		if err != nil {
+			return fmt.Errorf("load ancestor %s: %w", current, err)
+		}
+		if ancestor.ParentID == nil {
+			return nil
+		}
+		current = *ancestor.ParentID
+	}
+
+	return fmt.Errorf("task hierarchy deeper than %d levels", maxTaskDepth)
+}
+
+// Delete soft-deletes a task by stamping deleted_at
+func (r *EntTaskRepository) Delete(ctx context.Context, id uuid.UUID, actorID *uuid.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.client.Tx(ctx)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+
+	err = tx.Task.
+		UpdateOneID(id).
+		Where(task.DeletedAtIsNil()).
+		SetDeletedAt(time.Now()).
+		AddVersion(1).
 		Exec(ctx)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	if err := recordActivity(ctx, tx, id, actorID, taskactivity.ActionDeleted, nil); err != nil {
+		return rollback(tx, fmt.Errorf("record task activity: %w", err))
+	}
+
+	return tx.Commit()
+}
+
+// Restore clears deleted_at on a soft-deleted task
+func (r *EntTaskRepository) Restore(ctx context.Context, id uuid.UUID, actorID *uuid.UUID) (restored *ent.Task, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.Restore")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.client.Tx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+
+	restored, err = tx.Task.
+		UpdateOneID(id).
+		Where(task.DeletedAtNotNil()).
+		ClearDeletedAt().
+		AddVersion(1).
+		Save(ctx)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+
+	if err := recordActivity(ctx, tx, id, actorID, taskactivity.ActionRestored, nil); err != nil {
+		return nil, rollback(tx, fmt.Errorf("record task activity: %w", err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return restored, nil
 }
 
 // Batch operations
-func (r *EntTaskRepository) CreateBatch(ctx context.Context, inputs []*TaskInput, creatorID string) ([]*ent.Task, error) {
+func (r *EntTaskRepository) CreateBatch(ctx context.Context, inputs []*TaskInput, creatorID string) (created []*ent.Task, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.CreateBatch")
+	defer func() { tracing.End(span, err) }()
+
 	creatorUUID, err := uuid.Parse(creatorID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid creator ID: %w", err)
 	}
 
+	tx, err := r.client.Tx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+
 	builders := make([]*ent.TaskCreate, len(inputs))
 
 	for i, input := range inputs {
-		builder := r.client.Task.
+		builder := tx.Task.
 			Create().
 			SetTitle(input.Title).
 			SetDescription(input.Description).
@@ -303,6 +812,7 @@ func (r *EntTaskRepository) CreateBatch(ctx context.Context, inputs []*TaskInput
 			SetPriority(task.Priority(input.Priority)).
 			SetNillableAssignedTo(input.AssignedTo).
 			SetNillableDueDate(input.DueDate).
+			SetNillableParentID(input.ParentID).
 			SetTags(input.Tags).
 			SetMetadata(input.Metadata).
 			SetCreatorID(creatorUUID)
@@ -316,25 +826,101 @@ func (r *EntTaskRepository) CreateBatch(ctx context.Context, inputs []*TaskInput
 		builders[i] = builder
 	}
 
-	return r.client.Task.CreateBulk(builders...).Save(ctx)
+	tasks, err := tx.Task.CreateBulk(builders...).Save(ctx)
+	if err != nil {
+		return nil, rollback(tx, err)
+	}
+
+	activities := make([]*ent.TaskActivityCreate, len(tasks))
+	for i, created := range tasks {
+		activities[i] = tx.TaskActivity.Create().
+			SetTaskID(created.ID).
+			SetActorID(creatorUUID).
+			SetAction(taskactivity.ActionCreated)
+	}
+	if err := tx.TaskActivity.CreateBulk(activities...).Exec(ctx); err != nil {
+		return nil, rollback(tx, fmt.Errorf("record task activity: %w", err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return tasks, nil
 }
 
-// Transaction example
-func (r *EntTaskRepository) UpdateStatusBatch(ctx context.Context, ids []uuid.UUID, status string) error {
+// UpdateStatusBatch sets the status of every task in one transaction,
+// recording a status change for each task whose status differed
+func (r *EntTaskRepository) UpdateStatusBatch(ctx context.Context, ids []uuid.UUID, status string, actorID *uuid.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.UpdateStatusBatch")
+	defer func() { tracing.End(span, err) }()
+
 	tx, err := r.client.Tx(ctx)
 	if err != nil {
 		return fmt.Errorf("starting transaction: %w", err)
 	}
 
 	for _, id := range ids {
-		if err := tx.Task.UpdateOneID(id).SetStatus(task.Status(status)).Exec(ctx); err != nil {
+		before, err := tx.Task.Query().Where(task.ID(id), task.DeletedAtIsNil()).Only(ctx)
+		if err != nil {
+			return rollback(tx, fmt.Errorf("load task %s: %w", id, err))
+		}
+
+		if err := tx.Task.UpdateOneID(id).Where(task.DeletedAtIsNil()).SetStatus(task.Status(status)).AddVersion(1).Exec(ctx); err != nil {
 			return rollback(tx, fmt.Errorf("update task %s: %w", id, err))
 		}
+
+		if string(before.Status) == status {
+			continue
+		}
+		change := []taskFieldChange{{Field: "status", OldValue: string(before.Status), NewValue: status}}
+		if err := recordActivity(ctx, tx, id, actorID, taskactivity.ActionUpdated, change); err != nil {
+			return rollback(tx, fmt.Errorf("record task activity: %w", err))
+		}
 	}
 
 	return tx.Commit()
 }
 
+// DeleteBatch soft-deletes tasks in one transaction and returns the IDs it
+// deleted. Tasks that are already deleted or gone are skipped.
+func (r *EntTaskRepository) DeleteBatch(ctx context.Context, ids []uuid.UUID, actorID *uuid.UUID) (deleted []uuid.UUID, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskRepository.DeleteBatch")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.client.Tx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("starting transaction: %w", err)
+	}
+
+	now := time.Now()
+	deleted = make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		err := tx.Task.
+			UpdateOneID(id).
+			Where(task.DeletedAtIsNil()).
+			SetDeletedAt(now).
+			AddVersion(1).
+			Exec(ctx)
+		if ent.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, rollback(tx, fmt.Errorf("delete task %s: %w", id, err))
+		}
+
+		if err := recordActivity(ctx, tx, id, actorID, taskactivity.ActionDeleted, nil); err != nil {
+			return nil, rollback(tx, fmt.Errorf("record task activity: %w", err))
+		}
+		deleted = append(deleted, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
 // Helper function for transaction rollback
 func rollback(tx *ent.Tx, err error) error {
 	if rerr := tx.Rollback(); rerr != nil {
@@ -355,6 +941,7 @@ type TaskInput struct {
 	DueDate     *time.Time
 	Tags        []string
 	Metadata    map[string]interface{}
+	ParentID    *uuid.UUID // Makes the task a subtask of this task
 }
 
 type TaskUpdateInput struct {
@@ -367,19 +954,35 @@ type TaskUpdateInput struct {
 	DueDate     *time.Time
 	Tags        []string
 	Metadata    map[string]interface{}
+
+	ParentID        *uuid.UUID // Moves the task under this parent
+	ExpectedVersion *int       // Only update if the stored version matches
+	ActorID         *uuid.UUID // User recorded in the activity log
+}
+
+type TaskStats struct {
+	Total      int
+	ByStatus   map[string]int
+	ByPriority map[string]int
+	Overdue    int
 }
 
 type ListFilter struct {
-	Status        *string
-	Priority      *string
-	AssignedTo    *string
-	UserID        *string // Filter by user (either creator or assignee)
-	CreatorID     *string // Filter by creator specifically
-	Tags          []string
-	Search        string
-	SortBy        string
-	SortOrder     string
-	Limit         int
-	Offset        int
-	WithRelations bool // Include creator and assignee information
+	Status          *string
+	Priority        *string
+	AssignedTo      *string
+	UserID          *string // Filter by user (either creator or assignee)
+	CreatorID       *string // Filter by creator specifically
+	AssigneeID      *string // Filter by assignee specifically
+	Tags            []string
+	Search          string
+	SortBy          string // created_at, updated_at, due_date, priority or relevance
+	SortOrder       string
+	Limit           int
+	Offset          int
+	WithRelations   bool       // Include creator and assignee information
+	IncludeDeleted  bool       // Include soft-deleted tasks
+	DueAfter        *time.Time // Only tasks due at or after this time
+	DueBefore       *time.Time // Only tasks due at or before this time
+	ExcludeStatuses []string   // Skip tasks in any of these statuses
 }
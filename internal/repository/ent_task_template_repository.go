@@ -0,0 +1,125 @@
+// internal/repository/ent_task_template_repository.go
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/tasktemplate"
+	"github.com/gurkanbulca/taskmaster/pkg/tracing"
+)
+
+type EntTaskTemplateRepository struct {
+	client *ent.Client
+}
+
+func NewEntTaskTemplateRepository(client *ent.Client) *EntTaskTemplateRepository {
+	return &EntTaskTemplateRepository{
+		client: client,
+	}
+}
+
+// Create stores a new template owned by ownerID
+func (r *EntTaskTemplateRepository) Create(ctx context.Context, ownerID uuid.UUID, in *TaskTemplateInput) (created *ent.TaskTemplate, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskTemplateRepository.Create")
+	defer func() { tracing.End(span, err) }()
+
+	tags := in.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return r.client.TaskTemplate.
+		Create().
+		SetOwnerID(ownerID).
+		SetName(in.Name).
+		SetTitlePattern(in.TitlePattern).
+		SetDescription(in.Description).
+		SetPriority(tasktemplate.Priority(in.Priority)).
+		SetTags(tags).
+		SetShared(in.Shared).
+		Save(ctx)
+}
+
+// GetByID loads a template
+func (r *EntTaskTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (found *ent.TaskTemplate, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskTemplateRepository.GetByID")
+	defer func() { tracing.End(span, err) }()
+
+	return r.client.TaskTemplate.Get(ctx, id)
+}
+
+// List returns the owner's templates, plus everyone's shared templates when
+// includeShared is set, ordered by name
+func (r *EntTaskTemplateRepository) List(ctx context.Context, ownerID uuid.UUID, includeShared bool) (templates []*ent.TaskTemplate, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskTemplateRepository.List")
+	defer func() { tracing.End(span, err) }()
+
+	visible := tasktemplate.OwnerIDEQ(ownerID)
+	if includeShared {
+		visible = tasktemplate.Or(visible, tasktemplate.Shared(true))
+	}
+
+	return r.client.TaskTemplate.
+		Query().
+		Where(visible).
+		Order(ent.Asc(tasktemplate.FieldName), ent.Asc(tasktemplate.FieldID)).
+		All(ctx)
+}
+
+// Update applies the set fields of in to a template
+func (r *EntTaskTemplateRepository) Update(ctx context.Context, id uuid.UUID, in *TaskTemplateUpdateInput) (updated *ent.TaskTemplate, err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskTemplateRepository.Update")
+	defer func() { tracing.End(span, err) }()
+
+	update := r.client.TaskTemplate.UpdateOneID(id)
+	if in.Name != nil {
+		update.SetName(*in.Name)
+	}
+	if in.TitlePattern != nil {
+		update.SetTitlePattern(*in.TitlePattern)
+	}
+	if in.Description != nil {
+		update.SetDescription(*in.Description)
+	}
+	if in.Priority != nil {
+		update.SetPriority(tasktemplate.Priority(*in.Priority))
+	}
+	if in.Tags != nil {
+		update.SetTags(in.Tags)
+	}
+	if in.Shared != nil {
+		update.SetShared(*in.Shared)
+	}
+
+	return update.Save(ctx)
+}
+
+// Delete removes a template; tasks created from it are unaffected
+func (r *EntTaskTemplateRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := tracing.Start(ctx, "EntTaskTemplateRepository.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	return r.client.TaskTemplate.DeleteOneID(id).Exec(ctx)
+}
+
+type TaskTemplateInput struct {
+	Name         string
+	TitlePattern string
+	Description  string
+	Priority     string
+	Tags         []string
+	Shared       bool
+}
+
+// TaskTemplateUpdateInput holds template changes; nil fields are left as they are
+type TaskTemplateUpdateInput struct {
+	Name         *string
+	TitlePattern *string
+	Description  *string
+	Priority     *string
+	Tags         []string
+	Shared       *bool
+}
@@ -0,0 +1,105 @@
+// internal/repository/ent_user_repository.go
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/predicate"
+	"github.com/gurkanbulca/taskmaster/ent/generated/user"
+	"github.com/gurkanbulca/taskmaster/pkg/tracing"
+)
+
+type EntUserRepository struct {
+	client *ent.Client
+}
+
+func NewEntUserRepository(client *ent.Client) *EntUserRepository {
+	return &EntUserRepository{
+		client: client,
+	}
+}
+
+// List returns users matching the filter ordered by (created_at DESC, id DESC),
+// along with the total number of matches ignoring the cursor and limit
+func (r *EntUserRepository) List(ctx context.Context, filter UserListFilter) (users []*ent.User, total int, err error) {
+	ctx, span := tracing.Start(ctx, "EntUserRepository.List")
+	defer func() { tracing.End(span, err) }()
+
+	query := r.client.User.Query().Where(buildUserPredicates(filter)...)
+
+	totalCount, err := query.Clone().Count(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count users: %w", err)
+	}
+
+	// Resume strictly after the cursor position
+	if filter.AfterCreatedAt != nil && filter.AfterID != nil {
+		query = query.Where(user.Or(
+			user.CreatedAtLT(*filter.AfterCreatedAt),
+			user.And(
+				user.CreatedAtEQ(*filter.AfterCreatedAt),
+				user.IDLT(*filter.AfterID),
+			),
+		))
+	}
+
+	query = query.Order(ent.Desc(user.FieldCreatedAt), ent.Desc(user.FieldID))
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	users, err = query.All(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query users: %w", err)
+	}
+
+	return users, totalCount, nil
+}
+
+// buildUserPredicates translates a UserListFilter into query predicates
+func buildUserPredicates(filter UserListFilter) []predicate.User {
+	var predicates []predicate.User
+
+	if filter.Role != nil {
+		predicates = append(predicates, user.RoleEQ(user.Role(*filter.Role)))
+	}
+
+	if filter.IsActive != nil {
+		predicates = append(predicates, user.IsActiveEQ(*filter.IsActive))
+	}
+
+	if filter.EmailVerified != nil {
+		predicates = append(predicates, user.EmailVerifiedEQ(*filter.EmailVerified))
+	}
+
+	if filter.LockedAt != nil {
+		predicates = append(predicates, user.AccountLockedUntilGT(*filter.LockedAt))
+	}
+
+	if filter.Search != "" {
+		// Search in email and username
+		predicates = append(predicates, user.Or(
+			user.EmailContainsFold(filter.Search),
+			user.UsernameContainsFold(filter.Search),
+		))
+	}
+
+	return predicates
+}
+
+type UserListFilter struct {
+	Role           *string
+	IsActive       *bool
+	EmailVerified  *bool
+	Search         string     // Matches email or username, case-insensitive
+	LockedAt       *time.Time // Only users whose account lock lasts beyond this time
+	Limit          int
+	AfterCreatedAt *time.Time // Keyset cursor: created_at of the last row of the previous page
+	AfterID        *uuid.UUID // Keyset cursor: ID of the last row of the previous page
+}
@@ -2,13 +2,21 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -17,17 +25,29 @@ import (
 
 	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
 	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/apikey"
+	"github.com/gurkanbulca/taskmaster/ent/generated/comment"
 	"github.com/gurkanbulca/taskmaster/ent/generated/securityevent"
+	"github.com/gurkanbulca/taskmaster/ent/generated/session"
+	"github.com/gurkanbulca/taskmaster/ent/generated/task"
+	"github.com/gurkanbulca/taskmaster/ent/generated/taskactivity"
+	"github.com/gurkanbulca/taskmaster/ent/generated/tasktemplate"
 	"github.com/gurkanbulca/taskmaster/ent/generated/user"
 	"github.com/gurkanbulca/taskmaster/internal/config"
 	"github.com/gurkanbulca/taskmaster/internal/middleware"
+	"github.com/gurkanbulca/taskmaster/internal/repository"
 	"github.com/gurkanbulca/taskmaster/pkg/auth"
+	"github.com/gurkanbulca/taskmaster/pkg/authz"
+	"github.com/gurkanbulca/taskmaster/pkg/email"
+	"github.com/gurkanbulca/taskmaster/pkg/i18n"
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
 	"github.com/gurkanbulca/taskmaster/pkg/security"
 )
 
 type AuthService struct {
 	authv1.UnimplementedAuthServiceServer
 	client                   *ent.Client
+	userRepo                 *repository.EntUserRepository
 	tokenManager             *auth.TokenManager
 	passwordManager          *auth.PasswordManager
 	emailVerificationService *EmailVerificationService
@@ -35,8 +55,24 @@ type AuthService struct {
 	securityLogger           *SecurityLogger
 	securityService          *SecurityService // Add security service for event retrieval
 	securityConfig           config.SecurityConfig
+	totpManager              *auth.TOTPManager
+	emailService             email.EmailService
+	emailPreviewer           email.Previewer
+	oidcVerifier             auth.IDTokenVerifier
+	captchaVerifier          auth.CaptchaVerifier
+	apiKeys                  *auth.APIKeyStore
+	sessions                 *auth.SessionStore
+	activity                 *auth.ActivityTracker
+	meCache                  *meCache
+	logger                   logging.Logger
 }
 
+// totpBackupCodeCount is the number of backup codes issued when enabling TOTP
+const totpBackupCodeCount = 10
+
+// maxSecurityEventExportRows caps the rows returned by ExportSecurityEvents
+const maxSecurityEventExportRows = 10000
+
 // NewAuthService creates a new authentication service with configurable security settings
 func NewAuthService(
 	client *ent.Client,
@@ -48,6 +84,7 @@ func NewAuthService(
 ) *AuthService {
 	return &AuthService{
 		client:                   client,
+		userRepo:                 repository.NewEntUserRepository(client),
 		tokenManager:             tokenManager,
 		passwordManager:          auth.NewPasswordManager(),
 		emailVerificationService: emailVerificationService,
@@ -55,7 +92,59 @@ func NewAuthService(
 		securityLogger:           securityLogger,
 		securityService:          NewSecurityService(client), // Initialize security service
 		securityConfig:           securityConfig,
+		totpManager:              auth.NewTOTPManager("TaskMaster"),
+		apiKeys:                  auth.NewAPIKeyStore(client),
+		sessions:                 auth.NewSessionStore(client),
+		activity:                 auth.NewActivityTracker(client, securityConfig.SessionTimeoutDuration),
+		logger:                   logging.Default(),
+	}
+}
+
+// SetGetMeCacheTTL caches GetMe responses per user for ttl; 0 leaves the
+// cache off. Entries are invalidated by any change to the user made through
+// the service's client.
+func (s *AuthService) SetGetMeCacheTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	if s.meCache != nil {
+		s.meCache.ttl = ttl
+		return
 	}
+	s.meCache = newMeCache(ttl)
+	s.client.User.Use(s.meCache.hook())
+}
+
+// SetLogger replaces the default logger
+func (s *AuthService) SetLogger(logger logging.Logger) {
+	s.logger = logger
+}
+
+// SetPasswordManager replaces the default password manager, e.g. to configure
+// the strength score or hashing algorithm
+func (s *AuthService) SetPasswordManager(pm *auth.PasswordManager) {
+	s.passwordManager = pm
+}
+
+// SetEmailService enables security notification emails such as account lockout
+func (s *AuthService) SetEmailService(emailService email.EmailService) {
+	s.emailService = emailService
+}
+
+// SetEmailPreviewer enables PreviewEmail with templates rendered by previewer
+func (s *AuthService) SetEmailPreviewer(previewer email.Previewer) {
+	s.emailPreviewer = previewer
+}
+
+// SetOIDCVerifier enables LoginWithOIDC for ID tokens accepted by verifier
+func (s *AuthService) SetOIDCVerifier(verifier auth.IDTokenVerifier) {
+	s.oidcVerifier = verifier
+}
+
+// SetCaptchaVerifier requires a solved CAPTCHA for Register and
+// RequestPasswordReset, verified by verifier
+func (s *AuthService) SetCaptchaVerifier(verifier auth.CaptchaVerifier) {
+	s.captchaVerifier = verifier
 }
 
 // Register creates a new user account
@@ -65,22 +154,55 @@ func (s *AuthService) Register(ctx context.Context, req *authv1.RegisterRequest)
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	// Check if user already exists
-	exists, err := s.client.User.Query().
-		Where(
-			user.Or(
-				user.EmailEQ(strings.ToLower(req.Email)),
-				user.UsernameEQ(strings.ToLower(req.Username)),
-			),
-		).
-		Exist(ctx)
+	if err := s.verifyCaptcha(ctx, req.CaptchaToken); err != nil {
+		return nil, err
+	}
 
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to check user existence")
+	if err := s.passwordManager.CheckStrength(req.Password, req.Username, req.Email, req.FirstName, req.LastName); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	if exists {
-		return nil, status.Error(codes.AlreadyExists, "user with this email or username already exists")
+	// Check if user already exists
+	if s.securityConfig.HideRegistrationConflicts {
+		existing, err := s.client.User.Query().
+			Where(user.EmailEQ(strings.ToLower(req.Email))).
+			Only(ctx)
+		if err == nil {
+			// Answer as if the account was created, and tell the owner instead
+			s.notifyRegistrationConflict(ctx, existing, req.Password)
+			return &authv1.RegisterResponse{EmailVerificationRequired: true}, nil
+		}
+		if !ent.IsNotFound(err) {
+			return nil, status.Error(codes.Internal, "failed to check user existence")
+		}
+
+		// Usernames are public, so a taken one reveals nothing about the email
+		taken, err := s.client.User.Query().
+			Where(user.UsernameEQ(strings.ToLower(req.Username))).
+			Exist(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to check user existence")
+		}
+		if taken {
+			return nil, status.Error(codes.AlreadyExists, "username is already taken")
+		}
+	} else {
+		exists, err := s.client.User.Query().
+			Where(
+				user.Or(
+					user.EmailEQ(strings.ToLower(req.Email)),
+					user.UsernameEQ(strings.ToLower(req.Username)),
+				),
+			).
+			Exist(ctx)
+
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to check user existence")
+		}
+
+		if exists {
+			return nil, status.Error(codes.AlreadyExists, "user with this email or username already exists")
+		}
 	}
 
 	// Hash password
@@ -102,31 +224,25 @@ func (s *AuthService) Register(ctx context.Context, req *authv1.RegisterRequest)
 		SetPasswordChangedAt(time.Now()).
 		SetEmailNotificationsEnabled(true).
 		SetSecurityNotificationsEnabled(s.securityConfig.EnableSecurityNotifications).
+		SetLocale(middleware.GetLocaleFromContext(ctx)).
 		Save(ctx)
 
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to create user")
 	}
 
-	// Generate tokens
-	accessToken, refreshToken, expiresIn, err := s.tokenManager.GenerateTokenPair(
-		newUser.ID.String(),
-		newUser.Email,
-		newUser.Username,
-		string(newUser.Role),
-	)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to generate tokens")
+	if s.securityConfig.HideRegistrationConflicts {
+		// No tokens, so the response matches the one for a taken email; the
+		// user signs in after verifying their address
+		if err := s.emailVerificationService.SendVerificationEmail(ctx, newUser.ID.String()); err != nil {
+			s.logger.Error("failed to send verification email", "user_id", newUser.ID, "error", err)
+		}
+		return &authv1.RegisterResponse{EmailVerificationRequired: true}, nil
 	}
 
-	// Update user with refresh token
-	_, err = newUser.Update().
-		SetRefreshToken(refreshToken).
-		SetRefreshTokenExpiresAt(time.Now().Add(7 * 24 * time.Hour)).
-		Save(ctx)
-
+	accessToken, refreshToken, expiresIn, err := s.startSession(ctx, newUser, false)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to save refresh token")
+		return nil, err
 	}
 
 	// Send verification email if requested or required
@@ -134,7 +250,7 @@ func (s *AuthService) Register(ctx context.Context, req *authv1.RegisterRequest)
 	if req.SendVerificationEmail || s.securityConfig.RequireEmailVerification {
 		if err := s.emailVerificationService.SendVerificationEmail(ctx, newUser.ID.String()); err != nil {
 			// Log error but don't fail registration
-			log.Printf("Failed to send verification email: %v", err)
+			s.logger.Error("failed to send verification email", "user_id", newUser.ID, "error", err)
 		} else {
 			emailVerificationRequired = true
 		}
@@ -156,9 +272,6 @@ func (s *AuthService) Login(ctx context.Context, req *authv1.LoginRequest) (*aut
 		return nil, status.Error(codes.InvalidArgument, "email and password are required")
 	}
 
-	// Get client info from context
-	clientInfo := middleware.GetClientInfoFromContext(ctx)
-
 	// Find user by email or username
 	loginID := strings.ToLower(req.Email)
 	foundUser, err := s.client.User.Query().
@@ -189,6 +302,18 @@ func (s *AuthService) Login(ctx context.Context, req *authv1.LoginRequest) (*aut
 		}, status.Error(codes.PermissionDenied, fmt.Sprintf("account is locked until %s", foundUser.AccountLockedUntil.Format(time.RFC3339)))
 	}
 
+	// A lock that has run out gives the user a fresh set of attempts, so one
+	// more mistake doesn't lock the account again straight away
+	if foundUser.AccountLockedUntil != nil {
+		foundUser, err = foundUser.Update().
+			SetFailedLoginAttempts(0).
+			ClearAccountLockedUntil().
+			Save(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to update user")
+		}
+	}
+
 	// Check if account is active
 	if !foundUser.IsActive {
 		return nil, status.Error(codes.PermissionDenied, "account is deactivated")
@@ -197,25 +322,24 @@ func (s *AuthService) Login(ctx context.Context, req *authv1.LoginRequest) (*aut
 	// Verify password
 	if err := s.passwordManager.ComparePassword(foundUser.PasswordHash, req.Password); err != nil {
 		// Increment failed login attempts
-		failedAttempts := foundUser.FailedLoginAttempts + 1
-		update := foundUser.Update().SetFailedLoginAttempts(failedAttempts)
+		now := time.Now()
+		failedAttempts := s.nextFailedAttempts(foundUser, now)
+		update := foundUser.Update().
+			SetFailedLoginAttempts(failedAttempts).
+			SetLastFailedLoginAt(now)
 
 		// Lock account if max attempts exceeded (using configurable value)
 		if failedAttempts >= s.securityConfig.MaxLoginAttempts {
-			lockUntil := time.Now().Add(s.securityConfig.AccountLockoutDuration)
+			lockUntil := now.Add(s.securityConfig.AccountLockoutDuration)
 			update = update.SetAccountLockedUntil(lockUntil)
 
-			// Log account locked event
-			if err := s.securityLogger.LogAccountLocked(ctx, foundUser.ID,
-				fmt.Sprintf("max login attempts (%d) exceeded", s.securityConfig.MaxLoginAttempts)); err != nil {
-				// Log error but continue
-			}
-
-			// Save the update
+			// Save the update; the security hook records the lockout
 			if _, err := update.Save(ctx); err != nil {
-				log.Printf("Failed to update failed login attempts: %v", err)
+				s.logger.Error("failed to update failed login attempts", "user_id", foundUser.ID, "error", err)
 			}
 
+			s.notifyAccountLocked(ctx, foundUser, lockUntil)
+
 			// Return specific error for account lockout
 			return &authv1.LoginResponse{
 					AccountLocked: true,
@@ -227,7 +351,7 @@ func (s *AuthService) Login(ctx context.Context, req *authv1.LoginRequest) (*aut
 		} else {
 			// Not locked yet, just update failed attempts
 			if _, err := update.Save(ctx); err != nil {
-				log.Printf("Failed to update failed login attempts: %v", err)
+				s.logger.Error("failed to update failed login attempts", "user_id", foundUser.ID, "error", err)
 			}
 		}
 
@@ -240,23 +364,56 @@ func (s *AuthService) Login(ctx context.Context, req *authv1.LoginRequest) (*aut
 		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
 	}
 
-	// Generate tokens
-	accessToken, refreshToken, expiresIn, err := s.tokenManager.GenerateTokenPair(
-		foundUser.ID.String(),
-		foundUser.Email,
-		foundUser.Username,
-		string(foundUser.Role),
-	)
+	foundUser = s.upgradePasswordHash(ctx, foundUser, req.Password)
+
+	return s.finishLogin(ctx, foundUser, loginID, req.RememberMe)
+}
+
+// finishLogin requires a second factor when TOTP is enabled and otherwise
+// issues tokens
+func (s *AuthService) finishLogin(ctx context.Context, foundUser *ent.User, loginID string, rememberMe bool) (*authv1.LoginResponse, error) {
+	// Require a second factor before issuing tokens
+	if foundUser.TotpEnabled {
+		mfaToken, mfaExpiresIn, err := s.tokenManager.GenerateMFAToken(
+			foundUser.ID.String(),
+			foundUser.Email,
+			foundUser.Username,
+			string(foundUser.Role),
+			rememberMe,
+		)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to generate MFA token")
+		}
+
+		return &authv1.LoginResponse{
+			MfaRequired:  true,
+			MfaToken:     mfaToken,
+			MfaExpiresIn: mfaExpiresIn,
+		}, nil
+	}
+
+	return s.completeLogin(ctx, foundUser, loginID, rememberMe)
+}
+
+// completeLogin issues tokens and records a successful login. loginID is the
+// identifier the user signed in with, recorded on the login event; rememberMe
+// selects the longer refresh token lifetime.
+func (s *AuthService) completeLogin(ctx context.Context, foundUser *ent.User, loginID string, rememberMe bool) (*authv1.LoginResponse, error) {
+	clientInfo := middleware.GetClientInfoFromContext(ctx)
+
+	accessToken, refreshToken, expiresIn, err := s.startSession(ctx, foundUser, rememberMe)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to generate tokens")
+		return nil, err
 	}
 
-	// Update user with refresh token, last login, and reset failed attempts
+	newIP := s.isNewLoginIP(foundUser, clientInfo.IPAddress)
+
+	// Update last login and reset failed attempts
+	now := time.Now()
 	foundUser, err = foundUser.Update().
-		SetRefreshToken(refreshToken).
-		SetRefreshTokenExpiresAt(time.Now().Add(7 * 24 * time.Hour)).
-		SetLastLogin(time.Now()).
+		SetLastLogin(now).
 		SetLastLoginIP(clientInfo.IPAddress).
+		SetRecentLoginIps(recordLoginIP(foundUser.RecentLoginIps, clientInfo.IPAddress, s.securityConfig.LoginIPHistorySize)).
 		SetFailedLoginAttempts(0). // Reset failed attempts on successful login
 		ClearAccountLockedUntil(). // Clear any existing lock
 		Save(ctx)
@@ -266,10 +423,14 @@ func (s *AuthService) Login(ctx context.Context, req *authv1.LoginRequest) (*aut
 	}
 
 	// Log successful login
-	if err := s.securityLogger.LogLoginSuccess(ctx, foundUser.ID); err != nil {
+	if err := s.securityLogger.LogLoginSuccess(ctx, foundUser.ID, loginID); err != nil {
 		// Log error but don't fail login
 	}
 
+	if newIP {
+		s.reportNewLoginIP(ctx, foundUser, clientInfo.IPAddress)
+	}
+
 	// Check if email verification is required
 	emailVerificationRequired := !foundUser.EmailVerified && s.securityConfig.RequireEmailVerification
 
@@ -280,79 +441,270 @@ func (s *AuthService) Login(ctx context.Context, req *authv1.LoginRequest) (*aut
 		ExpiresIn:                 expiresIn,
 		EmailVerificationRequired: emailVerificationRequired,
 		AccountLocked:             false,
+		PasswordExpired:           s.passwordExpired(foundUser),
 	}, nil
 }
 
-// RefreshToken generates a new access token using a refresh token
-func (s *AuthService) RefreshToken(ctx context.Context, req *authv1.RefreshTokenRequest) (*authv1.RefreshTokenResponse, error) {
-	if req.RefreshToken == "" {
-		return nil, status.Error(codes.InvalidArgument, "refresh token is required")
+// startSession signs the calling device in: it records a new session for u
+// and issues the session's first token pair
+func (s *AuthService) startSession(ctx context.Context, u *ent.User, rememberMe bool) (accessToken, refreshToken string, expiresIn int64, err error) {
+	sessionID := uuid.New()
+	accessToken, refreshToken, expiresIn, err = s.tokenManager.GenerateTokenPairFor(
+		sessionID.String(),
+		u.ID.String(),
+		u.Email,
+		u.Username,
+		string(u.Role),
+		rememberMe,
+	)
+	if err != nil {
+		return "", "", 0, status.Error(codes.Internal, "failed to generate tokens")
 	}
 
-	// Validate refresh token
-	claims, err := s.tokenManager.ValidateRefreshToken(req.RefreshToken)
+	clientInfo := middleware.GetClientInfoFromContext(ctx)
+	_, err = s.sessions.Create(ctx, sessionID, u.ID, refreshToken, clientInfo.IPAddress, clientInfo.UserAgent,
+		time.Now().Add(s.tokenManager.RefreshTokenDurationFor(rememberMe)))
 	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		s.logger.Error("failed to create session", "user_id", u.ID, "error", err)
+		return "", "", 0, status.Error(codes.Internal, "failed to save refresh token")
+	}
+	return accessToken, refreshToken, expiresIn, nil
+}
+
+// LoginWithOIDC signs a user in with an ID token from the configured OIDC
+// provider. The identity is matched by provider subject first, then linked to
+// an existing account with the same email, and otherwise a new passwordless
+// account is provisioned.
+func (s *AuthService) LoginWithOIDC(ctx context.Context, req *authv1.LoginWithOIDCRequest) (*authv1.LoginResponse, error) {
+	if s.oidcVerifier == nil {
+		return nil, status.Error(codes.Unimplemented, "OIDC login is not configured")
+	}
+
+	if req.IdToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "ID token is required")
 	}
 
-	// Find user and verify refresh token matches
-	userUUID, err := uuid.Parse(claims.UserID)
+	claims, err := s.oidcVerifier.Verify(ctx, req.IdToken)
 	if err != nil {
-		return nil, status.Error(codes.Unauthenticated, "invalid user ID in token")
+		s.logger.Warn("OIDC ID token rejected", "error", err)
+		return nil, status.Error(codes.Unauthenticated, "invalid ID token")
 	}
 
-	foundUser, err := s.client.User.Query().
+	// Only a provider-verified email may be used to link or create an account
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, status.Error(codes.PermissionDenied, "ID token email is not verified")
+	}
+
+	foundUser, err := s.resolveOIDCUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if foundUser.AccountLockedUntil != nil && foundUser.AccountLockedUntil.After(time.Now()) {
+		return &authv1.LoginResponse{
+			AccountLocked: true,
+			LockedUntil:   timestamppb.New(*foundUser.AccountLockedUntil),
+		}, status.Error(codes.PermissionDenied, fmt.Sprintf("account is locked until %s", foundUser.AccountLockedUntil.Format(time.RFC3339)))
+	}
+
+	if !foundUser.IsActive {
+		return nil, status.Error(codes.PermissionDenied, "account is deactivated")
+	}
+
+	// An account found by email is linked only once it may sign in
+	if foundUser.OidcSubject == nil {
+		foundUser, err = s.linkOIDCIdentity(ctx, foundUser, claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.finishLogin(ctx, foundUser, foundUser.Email, false)
+}
+
+// resolveOIDCUser returns the account linked to the identity in claims, an
+// unlinked account with the same verified email, or a newly provisioned one
+func (s *AuthService) resolveOIDCUser(ctx context.Context, claims *auth.OIDCClaims) (*ent.User, error) {
+	linked, err := s.client.User.Query().
 		Where(
-			user.And(
-				user.ID(userUUID),
-				user.RefreshTokenEQ(req.RefreshToken),
-				user.IsActiveEQ(true),
-			),
+			user.OidcIssuerEQ(claims.Issuer),
+			user.OidcSubjectEQ(claims.Subject),
 		).
 		Only(ctx)
+	if err == nil {
+		return linked, nil
+	}
+	if !ent.IsNotFound(err) {
+		return nil, status.Error(codes.Internal, "failed to find user")
+	}
+
+	emailAddress := strings.ToLower(claims.Email)
+	existing, err := s.client.User.Query().Where(user.EmailEQ(emailAddress)).Only(ctx)
+	switch {
+	case err == nil:
+		if existing.OidcSubject != nil {
+			return nil, status.Error(codes.PermissionDenied, "account is linked to a different identity")
+		}
+
+		// Anyone can register an address they don't own; linking such an
+		// account would let its creator keep password access next to the
+		// provider's user
+		if !existing.EmailVerified {
+			return nil, status.Error(codes.FailedPrecondition, "an account with this email exists; verify its email address before signing in with this provider")
+		}
+
+		return existing, nil
+	case !ent.IsNotFound(err):
+		return nil, status.Error(codes.Internal, "failed to find user")
+	}
+
+	username, err := s.availableUsername(ctx, emailAddress)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to choose a username")
+	}
+
+	created, err := s.client.User.Create().
+		SetEmail(emailAddress).
+		SetUsername(username).
+		SetFirstName(claims.GivenName).
+		SetLastName(claims.FamilyName).
+		SetEmailVerified(true).
+		SetOidcIssuer(claims.Issuer).
+		SetOidcSubject(claims.Subject).
+		Save(ctx)
+	if err != nil {
+		if ent.IsConstraintError(err) {
+			return nil, status.Error(codes.AlreadyExists, "user with this email or username already exists")
+		}
+		return nil, status.Error(codes.Internal, "failed to create user")
+	}
 
+	return created, nil
+}
+
+// linkOIDCIdentity links the identity in claims to an account with the same
+// verified email
+func (s *AuthService) linkOIDCIdentity(ctx context.Context, existing *ent.User, claims *auth.OIDCClaims) (*ent.User, error) {
+	linked, err := existing.Update().
+		Where(user.OidcSubjectIsNil()).
+		SetOidcIssuer(claims.Issuer).
+		SetOidcSubject(claims.Subject).
+		Save(ctx)
 	if err != nil {
 		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.PermissionDenied, "account is linked to a different identity")
+		}
+		return nil, status.Error(codes.Internal, "failed to link account")
+	}
+
+	if err := s.securityLogger.LogFromContext(ctx, linked.ID, security.EventTypeSecurityAlert,
+		fmt.Sprintf("Linked sign-in identity from %s", claims.Issuer), security.SeverityMedium); err != nil {
+		// Log error but don't fail login
+	}
+
+	return linked, nil
+}
+
+// usernameDisallowedChars matches characters not allowed in usernames
+var usernameDisallowedChars = regexp.MustCompile(`[^a-z0-9_\-]+`)
+
+// availableUsername derives an unused username from the local part of an
+// email address, adding a random suffix when the plain form is taken
+func (s *AuthService) availableUsername(ctx context.Context, emailAddress string) (string, error) {
+	base := usernameDisallowedChars.ReplaceAllString(strings.SplitN(emailAddress, "@", 2)[0], "_")
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	if len(base) < 3 {
+		base = "user_" + base
+	}
+
+	candidate := base
+	for attempt := 0; attempt < 5; attempt++ {
+		taken, err := s.client.User.Query().Where(user.UsernameEQ(candidate)).Exist(ctx)
+		if err != nil {
+			return "", fmt.Errorf("check username: %w", err)
+		}
+		if !taken {
+			return candidate, nil
+		}
+
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", fmt.Errorf("generate username suffix: %w", err)
+		}
+		candidate = base + "-" + hex.EncodeToString(suffix)
+	}
+
+	return "", errors.New("no available username")
+}
+
+// RefreshToken generates a new access token using a refresh token
+func (s *AuthService) RefreshToken(ctx context.Context, req *authv1.RefreshTokenRequest) (*authv1.RefreshTokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh token is required")
+	}
+
+	// Validate refresh token
+	claims, err := s.tokenManager.ValidateRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
+
+	// Find the session holding this refresh token
+	foundSession, err := s.sessions.Find(ctx, req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrExpiredSession):
+			return nil, status.Error(codes.Unauthenticated, "refresh token expired")
+		case errors.Is(err, auth.ErrInvalidSession):
 			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		default:
+			return nil, status.Error(codes.Internal, "failed to find session")
 		}
-		return nil, status.Error(codes.Internal, "failed to find user")
 	}
 
-	// Check if refresh token is expired
-	if foundUser.RefreshTokenExpiresAt != nil && foundUser.RefreshTokenExpiresAt.Before(time.Now()) {
-		return nil, status.Error(codes.Unauthenticated, "refresh token expired")
+	foundUser := foundSession.Edges.Owner
+	if foundUser == nil || foundUser.ID.String() != claims.UserID || !foundUser.IsActive {
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
 	}
 
-	// Check if session has timed out (using configurable session timeout)
-	if foundUser.LastLogin != nil && time.Since(*foundUser.LastLogin) > s.securityConfig.SessionTimeoutDuration {
-		// Clear refresh token
-		if err := s.client.User.UpdateOneID(userUUID).
-			ClearRefreshToken().
-			ClearRefreshTokenExpiresAt().
-			Exec(ctx); err != nil {
-			log.Printf("Failed to clear expired refresh token: %v", err)
+	// Check if session has timed out (using configurable session timeout),
+	// counted from sign-in and from the session's last request
+	timedOut := time.Since(foundSession.CreatedAt) > s.securityConfig.SessionTimeoutDuration
+	if !timedOut {
+		if err := s.activity.Touch(ctx, foundSession.ID); err != nil {
+			if !errors.Is(err, auth.ErrIdleSession) {
+				return nil, status.Error(codes.Internal, "failed to check session activity")
+			}
+			timedOut = true
+		}
+	}
+	if timedOut {
+		if err := s.sessions.Revoke(ctx, foundUser.ID, foundSession.ID); err != nil {
+			s.logger.Error("failed to end timed out session", "user_id", foundUser.ID, "error", err)
 		}
 		return nil, status.Error(codes.Unauthenticated, "session has timed out, please login again")
 	}
 
-	// Generate new token pair
-	accessToken, refreshToken, expiresIn, err := s.tokenManager.GenerateTokenPair(
+	// Generate new token pair, keeping the login's refresh token lifetime
+	accessToken, refreshToken, expiresIn, err := s.tokenManager.GenerateTokenPairFor(
+		foundSession.ID.String(),
 		foundUser.ID.String(),
 		foundUser.Email,
 		foundUser.Username,
 		string(foundUser.Role),
+		claims.RememberMe,
 	)
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to generate tokens")
 	}
 
-	// Update refresh token
-	_, err = foundUser.Update().
-		SetRefreshToken(refreshToken).
-		SetRefreshTokenExpiresAt(time.Now().Add(7 * 24 * time.Hour)).
-		Save(ctx)
-
-	if err != nil {
+	// Rotate the session's refresh token so the old one stops working
+	if err := s.sessions.Rotate(ctx, foundSession.ID, req.RefreshToken, refreshToken, time.Now().Add(s.tokenManager.RefreshTokenDurationFor(claims.RememberMe))); err != nil {
+		if errors.Is(err, auth.ErrRefreshTokenReused) {
+			return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+		}
 		return nil, status.Error(codes.Internal, "failed to update refresh token")
 	}
 
@@ -363,35 +715,70 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *authv1.RefreshToken
 	}, nil
 }
 
-// Logout invalidates the user's refresh token
+// IntrospectToken reports whether an access token would be accepted by
+// protected endpoints, for proxies that authenticate requests themselves.
+// Invalid, expired and revoked tokens are reported as inactive, not as errors.
+func (s *AuthService) IntrospectToken(ctx context.Context, req *authv1.IntrospectTokenRequest) (*authv1.IntrospectTokenResponse, error) {
+	claims, err := s.tokenManager.ValidateAccessToken(ctx, req.Token)
+	if err != nil {
+		return &authv1.IntrospectTokenResponse{Active: false}, nil
+	}
+
+	// A valid signature isn't enough: the token's session must not have
+	// ended or timed out, and its user must still be active, as the auth
+	// interceptor requires
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return &authv1.IntrospectTokenResponse{Active: false}, nil
+	}
+	tokenSession, err := s.client.Session.Query().
+		Where(session.ID(sessionID)).
+		WithOwner().
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return &authv1.IntrospectTokenResponse{Active: false}, nil
+		}
+		return nil, status.Error(codes.Internal, "failed to get session")
+	}
+	tokenUser := tokenSession.Edges.Owner
+	if tokenUser.ID.String() != claims.UserID || !tokenUser.IsActive || s.activity.IsIdle(tokenSession.LastActivityAt) {
+		return &authv1.IntrospectTokenResponse{Active: false}, nil
+	}
+
+	// Report the user's current role, not the one the token was issued with
+	resp := &authv1.IntrospectTokenResponse{
+		Active:   true,
+		UserId:   claims.UserID,
+		Username: tokenUser.Username,
+		Role:     convertRoleToProto(tokenUser.Role),
+	}
+	if claims.ExpiresAt != nil {
+		resp.ExpiresAt = timestamppb.New(claims.ExpiresAt.Time)
+	}
+
+	return resp, nil
+}
+
+// Logout revokes the current access token and ends the session holding the
+// given refresh token. Other sessions of the user stay signed in.
 func (s *AuthService) Logout(ctx context.Context, req *authv1.LogoutRequest) (*emptypb.Empty, error) {
+	s.revokeCurrentAccessToken(ctx)
+
 	if req.RefreshToken == "" {
 		return &emptypb.Empty{}, nil
 	}
 
 	// Validate refresh token to get user ID
-	claims, err := s.tokenManager.ValidateRefreshToken(req.RefreshToken)
+	claims, err := s.tokenManager.ValidateRefreshToken(ctx, req.RefreshToken)
 	if err != nil {
 		// Even if token is invalid, we return success for logout
 		return &emptypb.Empty{}, nil
 	}
 
-	// Parse the user ID
-	userUUID, err := uuid.Parse(claims.UserID)
-	if err != nil {
-		// Invalid UUID, but still return success
-		return &emptypb.Empty{}, nil
-	}
-
-	// Clear refresh token from database
-	err = s.client.User.UpdateOneID(userUUID).
-		ClearRefreshToken().
-		ClearRefreshTokenExpiresAt().
-		Exec(ctx)
-
-	if err != nil && !ent.IsNotFound(err) {
+	if err := s.sessions.RevokeByToken(ctx, req.RefreshToken); err != nil {
 		// Log error but still return success for logout
-		log.Printf("Failed to clear refresh token for user %s: %v", claims.UserID, err)
+		s.logger.Error("failed to end session", "user_id", claims.UserID, "error", err)
 	}
 
 	return &emptypb.Empty{}, nil
@@ -405,6 +792,12 @@ func (s *AuthService) GetMe(ctx context.Context, _ *emptypb.Empty) (*authv1.GetM
 		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
 	}
 
+	if s.meCache != nil {
+		if cached, ok := s.meCache.get(userID); ok {
+			return cached, nil
+		}
+	}
+
 	// Find user
 	foundUser, err := s.client.User.Get(ctx, uuid.MustParse(userID))
 	if err != nil {
@@ -418,24 +811,46 @@ func (s *AuthService) GetMe(ctx context.Context, _ *emptypb.Empty) (*authv1.GetM
 	verificationStatus, err := s.emailVerificationService.GetVerificationStatus(ctx, userID)
 	if err != nil {
 		// Log error but don't fail the request
-		log.Printf("Failed to get email verification status: %v", err)
+		s.logger.Error("failed to get email verification status", "user_id", userID, "error", err)
 	}
 
 	response := &authv1.GetMeResponse{
-		User: s.convertUserToProto(foundUser),
+		User:            s.convertUserToProto(foundUser),
+		PasswordExpired: s.passwordExpired(foundUser),
+	}
+	if foundUser.PasswordChangedAt != nil {
+		response.PasswordAgeDays = int32(time.Since(*foundUser.PasswordChangedAt) / (24 * time.Hour))
 	}
 
 	if verificationStatus != nil {
-		response.EmailVerificationStatus = &authv1.EmailVerificationStatus{
-			EmailVerified: verificationStatus.EmailVerified,
-			Attempts:      int32(verificationStatus.Attempts),
-			MaxAttempts:   int32(verificationStatus.MaxAttempts),
-			IsExpired:     verificationStatus.IsExpired,
-			CanResend:     verificationStatus.CanResend,
+		response.EmailVerificationStatus = convertVerificationStatusToProto(verificationStatus)
+	}
+
+	// Get password reset status
+	resetStatus, err := s.passwordResetService.GetPasswordResetStatus(ctx, userID)
+	if err != nil {
+		// Log error but don't fail the request
+		s.logger.Error("failed to get password reset status", "user_id", userID, "error", err)
+	}
+
+	if resetStatus != nil {
+		response.PasswordResetStatus = &authv1.PasswordResetStatus{
+			Attempts:         int32(resetStatus.Attempts),
+			MaxAttempts:      int32(resetStatus.MaxAttempts),
+			IsExpired:        resetStatus.IsExpired,
+			HasActiveRequest: resetStatus.HasActiveRequest,
+			CanRequest:       resetStatus.CanRequest,
 		}
-		if verificationStatus.ExpiresAt != nil {
-			response.EmailVerificationStatus.ExpiresAt = timestamppb.New(*verificationStatus.ExpiresAt)
+		if resetStatus.ExpiresAt != nil {
+			response.PasswordResetStatus.ExpiresAt = timestamppb.New(*resetStatus.ExpiresAt)
 		}
+		if resetStatus.LastResetAt != nil {
+			response.PasswordResetStatus.LastResetAt = timestamppb.New(*resetStatus.LastResetAt)
+		}
+	}
+
+	if s.meCache != nil {
+		s.meCache.set(userID, response)
 	}
 
 	return response, nil
@@ -466,6 +881,9 @@ func (s *AuthService) UpdateProfile(ctx context.Context, req *authv1.UpdateProfi
 		}
 		update = update.SetPreferences(preferences)
 	}
+	if req.Locale != "" {
+		update = update.SetLocale(i18n.Normalize(req.Locale))
+	}
 
 	// Phase 2: Update notification settings
 	update = update.
@@ -513,31 +931,42 @@ func (s *AuthService) ChangePassword(ctx context.Context, req *authv1.ChangePass
 		return nil, status.Error(codes.InvalidArgument, "incorrect current password")
 	}
 
+	// Reject the current password and any still in the history
+	if err := s.passwordManager.CheckPasswordReuse(req.NewPassword, append([]string{foundUser.PasswordHash}, foundUser.PasswordHistory...)...); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "new password must not match a recently used password")
+	}
+
+	if err := s.passwordManager.CheckStrength(req.NewPassword, foundUser.Username, foundUser.Email, foundUser.FirstName, foundUser.LastName); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	// Hash new password
 	hashedPassword, err := s.passwordManager.HashPassword(req.NewPassword)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	// Update password and clear refresh token
+	// Sign out every session before the old password stops working
+	if _, err := s.sessions.RevokeAll(ctx, foundUser.ID); err != nil {
+		return nil, status.Error(codes.Internal, "failed to end sessions")
+	}
+
+	// Update password
 	_, err = foundUser.Update().
 		SetPasswordHash(hashedPassword).
+		SetPasswordHistory(auth.UpdatePasswordHistory(foundUser.PasswordHistory, foundUser.PasswordHash, s.securityConfig.PasswordHistorySize)).
 		SetPasswordChangedAt(time.Now()).
-		ClearRefreshToken().
-		ClearRefreshTokenExpiresAt().
 		Save(ctx)
 
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to update password")
 	}
 
-	// Log password change
-	if err := s.securityLogger.LogPasswordChanged(ctx, foundUser.ID); err != nil {
-		// Log error but don't fail
-	}
+	// Revoke the access token used for this request
+	s.revokeCurrentAccessToken(ctx)
 
 	// Send notification email if requested and enabled
-	if req.NotifyViaEmail && foundUser.SecurityNotificationsEnabled {
+	if req.NotifyViaEmail && notificationEnabled(foundUser, notifySecurityAlerts) {
 		// This would send an email notification about password change
 		// Implementation depends on email service
 	}
@@ -545,33 +974,512 @@ func (s *AuthService) ChangePassword(ctx context.Context, req *authv1.ChangePass
 	return &emptypb.Empty{}, nil
 }
 
-// Phase 2: Email Verification Methods
-
-// SendVerificationEmail sends a verification email to the authenticated user
-func (s *AuthService) SendVerificationEmail(ctx context.Context, _ *authv1.SendVerificationEmailRequest) (*emptypb.Empty, error) {
-	// Get user ID from context
+// DeactivateAccount deactivates the current user's account and signs it out.
+// Only an admin can reactivate it through SetUserActive.
+func (s *AuthService) DeactivateAccount(ctx context.Context, _ *authv1.DeactivateAccountRequest) (*emptypb.Empty, error) {
 	userID, ok := middleware.GetUserIDFromContext(ctx)
 	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
 	}
 
-	if err := s.emailVerificationService.SendVerificationEmail(ctx, userID); err != nil {
-		return nil, err
+	foundUser, err := s.client.User.Get(ctx, uuid.MustParse(userID))
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get user")
 	}
 
-	return &emptypb.Empty{}, nil
-}
+	if !foundUser.IsActive {
+		return nil, status.Error(codes.FailedPrecondition, "account is already deactivated")
+	}
 
-// VerifyEmail verifies a user's email address using a token
-func (s *AuthService) VerifyEmail(ctx context.Context, req *authv1.VerifyEmailRequest) (*emptypb.Empty, error) {
-	if err := s.emailVerificationService.VerifyEmail(ctx, req.Token); err != nil {
-		return nil, err
+	// Only an admin can reactivate accounts, so one must stay active
+	if foundUser.Role == user.RoleAdmin {
+		adminCount, err := countActiveAdmins(ctx, s.client.User)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to count admins")
+		}
+		if adminCount <= 1 {
+			return nil, status.Error(codes.FailedPrecondition, "cannot deactivate the last remaining admin")
+		}
+	}
+
+	if _, err := s.sessions.RevokeAll(ctx, foundUser.ID); err != nil {
+		return nil, status.Error(codes.Internal, "failed to end sessions")
 	}
 
+	if err := foundUser.Update().SetIsActive(false).Exec(ctx); err != nil {
+		return nil, status.Error(codes.Internal, "failed to deactivate account")
+	}
+
+	s.revokeCurrentAccessToken(ctx)
+
 	return &emptypb.Empty{}, nil
 }
 
-// ResendVerificationEmail resends the verification email
+// DeleteAccount permanently deletes the current user's account after
+// re-verifying the password. Owned tasks are deleted or orphaned depending on
+// the configured DeletedUserTaskPolicy.
+func (s *AuthService) DeleteAccount(ctx context.Context, req *authv1.DeleteAccountRequest) (*emptypb.Empty, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	if req.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "password is required")
+	}
+
+	foundUser, err := s.client.User.Get(ctx, uuid.MustParse(userID))
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+
+	if err := s.passwordManager.ComparePassword(foundUser.PasswordHash, req.Password); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "incorrect password")
+	}
+
+	if foundUser.Role == user.RoleAdmin && foundUser.IsActive {
+		adminCount, err := countActiveAdmins(ctx, s.client.User)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to count admins")
+		}
+		if adminCount <= 1 {
+			return nil, status.Error(codes.FailedPrecondition, "cannot delete the last remaining admin")
+		}
+	}
+
+	tx, err := s.client.Tx(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to delete account")
+	}
+
+	if err := s.deleteUserData(ctx, tx, foundUser.ID); err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			s.logger.Error("failed to roll back account deletion", "user_id", foundUser.ID, "error", rerr)
+		}
+		s.logger.Error("failed to delete account", "user_id", foundUser.ID, "error", err)
+		return nil, status.Error(codes.Internal, "failed to delete account")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, status.Error(codes.Internal, "failed to delete account")
+	}
+
+	s.revokeCurrentAccessToken(ctx)
+
+	// The user's own events are gone, so record the deletion as a system event
+	if err := s.securityLogger.LogSystemFromContext(ctx, security.EventTypeSecurityAlert,
+		"Account "+foundUser.ID.String()+" deleted by user", security.SeverityMedium); err != nil {
+		// Log error but don't fail
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// Two-Factor Authentication Methods
+
+// EnableTOTP generates a new TOTP secret for the authenticated user.
+// Two-factor authentication stays disabled until ConfirmTOTP verifies a code.
+func (s *AuthService) EnableTOTP(ctx context.Context, _ *authv1.EnableTOTPRequest) (*authv1.EnableTOTPResponse, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	foundUser, err := s.client.User.Get(ctx, uuid.MustParse(userID))
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+
+	if foundUser.TotpEnabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	secret, err := s.totpManager.GenerateSecret()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate TOTP secret")
+	}
+
+	if err := foundUser.Update().SetTotpSecret(secret).Exec(ctx); err != nil {
+		return nil, status.Error(codes.Internal, "failed to save TOTP secret")
+	}
+
+	provisioningURI := s.totpManager.ProvisioningURI(secret, foundUser.Email)
+
+	return &authv1.EnableTOTPResponse{
+		Secret:          secret,
+		ProvisioningUri: provisioningURI,
+		QrCodePayload:   provisioningURI,
+	}, nil
+}
+
+// ConfirmTOTP verifies the first code from the authenticator app, enables
+// two-factor authentication and returns single-use backup codes
+func (s *AuthService) ConfirmTOTP(ctx context.Context, req *authv1.ConfirmTOTPRequest) (*authv1.ConfirmTOTPResponse, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	if req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "code is required")
+	}
+
+	foundUser, err := s.client.User.Get(ctx, uuid.MustParse(userID))
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+
+	if foundUser.TotpEnabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+	if foundUser.TotpSecret == "" {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication setup has not been started")
+	}
+
+	if !s.totpManager.ValidateCode(foundUser.TotpSecret, req.Code, time.Now()) {
+		return nil, status.Error(codes.InvalidArgument, "invalid verification code")
+	}
+
+	backupCodes, backupHashes, err := s.totpManager.GenerateBackupCodes(totpBackupCodeCount)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate backup codes")
+	}
+
+	err = foundUser.Update().
+		SetTotpEnabled(true).
+		SetTotpBackupCodes(backupHashes).
+		Exec(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to enable two-factor authentication")
+	}
+
+	if err := s.securityLogger.LogFromContext(ctx, foundUser.ID, security.EventTypeSecurityAlert,
+		"two-factor authentication enabled", security.SeverityMedium); err != nil {
+		// Log error but don't fail
+	}
+
+	return &authv1.ConfirmTOTPResponse{
+		BackupCodes: backupCodes,
+	}, nil
+}
+
+// DisableTOTP turns off two-factor authentication after re-verifying the
+// password and a current TOTP or backup code
+func (s *AuthService) DisableTOTP(ctx context.Context, req *authv1.DisableTOTPRequest) (*emptypb.Empty, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	if req.Password == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "password and code are required")
+	}
+
+	foundUser, err := s.client.User.Get(ctx, uuid.MustParse(userID))
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+
+	if !foundUser.TotpEnabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+
+	if err := s.passwordManager.ComparePassword(foundUser.PasswordHash, req.Password); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "incorrect password")
+	}
+
+	if _, ok := s.verifySecondFactor(foundUser, req.Code); !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid verification code")
+	}
+
+	err = foundUser.Update().
+		SetTotpEnabled(false).
+		ClearTotpSecret().
+		ClearTotpBackupCodes().
+		Exec(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to disable two-factor authentication")
+	}
+
+	if err := s.securityLogger.LogFromContext(ctx, foundUser.ID, security.EventTypeSecurityAlert,
+		"two-factor authentication disabled", security.SeverityHigh); err != nil {
+		// Log error but don't fail
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// VerifyTOTPLogin completes a two-factor login by verifying a TOTP or backup
+// code against the MFA token returned by Login
+func (s *AuthService) VerifyTOTPLogin(ctx context.Context, req *authv1.VerifyTOTPLoginRequest) (*authv1.LoginResponse, error) {
+	if req.MfaToken == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "MFA token and code are required")
+	}
+
+	claims, err := s.tokenManager.ValidateMFAToken(ctx, req.MfaToken)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired MFA token")
+	}
+
+	foundUser, err := s.client.User.Get(ctx, uuid.MustParse(claims.UserID))
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired MFA token")
+		}
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+
+	if foundUser.AccountLockedUntil != nil && foundUser.AccountLockedUntil.After(time.Now()) {
+		return &authv1.LoginResponse{
+			AccountLocked: true,
+			LockedUntil:   timestamppb.New(*foundUser.AccountLockedUntil),
+		}, status.Error(codes.PermissionDenied, fmt.Sprintf("account is locked until %s", foundUser.AccountLockedUntil.Format(time.RFC3339)))
+	}
+
+	if !foundUser.IsActive {
+		return nil, status.Error(codes.PermissionDenied, "account is deactivated")
+	}
+
+	if !foundUser.TotpEnabled {
+		return nil, status.Error(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+
+	remainingBackupCodes, ok := s.verifySecondFactor(foundUser, req.Code)
+	if !ok {
+		// Wrong codes count towards the same lockout as wrong passwords
+		now := time.Now()
+		failedAttempts := s.nextFailedAttempts(foundUser, now)
+		update := foundUser.Update().
+			SetFailedLoginAttempts(failedAttempts).
+			SetLastFailedLoginAt(now)
+		var lockUntil time.Time
+		if failedAttempts >= s.securityConfig.MaxLoginAttempts {
+			lockUntil = now.Add(s.securityConfig.AccountLockoutDuration)
+			update = update.SetAccountLockedUntil(lockUntil)
+		}
+		if _, err := update.Save(ctx); err != nil {
+			s.logger.Error("failed to update failed login attempts", "user_id", foundUser.ID, "error", err)
+		}
+		if !lockUntil.IsZero() {
+			s.notifyAccountLocked(ctx, foundUser, lockUntil)
+		}
+
+		if err := s.securityLogger.LogLoginFailed(ctx, foundUser.Email, "invalid two-factor code"); err != nil {
+			// Log error but continue
+		}
+
+		return nil, status.Error(codes.Unauthenticated, "invalid verification code")
+	}
+
+	// Persist backup code consumption
+	if len(remainingBackupCodes) != len(foundUser.TotpBackupCodes) {
+		foundUser, err = foundUser.Update().SetTotpBackupCodes(remainingBackupCodes).Save(ctx)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to update backup codes")
+		}
+	}
+
+	// MFA tokens are single use
+	if claims.ExpiresAt != nil {
+		if err := s.tokenManager.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+			s.logger.Error("failed to revoke MFA token", "user_id", foundUser.ID, "token_id", claims.ID, "error", err)
+		}
+	}
+
+	// The MFA token carries the remember-me choice but not what was typed at
+	// the password step
+	return s.completeLogin(ctx, foundUser, foundUser.Email, claims.RememberMe)
+}
+
+// verifySecondFactor checks a TOTP code, falling back to backup codes.
+// It returns the remaining backup code hashes and whether the code was accepted.
+func (s *AuthService) verifySecondFactor(u *ent.User, code string) ([]string, bool) {
+	if s.totpManager.ValidateCode(u.TotpSecret, code, time.Now()) {
+		return u.TotpBackupCodes, true
+	}
+	return s.totpManager.ConsumeBackupCode(u.TotpBackupCodes, code)
+}
+
+// API Key Methods
+
+// CreateAPIKey issues an API key for the authenticated user. The raw key is
+// only returned here; afterwards only its prefix is shown.
+func (s *AuthService) CreateAPIKey(ctx context.Context, req *authv1.CreateAPIKeyRequest) (*authv1.CreateAPIKeyResponse, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	if len(req.Scopes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one scope is required")
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil {
+		t := req.ExpiresAt.AsTime()
+		if !t.After(time.Now()) {
+			return nil, status.Error(codes.InvalidArgument, "expiry must be in the future")
+		}
+		expiresAt = &t
+	}
+
+	key, rawKey, err := s.apiKeys.Create(ctx, uuid.MustParse(userID), req.Name, req.Scopes, expiresAt)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidScope) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, "failed to create API key")
+	}
+
+	if err := s.securityLogger.LogFromContext(ctx, key.UserID, security.EventTypeSecurityAlert,
+		fmt.Sprintf("API key %q created", key.Name), security.SeverityMedium); err != nil {
+		// Log error but don't fail
+	}
+
+	return &authv1.CreateAPIKeyResponse{
+		ApiKey: convertAPIKeyToProto(key),
+		Key:    rawKey,
+	}, nil
+}
+
+// ListAPIKeys returns the authenticated user's API keys, including revoked ones
+func (s *AuthService) ListAPIKeys(ctx context.Context, _ *authv1.ListAPIKeysRequest) (*authv1.ListAPIKeysResponse, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	keys, err := s.apiKeys.List(ctx, uuid.MustParse(userID))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list API keys")
+	}
+
+	protoKeys := make([]*authv1.APIKey, len(keys))
+	for i, key := range keys {
+		protoKeys[i] = convertAPIKeyToProto(key)
+	}
+
+	return &authv1.ListAPIKeysResponse{ApiKeys: protoKeys}, nil
+}
+
+// RevokeAPIKey disables one of the authenticated user's API keys
+func (s *AuthService) RevokeAPIKey(ctx context.Context, req *authv1.RevokeAPIKeyRequest) (*emptypb.Empty, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	keyID, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid API key ID")
+	}
+
+	if err := s.apiKeys.Revoke(ctx, uuid.MustParse(userID), keyID); err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "API key not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to revoke API key")
+	}
+
+	if err := s.securityLogger.LogFromContext(ctx, uuid.MustParse(userID), security.EventTypeSecurityAlert,
+		"API key revoked", security.SeverityMedium); err != nil {
+		// Log error but don't fail
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListSessions returns the devices the authenticated user is signed in on
+func (s *AuthService) ListSessions(ctx context.Context, _ *authv1.ListSessionsRequest) (*authv1.ListSessionsResponse, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	sessions, err := s.sessions.List(ctx, uuid.MustParse(userID))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to list sessions")
+	}
+
+	protoSessions := make([]*authv1.Session, len(sessions))
+	for i, sess := range sessions {
+		protoSessions[i] = convertSessionToProto(sess)
+	}
+
+	return &authv1.ListSessionsResponse{Sessions: protoSessions}, nil
+}
+
+// RevokeSession signs one of the authenticated user's sessions out. Its
+// refresh token stops working immediately; access tokens already issued to it
+// remain valid until they expire.
+func (s *AuthService) RevokeSession(ctx context.Context, req *authv1.RevokeSessionRequest) (*emptypb.Empty, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	sessionID, err := uuid.Parse(req.SessionId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid session ID")
+	}
+
+	if err := s.sessions.Revoke(ctx, uuid.MustParse(userID), sessionID); err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "session not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to revoke session")
+	}
+
+	if err := s.securityLogger.LogFromContext(ctx, uuid.MustParse(userID), security.EventTypeSecurityAlert,
+		"Session revoked", security.SeverityMedium); err != nil {
+		// Log error but don't fail
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// Phase 2: Email Verification Methods
+
+// SendVerificationEmail sends a verification email to the authenticated user
+func (s *AuthService) SendVerificationEmail(ctx context.Context, _ *authv1.SendVerificationEmailRequest) (*emptypb.Empty, error) {
+	// Get user ID from context
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	if err := s.emailVerificationService.SendVerificationEmail(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// VerifyEmail verifies a user's email address using a token
+func (s *AuthService) VerifyEmail(ctx context.Context, req *authv1.VerifyEmailRequest) (*emptypb.Empty, error) {
+	if err := s.emailVerificationService.VerifyEmail(ctx, req.Token); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ResendVerificationEmail resends the verification email
 func (s *AuthService) ResendVerificationEmail(ctx context.Context, _ *authv1.ResendVerificationEmailRequest) (*emptypb.Empty, error) {
 	// Get user ID from context
 	userID, ok := middleware.GetUserIDFromContext(ctx)
@@ -579,215 +1487,924 @@ func (s *AuthService) ResendVerificationEmail(ctx context.Context, _ *authv1.Res
 		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
 	}
 
-	if err := s.emailVerificationService.ResendVerificationEmail(ctx, userID); err != nil {
+	if err := s.emailVerificationService.ResendVerificationEmail(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// GetVerificationStatus returns the email verification status
+func (s *AuthService) GetVerificationStatus(ctx context.Context, _ *authv1.GetVerificationStatusRequest) (*authv1.GetVerificationStatusResponse, error) {
+	// Get user ID from context
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	status, err := s.emailVerificationService.GetVerificationStatus(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authv1.GetVerificationStatusResponse{
+		Status: convertVerificationStatusToProto(status),
+	}, nil
+}
+
+// RequestEmailChange sends a confirmation link to the new address; the
+// current email stays in effect until it is confirmed
+func (s *AuthService) RequestEmailChange(ctx context.Context, req *authv1.RequestEmailChangeRequest) (*emptypb.Empty, error) {
+	// Get user ID from context
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	if err := s.emailVerificationService.RequestEmailChange(ctx, userID, req.NewEmail); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ConfirmEmailChange applies a pending email change using its token
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, req *authv1.ConfirmEmailChangeRequest) (*emptypb.Empty, error) {
+	if err := s.emailVerificationService.ConfirmEmailChange(ctx, req.Token); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// Phase 2: Password Reset Methods
+
+// RequestPasswordReset initiates a password reset process
+func (s *AuthService) RequestPasswordReset(ctx context.Context, req *authv1.RequestPasswordResetRequest) (*emptypb.Empty, error) {
+	if err := s.verifyCaptcha(ctx, req.CaptchaToken); err != nil {
+		return nil, err
+	}
+
+	if err := s.passwordResetService.RequestPasswordReset(ctx, req.Email); err != nil {
+		// For security, we might want to return success even if the email doesn't exist
+		// to avoid revealing whether an email is registered
+		return &emptypb.Empty{}, nil
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// VerifyPasswordResetToken verifies if a password reset token is valid
+func (s *AuthService) VerifyPasswordResetToken(ctx context.Context, req *authv1.VerifyPasswordResetTokenRequest) (*authv1.VerifyPasswordResetTokenResponse, error) {
+	tokenInfo, err := s.passwordResetService.VerifyPasswordResetToken(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &authv1.VerifyPasswordResetTokenResponse{
+		IsValid: tokenInfo.IsValid,
+		Email:   tokenInfo.Email,
+	}
+
+	if tokenInfo.ExpiresAt != nil {
+		response.ExpiresAt = timestamppb.New(*tokenInfo.ExpiresAt)
+	}
+
+	return response, nil
+}
+
+// ResetPassword resets a user's password using a reset token
+func (s *AuthService) ResetPassword(ctx context.Context, req *authv1.ResetPasswordRequest) (*emptypb.Empty, error) {
+	if err := s.passwordResetService.ResetPassword(ctx, req.Token, req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// Phase 2: Security Methods - COMPLETE IMPLEMENTATION
+
+// GetSecurityEvents returns security events for the authenticated user
+func (s *AuthService) GetSecurityEvents(ctx context.Context, req *authv1.GetSecurityEventsRequest) (*authv1.GetSecurityEventsResponse, error) {
+	// Get user ID from context
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID")
+	}
+
+	// Build query
+	query := s.client.SecurityEvent.Query()
+
+	// Without read_all, only show their own events
+	if !authz.Can(userRole, authz.SecurityEventsReadAll) {
+		query = query.Where(securityevent.UserIDEQ(userUUID))
+	}
+
+	// Apply filters
+	query = filterSecurityEvents(query, req.EventType, req.Severity, req.FromDate, req.ToDate)
+
+	// Get total count
+	totalCount, err := query.Count(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to count security events")
+	}
+
+	// Apply pagination
+	pageSize := req.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	// Resume after the last event of the previous page
+	if req.PageToken != "" {
+		cursor, err := decodePageCursor(req.PageToken)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		query = query.Where(securityevent.Or(
+			securityevent.CreatedAtLT(cursor.CreatedAt),
+			securityevent.And(
+				securityevent.CreatedAtEQ(cursor.CreatedAt),
+				securityevent.IDLT(cursor.ID),
+			),
+		))
+	}
+
+	// Fetch one extra row to detect whether another page exists
+	query = query.
+		Limit(int(pageSize) + 1).
+		Order(ent.Desc(securityevent.FieldCreatedAt), ent.Desc(securityevent.FieldID))
+
+	// Execute query
+	events, err := query.All(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get security events")
+	}
+
+	// Create next page token
+	nextPageToken := ""
+	if len(events) > int(pageSize) {
+		events = events[:pageSize]
+		last := events[len(events)-1]
+		nextPageToken = encodePageCursor(last.CreatedAt, last.ID)
+	}
+
+	// Convert to proto
+	protoEvents := make([]*authv1.SecurityEvent, len(events))
+	for i, event := range events {
+		protoEvents[i] = s.convertSecurityEventToProto(event)
+	}
+
+	return &authv1.GetSecurityEventsResponse{
+		Events:        protoEvents,
+		NextPageToken: nextPageToken,
+		TotalCount:    int32(totalCount),
+	}, nil
+}
+
+// filterSecurityEvents narrows query to the given type, severity and date
+// range; unspecified and nil values don't filter
+func filterSecurityEvents(query *ent.SecurityEventQuery, eventType authv1.SecurityEventType, severity authv1.SecurityEventSeverity, fromDate, toDate *timestamppb.Timestamp) *ent.SecurityEventQuery {
+	if eventType != authv1.SecurityEventType_SECURITY_EVENT_TYPE_UNSPECIFIED {
+		if entEventType, err := security.ParseEventType(convertProtoEventTypeToString(eventType)); err == nil {
+			query = query.Where(securityevent.EventTypeEQ(entEventType))
+		}
+	}
+
+	if severity != authv1.SecurityEventSeverity_SECURITY_EVENT_SEVERITY_UNSPECIFIED {
+		if entSeverity, err := security.ParseSeverity(convertProtoSeverityToString(severity)); err == nil {
+			query = query.Where(securityevent.SeverityEQ(entSeverity))
+		}
+	}
+
+	if fromDate != nil {
+		query = query.Where(securityevent.CreatedAtGTE(fromDate.AsTime()))
+	}
+	if toDate != nil {
+		query = query.Where(securityevent.CreatedAtLTE(toDate.AsTime()))
+	}
+
+	return query
+}
+
+// securityEventCSVHeader lists the columns of ExportSecurityEvents
+var securityEventCSVHeader = []string{"timestamp", "type", "severity", "ip_address", "description", "resolved"}
+
+// ExportSecurityEvents returns security events as CSV, oldest first, for
+// support tickets. Regular users export their own events; admins export every
+// user's events or filter by user_id.
+func (s *AuthService) ExportSecurityEvents(ctx context.Context, req *authv1.ExportSecurityEventsRequest) (*authv1.ExportSecurityEventsResponse, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	query := s.client.SecurityEvent.Query()
+
+	// Same scoping as GetSecurityStats
+	if !authz.Can(userRole, authz.SecurityEventsReadAll) {
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user ID")
+		}
+		query = query.Where(securityevent.UserIDEQ(userUUID))
+	} else if req.UserId != "" {
+		filterUUID, err := uuid.Parse(req.UserId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user ID filter")
+		}
+		query = query.Where(securityevent.UserIDEQ(filterUUID))
+	}
+
+	query = filterSecurityEvents(query, req.EventType, req.Severity, req.FromDate, req.ToDate)
+
+	// One extra row tells whether the export was cut short
+	events, err := query.
+		Order(ent.Asc(securityevent.FieldCreatedAt), ent.Asc(securityevent.FieldID)).
+		Limit(maxSecurityEventExportRows + 1).
+		All(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get security events")
+	}
+
+	truncated := len(events) > maxSecurityEventExportRows
+	if truncated {
+		events = events[:maxSecurityEventExportRows]
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(securityEventCSVHeader); err != nil {
+		return nil, status.Error(codes.Internal, "failed to export security events")
+	}
+	for _, event := range events {
+		record := []string{
+			event.CreatedAt.UTC().Format(time.RFC3339),
+			string(event.EventType),
+			string(event.Severity),
+			csvSafe(event.IPAddress),
+			csvSafe(event.Description),
+			strconv.FormatBool(event.Resolved),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, status.Error(codes.Internal, "failed to export security events")
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, status.Error(codes.Internal, "failed to export security events")
+	}
+
+	return &authv1.ExportSecurityEventsResponse{
+		Csv:       buf.String(),
+		RowCount:  int32(len(events)),
+		Truncated: truncated,
+	}, nil
+}
+
+// csvSafe stops spreadsheets from evaluating a user-influenced cell as a formula
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// GetSecurityStats returns security event counters. Regular users only see
+// their own statistics; admins see global totals or filter by user_id.
+func (s *AuthService) GetSecurityStats(ctx context.Context, req *authv1.GetSecurityStatsRequest) (*authv1.GetSecurityStatsResponse, error) {
+	// Get user ID from context
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	var scopeUserID *uuid.UUID
+	if !authz.Can(userRole, authz.SecurityEventsReadAll) {
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user ID")
+		}
+		scopeUserID = &userUUID
+	} else if req.UserId != "" {
+		filterUUID, err := uuid.Parse(req.UserId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid user ID filter")
+		}
+		scopeUserID = &filterUUID
+	}
+
+	stats, err := s.securityService.GetSecurityStats(ctx, scopeUserID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to get security stats")
+	}
+
+	return &authv1.GetSecurityStatsResponse{
+		TotalEvents:        int32(stats.TotalEvents),
+		UnresolvedEvents:   int32(stats.UnresolvedEvents),
+		HighSeverityEvents: int32(stats.HighSeverityEvents),
+	}, nil
+}
+
+// BulkResolveSecurityEvents marks several security events resolved at once
+// (admin only). Resolving an already resolved event is a no-op.
+func (s *AuthService) BulkResolveSecurityEvents(ctx context.Context, req *authv1.BulkResolveSecurityEventsRequest) (*authv1.BulkResolveSecurityEventsResponse, error) {
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+	if !authz.Can(userRole, authz.SecurityEventsManage) {
+		return nil, status.Error(codes.PermissionDenied, "admin access required")
+	}
+
+	ids, err := parseBatchIDs(req.EventIds, "event")
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := s.securityService.BulkResolveSecurityEvents(ctx, ids)
+	if err != nil {
+		if errors.Is(err, ErrSecurityEventNotFound) {
+			return nil, status.Error(codes.NotFound, "security event not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to resolve security events")
+	}
+
+	return &authv1.BulkResolveSecurityEventsResponse{
+		ResolvedCount: int32(resolved),
+	}, nil
+}
+
+// DeleteSecurityEvents deletes specific security events, such as noise from
+// a misbehaving client (admin only). The deletion itself is recorded.
+func (s *AuthService) DeleteSecurityEvents(ctx context.Context, req *authv1.DeleteSecurityEventsRequest) (*authv1.DeleteSecurityEventsResponse, error) {
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+	if !authz.Can(userRole, authz.SecurityEventsManage) {
+		return nil, status.Error(codes.PermissionDenied, "admin access required")
+	}
+
+	ids, err := parseBatchIDs(req.EventIds, "event")
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.securityService.DeleteSecurityEvents(ctx, ids)
+	if err != nil {
+		if errors.Is(err, ErrSecurityEventNotFound) {
+			return nil, status.Error(codes.NotFound, "security event not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to delete security events")
+	}
+
+	if err := s.securityLogger.LogCurrentUserFromContext(ctx, security.EventTypeSecurityAlert,
+		fmt.Sprintf("Deleted %d security events", deleted), security.SeverityMedium); err != nil {
+		// Log error but don't fail
+	}
+
+	return &authv1.DeleteSecurityEventsResponse{
+		DeletedCount: int32(deleted),
+	}, nil
+}
+
+// UnlockAccount unlocks a user's account (admin only)
+func (s *AuthService) UnlockAccount(ctx context.Context, req *authv1.UnlockAccountRequest) (*emptypb.Empty, error) {
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+	if !authz.Can(userRole, authz.AccountUnlock) {
+		return nil, status.Error(codes.PermissionDenied, "admin access required")
+	}
+
+	userUUID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID")
+	}
+
+	// Unlock the account
+	err = s.client.User.UpdateOneID(userUUID).
+		SetFailedLoginAttempts(0).
+		ClearAccountLockedUntil().
+		Exec(ctx)
+
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to unlock account")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// SetUserActive activates or deactivates a user's account (admin only)
+func (s *AuthService) SetUserActive(ctx context.Context, req *authv1.SetUserActiveRequest) (*emptypb.Empty, error) {
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+	if !authz.Can(userRole, authz.UserManage) {
+		return nil, status.Error(codes.PermissionDenied, "admin access required")
+	}
+
+	userUUID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID")
+	}
+
+	if !req.IsActive {
+		target, err := s.client.User.Get(ctx, userUUID)
+		if err != nil {
+			if ent.IsNotFound(err) {
+				return nil, status.Error(codes.NotFound, "user not found")
+			}
+			return nil, status.Error(codes.Internal, "failed to get user")
+		}
+		if target.Role == user.RoleAdmin && target.IsActive {
+			adminCount, err := countActiveAdmins(ctx, s.client.User)
+			if err != nil {
+				return nil, status.Error(codes.Internal, "failed to count admins")
+			}
+			if adminCount <= 1 {
+				return nil, status.Error(codes.FailedPrecondition, "cannot deactivate the last remaining admin")
+			}
+		}
+	}
+
+	if err := s.client.User.UpdateOneID(userUUID).SetIsActive(req.IsActive).Exec(ctx); err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to update account status")
+	}
+
+	if !req.IsActive {
+		// Force the user to sign in again, which fails while inactive
+		if _, err := s.sessions.RevokeAll(ctx, userUUID); err != nil {
+			return nil, status.Error(codes.Internal, "failed to end sessions")
+		}
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListUsers lists users with optional filters and keyset pagination (admin only)
+func (s *AuthService) ListUsers(ctx context.Context, req *authv1.ListUsersRequest) (*authv1.ListUsersResponse, error) {
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+	if !authz.Can(userRole, authz.UserManage) {
+		return nil, status.Error(codes.PermissionDenied, "admin access required")
+	}
+
+	filter := repository.UserListFilter{
+		IsActive:      req.IsActive,
+		EmailVerified: req.EmailVerified,
+		Search:        strings.TrimSpace(req.Search),
+	}
+
+	if req.Role != authv1.UserRole_USER_ROLE_UNSPECIFIED {
+		role := convertProtoRoleToString(req.Role)
+		if role == "" {
+			return nil, status.Error(codes.InvalidArgument, "invalid role filter")
+		}
+		filter.Role = &role
+	}
+
+	users, nextPageToken, totalCount, err := s.listUserPage(ctx, filter, req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	protoUsers := make([]*authv1.User, len(users))
+	for i, u := range users {
+		protoUsers[i] = s.convertUserToProto(u)
+	}
+
+	return &authv1.ListUsersResponse{
+		Users:         protoUsers,
+		NextPageToken: nextPageToken,
+		TotalCount:    int32(totalCount),
+	}, nil
+}
+
+// ListLockedAccounts lists users whose account is currently locked after
+// failed logins, newest accounts first (admin only)
+func (s *AuthService) ListLockedAccounts(ctx context.Context, req *authv1.ListLockedAccountsRequest) (*authv1.ListLockedAccountsResponse, error) {
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+	if !authz.Can(userRole, authz.AccountUnlock) {
+		return nil, status.Error(codes.PermissionDenied, "admin access required")
+	}
+
+	now := time.Now()
+	users, nextPageToken, totalCount, err := s.listUserPage(ctx, repository.UserListFilter{LockedAt: &now}, req.PageSize, req.PageToken)
+	if err != nil {
 		return nil, err
 	}
 
-	return &emptypb.Empty{}, nil
+	accounts := make([]*authv1.LockedAccount, len(users))
+	for i, u := range users {
+		accounts[i] = &authv1.LockedAccount{
+			User:                s.convertUserToProto(u),
+			LockedUntil:         timestamppb.New(*u.AccountLockedUntil),
+			FailedLoginAttempts: int32(u.FailedLoginAttempts),
+		}
+	}
+
+	return &authv1.ListLockedAccountsResponse{
+		Accounts:      accounts,
+		NextPageToken: nextPageToken,
+		TotalCount:    int32(totalCount),
+	}, nil
 }
 
-// GetVerificationStatus returns the email verification status
-func (s *AuthService) GetVerificationStatus(ctx context.Context, _ *authv1.GetVerificationStatusRequest) (*authv1.GetVerificationStatusResponse, error) {
-	// Get user ID from context
-	userID, ok := middleware.GetUserIDFromContext(ctx)
-	if !ok {
-		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+// listUserPage fetches one keyset-paginated page of users matching filter,
+// returning the token for the next page, if any, and the total match count
+func (s *AuthService) listUserPage(ctx context.Context, filter repository.UserListFilter, pageSize int32, pageToken string) ([]*ent.User, string, int, error) {
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	if pageSize > 100 {
+		pageSize = 100
 	}
 
-	status, err := s.emailVerificationService.GetVerificationStatus(ctx, userID)
-	if err != nil {
-		return nil, err
+	// Fetch one extra row to detect whether another page exists
+	filter.Limit = int(pageSize) + 1
+
+	if pageToken != "" {
+		cursor, err := decodePageCursor(pageToken)
+		if err != nil {
+			return nil, "", 0, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+		filter.AfterCreatedAt = &cursor.CreatedAt
+		filter.AfterID = &cursor.ID
 	}
 
-	response := &authv1.GetVerificationStatusResponse{
-		Status: &authv1.EmailVerificationStatus{
-			EmailVerified: status.EmailVerified,
-			Attempts:      int32(status.Attempts),
-			MaxAttempts:   int32(status.MaxAttempts),
-			IsExpired:     status.IsExpired,
-			CanResend:     status.CanResend,
-		},
+	users, totalCount, err := s.userRepo.List(ctx, filter)
+	if err != nil {
+		return nil, "", 0, status.Error(codes.Internal, "failed to list users")
 	}
 
-	if status.ExpiresAt != nil {
-		response.Status.ExpiresAt = timestamppb.New(*status.ExpiresAt)
+	nextPageToken := ""
+	if len(users) > int(pageSize) {
+		users = users[:pageSize]
+		last := users[len(users)-1]
+		nextPageToken = encodePageCursor(last.CreatedAt, last.ID)
 	}
 
-	return response, nil
+	return users, nextPageToken, totalCount, nil
 }
 
-// Phase 2: Password Reset Methods
+// GetUserByID returns a user by ID (admin only)
+func (s *AuthService) GetUserByID(ctx context.Context, req *authv1.GetUserByIDRequest) (*authv1.GetUserByIDResponse, error) {
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+	if !authz.Can(userRole, authz.UserManage) {
+		return nil, status.Error(codes.PermissionDenied, "admin access required")
+	}
 
-// RequestPasswordReset initiates a password reset process
-func (s *AuthService) RequestPasswordReset(ctx context.Context, req *authv1.RequestPasswordResetRequest) (*emptypb.Empty, error) {
-	if err := s.passwordResetService.RequestPasswordReset(ctx, req.Email); err != nil {
-		// For security, we might want to return success even if the email doesn't exist
-		// to avoid revealing whether an email is registered
-		return &emptypb.Empty{}, nil
+	userUUID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID")
 	}
 
-	return &emptypb.Empty{}, nil
+	foundUser, err := s.client.User.Get(ctx, userUUID)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+
+	return &authv1.GetUserByIDResponse{
+		User: s.convertUserToProto(foundUser),
+	}, nil
 }
 
-// VerifyPasswordResetToken verifies if a password reset token is valid
-func (s *AuthService) VerifyPasswordResetToken(ctx context.Context, req *authv1.VerifyPasswordResetTokenRequest) (*authv1.VerifyPasswordResetTokenResponse, error) {
-	tokenInfo, err := s.passwordResetService.VerifyPasswordResetToken(ctx, req.Token)
-	if err != nil {
-		return nil, err
+// UpdateUserRole changes a user's role (admin only). The last remaining admin
+// cannot be demoted.
+func (s *AuthService) UpdateUserRole(ctx context.Context, req *authv1.UpdateUserRoleRequest) (*authv1.UpdateUserRoleResponse, error) {
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+	if !authz.Can(userRole, authz.UserManage) {
+		return nil, status.Error(codes.PermissionDenied, "admin access required")
 	}
 
-	response := &authv1.VerifyPasswordResetTokenResponse{
-		IsValid: tokenInfo.IsValid,
-		Email:   tokenInfo.Email,
+	userUUID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user ID")
 	}
 
-	if tokenInfo.ExpiresAt != nil {
-		response.ExpiresAt = timestamppb.New(*tokenInfo.ExpiresAt)
+	newRole := user.Role(convertProtoRoleToString(req.Role))
+	if err := user.RoleValidator(newRole); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid role")
 	}
 
-	return response, nil
-}
+	tx, err := s.client.Tx(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to update role")
+	}
 
-// ResetPassword resets a user's password using a reset token
-func (s *AuthService) ResetPassword(ctx context.Context, req *authv1.ResetPasswordRequest) (*emptypb.Empty, error) {
-	if err := s.passwordResetService.ResetPassword(ctx, req.Token, req.NewPassword); err != nil {
+	updatedUser, err := s.updateUserRoleTx(ctx, tx, userUUID, newRole)
+	if err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			s.logger.Error("failed to roll back role update", "user_id", userUUID, "error", rerr)
+		}
 		return nil, err
 	}
 
-	return &emptypb.Empty{}, nil
+	if err := tx.Commit(); err != nil {
+		return nil, status.Error(codes.Internal, "failed to update role")
+	}
+
+	return &authv1.UpdateUserRoleResponse{
+		User: s.convertUserToProto(updatedUser),
+	}, nil
 }
 
-// Phase 2: Security Methods - COMPLETE IMPLEMENTATION
+// updateUserRoleTx sets a user's role inside tx and returns the updated user.
+// Errors are gRPC status errors.
+func (s *AuthService) updateUserRoleTx(ctx context.Context, tx *ent.Tx, userID uuid.UUID, newRole user.Role) (*ent.User, error) {
+	foundUser, err := tx.User.Get(ctx, userID)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
 
-// GetSecurityEvents returns security events for the authenticated user
-func (s *AuthService) GetSecurityEvents(ctx context.Context, req *authv1.GetSecurityEventsRequest) (*authv1.GetSecurityEventsResponse, error) {
-	// Get user ID from context
-	userID, ok := middleware.GetUserIDFromContext(ctx)
-	if !ok {
-		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	if foundUser.Role == newRole {
+		return foundUser, nil
 	}
 
-	// Get user role to check if they're admin
-	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+	// Inactive admins can't sign in, so only active ones count
+	if foundUser.Role == user.RoleAdmin && foundUser.IsActive {
+		adminCount, err := lockActiveAdmins(ctx, tx)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to count admins")
+		}
+		if adminCount <= 1 {
+			return nil, status.Error(codes.FailedPrecondition, "cannot demote the last remaining admin")
+		}
+	}
 
-	userUUID, err := uuid.Parse(userID)
+	updatedUser, err := foundUser.Update().SetRole(newRole).Save(ctx)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid user ID")
+		return nil, status.Error(codes.Internal, "failed to update role")
 	}
 
-	// Build query
-	query := s.client.SecurityEvent.Query()
+	return updatedUser, nil
+}
 
-	// If not admin, only show their own events
-	if userRole != "admin" {
-		query = query.Where(securityevent.UserIDEQ(userUUID))
+// countActiveAdmins counts the admins that can still sign in
+func countActiveAdmins(ctx context.Context, users *ent.UserClient) (int, error) {
+	return users.Query().Where(user.RoleEQ(user.RoleAdmin), user.IsActiveEQ(true)).Count(ctx)
+}
+
+// lockActiveAdmins counts the active admins like countActiveAdmins, but locks
+// their rows until tx ends. Two concurrent demotions then can't both count
+// the other admin and together leave none.
+func lockActiveAdmins(ctx context.Context, tx *ent.Tx) (int, error) {
+	ids, err := tx.User.Query().
+		Where(user.RoleEQ(user.RoleAdmin), user.IsActiveEQ(true), forUpdate).
+		IDs(ctx)
+	return len(ids), err
+}
+
+// forUpdate is a query predicate that locks the selected rows. SQLite has no
+// FOR UPDATE and serializes write transactions on its own.
+func forUpdate(s *entsql.Selector) {
+	if s.Dialect() != dialect.SQLite {
+		s.ForUpdate()
 	}
+}
 
-	// Apply filters
-	if req.EventType != authv1.SecurityEventType_SECURITY_EVENT_TYPE_UNSPECIFIED {
-		eventType := convertProtoEventTypeToString(req.EventType)
-		if entEventType, err := security.ParseEventType(eventType); err == nil {
-			query = query.Where(securityevent.EventTypeEQ(entEventType))
-		}
+// Helper functions
+
+// notifyAccountLocked emails the user about a lockout if they have security
+// notifications enabled. Failures are only logged.
+func (s *AuthService) notifyAccountLocked(ctx context.Context, u *ent.User, lockedUntil time.Time) {
+	if s.emailService == nil || !notificationEnabled(u, notifySecurityAlerts) {
+		return
 	}
 
-	// Apply date filters
-	if req.FromDate != nil {
-		query = query.Where(securityevent.CreatedAtGTE(req.FromDate.AsTime()))
+	if err := s.emailService.SendAccountLockedEmail(ctx, u, lockedUntil); err != nil {
+		s.logger.Error("failed to send account locked email", "user_id", u.ID, "error", err)
 	}
-	if req.ToDate != nil {
-		query = query.Where(securityevent.CreatedAtLTE(req.ToDate.AsTime()))
+}
+
+// notifyRegistrationConflict emails the owner of an existing account that
+// someone tried to register with their address. The password is hashed anyway
+// so the response takes as long as a real registration.
+func (s *AuthService) notifyRegistrationConflict(ctx context.Context, u *ent.User, password string) {
+	if _, err := s.passwordManager.HashPassword(password); err != nil {
+		s.logger.Error("failed to hash password", "error", err)
 	}
 
-	// Get total count
-	totalCount, err := query.Count(ctx)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to count security events")
+	if s.emailService == nil || !notificationEnabled(u, notifySecurityAlerts) {
+		return
 	}
 
-	// Apply pagination
-	pageSize := req.PageSize
-	if pageSize <= 0 {
-		pageSize = 10
+	message := "Someone tried to create a new account with this email address. If it was you, sign in or reset your password instead."
+	if err := s.emailService.SendSecurityAlertEmail(ctx, u, message); err != nil {
+		s.logger.Error("failed to send registration conflict email", "user_id", u.ID, "error", err)
 	}
-	if pageSize > 100 {
-		pageSize = 100
+}
+
+// verifyCaptcha checks the CAPTCHA token from the request field, falling back
+// to the x-captcha-token header. It is a no-op unless a verifier is configured.
+func (s *AuthService) verifyCaptcha(ctx context.Context, token string) error {
+	if s.captchaVerifier == nil {
+		return nil
 	}
 
-	// TODO: Implement proper pagination with page tokens
-	// For now, use simple offset-based pagination
-	offset := 0
-	if req.PageToken != "" {
-		// Parse page token (simplified - in production, use proper token encoding)
-		fmt.Sscanf(req.PageToken, "offset:%d", &offset)
+	if token == "" {
+		token, _ = middleware.GetCaptchaTokenFromContext(ctx)
+	}
+	if token == "" {
+		return status.Error(codes.InvalidArgument, "CAPTCHA token is required")
 	}
 
-	query = query.
-		Limit(int(pageSize)).
-		Offset(offset).
-		Order(ent.Desc(securityevent.FieldCreatedAt))
+	if err := s.captchaVerifier.Verify(ctx, token, middleware.GetIPAddressFromContext(ctx)); err != nil {
+		if errors.Is(err, auth.ErrInvalidCaptcha) {
+			return status.Error(codes.PermissionDenied, "CAPTCHA verification failed")
+		}
+		s.logger.Error("failed to verify CAPTCHA", "error", err)
+		return status.Error(codes.Unavailable, "CAPTCHA verification is unavailable, please try again later")
+	}
 
-	// Execute query
-	events, err := query.All(ctx)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "failed to get security events")
+	return nil
+}
+
+// nextFailedAttempts returns the failed attempt count including one more
+// failure at now. The count restarts when the previous failure falls outside
+// the configured window, so only recent bursts lead to a lockout.
+func (s *AuthService) nextFailedAttempts(u *ent.User, now time.Time) int {
+	window := s.securityConfig.FailedLoginWindow
+	if window > 0 && u.LastFailedLoginAt != nil && now.Sub(*u.LastFailedLoginAt) > window {
+		return 1
 	}
+	return u.FailedLoginAttempts + 1
+}
 
-	// Convert to proto
-	protoEvents := make([]*authv1.SecurityEvent, len(events))
-	for i, event := range events {
-		protoEvents[i] = s.convertSecurityEventToProto(event)
+// isNewLoginIP reports whether a login from ip should be flagged because the
+// user has signed in before but never from that address
+func (s *AuthService) isNewLoginIP(u *ent.User, ip string) bool {
+	if !s.securityConfig.DetectNewLoginIP || ip == "" {
+		return false
 	}
 
-	// Create next page token
-	nextPageToken := ""
-	if len(events) == int(pageSize) && offset+int(pageSize) < totalCount {
-		nextPageToken = fmt.Sprintf("offset:%d", offset+int(pageSize))
+	// Users from before the history existed still have their last login IP
+	known := u.RecentLoginIps
+	if len(known) == 0 && u.LastLoginIP != "" {
+		known = []string{u.LastLoginIP}
 	}
 
-	return &authv1.GetSecurityEventsResponse{
-		Events:        protoEvents,
-		NextPageToken: nextPageToken,
-		TotalCount:    int32(totalCount),
-	}, nil
+	return len(known) > 0 && !slices.Contains(known, ip)
 }
 
-// UnlockAccount unlocks a user's account (admin only)
-func (s *AuthService) UnlockAccount(ctx context.Context, req *authv1.UnlockAccountRequest) (*emptypb.Empty, error) {
-	// Check if user is admin
-	userRole, ok := middleware.GetUserRoleFromContext(ctx)
-	if !ok || userRole != "admin" {
-		return nil, status.Error(codes.PermissionDenied, "admin access required")
+// reportNewLoginIP records a suspicious activity event for a login from an
+// unfamiliar IP and alerts the user by email. Failures are only logged.
+func (s *AuthService) reportNewLoginIP(ctx context.Context, u *ent.User, ip string) {
+	if err := s.securityLogger.LogSuspiciousActivity(ctx, u.ID, "Login from new IP address "+ip); err != nil {
+		s.logger.Error("failed to record new login IP", "user_id", u.ID, "error", err)
 	}
 
-	userUUID, err := uuid.Parse(req.UserId)
-	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "invalid user ID")
+	if s.emailService == nil || !notificationEnabled(u, notifySecurityAlerts) {
+		return
 	}
 
-	// Unlock the account
-	err = s.client.User.UpdateOneID(userUUID).
-		SetFailedLoginAttempts(0).
-		ClearAccountLockedUntil().
-		Exec(ctx)
+	message := fmt.Sprintf("Your account was signed in to from a new IP address (%s). If this wasn't you, change your password and revoke the session.", ip)
+	if err := s.emailService.SendSecurityAlertEmail(ctx, u, message); err != nil {
+		s.logger.Error("failed to send new login IP alert", "user_id", u.ID, "error", err)
+	}
+}
+
+// recordLoginIP moves ip to the front of history, keeping at most size entries
+func recordLoginIP(history []string, ip string, size int) []string {
+	if ip == "" {
+		return history
+	}
+
+	updated := []string{ip}
+	for _, known := range history {
+		if known != ip && len(updated) < size {
+			updated = append(updated, known)
+		}
+	}
+	return updated
+}
+
+// upgradePasswordHash re-hashes a verified password when the stored hash uses
+// an outdated algorithm or parameters. Failures are only logged.
+func (s *AuthService) upgradePasswordHash(ctx context.Context, u *ent.User, password string) *ent.User {
+	if !s.passwordManager.NeedsRehash(u.PasswordHash) {
+		return u
+	}
 
+	hashedPassword, err := s.passwordManager.Rehash(password)
 	if err != nil {
-		if ent.IsNotFound(err) {
-			return nil, status.Error(codes.NotFound, "user not found")
+		s.logger.Error("failed to rehash password", "user_id", u.ID, "error", err)
+		return u
+	}
+
+	// Same password, new parameters: not a password change worth auditing
+	updated, err := u.Update().SetPasswordHash(hashedPassword).Save(withoutSecurityAudit(ctx))
+	if err != nil {
+		s.logger.Error("failed to upgrade password hash", "user_id", u.ID, "error", err)
+		return u
+	}
+	return updated
+}
+
+// passwordExpired reports whether the user's password is older than the
+// configured maximum age. Accounts without a recorded change never expire.
+func (s *AuthService) passwordExpired(u *ent.User) bool {
+	if s.securityConfig.MaxPasswordAge <= 0 || u.PasswordChangedAt == nil {
+		return false
+	}
+	return time.Since(*u.PasswordChangedAt) > s.securityConfig.MaxPasswordAge
+}
+
+// deleteUserData removes a user with their security events, API keys, sessions and task templates inside tx. Tasks
+// they created are deleted or orphaned per policy; tasks assigned to them are
+// unassigned.
+func (s *AuthService) deleteUserData(ctx context.Context, tx *ent.Tx, userID uuid.UUID) error {
+	if _, err := tx.SecurityEvent.Delete().Where(securityevent.UserIDEQ(userID)).Exec(ctx); err != nil {
+		return fmt.Errorf("delete security events: %w", err)
+	}
+
+	if _, err := tx.ApiKey.Delete().Where(apikey.UserIDEQ(userID)).Exec(ctx); err != nil {
+		return fmt.Errorf("delete API keys: %w", err)
+	}
+
+	if _, err := tx.Session.Delete().Where(session.UserIDEQ(userID)).Exec(ctx); err != nil {
+		return fmt.Errorf("delete sessions: %w", err)
+	}
+
+	if _, err := tx.Comment.Delete().Where(comment.AuthorIDEQ(userID)).Exec(ctx); err != nil {
+		return fmt.Errorf("delete comments: %w", err)
+	}
+
+	if _, err := tx.TaskTemplate.Delete().Where(tasktemplate.OwnerIDEQ(userID)).Exec(ctx); err != nil {
+		return fmt.Errorf("delete task templates: %w", err)
+	}
+
+	// Task history outlives its actors
+	if err := tx.TaskActivity.Update().Where(taskactivity.ActorIDEQ(userID)).ClearActor().Exec(ctx); err != nil {
+		return fmt.Errorf("detach task activity: %w", err)
+	}
+
+	createdByUser := task.HasCreatorWith(user.ID(userID))
+	if s.securityConfig.DeletedUserTaskPolicy == config.DeletedUserTasksDelete {
+		if _, err := tx.Comment.Delete().Where(comment.HasTaskWith(createdByUser)).Exec(ctx); err != nil {
+			return fmt.Errorf("delete task comments: %w", err)
+		}
+		if _, err := tx.TaskActivity.Delete().Where(taskactivity.HasTaskWith(createdByUser)).Exec(ctx); err != nil {
+			return fmt.Errorf("delete task activity: %w", err)
+		}
+		if _, err := tx.Task.Delete().Where(createdByUser).Exec(ctx); err != nil {
+			return fmt.Errorf("delete tasks: %w", err)
+		}
+	} else {
+		if err := tx.Task.Update().Where(createdByUser).ClearCreator().Exec(ctx); err != nil {
+			return fmt.Errorf("orphan tasks: %w", err)
 		}
-		return nil, status.Error(codes.Internal, "failed to unlock account")
 	}
 
-	// Log the unlock event
-	if err := s.securityLogger.LogFromContext(ctx, userUUID, security.EventTypeAccountUnlocked,
-		"Account unlocked by admin", security.SeverityLow); err != nil {
-		// Log error but don't fail
+	err := tx.Task.Update().
+		Where(task.HasAssigneeWith(user.ID(userID))).
+		ClearAssignee().
+		ClearAssignedTo().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("unassign tasks: %w", err)
 	}
 
-	return &emptypb.Empty{}, nil
+	if err := tx.User.DeleteOneID(userID).Exec(ctx); err != nil {
+		return fmt.Errorf("delete user: %w", err)
+	}
+
+	return nil
 }
 
-// Helper functions
+// revokeCurrentAccessToken blacklists the access token that authenticated the request
+func (s *AuthService) revokeCurrentAccessToken(ctx context.Context) {
+	jti, ok := middleware.GetTokenIDFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	expiresAt, ok := middleware.GetTokenExpiresAtFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	if err := s.tokenManager.RevokeToken(ctx, jti, expiresAt); err != nil {
+		s.logger.Error("failed to revoke access token", "token_id", jti, "error", err)
+	}
+}
 
 func (s *AuthService) validateRegisterRequest(req *authv1.RegisterRequest) error {
 	if err := auth.ValidateEmail(req.Email); err != nil {
@@ -817,6 +2434,7 @@ func (s *AuthService) convertUserToProto(u *ent.User) *authv1.User {
 		EmailVerified:                u.EmailVerified,
 		EmailNotificationsEnabled:    u.EmailNotificationsEnabled,
 		SecurityNotificationsEnabled: u.SecurityNotificationsEnabled,
+		Locale:                       u.Locale,
 		FailedLoginAttempts:          int32(u.FailedLoginAttempts),
 		CreatedAt:                    timestamppb.New(u.CreatedAt),
 		UpdatedAt:                    timestamppb.New(u.UpdatedAt),
@@ -837,6 +2455,58 @@ func (s *AuthService) convertUserToProto(u *ent.User) *authv1.User {
 	return proto
 }
 
+func convertVerificationStatusToProto(s *EmailVerificationStatus) *authv1.EmailVerificationStatus {
+	proto := &authv1.EmailVerificationStatus{
+		EmailVerified:      s.EmailVerified,
+		Attempts:           int32(s.Attempts),
+		MaxAttempts:        int32(s.MaxAttempts),
+		IsExpired:          s.IsExpired,
+		CanResend:          s.CanResend,
+		SecondsUntilResend: s.SecondsUntilResend,
+	}
+
+	if s.ExpiresAt != nil {
+		proto.ExpiresAt = timestamppb.New(*s.ExpiresAt)
+	}
+
+	return proto
+}
+
+func convertAPIKeyToProto(key *ent.ApiKey) *authv1.APIKey {
+	proto := &authv1.APIKey{
+		Id:        key.ID.String(),
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    key.Scopes,
+		CreatedAt: timestamppb.New(key.CreatedAt),
+	}
+
+	if key.ExpiresAt != nil {
+		proto.ExpiresAt = timestamppb.New(*key.ExpiresAt)
+	}
+
+	if key.LastUsedAt != nil {
+		proto.LastUsedAt = timestamppb.New(*key.LastUsedAt)
+	}
+
+	if key.RevokedAt != nil {
+		proto.RevokedAt = timestamppb.New(*key.RevokedAt)
+	}
+
+	return proto
+}
+
+func convertSessionToProto(sess *ent.Session) *authv1.Session {
+	return &authv1.Session{
+		Id:         sess.ID.String(),
+		IpAddress:  sess.IPAddress,
+		UserAgent:  sess.UserAgent,
+		CreatedAt:  timestamppb.New(sess.CreatedAt),
+		LastUsedAt: timestamppb.New(sess.LastUsedAt),
+		ExpiresAt:  timestamppb.New(sess.ExpiresAt),
+	}
+}
+
 func (s *AuthService) convertSecurityEventToProto(event *ent.SecurityEvent) *authv1.SecurityEvent {
 	proto := &authv1.SecurityEvent{
 		Id:          event.ID.String(),
@@ -873,6 +2543,19 @@ func convertRoleToProto(role user.Role) authv1.UserRole {
 	}
 }
 
+func convertProtoRoleToString(role authv1.UserRole) string {
+	switch role {
+	case authv1.UserRole_USER_ROLE_ADMIN:
+		return string(user.RoleAdmin)
+	case authv1.UserRole_USER_ROLE_MANAGER:
+		return string(user.RoleManager)
+	case authv1.UserRole_USER_ROLE_USER:
+		return string(user.RoleUser)
+	default:
+		return ""
+	}
+}
+
 func convertStringEventTypeToProto(eventType string) authv1.SecurityEventType {
 	switch eventType {
 	case "login_success":
@@ -945,3 +2628,18 @@ func convertStringSeverityToProto(severity string) authv1.SecurityEventSeverity
 		return authv1.SecurityEventSeverity_SECURITY_EVENT_SEVERITY_UNSPECIFIED
 	}
 }
+
+func convertProtoSeverityToString(severity authv1.SecurityEventSeverity) string {
+	switch severity {
+	case authv1.SecurityEventSeverity_SECURITY_EVENT_SEVERITY_LOW:
+		return security.SeverityLow
+	case authv1.SecurityEventSeverity_SECURITY_EVENT_SEVERITY_MEDIUM:
+		return security.SeverityMedium
+	case authv1.SecurityEventSeverity_SECURITY_EVENT_SEVERITY_HIGH:
+		return security.SeverityHigh
+	case authv1.SecurityEventSeverity_SECURITY_EVENT_SEVERITY_CRITICAL:
+		return security.SeverityCritical
+	default:
+		return ""
+	}
+}
@@ -3,24 +3,34 @@ package service
 
 import (
 	"context"
+	"encoding/csv"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
 	ent "github.com/gurkanbulca/taskmaster/ent/generated"
 	"github.com/gurkanbulca/taskmaster/ent/generated/enttest"
+	"github.com/gurkanbulca/taskmaster/ent/generated/securityevent"
+	"github.com/gurkanbulca/taskmaster/ent/generated/session"
+	"github.com/gurkanbulca/taskmaster/ent/generated/task"
 	"github.com/gurkanbulca/taskmaster/ent/generated/user"
 	"github.com/gurkanbulca/taskmaster/internal/config"
 	"github.com/gurkanbulca/taskmaster/internal/middleware"
 	"github.com/gurkanbulca/taskmaster/pkg/auth"
 	"github.com/gurkanbulca/taskmaster/pkg/email"
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -28,6 +38,7 @@ import (
 // Test helpers
 func setupTestDB(t *testing.T) *ent.Client {
 	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	RegisterSecurityHooks(client, logging.Default())
 	return client
 }
 
@@ -56,14 +67,41 @@ func createTestSecurityConfig() config.SecurityConfig {
 		MaxLoginAttempts:             3,
 		AccountLockoutDuration:       15 * time.Minute,
 		MaxEmailVerificationAttempts: 5,
+		EmailVerificationResendWait:  time.Hour,
 		MaxPasswordResetAttempts:     5,
 		PasswordResetRateLimit:       15 * time.Minute,
 		EnableSecurityNotifications:  true,
 		RequireEmailVerification:     false,
 		SessionTimeoutDuration:       30 * 24 * time.Hour,
+		LoginIPHistorySize:           5,
 	}
 }
 
+// newTestAuthService wires an AuthService with mock email delivery
+func newTestAuthService(client *ent.Client, securityConfig config.SecurityConfig) *AuthService {
+	tokenManager := auth.NewTokenManager(
+		"test-access-secret",
+		"test-refresh-secret",
+		15*time.Minute,
+		7*24*time.Hour,
+	)
+
+	mockEmailService := email.NewMockEmailService()
+	securityService := NewSecurityService(client)
+	securityLogger := NewSecurityLogger(securityService)
+	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
+
+	return NewAuthService(
+		client,
+		tokenManager,
+		emailVerificationService,
+		passwordResetService,
+		securityLogger,
+		securityConfig,
+	)
+}
+
 // Test AuthService
 func TestAuthService_Register(t *testing.T) {
 	tests := []struct {
@@ -159,8 +197,8 @@ func TestAuthService_Register(t *testing.T) {
 			mockEmailService := email.NewMockEmailService()
 			securityService := NewSecurityService(client)
 			securityLogger := NewSecurityLogger(securityService)
-			emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger)
-			passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger)
+			emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+			passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
 
 			authService := NewAuthService(
 				client,
@@ -192,6 +230,70 @@ func TestAuthService_Register(t *testing.T) {
 	}
 }
 
+func TestAuthService_Register_Conflicts(t *testing.T) {
+	tests := []struct {
+		name            string
+		hideConflicts   bool
+		email           string
+		username        string
+		expectedCode    codes.Code
+		wantAlertEmail  bool
+		wantUserCreated bool
+	}{
+		{name: "strict, taken email", email: "test@example.com", username: "newuser", expectedCode: codes.AlreadyExists},
+		{name: "strict, taken username", email: "new@example.com", username: "testuser", expectedCode: codes.AlreadyExists},
+		{name: "hidden, new account", hideConflicts: true, email: "new@example.com", username: "newuser", expectedCode: codes.OK, wantUserCreated: true},
+		{name: "hidden, taken email", hideConflicts: true, email: "test@example.com", username: "newuser", expectedCode: codes.OK, wantAlertEmail: true},
+		{name: "hidden, taken username", hideConflicts: true, email: "new@example.com", username: "testuser", expectedCode: codes.AlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			client := setupTestDB(t)
+			defer client.Close()
+
+			createTestUser(t, client)
+			securityConfig := createTestSecurityConfig()
+			securityConfig.HideRegistrationConflicts = tt.hideConflicts
+			authService := newTestAuthService(client, securityConfig)
+			mockEmailService := email.NewMockEmailService()
+			authService.SetEmailService(mockEmailService)
+
+			resp, err := authService.Register(context.Background(), &authv1.RegisterRequest{
+				Email:    tt.email,
+				Username: tt.username,
+				Password: "SecurePass123!",
+			})
+			require.Equal(t, tt.expectedCode, status.Code(err), err)
+
+			if tt.hideConflicts && err == nil {
+				// New and taken emails get the same response, without tokens
+				assert.Nil(t, resp.User)
+				assert.Empty(t, resp.AccessToken)
+				assert.Empty(t, resp.RefreshToken)
+				assert.True(t, resp.EmailVerificationRequired)
+			}
+			if tt.hideConflicts && tt.expectedCode == codes.AlreadyExists {
+				assert.NotContains(t, status.Convert(err).Message(), "email")
+			}
+
+			sent := mockEmailService.GetSentEmails()
+			if tt.wantAlertEmail {
+				require.Len(t, sent, 1)
+				assert.Equal(t, "security_alert", sent[0].Template)
+				assert.Equal(t, "test@example.com", sent[0].To)
+			} else {
+				assert.Empty(t, sent)
+			}
+
+			created, err := client.User.Query().Where(user.UsernameEQ(tt.username), user.EmailEQ(tt.email)).Exist(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUserCreated, created)
+		})
+	}
+}
+
 func TestAuthService_Login(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -287,8 +389,8 @@ func TestAuthService_Login(t *testing.T) {
 			mockEmailService := email.NewMockEmailService()
 			securityService := NewSecurityService(client)
 			securityLogger := NewSecurityLogger(securityService)
-			emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger)
-			passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger)
+			emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+			passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
 
 			authService := NewAuthService(
 				client,
@@ -332,6 +434,68 @@ func TestAuthService_Login(t *testing.T) {
 	}
 }
 
+func TestAuthService_LoginSecurityEventMetadata(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	tokenManager := auth.NewTokenManager("test-access-secret", "test-refresh-secret", 15*time.Minute, 7*24*time.Hour)
+	mockEmailService := email.NewMockEmailService()
+	securityLogger := NewSecurityLogger(NewSecurityService(client))
+	authService := NewAuthService(
+		client,
+		tokenManager,
+		NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{}),
+		NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{}),
+		securityLogger,
+		createTestSecurityConfig(),
+	)
+
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, middleware.ContextKeyIPAddress, "203.0.113.7")
+	ctx = context.WithValue(ctx, middleware.ContextKeyUserAgent, "taskmaster-cli/1.0")
+
+	// loginEvent returns the single event of eventType and clears the log
+	loginEvent := func(eventType securityevent.EventType) *ent.SecurityEvent {
+		event, err := client.SecurityEvent.Query().
+			Where(securityevent.EventTypeEQ(eventType)).
+			Only(ctx)
+		require.NoError(t, err)
+		_, err = client.SecurityEvent.Delete().Exec(ctx)
+		require.NoError(t, err)
+		return event
+	}
+	assertMetadata := func(event *ent.SecurityEvent, loginID string) {
+		assert.Equal(t, "203.0.113.7", event.Metadata["ip_address"])
+		assert.Equal(t, "taskmaster-cli/1.0", event.Metadata["user_agent"])
+		assert.Equal(t, loginID, event.Metadata["login_id"])
+	}
+
+	t.Run("success", func(t *testing.T) {
+		_, err := authService.Login(ctx, &authv1.LoginRequest{Email: "TestUser", Password: "TestPass123!"})
+		require.NoError(t, err)
+
+		event := loginEvent(securityevent.EventTypeLoginSuccess)
+		assert.Equal(t, testUser.ID, event.UserID)
+		assertMetadata(event, "testuser")
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		_, err := authService.Login(ctx, &authv1.LoginRequest{Email: "test@example.com", Password: "WrongPassword123!"})
+		require.Error(t, err)
+
+		assertMetadata(loginEvent(securityevent.EventTypeLoginFailed), "test@example.com")
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := authService.Login(ctx, &authv1.LoginRequest{Email: "nobody@example.com", Password: "TestPass123!"})
+		require.Error(t, err)
+
+		assertMetadata(loginEvent(securityevent.EventTypeLoginFailed), "nobody@example.com")
+	})
+}
+
 func TestAuthService_AccountLockout(t *testing.T) {
 	// Setup
 	client := setupTestDB(t)
@@ -350,8 +514,8 @@ func TestAuthService_AccountLockout(t *testing.T) {
 	mockEmailService := email.NewMockEmailService()
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
-	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger)
-	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger)
+	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
 
 	// Create auth service with max 3 login attempts
 	securityConfig := createTestSecurityConfig()
@@ -417,6 +581,103 @@ func TestAuthService_AccountLockout(t *testing.T) {
 	assert.Contains(t, st.Message(), "account is locked")
 }
 
+func TestAuthService_LockoutExpiry(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	securityConfig := createTestSecurityConfig()
+	securityConfig.MaxLoginAttempts = 3
+	authService := newTestAuthService(client, securityConfig)
+
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyIPAddress, "127.0.0.1")
+	wrongPassword := &authv1.LoginRequest{Email: testUser.Email, Password: "WrongPassword123!"}
+
+	for i := 0; i < 3; i++ {
+		_, err := authService.Login(ctx, wrongPassword)
+		require.Error(t, err)
+	}
+	NewTestHelpers(t, client).AssertUserLocked(testUser.ID)
+
+	// Let the lock run out
+	require.NoError(t, client.User.UpdateOneID(testUser.ID).
+		SetAccountLockedUntil(time.Now().Add(-time.Second)).
+		Exec(ctx))
+
+	// One more mistake counts as the first of a new series
+	_, err := authService.Login(ctx, wrongPassword)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	updatedUser, err := client.User.Get(ctx, testUser.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, updatedUser.FailedLoginAttempts)
+	assert.Nil(t, updatedUser.AccountLockedUntil)
+
+	// The correct password works
+	_, err = authService.Login(ctx, &authv1.LoginRequest{Email: testUser.Email, Password: "TestPass123!"})
+	require.NoError(t, err)
+}
+
+func TestAuthService_AccountLockedEmail(t *testing.T) {
+	tests := []struct {
+		name                 string
+		notificationsEnabled bool
+		wantEmail            bool
+	}{
+		{name: "notifications enabled", notificationsEnabled: true, wantEmail: true},
+		{name: "notifications disabled", notificationsEnabled: false, wantEmail: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			client := setupTestDB(t)
+			defer client.Close()
+
+			testUser := createTestUser(t, client)
+			testUser = testUser.Update().
+				SetSecurityNotificationsEnabled(tt.notificationsEnabled).
+				SaveX(context.Background())
+
+			authService := newTestAuthService(client, createTestSecurityConfig())
+			mockEmailService := email.NewMockEmailService()
+			authService.SetEmailService(mockEmailService)
+
+			ctx := context.WithValue(context.Background(), middleware.ContextKeyIPAddress, "127.0.0.1")
+			req := &authv1.LoginRequest{Email: testUser.Email, Password: "WrongPassword123!"}
+
+			// Failed attempts below the limit never send the email
+			for i := 1; i < 3; i++ {
+				_, err := authService.Login(ctx, req)
+				require.Error(t, err)
+				assert.Empty(t, mockEmailService.GetSentEmails())
+			}
+
+			// The attempt that triggers the lockout does
+			_, err := authService.Login(ctx, req)
+			require.Error(t, err)
+			assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+			if !tt.wantEmail {
+				assert.Empty(t, mockEmailService.GetSentEmails())
+				return
+			}
+
+			sent := mockEmailService.GetSentEmails()
+			require.Len(t, sent, 1)
+			assert.Equal(t, "account_locked", sent[0].Template)
+			assert.Equal(t, testUser.Email, sent[0].To)
+			assert.True(t, sent[0].Data.ExpiresAt.After(time.Now()))
+
+			// Attempts while already locked don't send it again
+			_, err = authService.Login(ctx, req)
+			require.Error(t, err)
+			assert.Len(t, mockEmailService.GetSentEmails(), 1)
+		})
+	}
+}
+
 func TestAuthService_RefreshToken(t *testing.T) {
 	// Setup
 	client := setupTestDB(t)
@@ -440,19 +701,26 @@ func TestAuthService_RefreshToken(t *testing.T) {
 	)
 	require.NoError(t, err)
 
-	// Save refresh token to user
-	testUser, err = testUser.Update().
-		SetRefreshToken(refreshToken).
-		SetRefreshTokenExpiresAt(time.Now().Add(7 * 24 * time.Hour)).
-		SetLastLogin(time.Now()).
-		Save(context.Background())
+	_, expiredRefreshToken, _, err := tokenManager.GenerateTokenPair(
+		testUser.ID.String(),
+		testUser.Email,
+		testUser.Username,
+		string(testUser.Role),
+	)
+	require.NoError(t, err)
+
+	// Start a session for each refresh token, one of them already expired
+	sessions := auth.NewSessionStore(client)
+	_, err = sessions.Create(context.Background(), uuid.New(), testUser.ID, refreshToken, "127.0.0.1", "test-agent", time.Now().Add(7*24*time.Hour))
+	require.NoError(t, err)
+	_, err = sessions.Create(context.Background(), uuid.New(), testUser.ID, expiredRefreshToken, "127.0.0.1", "test-agent", time.Now().Add(-1*time.Hour))
 	require.NoError(t, err)
 
 	mockEmailService := email.NewMockEmailService()
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
-	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger)
-	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger)
+	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
 
 	authService := NewAuthService(
 		client,
@@ -466,7 +734,6 @@ func TestAuthService_RefreshToken(t *testing.T) {
 	tests := []struct {
 		name         string
 		refreshToken string
-		setupFunc    func()
 		wantErr      bool
 		expectedCode codes.Code
 	}{
@@ -475,6 +742,12 @@ func TestAuthService_RefreshToken(t *testing.T) {
 			refreshToken: refreshToken,
 			wantErr:      false,
 		},
+		{
+			name:         "rotated refresh token",
+			refreshToken: refreshToken,
+			wantErr:      true,
+			expectedCode: codes.Unauthenticated,
+		},
 		{
 			name:         "invalid refresh token",
 			refreshToken: "invalid-token",
@@ -489,13 +762,7 @@ func TestAuthService_RefreshToken(t *testing.T) {
 		},
 		{
 			name:         "expired refresh token",
-			refreshToken: refreshToken,
-			setupFunc: func() {
-				// Set refresh token as expired
-				testUser.Update().
-					SetRefreshTokenExpiresAt(time.Now().Add(-1 * time.Hour)).
-					Save(context.Background())
-			},
+			refreshToken: expiredRefreshToken,
 			wantErr:      true,
 			expectedCode: codes.Unauthenticated,
 		},
@@ -503,10 +770,6 @@ func TestAuthService_RefreshToken(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.setupFunc != nil {
-				tt.setupFunc()
-			}
-
 			req := &authv1.RefreshTokenRequest{
 				RefreshToken: tt.refreshToken,
 			}
@@ -529,6 +792,153 @@ func TestAuthService_RefreshToken(t *testing.T) {
 	}
 }
 
+func TestAuthService_ConfiguredTokenDurations(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	tokenManager := auth.NewTokenManager("test-access-secret", "test-refresh-secret", 5*time.Minute, 48*time.Hour)
+	mockEmailService := email.NewMockEmailService()
+	securityLogger := NewSecurityLogger(NewSecurityService(client))
+	authService := NewAuthService(
+		client,
+		tokenManager,
+		NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{}),
+		NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{}),
+		securityLogger,
+		createTestSecurityConfig(),
+	)
+
+	// assertSessionExpiry checks the session holding refreshToken expires
+	// after the configured refresh duration
+	assertSessionExpiry := func(t *testing.T, refreshToken string) {
+		sess, err := auth.NewSessionStore(client).Find(context.Background(), refreshToken)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(48*time.Hour), sess.ExpiresAt, time.Minute)
+	}
+
+	registerResp, err := authService.Register(context.Background(), &authv1.RegisterRequest{
+		Email:    "durations@example.com",
+		Username: "durations",
+		Password: "Lifetime!Pass2024x",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), registerResp.ExpiresIn)
+	assertSessionExpiry(t, registerResp.RefreshToken)
+
+	loginResp, err := authService.Login(context.Background(), &authv1.LoginRequest{
+		Email:    "durations@example.com",
+		Password: "Lifetime!Pass2024x",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), loginResp.ExpiresIn)
+	assertSessionExpiry(t, loginResp.RefreshToken)
+
+	refreshResp, err := authService.RefreshToken(context.Background(), &authv1.RefreshTokenRequest{
+		RefreshToken: loginResp.RefreshToken,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), refreshResp.ExpiresIn)
+	assertSessionExpiry(t, refreshResp.RefreshToken)
+}
+
+func TestAuthService_LoginRememberMe(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+
+	tokenManager := auth.NewTokenManager("test-access-secret", "test-refresh-secret", 15*time.Minute, 24*time.Hour)
+	tokenManager.SetRememberMeDuration(30 * 24 * time.Hour)
+	mockEmailService := email.NewMockEmailService()
+	securityLogger := NewSecurityLogger(NewSecurityService(client))
+	authService := NewAuthService(
+		client,
+		tokenManager,
+		NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{}),
+		NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{}),
+		securityLogger,
+		createTestSecurityConfig(),
+	)
+
+	sessionExpiry := func(t *testing.T, refreshToken string) time.Time {
+		sess, err := auth.NewSessionStore(client).Find(context.Background(), refreshToken)
+		require.NoError(t, err)
+		return sess.ExpiresAt
+	}
+
+	login := func(t *testing.T, rememberMe bool) *authv1.LoginResponse {
+		resp, err := authService.Login(context.Background(), &authv1.LoginRequest{
+			Email:      testUser.Email,
+			Password:   "TestPass123!",
+			RememberMe: rememberMe,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	normal := login(t, false)
+	remembered := login(t, true)
+
+	normalExpiry := sessionExpiry(t, normal.RefreshToken)
+	rememberedExpiry := sessionExpiry(t, remembered.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), normalExpiry, time.Minute)
+	assert.WithinDuration(t, time.Now().Add(30*24*time.Hour), rememberedExpiry, time.Minute)
+	assert.True(t, rememberedExpiry.After(normalExpiry))
+
+	t.Run("refresh keeps the lifetime", func(t *testing.T) {
+		resp, err := authService.RefreshToken(context.Background(), &authv1.RefreshTokenRequest{
+			RefreshToken: remembered.RefreshToken,
+		})
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(30*24*time.Hour), sessionExpiry(t, resp.RefreshToken), time.Minute)
+	})
+}
+
+func TestAuthService_RefreshToken_IdleTimeout(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	loginResp, err := authService.Login(context.Background(), &authv1.LoginRequest{
+		Email:    testUser.Email,
+		Password: "TestPass123!",
+	})
+	require.NoError(t, err)
+
+	// Idle for longer than the 30 day session timeout
+	sessions := auth.NewSessionStore(client)
+	idleSession, err := sessions.Find(context.Background(), loginResp.RefreshToken)
+	require.NoError(t, err)
+	client.Session.UpdateOneID(idleSession.ID).
+		SetLastActivityAt(time.Now().Add(-31 * 24 * time.Hour)).
+		ExecX(context.Background())
+
+	// Being active on another device doesn't keep the idle one alive
+	otherResp, err := authService.Login(context.Background(), &authv1.LoginRequest{
+		Email:    testUser.Email,
+		Password: "TestPass123!",
+	})
+	require.NoError(t, err)
+	_, err = authService.RefreshToken(context.Background(), &authv1.RefreshTokenRequest{
+		RefreshToken: otherResp.RefreshToken,
+	})
+	require.NoError(t, err)
+
+	_, err = authService.RefreshToken(context.Background(), &authv1.RefreshTokenRequest{
+		RefreshToken: loginResp.RefreshToken,
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// The session is ended, so signing in again is the only way back
+	_, err = sessions.Find(context.Background(), loginResp.RefreshToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidSession)
+}
+
 func TestAuthService_GetMe(t *testing.T) {
 	// Setup
 	client := setupTestDB(t)
@@ -546,8 +956,8 @@ func TestAuthService_GetMe(t *testing.T) {
 	mockEmailService := email.NewMockEmailService()
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
-	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger)
-	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger)
+	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
 
 	authService := NewAuthService(
 		client,
@@ -614,56 +1024,666 @@ func TestAuthService_GetMe(t *testing.T) {
 	}
 }
 
-func TestAuthService_ChangePassword(t *testing.T) {
+func TestAuthService_GetMe_PasswordResetStatus(t *testing.T) {
 	// Setup
 	client := setupTestDB(t)
 	defer client.Close()
 
 	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyUserID, testUser.ID.String())
 
-	tokenManager := auth.NewTokenManager(
-		"test-access-secret",
-		"test-refresh-secret",
-		15*time.Minute,
-		7*24*time.Hour,
-	)
+	// No reset requested yet
+	resp, err := authService.GetMe(ctx, nil)
+	require.NoError(t, err)
+	require.NotNil(t, resp.PasswordResetStatus)
+	assert.False(t, resp.PasswordResetStatus.HasActiveRequest)
+	assert.True(t, resp.PasswordResetStatus.CanRequest)
+	assert.Equal(t, int32(0), resp.PasswordResetStatus.Attempts)
+	assert.Nil(t, resp.PasswordResetStatus.ExpiresAt)
 
-	mockEmailService := email.NewMockEmailService()
-	securityService := NewSecurityService(client)
-	securityLogger := NewSecurityLogger(securityService)
-	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger)
-	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger)
+	_, err = authService.RequestPasswordReset(context.Background(), &authv1.RequestPasswordResetRequest{Email: testUser.Email})
+	require.NoError(t, err)
 
-	authService := NewAuthService(
-		client,
-		tokenManager,
-		emailVerificationService,
-		passwordResetService,
-		securityLogger,
-		createTestSecurityConfig(),
+	// The active request is reported, and another one is rate limited
+	resp, err = authService.GetMe(ctx, nil)
+	require.NoError(t, err)
+	require.NotNil(t, resp.PasswordResetStatus)
+	assert.True(t, resp.PasswordResetStatus.HasActiveRequest)
+	assert.False(t, resp.PasswordResetStatus.IsExpired)
+	assert.False(t, resp.PasswordResetStatus.CanRequest)
+	assert.Equal(t, int32(1), resp.PasswordResetStatus.Attempts)
+	assert.Equal(t, int32(MaxPasswordResetAttempts), resp.PasswordResetStatus.MaxAttempts)
+	require.NotNil(t, resp.PasswordResetStatus.ExpiresAt)
+	assert.True(t, resp.PasswordResetStatus.ExpiresAt.AsTime().After(time.Now()))
+}
+
+func TestAuthService_Logout_RevokesAccessToken(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	authService.tokenManager.SetBlacklist(auth.NewTokenBlacklist(client))
+
+	accessToken, refreshToken, _, err := authService.tokenManager.GenerateTokenPair(
+		testUser.ID.String(), testUser.Email, testUser.Username, string(testUser.Role),
 	)
+	require.NoError(t, err)
+
+	claims, err := authService.tokenManager.ValidateAccessToken(context.Background(), accessToken)
+	require.NoError(t, err)
 
+	// Simulate the context populated by the auth interceptor
 	ctx := context.Background()
-	ctx = context.WithValue(ctx, middleware.ContextKeyUserID, testUser.ID.String())
+	ctx = context.WithValue(ctx, middleware.ContextKeyUserID, claims.UserID)
+	ctx = context.WithValue(ctx, middleware.ContextKeyTokenID, claims.ID)
+	ctx = context.WithValue(ctx, middleware.ContextKeyTokenExp, claims.ExpiresAt.Time)
 
-	tests := []struct {
-		name         string
-		request      *authv1.ChangePasswordRequest
-		wantErr      bool
-		expectedCode codes.Code
-	}{
-		{
-			name: "successful password change",
-			request: &authv1.ChangePasswordRequest{
-				CurrentPassword: "TestPass123!",
-				NewPassword:     "NewSecurePass456!",
-			},
-			wantErr: false,
-		},
-		{
-			name: "incorrect current password",
-			request: &authv1.ChangePasswordRequest{
-				CurrentPassword: "WrongPassword123!",
+	_, err = authService.Logout(ctx, &authv1.LogoutRequest{RefreshToken: refreshToken})
+	require.NoError(t, err)
+
+	_, err = authService.tokenManager.ValidateAccessToken(ctx, accessToken)
+	assert.ErrorIs(t, err, auth.ErrRevokedToken)
+}
+
+func TestAuthService_IntrospectToken(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	authService.tokenManager.SetBlacklist(auth.NewTokenBlacklist(client))
+
+	login := func() *authv1.LoginResponse {
+		resp, err := authService.Login(ctx, &authv1.LoginRequest{Email: testUser.Email, Password: "TestPass123!"})
+		require.NoError(t, err)
+		return resp
+	}
+	loginResp := login()
+	accessToken, refreshToken := loginResp.AccessToken, loginResp.RefreshToken
+	claims, err := authService.tokenManager.ValidateAccessToken(ctx, accessToken)
+	require.NoError(t, err)
+	sessionID := uuid.MustParse(claims.SessionID)
+
+	// Same secrets, but tokens are already expired when issued
+	expiredManager := auth.NewTokenManager("test-access-secret", "test-refresh-secret", -time.Minute, time.Hour)
+	expiredToken, _, _, err := expiredManager.GenerateTokenPair(
+		testUser.ID.String(), testUser.Email, testUser.Username, string(testUser.Role),
+	)
+	require.NoError(t, err)
+
+	revokedToken := login().AccessToken
+	revokedClaims, err := authService.tokenManager.ValidateAccessToken(ctx, revokedToken)
+	require.NoError(t, err)
+	require.NoError(t, authService.tokenManager.RevokeToken(ctx, revokedClaims.ID, revokedClaims.ExpiresAt.Time))
+
+	t.Run("valid token", func(t *testing.T) {
+		resp, err := authService.IntrospectToken(ctx, &authv1.IntrospectTokenRequest{Token: accessToken})
+		require.NoError(t, err)
+		assert.True(t, resp.Active)
+		assert.Equal(t, testUser.ID.String(), resp.UserId)
+		assert.Equal(t, testUser.Username, resp.Username)
+		assert.Equal(t, authv1.UserRole_USER_ROLE_USER, resp.Role)
+		require.NotNil(t, resp.ExpiresAt)
+		assert.True(t, resp.ExpiresAt.AsTime().After(time.Now()))
+	})
+
+	inactive := []struct {
+		name  string
+		token string
+	}{
+		{name: "expired token", token: expiredToken},
+		{name: "malformed token", token: "not-a-jwt"},
+		{name: "refresh token", token: refreshToken},
+		{name: "revoked token", token: revokedToken},
+	}
+
+	for _, tt := range inactive {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := authService.IntrospectToken(ctx, &authv1.IntrospectTokenRequest{Token: tt.token})
+			require.NoError(t, err)
+			assert.False(t, resp.Active)
+			assert.Empty(t, resp.UserId)
+			assert.Nil(t, resp.ExpiresAt)
+		})
+	}
+
+	t.Run("user no longer allowed", func(t *testing.T) {
+		sessionlessToken, _, _, err := authService.tokenManager.GenerateTokenPair(
+			testUser.ID.String(), testUser.Email, testUser.Username, string(testUser.Role),
+		)
+		require.NoError(t, err)
+		endedToken, _, _, err := authService.tokenManager.GenerateTokenPairFor(
+			uuid.New().String(), testUser.ID.String(), testUser.Email, testUser.Username, string(testUser.Role), false,
+		)
+		require.NoError(t, err)
+
+		tests := []struct {
+			name   string
+			token  string
+			change func()
+			undo   func()
+		}{
+			{name: "token without session", token: sessionlessToken, change: func() {}, undo: func() {}},
+			{name: "ended session", token: endedToken, change: func() {}, undo: func() {}},
+			{
+				name:   "deactivated user",
+				token:  accessToken,
+				change: func() { client.User.UpdateOneID(testUser.ID).SetIsActive(false).ExecX(ctx) },
+				undo:   func() { client.User.UpdateOneID(testUser.ID).SetIsActive(true).ExecX(ctx) },
+			},
+			{
+				name:  "idle session",
+				token: accessToken,
+				change: func() {
+					client.Session.UpdateOneID(sessionID).SetLastActivityAt(time.Now().Add(-31 * 24 * time.Hour)).ExecX(ctx)
+				},
+				undo: func() { client.Session.UpdateOneID(sessionID).SetLastActivityAt(time.Now()).ExecX(ctx) },
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.change()
+				defer tt.undo()
+
+				resp, err := authService.IntrospectToken(ctx, &authv1.IntrospectTokenRequest{Token: tt.token})
+				require.NoError(t, err)
+				assert.False(t, resp.Active)
+				assert.Empty(t, resp.UserId)
+			})
+		}
+	})
+
+	t.Run("role changed since issue", func(t *testing.T) {
+		client.User.UpdateOneID(testUser.ID).SetRole(user.RoleManager).ExecX(ctx)
+		defer client.User.UpdateOneID(testUser.ID).SetRole(user.RoleUser).ExecX(ctx)
+
+		resp, err := authService.IntrospectToken(ctx, &authv1.IntrospectTokenRequest{Token: accessToken})
+		require.NoError(t, err)
+		assert.True(t, resp.Active)
+		assert.Equal(t, authv1.UserRole_USER_ROLE_MANAGER, resp.Role)
+	})
+}
+
+func TestAuthService_TOTPLogin(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	userCtx := context.WithValue(context.Background(), middleware.ContextKeyUserID, testUser.ID.String())
+
+	// Enable and confirm TOTP
+	enableResp, err := authService.EnableTOTP(userCtx, &authv1.EnableTOTPRequest{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, enableResp.Secret)
+	assert.Contains(t, enableResp.ProvisioningUri, "otpauth://totp/")
+
+	_, err = authService.ConfirmTOTP(userCtx, &authv1.ConfirmTOTPRequest{Code: "000000"})
+	require.Error(t, err)
+
+	code, err := authService.totpManager.GenerateCode(enableResp.Secret, time.Now())
+	require.NoError(t, err)
+
+	confirmResp, err := authService.ConfirmTOTP(userCtx, &authv1.ConfirmTOTPRequest{Code: code})
+	require.NoError(t, err)
+	require.Len(t, confirmResp.BackupCodes, totpBackupCodeCount)
+
+	// Password step no longer issues tokens
+	loginResp, err := authService.Login(context.Background(), &authv1.LoginRequest{
+		Email:    testUser.Email,
+		Password: "TestPass123!",
+	})
+	require.NoError(t, err)
+	assert.True(t, loginResp.MfaRequired)
+	assert.NotEmpty(t, loginResp.MfaToken)
+	assert.Empty(t, loginResp.AccessToken)
+
+	// MFA token cannot be used as an access token
+	_, err = authService.tokenManager.ValidateAccessToken(context.Background(), loginResp.MfaToken)
+	assert.Error(t, err)
+
+	// Wrong code is rejected
+	_, err = authService.VerifyTOTPLogin(context.Background(), &authv1.VerifyTOTPLoginRequest{
+		MfaToken: loginResp.MfaToken,
+		Code:     "not-a-code",
+	})
+	require.Error(t, err)
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.Unauthenticated, st.Code())
+
+	// TOTP code completes login
+	verifyResp, err := authService.VerifyTOTPLogin(context.Background(), &authv1.VerifyTOTPLoginRequest{
+		MfaToken: loginResp.MfaToken,
+		Code:     code,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, verifyResp.AccessToken)
+	assert.NotEmpty(t, verifyResp.RefreshToken)
+
+	// Backup code works once
+	loginResp, err = authService.Login(context.Background(), &authv1.LoginRequest{
+		Email:    testUser.Email,
+		Password: "TestPass123!",
+	})
+	require.NoError(t, err)
+
+	_, err = authService.VerifyTOTPLogin(context.Background(), &authv1.VerifyTOTPLoginRequest{
+		MfaToken: loginResp.MfaToken,
+		Code:     confirmResp.BackupCodes[0],
+	})
+	require.NoError(t, err)
+
+	updatedUser, err := client.User.Get(context.Background(), testUser.ID)
+	require.NoError(t, err)
+	assert.Len(t, updatedUser.TotpBackupCodes, totpBackupCodeCount-1)
+
+	_, err = authService.VerifyTOTPLogin(context.Background(), &authv1.VerifyTOTPLoginRequest{
+		MfaToken: loginResp.MfaToken,
+		Code:     confirmResp.BackupCodes[0],
+	})
+	assert.Error(t, err)
+
+	// Disable requires password and a valid code
+	_, err = authService.DisableTOTP(userCtx, &authv1.DisableTOTPRequest{
+		Password: "WrongPass123!",
+		Code:     code,
+	})
+	require.Error(t, err)
+
+	_, err = authService.DisableTOTP(userCtx, &authv1.DisableTOTPRequest{
+		Password: "TestPass123!",
+		Code:     confirmResp.BackupCodes[1],
+	})
+	require.NoError(t, err)
+
+	updatedUser, err = client.User.Get(context.Background(), testUser.ID)
+	require.NoError(t, err)
+	assert.False(t, updatedUser.TotpEnabled)
+	assert.Empty(t, updatedUser.TotpSecret)
+}
+
+// fakeIDTokenVerifier accepts the raw tokens in its map and rejects all others
+type fakeIDTokenVerifier map[string]*auth.OIDCClaims
+
+func (f fakeIDTokenVerifier) Verify(_ context.Context, rawIDToken string) (*auth.OIDCClaims, error) {
+	claims, ok := f[rawIDToken]
+	if !ok {
+		return nil, auth.ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func oidcClaims(subject, emailAddress string, verified bool) *auth.OIDCClaims {
+	claims := &auth.OIDCClaims{Email: emailAddress, EmailVerified: verified, GivenName: "Oidc", FamilyName: "User"}
+	claims.Issuer = "https://accounts.google.com"
+	claims.Subject = subject
+	return claims
+}
+
+func TestAuthService_LoginWithOIDC(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	// Disabled until a verifier is configured
+	_, err := authService.LoginWithOIDC(ctx, &authv1.LoginWithOIDCRequest{IdToken: "new-user"})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.Unimplemented, st.Code())
+
+	authService.SetOIDCVerifier(fakeIDTokenVerifier{
+		"new-user":       oidcClaims("sub-new", "Newcomer@Example.com", true),
+		"existing-user":  oidcClaims("sub-existing", testUser.Email, true),
+		"unverified":     oidcClaims("sub-unverified", "unverified@example.com", false),
+		"other-identity": oidcClaims("sub-other", testUser.Email, true),
+		"squatted":       oidcClaims("sub-squatted", "victim@example.com", true),
+		"locked":         oidcClaims("sub-locked", "locked@example.com", true),
+		"deactivated":    oidcClaims("sub-deactivated", "deactivated@example.com", true),
+	})
+
+	t.Run("provisions a new user", func(t *testing.T) {
+		resp, err := authService.LoginWithOIDC(ctx, &authv1.LoginWithOIDCRequest{IdToken: "new-user"})
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.AccessToken)
+		assert.Equal(t, "newcomer@example.com", resp.User.Email)
+
+		created, err := client.User.Query().Where(user.EmailEQ("newcomer@example.com")).Only(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "newcomer", created.Username)
+		assert.True(t, created.EmailVerified)
+		assert.Empty(t, created.PasswordHash)
+		require.NotNil(t, created.OidcSubject)
+		assert.Equal(t, "sub-new", *created.OidcSubject)
+
+		// Signing in again reuses the account
+		_, err = authService.LoginWithOIDC(ctx, &authv1.LoginWithOIDCRequest{IdToken: "new-user"})
+		require.NoError(t, err)
+		count, err := client.User.Query().Where(user.EmailEQ("newcomer@example.com")).Count(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		// Passwordless accounts can't sign in with a password
+		_, err = authService.Login(ctx, &authv1.LoginRequest{Email: "newcomer@example.com", Password: "TestPass123!"})
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.Unauthenticated, st.Code())
+	})
+
+	t.Run("links an existing user by email", func(t *testing.T) {
+		client.User.UpdateOneID(testUser.ID).SetEmailVerified(true).ExecX(ctx)
+
+		resp, err := authService.LoginWithOIDC(ctx, &authv1.LoginWithOIDCRequest{IdToken: "existing-user"})
+		require.NoError(t, err)
+		assert.Equal(t, testUser.ID.String(), resp.User.Id)
+
+		linked, err := client.User.Get(ctx, testUser.ID)
+		require.NoError(t, err)
+		require.NotNil(t, linked.OidcSubject)
+		assert.Equal(t, "sub-existing", *linked.OidcSubject)
+		assert.True(t, linked.EmailVerified)
+		assert.Equal(t, testUser.PasswordHash, linked.PasswordHash)
+
+		// A second identity with the same email can't take over the account
+		_, err = authService.LoginWithOIDC(ctx, &authv1.LoginWithOIDCRequest{IdToken: "other-identity"})
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.PermissionDenied, st.Code())
+	})
+
+	t.Run("does not link accounts with an unverified email", func(t *testing.T) {
+		// Someone registered the address first, without being able to verify it
+		helpers := NewTestHelpers(t, client)
+		squatter := helpers.CreateTestUser("victim@example.com", "squatter", "TestPass123!")
+
+		_, err := authService.LoginWithOIDC(ctx, &authv1.LoginWithOIDCRequest{IdToken: "squatted"})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		unlinked, err := client.User.Get(ctx, squatter.ID)
+		require.NoError(t, err)
+		assert.Nil(t, unlinked.OidcSubject)
+		assert.False(t, unlinked.EmailVerified)
+	})
+
+	t.Run("does not link locked or deactivated accounts", func(t *testing.T) {
+		helpers := NewTestHelpers(t, client)
+		locked := helpers.CreateTestUser("locked@example.com", "lockeduser", "TestPass123!")
+		client.User.UpdateOneID(locked.ID).SetEmailVerified(true).SetAccountLockedUntil(time.Now().Add(time.Hour)).ExecX(ctx)
+		deactivated := helpers.CreateTestUser("deactivated@example.com", "deactivateduser", "TestPass123!")
+		client.User.UpdateOneID(deactivated.ID).SetEmailVerified(true).SetIsActive(false).ExecX(ctx)
+
+		_, err := authService.LoginWithOIDC(ctx, &authv1.LoginWithOIDCRequest{IdToken: "locked"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		_, err = authService.LoginWithOIDC(ctx, &authv1.LoginWithOIDCRequest{IdToken: "deactivated"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		for _, id := range []uuid.UUID{locked.ID, deactivated.ID} {
+			u, err := client.User.Get(ctx, id)
+			require.NoError(t, err)
+			assert.Nil(t, u.OidcSubject)
+		}
+	})
+
+	t.Run("rejects unverified email and invalid tokens", func(t *testing.T) {
+		_, err := authService.LoginWithOIDC(ctx, &authv1.LoginWithOIDCRequest{IdToken: "unverified"})
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.PermissionDenied, st.Code())
+
+		_, err = authService.LoginWithOIDC(ctx, &authv1.LoginWithOIDCRequest{IdToken: "forged"})
+		st, _ = status.FromError(err)
+		assert.Equal(t, codes.Unauthenticated, st.Code())
+
+		exists, err := client.User.Query().Where(user.EmailEQ("unverified@example.com")).Exist(ctx)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestAuthService_APIKeys(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	userCtx := context.WithValue(context.Background(), middleware.ContextKeyUserID, testUser.ID.String())
+
+	interceptor := middleware.NewUpdatedAuthInterceptor(authService.tokenManager)
+	interceptor.SetAPIKeyStore(auth.NewAPIKeyStore(client), 3)
+
+	// callWithKey runs a request for method through the auth interceptor
+	callWithKey := func(method, rawKey string) (string, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(middleware.APIKeyHeader, rawKey))
+		var callerID string
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			callerID, _ = middleware.GetUserIDFromContext(ctx)
+			return nil, nil
+		}
+		_, err := interceptor.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return callerID, err
+	}
+
+	_, err := authService.CreateAPIKey(userCtx, &authv1.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"tasks:admin"}})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	created, err := authService.CreateAPIKey(userCtx, &authv1.CreateAPIKeyRequest{Name: "ci", Scopes: []string{auth.ScopeTasksRead}})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Key, created.ApiKey.Prefix))
+
+	t.Run("authenticates as the owner", func(t *testing.T) {
+		callerID, err := callWithKey("/task.v1.TaskService/ListTasks", created.Key)
+		require.NoError(t, err)
+		assert.Equal(t, testUser.ID.String(), callerID)
+
+		listResp, err := authService.ListAPIKeys(userCtx, &authv1.ListAPIKeysRequest{})
+		require.NoError(t, err)
+		require.Len(t, listResp.ApiKeys, 1)
+		assert.NotNil(t, listResp.ApiKeys[0].LastUsedAt)
+	})
+
+	t.Run("enforces scopes", func(t *testing.T) {
+		_, err := callWithKey("/task.v1.TaskService/CreateTask", created.Key)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		_, err = callWithKey("/auth.v1.AuthService/CreateAPIKey", created.Key)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("rate limits each key", func(t *testing.T) {
+		_, err := callWithKey("/task.v1.TaskService/GetTask", created.Key)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("rejects unknown and revoked keys", func(t *testing.T) {
+		_, err := callWithKey("/task.v1.TaskService/ListTasks", "tm_not-a-real-key")
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		other, err := authService.CreateAPIKey(userCtx, &authv1.CreateAPIKeyRequest{Name: "deploy", Scopes: []string{auth.ScopeTasksWrite}})
+		require.NoError(t, err)
+
+		// Another user's key is not found
+		otherCtx := context.WithValue(context.Background(), middleware.ContextKeyUserID, uuid.New().String())
+		_, err = authService.RevokeAPIKey(otherCtx, &authv1.RevokeAPIKeyRequest{Id: other.ApiKey.Id})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = authService.RevokeAPIKey(userCtx, &authv1.RevokeAPIKeyRequest{Id: other.ApiKey.Id})
+		require.NoError(t, err)
+
+		_, err = callWithKey("/task.v1.TaskService/CreateTask", other.Key)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestAuthService_Sessions(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	// Sign in from two devices
+	loginFrom := func(ipAddress, userAgent string) *authv1.LoginResponse {
+		ctx := context.WithValue(context.Background(), middleware.ContextKeyIPAddress, ipAddress)
+		ctx = context.WithValue(ctx, middleware.ContextKeyUserAgent, userAgent)
+		resp, err := authService.Login(ctx, &authv1.LoginRequest{Email: "test@example.com", Password: "TestPass123!"})
+		require.NoError(t, err)
+		return resp
+	}
+	laptop := loginFrom("203.0.113.10", "Firefox on Linux")
+	phone := loginFrom("198.51.100.7", "TaskMaster iOS")
+
+	userCtx := context.WithValue(context.Background(), middleware.ContextKeyUserID, testUser.ID.String())
+	listed, err := authService.ListSessions(userCtx, &authv1.ListSessionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, listed.Sessions, 2)
+
+	devices := map[string]*authv1.Session{}
+	for _, sess := range listed.Sessions {
+		devices[sess.UserAgent] = sess
+		assert.NotNil(t, sess.CreatedAt)
+		assert.NotNil(t, sess.ExpiresAt)
+	}
+	require.Contains(t, devices, "Firefox on Linux")
+	require.Contains(t, devices, "TaskMaster iOS")
+	assert.Equal(t, "198.51.100.7", devices["TaskMaster iOS"].IpAddress)
+
+	// Another user cannot revoke the session
+	otherCtx := context.WithValue(context.Background(), middleware.ContextKeyUserID, uuid.New().String())
+	_, err = authService.RevokeSession(otherCtx, &authv1.RevokeSessionRequest{SessionId: devices["TaskMaster iOS"].Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Revoke the phone session
+	_, err = authService.RevokeSession(userCtx, &authv1.RevokeSessionRequest{SessionId: devices["TaskMaster iOS"].Id})
+	require.NoError(t, err)
+
+	_, err = authService.RefreshToken(context.Background(), &authv1.RefreshTokenRequest{RefreshToken: phone.RefreshToken})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// The laptop stays signed in
+	refreshed, err := authService.RefreshToken(context.Background(), &authv1.RefreshTokenRequest{RefreshToken: laptop.RefreshToken})
+	require.NoError(t, err)
+	assert.NotEmpty(t, refreshed.RefreshToken)
+
+	listed, err = authService.ListSessions(userCtx, &authv1.ListSessionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, listed.Sessions, 1)
+	assert.Equal(t, devices["Firefox on Linux"].Id, listed.Sessions[0].Id)
+
+	// Revoking it again reports it as gone
+	_, err = authService.RevokeSession(userCtx, &authv1.RevokeSessionRequest{SessionId: devices["TaskMaster iOS"].Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAuthService_Captcha(t *testing.T) {
+	tests := []struct {
+		name         string
+		verifier     auth.CaptchaVerifier
+		fieldToken   string
+		headerToken  string
+		expectedCode codes.Code
+	}{
+		{name: "disabled skips the check", verifier: nil, expectedCode: codes.OK},
+		{name: "valid token in request", verifier: &auth.MockCaptchaVerifier{ValidToken: "solved"}, fieldToken: "solved", expectedCode: codes.OK},
+		{name: "valid token in header", verifier: &auth.MockCaptchaVerifier{ValidToken: "solved"}, headerToken: "solved", expectedCode: codes.OK},
+		{name: "missing token", verifier: &auth.MockCaptchaVerifier{ValidToken: "solved"}, expectedCode: codes.InvalidArgument},
+		{name: "invalid token", verifier: &auth.MockCaptchaVerifier{ValidToken: "solved"}, fieldToken: "forged", expectedCode: codes.PermissionDenied},
+		{name: "provider unavailable", verifier: &auth.MockCaptchaVerifier{Err: fmt.Errorf("connection refused")}, fieldToken: "solved", expectedCode: codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			client := setupTestDB(t)
+			defer client.Close()
+
+			createTestUser(t, client)
+			authService := newTestAuthService(client, createTestSecurityConfig())
+			if tt.verifier != nil {
+				authService.SetCaptchaVerifier(tt.verifier)
+			}
+
+			ctx := context.Background()
+			if tt.headerToken != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(middleware.CaptchaTokenHeader, tt.headerToken))
+			}
+
+			_, err := authService.Register(ctx, &authv1.RegisterRequest{
+				Email:        "captcha@example.com",
+				Username:     "captchauser",
+				Password:     "TestPass123!",
+				CaptchaToken: tt.fieldToken,
+			})
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+
+			_, err = authService.RequestPasswordReset(ctx, &authv1.RequestPasswordResetRequest{
+				Email:        "test@example.com",
+				CaptchaToken: tt.fieldToken,
+			})
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+
+	tokenManager := auth.NewTokenManager(
+		"test-access-secret",
+		"test-refresh-secret",
+		15*time.Minute,
+		7*24*time.Hour,
+	)
+
+	mockEmailService := email.NewMockEmailService()
+	securityService := NewSecurityService(client)
+	securityLogger := NewSecurityLogger(securityService)
+	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
+
+	authService := NewAuthService(
+		client,
+		tokenManager,
+		emailVerificationService,
+		passwordResetService,
+		securityLogger,
+		createTestSecurityConfig(),
+	)
+
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, middleware.ContextKeyUserID, testUser.ID.String())
+
+	tests := []struct {
+		name         string
+		request      *authv1.ChangePasswordRequest
+		wantErr      bool
+		expectedCode codes.Code
+	}{
+		{
+			name: "successful password change",
+			request: &authv1.ChangePasswordRequest{
+				CurrentPassword: "TestPass123!",
+				NewPassword:     "NewSecurePass456!",
+			},
+			wantErr: false,
+		},
+		{
+			name: "incorrect current password",
+			request: &authv1.ChangePasswordRequest{
+				CurrentPassword: "WrongPassword123!",
 				NewPassword:     "NewSecurePass456!",
 			},
 			wantErr:      true,
@@ -710,41 +1730,162 @@ func TestAuthService_ChangePassword(t *testing.T) {
 				err = passwordManager.ComparePassword(updatedUser.PasswordHash, tt.request.NewPassword)
 				assert.NoError(t, err)
 
-				// Verify refresh token was cleared
-				assert.Empty(t, updatedUser.RefreshToken)
+				// Verify all sessions were ended
+				sessionCount, err := client.Session.Query().Where(session.UserIDEQ(testUser.ID)).Count(ctx)
+				require.NoError(t, err)
+				assert.Zero(t, sessionCount)
 			}
 		})
 	}
 }
 
-func TestAuthService_UpdateProfile(t *testing.T) {
+func TestAuthService_PasswordStrength(t *testing.T) {
 	// Setup
 	client := setupTestDB(t)
 	defer client.Close()
 
 	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyUserID, testUser.ID.String())
 
-	tokenManager := auth.NewTokenManager(
-		"test-access-secret",
-		"test-refresh-secret",
-		15*time.Minute,
-		7*24*time.Hour,
-	)
-
-	mockEmailService := email.NewMockEmailService()
-	securityService := NewSecurityService(client)
-	securityLogger := NewSecurityLogger(securityService)
-	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger)
-	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger)
-
-	authService := NewAuthService(
-		client,
-		tokenManager,
-		emailVerificationService,
-		passwordResetService,
-		securityLogger,
-		createTestSecurityConfig(),
-	)
+	tests := []struct {
+		name     string
+		call     func() error
+		wantCode codes.Code
+	}{
+		{
+			name: "register with common password",
+			call: func() error {
+				_, err := authService.Register(context.Background(), &authv1.RegisterRequest{
+					Email: "common@example.com", Username: "commonuser", Password: "Password1",
+				})
+				return err
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "register with password containing username",
+			call: func() error {
+				_, err := authService.Register(context.Background(), &authv1.RegisterRequest{
+					Email: "ada@example.com", Username: "lovelace", Password: "Lovelace#1815x",
+				})
+				return err
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "register with strong password",
+			call: func() error {
+				_, err := authService.Register(context.Background(), &authv1.RegisterRequest{
+					Email: "strong@example.com", Username: "stronguser", Password: "Zq8#vLm2pR",
+				})
+				return err
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "change to password containing username",
+			call: func() error {
+				_, err := authService.ChangePassword(ctx, &authv1.ChangePasswordRequest{
+					CurrentPassword: "TestPass123!", NewPassword: "MyTestuser#42x",
+				})
+				return err
+			},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "change to sequence password",
+			call: func() error {
+				_, err := authService.ChangePassword(ctx, &authv1.ChangePasswordRequest{
+					CurrentPassword: "TestPass123!", NewPassword: "Abcdefgh1",
+				})
+				return err
+			},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantCode, status.Code(tt.call()))
+		})
+	}
+}
+
+func TestAuthService_ChangePassword_History(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+
+	securityConfig := createTestSecurityConfig()
+	securityConfig.PasswordHistorySize = 3
+	authService := newTestAuthService(client, securityConfig)
+
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyUserID, testUser.ID.String())
+
+	current := "TestPass123!"
+	changeTo := func(newPassword string) error {
+		_, err := authService.ChangePassword(ctx, &authv1.ChangePasswordRequest{
+			CurrentPassword: current,
+			NewPassword:     newPassword,
+		})
+		if err == nil {
+			current = newPassword
+		}
+		return err
+	}
+
+	// TestPass123! -> Kilo#4821 -> Lima#4821 -> Mike#4821 -> Oscar#4821
+	for _, p := range []string{"Kilo#4821", "Lima#4821", "Mike#4821", "Oscar#4821"} {
+		require.NoError(t, changeTo(p))
+	}
+
+	// The current password and the last 3 are all blocked
+	for _, p := range []string{"Oscar#4821", "Mike#4821", "Lima#4821", "Kilo#4821"} {
+		err := changeTo(p)
+		require.Error(t, err, p)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+
+	updatedUser, err := client.User.Get(context.Background(), testUser.ID)
+	require.NoError(t, err)
+	assert.Len(t, updatedUser.PasswordHistory, 3)
+
+	// One more change ages the oldest password out of the history
+	require.NoError(t, changeTo("Papa#4821"))
+	assert.NoError(t, changeTo("Kilo#4821"))
+}
+
+func TestAuthService_UpdateProfile(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+
+	tokenManager := auth.NewTokenManager(
+		"test-access-secret",
+		"test-refresh-secret",
+		15*time.Minute,
+		7*24*time.Hour,
+	)
+
+	mockEmailService := email.NewMockEmailService()
+	securityService := NewSecurityService(client)
+	securityLogger := NewSecurityLogger(securityService)
+	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
+
+	authService := NewAuthService(
+		client,
+		tokenManager,
+		emailVerificationService,
+		passwordResetService,
+		securityLogger,
+		createTestSecurityConfig(),
+	)
 
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, middleware.ContextKeyUserID, testUser.ID.String())
@@ -827,8 +1968,8 @@ func TestAuthService_GetSecurityEvents(t *testing.T) {
 	mockEmailService := email.NewMockEmailService()
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
-	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger)
-	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger)
+	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
 
 	authService := NewAuthService(
 		client,
@@ -911,29 +2052,26 @@ func TestAuthService_GetSecurityEvents(t *testing.T) {
 	}
 }
 
-func TestAuthService_UnlockAccount(t *testing.T) {
+func TestAuthService_GetSecurityEvents_SeverityFilter(t *testing.T) {
 	// Setup
 	client := setupTestDB(t)
 	defer client.Close()
 
-	// Create locked user
-	lockedUser := createTestUser(t, client)
-	lockTime := time.Now().Add(1 * time.Hour)
-	lockedUser, err := lockedUser.Update().
-		SetFailedLoginAttempts(5).
-		SetAccountLockedUntil(lockTime).
-		Save(context.Background())
-	require.NoError(t, err)
+	testUser := createTestUser(t, client)
 
-	// Create admin user
-	adminUser, err := client.User.Create().
-		SetEmail("admin@example.com").
-		SetUsername("admin").
-		SetPasswordHash("hash").
-		SetRole(user.RoleAdmin).
-		SetIsActive(true).
-		Save(context.Background())
-	require.NoError(t, err)
+	// Create events for each severity: 1 low, 2 medium, 3 high, 4 critical
+	severities := []string{"low", "medium", "high", "critical"}
+	for i, severity := range severities {
+		for j := 0; j <= i; j++ {
+			_, err := client.SecurityEvent.Create().
+				SetUserID(testUser.ID).
+				SetEventType("security_alert").
+				SetDescription(fmt.Sprintf("%s event %d", severity, j)).
+				SetSeverity(securityevent.Severity(severity)).
+				Save(context.Background())
+			require.NoError(t, err)
+		}
+	}
 
 	tokenManager := auth.NewTokenManager(
 		"test-access-secret",
@@ -945,8 +2083,8 @@ func TestAuthService_UnlockAccount(t *testing.T) {
 	mockEmailService := email.NewMockEmailService()
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
-	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger)
-	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger)
+	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
 
 	authService := NewAuthService(
 		client,
@@ -958,71 +2096,1407 @@ func TestAuthService_UnlockAccount(t *testing.T) {
 	)
 
 	tests := []struct {
-		name         string
-		userRole     string
-		request      *authv1.UnlockAccountRequest
-		wantErr      bool
-		expectedCode codes.Code
+		name          string
+		severity      authv1.SecurityEventSeverity
+		expectedCount int
 	}{
 		{
-			name:     "admin can unlock account",
-			userRole: "admin",
-			request: &authv1.UnlockAccountRequest{
-				UserId: lockedUser.ID.String(),
-			},
-			wantErr: false,
+			name:          "unspecified returns all",
+			severity:      authv1.SecurityEventSeverity_SECURITY_EVENT_SEVERITY_UNSPECIFIED,
+			expectedCount: 10,
 		},
 		{
-			name:     "non-admin cannot unlock",
+			name:          "low",
+			severity:      authv1.SecurityEventSeverity_SECURITY_EVENT_SEVERITY_LOW,
+			expectedCount: 1,
+		},
+		{
+			name:          "medium",
+			severity:      authv1.SecurityEventSeverity_SECURITY_EVENT_SEVERITY_MEDIUM,
+			expectedCount: 2,
+		},
+		{
+			name:          "high",
+			severity:      authv1.SecurityEventSeverity_SECURITY_EVENT_SEVERITY_HIGH,
+			expectedCount: 3,
+		},
+		{
+			name:          "critical",
+			severity:      authv1.SecurityEventSeverity_SECURITY_EVENT_SEVERITY_CRITICAL,
+			expectedCount: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctx = context.WithValue(ctx, middleware.ContextKeyUserID, testUser.ID.String())
+			ctx = context.WithValue(ctx, middleware.ContextKeyUserRole, "user")
+
+			resp, err := authService.GetSecurityEvents(ctx, &authv1.GetSecurityEventsRequest{
+				PageSize: 20,
+				Severity: tt.severity,
+			})
+
+			require.NoError(t, err)
+			assert.Len(t, resp.Events, tt.expectedCount)
+			assert.Equal(t, int32(tt.expectedCount), resp.TotalCount)
+
+			if tt.severity != authv1.SecurityEventSeverity_SECURITY_EVENT_SEVERITY_UNSPECIFIED {
+				for _, event := range resp.Events {
+					assert.Equal(t, tt.severity, event.Severity)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthService_GetSecurityEvents_CursorPagination(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+
+	createEvent := func(description string) {
+		_, err := client.SecurityEvent.Create().
+			SetUserID(testUser.ID).
+			SetEventType("login_success").
+			SetDescription(description).
+			SetSeverity("low").
+			Save(context.Background())
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 7; i++ {
+		createEvent(fmt.Sprintf("Event %d", i))
+	}
+
+	tokenManager := auth.NewTokenManager(
+		"test-access-secret",
+		"test-refresh-secret",
+		15*time.Minute,
+		7*24*time.Hour,
+	)
+
+	mockEmailService := email.NewMockEmailService()
+	securityService := NewSecurityService(client)
+	securityLogger := NewSecurityLogger(securityService)
+	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
+
+	authService := NewAuthService(
+		client,
+		tokenManager,
+		emailVerificationService,
+		passwordResetService,
+		securityLogger,
+		createTestSecurityConfig(),
+	)
+
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, middleware.ContextKeyUserID, testUser.ID.String())
+	ctx = context.WithValue(ctx, middleware.ContextKeyUserRole, "user")
+
+	t.Run("no duplicates when events are inserted between pages", func(t *testing.T) {
+		seen := make(map[string]bool)
+		pageToken := ""
+		pages := 0
+
+		for {
+			resp, err := authService.GetSecurityEvents(ctx, &authv1.GetSecurityEventsRequest{
+				PageSize:  3,
+				PageToken: pageToken,
+			})
+			require.NoError(t, err)
+
+			for _, event := range resp.Events {
+				assert.False(t, seen[event.Id], "event %s returned twice", event.Id)
+				seen[event.Id] = true
+			}
+
+			// Newer events must not shift the following pages
+			createEvent(fmt.Sprintf("Inserted after page %d", pages))
+			pages++
+
+			if resp.NextPageToken == "" {
+				break
+			}
+			pageToken = resp.NextPageToken
+		}
+
+		assert.Len(t, seen, 7)
+		assert.Equal(t, 3, pages)
+	})
+
+	t.Run("malformed page token", func(t *testing.T) {
+		for _, token := range []string{"offset:3", "not-base64!", "Zm9vYmFy"} {
+			_, err := authService.GetSecurityEvents(ctx, &authv1.GetSecurityEventsRequest{
+				PageSize:  3,
+				PageToken: token,
+			})
+			require.Error(t, err)
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, codes.InvalidArgument, st.Code())
+		}
+	})
+}
+
+func TestAuthService_GetSecurityStats(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	adminUser, err := client.User.Create().
+		SetEmail("admin@example.com").
+		SetUsername("admin").
+		SetPasswordHash("hash").
+		SetRole(user.RoleAdmin).
+		SetIsActive(true).
+		Save(context.Background())
+	require.NoError(t, err)
+
+	createEvent := func(userID uuid.UUID, severity string, resolved bool) {
+		_, err := client.SecurityEvent.Create().
+			SetUserID(userID).
+			SetEventType("security_alert").
+			SetSeverity(securityevent.Severity(severity)).
+			SetResolved(resolved).
+			Save(context.Background())
+		require.NoError(t, err)
+	}
+
+	// Regular user: 3 events, 2 unresolved, 1 high severity
+	createEvent(testUser.ID, "low", false)
+	createEvent(testUser.ID, "medium", true)
+	createEvent(testUser.ID, "high", false)
+
+	// Admin: 2 events, 1 unresolved, 2 high/critical severity
+	createEvent(adminUser.ID, "critical", false)
+	createEvent(adminUser.ID, "high", true)
+
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	tests := []struct {
+		name               string
+		userID             string
+		userRole           string
+		request            *authv1.GetSecurityStatsRequest
+		wantErr            bool
+		expectedCode       codes.Code
+		expectedTotal      int32
+		expectedUnresolved int32
+		expectedHigh       int32
+	}{
+		{
+			name:               "regular user sees own stats",
+			userID:             testUser.ID.String(),
+			userRole:           "user",
+			request:            &authv1.GetSecurityStatsRequest{},
+			expectedTotal:      3,
+			expectedUnresolved: 2,
+			expectedHigh:       1,
+		},
+		{
+			name:     "regular user cannot request another user's stats",
+			userID:   testUser.ID.String(),
 			userRole: "user",
-			request: &authv1.UnlockAccountRequest{
-				UserId: lockedUser.ID.String(),
+			request: &authv1.GetSecurityStatsRequest{
+				UserId: adminUser.ID.String(),
 			},
-			wantErr:      true,
-			expectedCode: codes.PermissionDenied,
+			expectedTotal:      3,
+			expectedUnresolved: 2,
+			expectedHigh:       1,
 		},
 		{
-			name:     "invalid user ID",
+			name:               "admin sees global stats",
+			userID:             adminUser.ID.String(),
+			userRole:           "admin",
+			request:            &authv1.GetSecurityStatsRequest{},
+			expectedTotal:      5,
+			expectedUnresolved: 3,
+			expectedHigh:       3,
+		},
+		{
+			name:     "admin filters by user",
+			userID:   adminUser.ID.String(),
 			userRole: "admin",
-			request: &authv1.UnlockAccountRequest{
-				UserId: "invalid-uuid",
+			request: &authv1.GetSecurityStatsRequest{
+				UserId: testUser.ID.String(),
 			},
-			wantErr:      true,
-			expectedCode: codes.InvalidArgument,
+			expectedTotal:      3,
+			expectedUnresolved: 2,
+			expectedHigh:       1,
 		},
 		{
-			name:     "non-existent user",
+			name:     "admin with invalid user filter",
+			userID:   adminUser.ID.String(),
 			userRole: "admin",
-			request: &authv1.UnlockAccountRequest{
-				UserId: uuid.New().String(),
+			request: &authv1.GetSecurityStatsRequest{
+				UserId: "invalid-uuid",
 			},
 			wantErr:      true,
-			expectedCode: codes.NotFound,
+			expectedCode: codes.InvalidArgument,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := context.Background()
-			ctx = context.WithValue(ctx, middleware.ContextKeyUserID, adminUser.ID.String())
+			ctx = context.WithValue(ctx, middleware.ContextKeyUserID, tt.userID)
 			ctx = context.WithValue(ctx, middleware.ContextKeyUserRole, tt.userRole)
 
-			_, err := authService.UnlockAccount(ctx, tt.request)
+			resp, err := authService.GetSecurityStats(ctx, tt.request)
 
 			if tt.wantErr {
 				require.Error(t, err)
 				st, ok := status.FromError(err)
 				require.True(t, ok)
 				assert.Equal(t, tt.expectedCode, st.Code())
-			} else {
-				require.NoError(t, err)
-
-				// Verify account was unlocked
-				unlockedUser, err := client.User.Get(ctx, lockedUser.ID)
-				require.NoError(t, err)
-				assert.Equal(t, 0, unlockedUser.FailedLoginAttempts)
-				assert.Nil(t, unlockedUser.AccountLockedUntil)
+				return
 			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedTotal, resp.TotalEvents)
+			assert.Equal(t, tt.expectedUnresolved, resp.UnresolvedEvents)
+			assert.Equal(t, tt.expectedHigh, resp.HighSeverityEvents)
 		})
 	}
 }
+
+func TestAuthService_ExportSecurityEvents(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	adminUser, err := client.User.Create().
+		SetEmail("admin@example.com").
+		SetUsername("admin").
+		SetPasswordHash("hash").
+		SetRole(user.RoleAdmin).
+		SetIsActive(true).
+		Save(context.Background())
+	require.NoError(t, err)
+
+	baseTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	createEvent := func(userID uuid.UUID, eventType securityevent.EventType, severity securityevent.Severity, description string, resolved bool, offset time.Duration) {
+		_, err := client.SecurityEvent.Create().
+			SetUserID(userID).
+			SetEventType(eventType).
+			SetSeverity(severity).
+			SetIPAddress("203.0.113.7").
+			SetDescription(description).
+			SetResolved(resolved).
+			SetCreatedAt(baseTime.Add(offset)).
+			Save(context.Background())
+		require.NoError(t, err)
+	}
+
+	createEvent(testUser.ID, securityevent.EventTypeLoginFailed, securityevent.SeverityMedium, "Invalid password, try again", false, time.Hour)
+	createEvent(testUser.ID, securityevent.EventTypeLoginSuccess, securityevent.SeverityLow, "=HYPERLINK(\"http://evil\")", true, 0)
+	createEvent(adminUser.ID, securityevent.EventTypePasswordChanged, securityevent.SeverityMedium, "Admin event", false, 2*time.Hour)
+
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	export := func(t *testing.T, userID, role string, req *authv1.ExportSecurityEventsRequest) [][]string {
+		ctx := context.WithValue(context.Background(), middleware.ContextKeyUserID, userID)
+		ctx = context.WithValue(ctx, middleware.ContextKeyUserRole, role)
+
+		resp, err := authService.ExportSecurityEvents(ctx, req)
+		require.NoError(t, err)
+
+		records, err := csv.NewReader(strings.NewReader(resp.Csv)).ReadAll()
+		require.NoError(t, err)
+		require.NotEmpty(t, records)
+		assert.Equal(t, []string{"timestamp", "type", "severity", "ip_address", "description", "resolved"}, records[0])
+		assert.Equal(t, int32(len(records)-1), resp.RowCount)
+		assert.False(t, resp.Truncated)
+		return records[1:]
+	}
+
+	t.Run("regular user exports only their own events", func(t *testing.T) {
+		rows := export(t, testUser.ID.String(), "user", &authv1.ExportSecurityEventsRequest{
+			UserId: adminUser.ID.String(), // Ignored without admin rights
+		})
+
+		assert.Equal(t, [][]string{
+			{"2024-03-01T12:00:00Z", "login_success", "low", "203.0.113.7", "'=HYPERLINK(\"http://evil\")", "true"},
+			{"2024-03-01T13:00:00Z", "login_failed", "medium", "203.0.113.7", "Invalid password, try again", "false"},
+		}, rows)
+	})
+
+	t.Run("admin exports every user's events", func(t *testing.T) {
+		rows := export(t, adminUser.ID.String(), "admin", &authv1.ExportSecurityEventsRequest{})
+		require.Len(t, rows, 3)
+		assert.Equal(t, "password_changed", rows[2][1])
+	})
+
+	t.Run("admin filters by user and type", func(t *testing.T) {
+		rows := export(t, adminUser.ID.String(), "admin", &authv1.ExportSecurityEventsRequest{
+			UserId:    testUser.ID.String(),
+			EventType: authv1.SecurityEventType_SECURITY_EVENT_TYPE_LOGIN_FAILED,
+		})
+		require.Len(t, rows, 1)
+		assert.Equal(t, "login_failed", rows[0][1])
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		_, err := authService.ExportSecurityEvents(context.Background(), &authv1.ExportSecurityEventsRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestAuthService_BulkResolveSecurityEvents(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	createEvent := func(resolved bool) uuid.UUID {
+		event, err := client.SecurityEvent.Create().
+			SetUserID(testUser.ID).
+			SetEventType("login_failed").
+			SetSeverity(securityevent.SeverityMedium).
+			SetResolved(resolved).
+			Save(context.Background())
+		require.NoError(t, err)
+		return event.ID
+	}
+
+	resolvedEvent := createEvent(true)
+	openEvent1 := createEvent(false)
+	openEvent2 := createEvent(false)
+	untouched := createEvent(false)
+
+	roleCtx := func(role string) context.Context {
+		ctx := context.WithValue(context.Background(), middleware.ContextKeyUserID, testUser.ID.String())
+		return context.WithValue(ctx, middleware.ContextKeyUserRole, role)
+	}
+	isResolved := func(id uuid.UUID) bool {
+		event, err := client.SecurityEvent.Get(context.Background(), id)
+		require.NoError(t, err)
+		return event.Resolved
+	}
+
+	t.Run("non-admin is rejected", func(t *testing.T) {
+		_, err := authService.BulkResolveSecurityEvents(roleCtx("user"), &authv1.BulkResolveSecurityEventsRequest{
+			EventIds: []string{openEvent1.String()},
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.False(t, isResolved(openEvent1))
+	})
+
+	t.Run("unknown event changes nothing", func(t *testing.T) {
+		_, err := authService.BulkResolveSecurityEvents(roleCtx("admin"), &authv1.BulkResolveSecurityEventsRequest{
+			EventIds: []string{openEvent1.String(), uuid.New().String()},
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.False(t, isResolved(openEvent1))
+	})
+
+	t.Run("invalid event ID", func(t *testing.T) {
+		_, err := authService.BulkResolveSecurityEvents(roleCtx("admin"), &authv1.BulkResolveSecurityEventsRequest{
+			EventIds: []string{"not-a-uuid"},
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("mix of resolved and unresolved events", func(t *testing.T) {
+		req := &authv1.BulkResolveSecurityEventsRequest{
+			EventIds: []string{resolvedEvent.String(), openEvent1.String(), openEvent2.String(), openEvent1.String()},
+		}
+
+		resp, err := authService.BulkResolveSecurityEvents(roleCtx("admin"), req)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), resp.ResolvedCount)
+		assert.True(t, isResolved(resolvedEvent))
+		assert.True(t, isResolved(openEvent1))
+		assert.True(t, isResolved(openEvent2))
+		assert.False(t, isResolved(untouched))
+
+		// Resolving again is a no-op
+		resp, err = authService.BulkResolveSecurityEvents(roleCtx("admin"), req)
+		require.NoError(t, err)
+		assert.Equal(t, int32(0), resp.ResolvedCount)
+	})
+}
+
+func TestAuthService_DeleteSecurityEvents(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	createEvent := func() uuid.UUID {
+		event, err := client.SecurityEvent.Create().
+			SetUserID(testUser.ID).
+			SetEventType("login_failed").
+			SetSeverity(securityevent.SeverityLow).
+			Save(context.Background())
+		require.NoError(t, err)
+		return event.ID
+	}
+
+	noise1 := createEvent()
+	noise2 := createEvent()
+	kept := createEvent()
+
+	roleCtx := func(role string) context.Context {
+		ctx := context.WithValue(context.Background(), middleware.ContextKeyUserID, testUser.ID.String())
+		return context.WithValue(ctx, middleware.ContextKeyUserRole, role)
+	}
+	exists := func(id uuid.UUID) bool {
+		found, err := client.SecurityEvent.Query().Where(securityevent.ID(id)).Exist(context.Background())
+		require.NoError(t, err)
+		return found
+	}
+
+	// Non-admins cannot delete events, not even their own
+	_, err := authService.DeleteSecurityEvents(roleCtx("user"), &authv1.DeleteSecurityEventsRequest{
+		EventIds: []string{noise1.String()},
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.True(t, exists(noise1))
+
+	// One unknown ID rolls back the whole deletion
+	_, err = authService.DeleteSecurityEvents(roleCtx("admin"), &authv1.DeleteSecurityEventsRequest{
+		EventIds: []string{noise1.String(), uuid.New().String()},
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.True(t, exists(noise1))
+
+	resp, err := authService.DeleteSecurityEvents(roleCtx("admin"), &authv1.DeleteSecurityEventsRequest{
+		EventIds: []string{noise1.String(), noise2.String()},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), resp.DeletedCount)
+	assert.False(t, exists(noise1))
+	assert.False(t, exists(noise2))
+	assert.True(t, exists(kept))
+
+	// The deletion is audited
+	audits, err := client.SecurityEvent.Query().
+		Where(securityevent.DescriptionEQ("Deleted 2 security events")).
+		Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, audits)
+}
+
+func TestAuthService_UnlockAccount(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	// Create locked user
+	lockedUser := createTestUser(t, client)
+	lockTime := time.Now().Add(1 * time.Hour)
+	lockedUser, err := lockedUser.Update().
+		SetFailedLoginAttempts(5).
+		SetAccountLockedUntil(lockTime).
+		Save(context.Background())
+	require.NoError(t, err)
+
+	// Create admin user
+	adminUser, err := client.User.Create().
+		SetEmail("admin@example.com").
+		SetUsername("admin").
+		SetPasswordHash("hash").
+		SetRole(user.RoleAdmin).
+		SetIsActive(true).
+		Save(context.Background())
+	require.NoError(t, err)
+
+	tokenManager := auth.NewTokenManager(
+		"test-access-secret",
+		"test-refresh-secret",
+		15*time.Minute,
+		7*24*time.Hour,
+	)
+
+	mockEmailService := email.NewMockEmailService()
+	securityService := NewSecurityService(client)
+	securityLogger := NewSecurityLogger(securityService)
+	emailVerificationService := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+	passwordResetService := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
+
+	authService := NewAuthService(
+		client,
+		tokenManager,
+		emailVerificationService,
+		passwordResetService,
+		securityLogger,
+		createTestSecurityConfig(),
+	)
+
+	tests := []struct {
+		name         string
+		userRole     string
+		request      *authv1.UnlockAccountRequest
+		wantErr      bool
+		expectedCode codes.Code
+	}{
+		{
+			name:     "admin can unlock account",
+			userRole: "admin",
+			request: &authv1.UnlockAccountRequest{
+				UserId: lockedUser.ID.String(),
+			},
+			wantErr: false,
+		},
+		{
+			name:     "non-admin cannot unlock",
+			userRole: "user",
+			request: &authv1.UnlockAccountRequest{
+				UserId: lockedUser.ID.String(),
+			},
+			wantErr:      true,
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:     "invalid user ID",
+			userRole: "admin",
+			request: &authv1.UnlockAccountRequest{
+				UserId: "invalid-uuid",
+			},
+			wantErr:      true,
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:     "non-existent user",
+			userRole: "admin",
+			request: &authv1.UnlockAccountRequest{
+				UserId: uuid.New().String(),
+			},
+			wantErr:      true,
+			expectedCode: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctx = context.WithValue(ctx, middleware.ContextKeyUserID, adminUser.ID.String())
+			ctx = context.WithValue(ctx, middleware.ContextKeyUserRole, tt.userRole)
+
+			_, err := authService.UnlockAccount(ctx, tt.request)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.expectedCode, st.Code())
+			} else {
+				require.NoError(t, err)
+
+				// Verify account was unlocked
+				unlockedUser, err := client.User.Get(ctx, lockedUser.ID)
+				require.NoError(t, err)
+				assert.Equal(t, 0, unlockedUser.FailedLoginAttempts)
+				assert.Nil(t, unlockedUser.AccountLockedUntil)
+			}
+		})
+	}
+}
+
+func TestAuthService_Login_UpgradesPasswordHash(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	// The user starts out with a bcrypt hash
+	testUser := createTestUser(t, client)
+	require.True(t, strings.HasPrefix(testUser.PasswordHash, "$2"))
+
+	passwordManager := auth.NewPasswordManager()
+	require.NoError(t, passwordManager.SetHashAlgorithm(auth.HashAlgorithmArgon2id, auth.Argon2Params{
+		Memory: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32,
+	}))
+
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	authService.SetPasswordManager(passwordManager)
+
+	// Wrong password does not touch the hash
+	_, err := authService.Login(context.Background(), &authv1.LoginRequest{Email: "test@example.com", Password: "WrongPass123!"})
+	require.Error(t, err)
+	unchanged, err := client.User.Get(context.Background(), testUser.ID)
+	require.NoError(t, err)
+	assert.Equal(t, testUser.PasswordHash, unchanged.PasswordHash)
+
+	// Successful login re-hashes with Argon2id
+	_, err = authService.Login(context.Background(), &authv1.LoginRequest{Email: "test@example.com", Password: "TestPass123!"})
+	require.NoError(t, err)
+
+	upgraded, err := client.User.Get(context.Background(), testUser.ID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(upgraded.PasswordHash, "$argon2id$"))
+
+	// The upgraded hash keeps working and is not re-hashed again
+	_, err = authService.Login(context.Background(), &authv1.LoginRequest{Email: "test@example.com", Password: "TestPass123!"})
+	require.NoError(t, err)
+
+	again, err := client.User.Get(context.Background(), testUser.ID)
+	require.NoError(t, err)
+	assert.Equal(t, upgraded.PasswordHash, again.PasswordHash)
+}
+
+func TestAuthService_Login_PasswordExpired(t *testing.T) {
+	tests := []struct {
+		name            string
+		changedAgo      time.Duration
+		expectedExpired bool
+		expectedAgeDays int32
+	}{
+		{name: "recently changed", changedAgo: 24 * time.Hour, expectedExpired: false, expectedAgeDays: 1},
+		{name: "older than max age", changedAgo: 100 * 24 * time.Hour, expectedExpired: true, expectedAgeDays: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			client := setupTestDB(t)
+			defer client.Close()
+
+			testUser := createTestUser(t, client)
+			_, err := testUser.Update().
+				SetPasswordChangedAt(time.Now().Add(-tt.changedAgo)).
+				Save(context.Background())
+			require.NoError(t, err)
+
+			securityConfig := createTestSecurityConfig()
+			securityConfig.MaxPasswordAge = 90 * 24 * time.Hour
+			authService := newTestAuthService(client, securityConfig)
+
+			// An expired password still authenticates
+			resp, err := authService.Login(context.Background(), &authv1.LoginRequest{Email: "test@example.com", Password: "TestPass123!"})
+			require.NoError(t, err)
+			assert.NotEmpty(t, resp.AccessToken)
+			assert.Equal(t, tt.expectedExpired, resp.PasswordExpired)
+
+			ctx := context.WithValue(context.Background(), middleware.ContextKeyUserID, testUser.ID.String())
+			me, err := authService.GetMe(ctx, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedExpired, me.PasswordExpired)
+			assert.Equal(t, tt.expectedAgeDays, me.PasswordAgeDays)
+		})
+	}
+}
+
+func TestAuthService_Login_FailedAttemptWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		spacing    time.Duration
+		wantLocked bool
+	}{
+		{name: "failures within the window lock", spacing: 0, wantLocked: true},
+		{name: "failures spaced beyond the window never lock", spacing: 2 * time.Hour, wantLocked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			client := setupTestDB(t)
+			defer client.Close()
+
+			testUser := createTestUser(t, client)
+			securityConfig := createTestSecurityConfig()
+			securityConfig.FailedLoginWindow = time.Hour
+			authService := newTestAuthService(client, securityConfig)
+
+			req := &authv1.LoginRequest{Email: "test@example.com", Password: "WrongPassword123!"}
+			var err error
+			for i := 0; i < securityConfig.MaxLoginAttempts+2; i++ {
+				if tt.spacing > 0 && i > 0 {
+					// Pretend the previous failure happened long ago
+					require.NoError(t, client.User.UpdateOneID(testUser.ID).
+						SetLastFailedLoginAt(time.Now().Add(-tt.spacing)).
+						Exec(context.Background()))
+				}
+
+				_, err = authService.Login(context.Background(), req)
+				require.Error(t, err)
+				if status.Code(err) == codes.PermissionDenied {
+					break
+				}
+			}
+
+			updatedUser, getErr := client.User.Get(context.Background(), testUser.ID)
+			require.NoError(t, getErr)
+
+			if tt.wantLocked {
+				assert.Equal(t, codes.PermissionDenied, status.Code(err))
+				assert.Equal(t, securityConfig.MaxLoginAttempts, updatedUser.FailedLoginAttempts)
+				assert.NotNil(t, updatedUser.AccountLockedUntil)
+				return
+			}
+
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			assert.Equal(t, 1, updatedUser.FailedLoginAttempts)
+			assert.Nil(t, updatedUser.AccountLockedUntil)
+			assert.NotNil(t, updatedUser.LastFailedLoginAt)
+		})
+	}
+}
+
+func TestAuthService_Login_NewIPDetection(t *testing.T) {
+	tests := []struct {
+		name      string
+		detect    bool
+		loginIPs  []string
+		wantEvent bool
+	}{
+		{name: "first login", detect: true, loginIPs: []string{"203.0.113.10"}, wantEvent: false},
+		{name: "known IP", detect: true, loginIPs: []string{"203.0.113.10", "203.0.113.10"}, wantEvent: false},
+		{name: "new IP", detect: true, loginIPs: []string{"203.0.113.10", "198.51.100.7"}, wantEvent: true},
+		{name: "detection disabled", detect: false, loginIPs: []string{"203.0.113.10", "198.51.100.7"}, wantEvent: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			client := setupTestDB(t)
+			defer client.Close()
+
+			testUser := createTestUser(t, client)
+			securityConfig := createTestSecurityConfig()
+			securityConfig.DetectNewLoginIP = tt.detect
+			authService := newTestAuthService(client, securityConfig)
+
+			mockEmailService := email.NewMockEmailService()
+			authService.SetEmailService(mockEmailService)
+
+			for _, ip := range tt.loginIPs {
+				ctx := context.WithValue(context.Background(), middleware.ContextKeyIPAddress, ip)
+				_, err := authService.Login(ctx, &authv1.LoginRequest{Email: "test@example.com", Password: "TestPass123!"})
+				require.NoError(t, err)
+			}
+
+			eventCount, err := client.SecurityEvent.Query().
+				Where(
+					securityevent.UserIDEQ(testUser.ID),
+					securityevent.EventTypeEQ(securityevent.EventTypeSuspiciousActivity),
+				).
+				Count(context.Background())
+			require.NoError(t, err)
+
+			sent := mockEmailService.GetSentEmails()
+			if !tt.wantEvent {
+				assert.Zero(t, eventCount)
+				assert.Empty(t, sent)
+				return
+			}
+
+			assert.Equal(t, 1, eventCount)
+			require.Len(t, sent, 1)
+			assert.Equal(t, "security_alert", sent[0].Template)
+			assert.Contains(t, sent[0].Data.AlertMessage, tt.loginIPs[len(tt.loginIPs)-1])
+		})
+	}
+}
+
+func TestRecordLoginIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		history  []string
+		ip       string
+		expected []string
+	}{
+		{name: "empty history", history: nil, ip: "10.0.0.1", expected: []string{"10.0.0.1"}},
+		{name: "new IP goes first", history: []string{"10.0.0.1"}, ip: "10.0.0.2", expected: []string{"10.0.0.2", "10.0.0.1"}},
+		{name: "known IP moves to front", history: []string{"10.0.0.1", "10.0.0.2"}, ip: "10.0.0.2", expected: []string{"10.0.0.2", "10.0.0.1"}},
+		{name: "oldest IP is dropped", history: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, ip: "10.0.0.4", expected: []string{"10.0.0.4", "10.0.0.1", "10.0.0.2"}},
+		{name: "unknown IP is ignored", history: []string{"10.0.0.1"}, ip: "", expected: []string{"10.0.0.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, recordLoginIP(tt.history, tt.ip, 3))
+		})
+	}
+}
+
+func TestAuthService_DeactivateAccount(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	loginResp, err := authService.Login(context.Background(), &authv1.LoginRequest{Email: "test@example.com", Password: "TestPass123!"})
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyUserID, testUser.ID.String())
+
+	_, err = authService.DeactivateAccount(ctx, &authv1.DeactivateAccountRequest{})
+	require.NoError(t, err)
+
+	deactivated, err := client.User.Get(context.Background(), testUser.ID)
+	require.NoError(t, err)
+	assert.False(t, deactivated.IsActive)
+
+	sessionCount, err := client.Session.Query().Where(session.UserIDEQ(testUser.ID)).Count(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, sessionCount)
+
+	// The security event is recorded
+	eventCount, err := client.SecurityEvent.Query().
+		Where(
+			securityevent.UserIDEQ(testUser.ID),
+			securityevent.EventTypeEQ(securityevent.EventTypeSecurityAlert),
+		).
+		Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, eventCount)
+
+	// The old refresh token and new logins are rejected
+	_, err = authService.RefreshToken(context.Background(), &authv1.RefreshTokenRequest{RefreshToken: loginResp.RefreshToken})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = authService.Login(context.Background(), &authv1.LoginRequest{Email: "test@example.com", Password: "TestPass123!"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Deactivating twice is rejected
+	_, err = authService.DeactivateAccount(ctx, &authv1.DeactivateAccountRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestAuthService_DeactivateAccount_LastAdmin(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	helpers := NewTestHelpers(t, client)
+	admin := helpers.CreateAdminUser("admin@example.com", "admin", "AdminPass123!")
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	ctx := userContext(admin.ID.String(), "admin")
+
+	// The last active admin can't lock everyone out of administration
+	_, err := authService.DeactivateAccount(ctx, &authv1.DeactivateAccountRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	unchanged, err := client.User.Get(context.Background(), admin.ID)
+	require.NoError(t, err)
+	assert.True(t, unchanged.IsActive)
+
+	// With another active admin it succeeds
+	helpers.CreateAdminUser("admin2@example.com", "admin2", "AdminPass123!")
+	_, err = authService.DeactivateAccount(ctx, &authv1.DeactivateAccountRequest{})
+	require.NoError(t, err)
+}
+
+func TestAuthService_SetUserActive(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	_, err := testUser.Update().SetIsActive(false).Save(context.Background())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		userRole     string
+		request      *authv1.SetUserActiveRequest
+		wantErr      bool
+		expectedCode codes.Code
+	}{
+		{
+			name:         "non-admin cannot reactivate others",
+			userRole:     "user",
+			request:      &authv1.SetUserActiveRequest{UserId: testUser.ID.String(), IsActive: true},
+			wantErr:      true,
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "manager cannot deactivate others",
+			userRole:     "manager",
+			request:      &authv1.SetUserActiveRequest{UserId: testUser.ID.String(), IsActive: false},
+			wantErr:      true,
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name:         "invalid user ID",
+			userRole:     "admin",
+			request:      &authv1.SetUserActiveRequest{UserId: "invalid-uuid", IsActive: true},
+			wantErr:      true,
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "non-existent user",
+			userRole:     "admin",
+			request:      &authv1.SetUserActiveRequest{UserId: uuid.New().String(), IsActive: true},
+			wantErr:      true,
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:     "admin can reactivate account",
+			userRole: "admin",
+			request:  &authv1.SetUserActiveRequest{UserId: testUser.ID.String(), IsActive: true},
+			wantErr:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			ctx = context.WithValue(ctx, middleware.ContextKeyUserID, uuid.New().String())
+			ctx = context.WithValue(ctx, middleware.ContextKeyUserRole, tt.userRole)
+
+			_, err := authService.SetUserActive(ctx, tt.request)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedCode, status.Code(err))
+
+				unchanged, err := client.User.Get(context.Background(), testUser.ID)
+				require.NoError(t, err)
+				assert.False(t, unchanged.IsActive)
+				return
+			}
+
+			require.NoError(t, err)
+
+			// Reactivation restores access
+			_, err = authService.Login(context.Background(), &authv1.LoginRequest{Email: "test@example.com", Password: "TestPass123!"})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAuthService_DeleteAccount(t *testing.T) {
+	tests := []struct {
+		name              string
+		policy            string
+		expectOwnedExists bool
+	}{
+		{name: "orphan owned tasks", policy: config.DeletedUserTasksOrphan, expectOwnedExists: true},
+		{name: "delete owned tasks", policy: config.DeletedUserTasksDelete, expectOwnedExists: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			client := setupTestDB(t)
+			defer client.Close()
+			ctx := context.Background()
+
+			testUser := createTestUser(t, client)
+			otherUser, err := client.User.Create().
+				SetEmail("other@example.com").
+				SetUsername("otheruser").
+				SetPasswordHash("hash").
+				Save(ctx)
+			require.NoError(t, err)
+
+			ownedTask, err := client.Task.Create().SetTitle("Owned").SetCreator(testUser).Save(ctx)
+			require.NoError(t, err)
+			assignedTask, err := client.Task.Create().
+				SetTitle("Assigned").
+				SetCreator(otherUser).
+				SetAssignee(testUser).
+				SetAssignedTo(testUser.ID.String()).
+				Save(ctx)
+			require.NoError(t, err)
+
+			securityConfig := createTestSecurityConfig()
+			securityConfig.DeletedUserTaskPolicy = tt.policy
+			authService := newTestAuthService(client, securityConfig)
+
+			require.NoError(t, authService.securityLogger.LogPasswordChanged(ctx, testUser.ID))
+			require.NoError(t, authService.securityLogger.LogPasswordChanged(ctx, otherUser.ID))
+
+			userCtx := context.WithValue(ctx, middleware.ContextKeyUserID, testUser.ID.String())
+
+			// Password re-authentication is required
+			_, err = authService.DeleteAccount(userCtx, &authv1.DeleteAccountRequest{})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			_, err = authService.DeleteAccount(userCtx, &authv1.DeleteAccountRequest{Password: "WrongPass123!"})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+			exists, err := client.User.Query().Where(user.ID(testUser.ID)).Exist(ctx)
+			require.NoError(t, err)
+			require.True(t, exists)
+
+			_, err = authService.DeleteAccount(userCtx, &authv1.DeleteAccountRequest{Password: "TestPass123!"})
+			require.NoError(t, err)
+
+			// The user and their security events are gone
+			exists, err = client.User.Query().Where(user.ID(testUser.ID)).Exist(ctx)
+			require.NoError(t, err)
+			assert.False(t, exists)
+
+			eventCount, err := client.SecurityEvent.Query().Where(securityevent.UserIDEQ(testUser.ID)).Count(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, eventCount)
+
+			otherEvents, err := client.SecurityEvent.Query().Where(securityevent.UserIDEQ(otherUser.ID)).Count(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 1, otherEvents)
+
+			// Owned tasks follow the policy
+			owned, err := client.Task.Query().Where(task.ID(ownedTask.ID)).WithCreator().Only(ctx)
+			if tt.expectOwnedExists {
+				require.NoError(t, err)
+				assert.Nil(t, owned.Edges.Creator)
+			} else {
+				assert.True(t, ent.IsNotFound(err))
+			}
+
+			// Tasks created by others remain but are unassigned
+			assigned, err := client.Task.Query().Where(task.ID(assignedTask.ID)).WithCreator().WithAssignee().Only(ctx)
+			require.NoError(t, err)
+			assert.Nil(t, assigned.Edges.Assignee)
+			assert.Empty(t, assigned.AssignedTo)
+			require.NotNil(t, assigned.Edges.Creator)
+			assert.Equal(t, otherUser.ID, assigned.Edges.Creator.ID)
+		})
+	}
+}
+
+func TestAuthService_DeleteAccount_LastAdmin(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	helpers := NewTestHelpers(t, client)
+	admin := helpers.CreateAdminUser("admin@example.com", "admin", "AdminPass123!")
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	ctx := userContext(admin.ID.String(), "admin")
+
+	_, err := authService.DeleteAccount(ctx, &authv1.DeleteAccountRequest{Password: "AdminPass123!"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	exists, err := client.User.Query().Where(user.ID(admin.ID)).Exist(context.Background())
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// With another active admin it succeeds
+	helpers.CreateAdminUser("admin2@example.com", "admin2", "AdminPass123!")
+	_, err = authService.DeleteAccount(ctx, &authv1.DeleteAccountRequest{Password: "AdminPass123!"})
+	require.NoError(t, err)
+}
+
+func TestAuthService_ListUsers(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	helpers := NewTestHelpers(t, client)
+	admin := helpers.CreateAdminUser("admin@example.com", "admin", "AdminPass123!")
+	helpers.CreateManagerUser("manager@example.com", "manager", "ManagerPass123!")
+	helpers.CreateVerifiedUser("verified@example.com", "verified", "VerifiedPass123!")
+	for i := 0; i < 3; i++ {
+		helpers.CreateTestUser(fmt.Sprintf("user%d@example.com", i), fmt.Sprintf("user%d", i), "TestPass123!")
+	}
+
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	adminCtx := context.WithValue(ctx, middleware.ContextKeyUserID, admin.ID.String())
+	adminCtx = context.WithValue(adminCtx, middleware.ContextKeyUserRole, "admin")
+
+	emails := func(users []*authv1.User) []string {
+		result := make([]string, len(users))
+		for i, u := range users {
+			result[i] = u.Email
+		}
+		return result
+	}
+
+	t.Run("role filter", func(t *testing.T) {
+		resp, err := authService.ListUsers(adminCtx, &authv1.ListUsersRequest{Role: authv1.UserRole_USER_ROLE_MANAGER})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"manager@example.com"}, emails(resp.Users))
+		assert.Equal(t, int32(1), resp.TotalCount)
+	})
+
+	t.Run("verified filter", func(t *testing.T) {
+		verified := true
+		resp, err := authService.ListUsers(adminCtx, &authv1.ListUsersRequest{EmailVerified: &verified})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"admin@example.com", "manager@example.com", "verified@example.com"}, emails(resp.Users))
+
+		unverified := false
+		resp, err = authService.ListUsers(adminCtx, &authv1.ListUsersRequest{EmailVerified: &unverified})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"user0@example.com", "user1@example.com", "user2@example.com"}, emails(resp.Users))
+	})
+
+	t.Run("search", func(t *testing.T) {
+		resp, err := authService.ListUsers(adminCtx, &authv1.ListUsersRequest{Search: "USER1"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user1@example.com"}, emails(resp.Users))
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		seen := make(map[string]bool)
+		pageToken := ""
+		pages := 0
+		for {
+			resp, err := authService.ListUsers(adminCtx, &authv1.ListUsersRequest{PageSize: 4, PageToken: pageToken})
+			require.NoError(t, err)
+			assert.Equal(t, int32(6), resp.TotalCount)
+			pages++
+
+			for _, u := range resp.Users {
+				assert.False(t, seen[u.Id], "user %s returned twice", u.Email)
+				seen[u.Id] = true
+			}
+
+			if resp.NextPageToken == "" {
+				break
+			}
+			pageToken = resp.NextPageToken
+		}
+		assert.Equal(t, 2, pages)
+		assert.Len(t, seen, 6)
+
+		_, err := authService.ListUsers(adminCtx, &authv1.ListUsersRequest{PageToken: "not-a-token"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("non-admin rejected", func(t *testing.T) {
+		for _, role := range []string{"user", "manager"} {
+			userCtx := context.WithValue(ctx, middleware.ContextKeyUserID, admin.ID.String())
+			userCtx = context.WithValue(userCtx, middleware.ContextKeyUserRole, role)
+
+			_, err := authService.ListUsers(userCtx, &authv1.ListUsersRequest{})
+			assert.Equal(t, codes.PermissionDenied, status.Code(err), role)
+		}
+	})
+}
+
+func TestAuthService_ListLockedAccounts(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	helpers := NewTestHelpers(t, client)
+	admin := helpers.CreateAdminUser("admin@example.com", "admin", "AdminPass123!")
+	lock := func(email, username string, until time.Time, attempts int) *ent.User {
+		u := helpers.CreateTestUser(email, username, "TestPass123!")
+		return u.Update().
+			SetAccountLockedUntil(until).
+			SetFailedLoginAttempts(attempts).
+			SaveX(ctx)
+	}
+	locked := lock("locked@example.com", "locked", time.Now().Add(10*time.Minute), 5)
+	lock("expired@example.com", "expired", time.Now().Add(-time.Minute), 5)
+	helpers.CreateTestUser("unlocked@example.com", "unlocked", "TestPass123!")
+	for i := 0; i < 3; i++ {
+		lock(fmt.Sprintf("locked%d@example.com", i), fmt.Sprintf("locked%d", i), time.Now().Add(time.Hour), 3)
+	}
+
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	adminCtx := context.WithValue(ctx, middleware.ContextKeyUserID, admin.ID.String())
+	adminCtx = context.WithValue(adminCtx, middleware.ContextKeyUserRole, "admin")
+
+	t.Run("only currently locked accounts", func(t *testing.T) {
+		resp, err := authService.ListLockedAccounts(adminCtx, &authv1.ListLockedAccountsRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), resp.TotalCount)
+
+		var emails []string
+		for _, account := range resp.Accounts {
+			emails = append(emails, account.User.Email)
+			if account.User.Id == locked.ID.String() {
+				assert.Equal(t, int32(5), account.FailedLoginAttempts)
+				assert.WithinDuration(t, *locked.AccountLockedUntil, account.LockedUntil.AsTime(), time.Second)
+			}
+		}
+		assert.ElementsMatch(t, []string{"locked@example.com", "locked0@example.com", "locked1@example.com", "locked2@example.com"}, emails)
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		seen := make(map[string]bool)
+		pageToken := ""
+		pages := 0
+		for {
+			resp, err := authService.ListLockedAccounts(adminCtx, &authv1.ListLockedAccountsRequest{PageSize: 3, PageToken: pageToken})
+			require.NoError(t, err)
+			pages++
+
+			for _, account := range resp.Accounts {
+				assert.False(t, seen[account.User.Id], "account %s returned twice", account.User.Email)
+				seen[account.User.Id] = true
+			}
+
+			if resp.NextPageToken == "" {
+				break
+			}
+			pageToken = resp.NextPageToken
+		}
+		assert.Equal(t, 2, pages)
+		assert.Len(t, seen, 4)
+	})
+
+	t.Run("unlocked accounts drop out", func(t *testing.T) {
+		_, err := authService.UnlockAccount(adminCtx, &authv1.UnlockAccountRequest{UserId: locked.ID.String()})
+		require.NoError(t, err)
+
+		resp, err := authService.ListLockedAccounts(adminCtx, &authv1.ListLockedAccountsRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), resp.TotalCount)
+		for _, account := range resp.Accounts {
+			assert.NotEqual(t, locked.ID.String(), account.User.Id)
+		}
+	})
+
+	t.Run("non-admin rejected", func(t *testing.T) {
+		for _, role := range []string{"user", "manager"} {
+			userCtx := context.WithValue(ctx, middleware.ContextKeyUserID, admin.ID.String())
+			userCtx = context.WithValue(userCtx, middleware.ContextKeyUserRole, role)
+
+			_, err := authService.ListLockedAccounts(userCtx, &authv1.ListLockedAccountsRequest{})
+			assert.Equal(t, codes.PermissionDenied, status.Code(err), role)
+		}
+	})
+}
+
+func TestAuthService_GetUserByID(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	tests := []struct {
+		name         string
+		userRole     string
+		userID       string
+		expectedCode codes.Code
+	}{
+		{name: "admin can get user", userRole: "admin", userID: testUser.ID.String(), expectedCode: codes.OK},
+		{name: "non-admin rejected", userRole: "user", userID: testUser.ID.String(), expectedCode: codes.PermissionDenied},
+		{name: "invalid user ID", userRole: "admin", userID: "invalid-uuid", expectedCode: codes.InvalidArgument},
+		{name: "unknown user", userRole: "admin", userID: uuid.New().String(), expectedCode: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), middleware.ContextKeyUserRole, tt.userRole)
+
+			resp, err := authService.GetUserByID(ctx, &authv1.GetUserByIDRequest{UserId: tt.userID})
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode == codes.OK {
+				assert.Equal(t, testUser.Email, resp.User.Email)
+			}
+		})
+	}
+}
+
+func TestForUpdate(t *testing.T) {
+	query := func(d string) string {
+		s := entsql.Dialect(d).Select("id").From(entsql.Table("users"))
+		forUpdate(s)
+		q, _ := s.Query()
+		return q
+	}
+
+	assert.True(t, strings.HasSuffix(query(dialect.Postgres), "FOR UPDATE"))
+	assert.NotContains(t, query(dialect.SQLite), "FOR UPDATE")
+}
+
+func TestAuthService_UpdateUserRole(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	helpers := NewTestHelpers(t, client)
+	admin := helpers.CreateAdminUser("admin@example.com", "admin", "AdminPass123!")
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+
+	adminCtx := context.WithValue(context.Background(), middleware.ContextKeyUserID, admin.ID.String())
+	adminCtx = context.WithValue(adminCtx, middleware.ContextKeyUserRole, "admin")
+
+	// Permission enforcement
+	for _, role := range []string{"user", "manager"} {
+		ctx := context.WithValue(context.Background(), middleware.ContextKeyUserRole, role)
+		_, err := authService.UpdateUserRole(ctx, &authv1.UpdateUserRoleRequest{
+			UserId: testUser.ID.String(),
+			Role:   authv1.UserRole_USER_ROLE_ADMIN,
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), role)
+	}
+
+	// Validation
+	_, err := authService.UpdateUserRole(adminCtx, &authv1.UpdateUserRoleRequest{UserId: testUser.ID.String()})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = authService.UpdateUserRole(adminCtx, &authv1.UpdateUserRoleRequest{
+		UserId: uuid.New().String(),
+		Role:   authv1.UserRole_USER_ROLE_MANAGER,
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// The only admin cannot be demoted
+	_, err = authService.UpdateUserRole(adminCtx, &authv1.UpdateUserRoleRequest{
+		UserId: admin.ID.String(),
+		Role:   authv1.UserRole_USER_ROLE_USER,
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Valid promotion
+	resp, err := authService.UpdateUserRole(adminCtx, &authv1.UpdateUserRoleRequest{
+		UserId: testUser.ID.String(),
+		Role:   authv1.UserRole_USER_ROLE_ADMIN,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, authv1.UserRole_USER_ROLE_ADMIN, resp.User.Role)
+
+	promoted, err := client.User.Get(context.Background(), testUser.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.RoleAdmin, promoted.Role)
+
+	eventCount, err := client.SecurityEvent.Query().
+		Where(
+			securityevent.UserIDEQ(testUser.ID),
+			securityevent.EventTypeEQ(securityevent.EventTypeSecurityAlert),
+		).
+		Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, eventCount)
+
+	// With a second admin, the first one can now be demoted
+	_, err = authService.UpdateUserRole(adminCtx, &authv1.UpdateUserRoleRequest{
+		UserId: admin.ID.String(),
+		Role:   authv1.UserRole_USER_ROLE_MANAGER,
+	})
+	require.NoError(t, err)
+
+	// ...which leaves the promoted user as the last admin
+	_, err = authService.UpdateUserRole(adminCtx, &authv1.UpdateUserRoleRequest{
+		UserId: testUser.ID.String(),
+		Role:   authv1.UserRole_USER_ROLE_USER,
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// An inactive admin doesn't count towards keeping one
+	client.User.UpdateOneID(admin.ID).SetRole(user.RoleAdmin).SetIsActive(false).ExecX(context.Background())
+	_, err = authService.UpdateUserRole(adminCtx, &authv1.UpdateUserRoleRequest{
+		UserId: testUser.ID.String(),
+		Role:   authv1.UserRole_USER_ROLE_USER,
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = authService.SetUserActive(adminCtx, &authv1.SetUserActiveRequest{UserId: testUser.ID.String(), IsActive: false})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// ...and can itself be demoted
+	_, err = authService.UpdateUserRole(adminCtx, &authv1.UpdateUserRoleRequest{
+		UserId: admin.ID.String(),
+		Role:   authv1.UserRole_USER_ROLE_USER,
+	})
+	require.NoError(t, err)
+}
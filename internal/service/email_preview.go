@@ -0,0 +1,53 @@
+// internal/service/email_preview.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
+	"github.com/gurkanbulca/taskmaster/internal/middleware"
+	"github.com/gurkanbulca/taskmaster/pkg/authz"
+	"github.com/gurkanbulca/taskmaster/pkg/email"
+)
+
+// PreviewEmail renders an email template with sample data without sending
+// it, so admins can check template overrides and translations (admin only)
+func (s *AuthService) PreviewEmail(ctx context.Context, req *authv1.PreviewEmailRequest) (*authv1.PreviewEmailResponse, error) {
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+	if !authz.Can(userRole, authz.UserManage) {
+		return nil, status.Error(codes.PermissionDenied, "admin access required")
+	}
+
+	if s.emailPreviewer == nil {
+		return nil, status.Error(codes.Unavailable, "email previews are not available")
+	}
+	if req.Template == "" {
+		return nil, status.Error(codes.InvalidArgument, "template is required")
+	}
+
+	rendered, err := s.emailPreviewer.Preview(req.Template, req.Locale, req.Data)
+	if err != nil {
+		switch {
+		case errors.Is(err, email.ErrUnknownTemplate):
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("unknown template %q, expected one of: %s",
+				req.Template, strings.Join(email.TemplateNames(), ", ")))
+		case errors.Is(err, email.ErrUnknownSampleField):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			// Usually a broken template override, which is what the admin is checking
+			return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("failed to render template: %v", err))
+		}
+	}
+
+	return &authv1.PreviewEmailResponse{
+		Subject:  rendered.Subject,
+		HtmlBody: rendered.HTMLBody,
+		TextBody: rendered.TextBody,
+	}, nil
+}
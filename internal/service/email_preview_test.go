@@ -0,0 +1,70 @@
+// internal/service/email_preview_test.go
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
+	"github.com/gurkanbulca/taskmaster/pkg/email"
+)
+
+func TestAuthService_PreviewEmail(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	previewer, err := email.NewSMTPEmailService(&email.Config{AppName: "TaskMaster", BaseURL: "https://app.example.com"})
+	require.NoError(t, err)
+	authService.SetEmailPreviewer(previewer)
+	adminCtx := userContext("admin-id", "admin")
+
+	t.Run("renders each built-in template", func(t *testing.T) {
+		for _, name := range email.TemplateNames() {
+			t.Run(name, func(t *testing.T) {
+				resp, err := authService.PreviewEmail(adminCtx, &authv1.PreviewEmailRequest{Template: name})
+				require.NoError(t, err)
+				assert.Contains(t, resp.Subject+resp.HtmlBody+resp.TextBody, "TaskMaster")
+				assert.NotContains(t, resp.HtmlBody, "{{.AppName}}")
+				assert.NotContains(t, resp.TextBody, "{{.AppName}}")
+			})
+		}
+	})
+
+	t.Run("uses sample data", func(t *testing.T) {
+		resp, err := authService.PreviewEmail(adminCtx, &authv1.PreviewEmailRequest{
+			Template: "invite",
+			Data:     map[string]string{"role": "manager", "token": "preview"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, resp.TextBody, "as manager")
+		assert.Contains(t, resp.TextBody, "https://app.example.com/accept-invite?token=preview")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			role     string
+			req      *authv1.PreviewEmailRequest
+			wantCode codes.Code
+		}{
+			{name: "non-admin", role: "manager", req: &authv1.PreviewEmailRequest{Template: "welcome"}, wantCode: codes.PermissionDenied},
+			{name: "missing template", role: "admin", req: &authv1.PreviewEmailRequest{}, wantCode: codes.InvalidArgument},
+			{name: "unknown template", role: "admin", req: &authv1.PreviewEmailRequest{Template: "newsletter"}, wantCode: codes.InvalidArgument},
+			{name: "unknown sample field", role: "admin", req: &authv1.PreviewEmailRequest{Template: "welcome", Data: map[string]string{"colour": "blue"}}, wantCode: codes.InvalidArgument},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := authService.PreviewEmail(userContext("some-id", tt.role), tt.req)
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, status.Code(err))
+			})
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +15,7 @@ import (
 
 	ent "github.com/gurkanbulca/taskmaster/ent/generated"
 	"github.com/gurkanbulca/taskmaster/ent/generated/user"
+	"github.com/gurkanbulca/taskmaster/pkg/auth"
 	"github.com/gurkanbulca/taskmaster/pkg/email"
 )
 
@@ -22,24 +24,53 @@ const (
 	EmailVerificationTokenLength = 32
 	// EmailVerificationTokenDuration is how long verification tokens are valid
 	EmailVerificationTokenDuration = 24 * time.Hour
-	// MaxEmailVerificationAttempts is the maximum number of verification attempts
+	// EmailChangeTokenDuration is how long a pending email change can be confirmed
+	EmailChangeTokenDuration = 24 * time.Hour
+	// MaxEmailVerificationAttempts is the default number of verification emails per user
 	MaxEmailVerificationAttempts = 5
+	// EmailVerificationResendWait is the default minimum time between verification emails
+	EmailVerificationResendWait = time.Hour
 )
 
+// EmailVerificationConfig limits verification emails. Zero fields fall back
+// to the package defaults.
+type EmailVerificationConfig struct {
+	MaxAttempts int           // Verification emails sent before further sends are refused
+	ResendWait  time.Duration // Minimum time between verification emails
+}
+
 // EmailVerificationService handles email verification logic
 type EmailVerificationService struct {
 	client         *ent.Client
 	emailService   email.EmailService
 	securityLogger *SecurityLogger
+	config         EmailVerificationConfig
 }
 
 // NewEmailVerificationService creates a new email verification service
-func NewEmailVerificationService(client *ent.Client, emailService email.EmailService, securityLogger *SecurityLogger) *EmailVerificationService {
+func NewEmailVerificationService(client *ent.Client, emailService email.EmailService, securityLogger *SecurityLogger, verificationConfig EmailVerificationConfig) *EmailVerificationService {
+	if verificationConfig.MaxAttempts <= 0 {
+		verificationConfig.MaxAttempts = MaxEmailVerificationAttempts
+	}
+	if verificationConfig.ResendWait <= 0 {
+		verificationConfig.ResendWait = EmailVerificationResendWait
+	}
+
 	return &EmailVerificationService{
 		client:         client,
 		emailService:   emailService,
 		securityLogger: securityLogger,
+		config:         verificationConfig,
+	}
+}
+
+// nextResendAt returns when another verification email may be sent, based on
+// when the current token was issued
+func (s *EmailVerificationService) nextResendAt(u *ent.User) time.Time {
+	if u.EmailVerificationExpiresAt == nil {
+		return time.Time{}
 	}
+	return u.EmailVerificationExpiresAt.Add(-EmailVerificationTokenDuration).Add(s.config.ResendWait)
 }
 
 // SendVerificationEmail sends a verification email to the user
@@ -64,7 +95,7 @@ func (s *EmailVerificationService) SendVerificationEmail(ctx context.Context, us
 	}
 
 	// Check verification attempts
-	if foundUser.EmailVerificationAttempts >= MaxEmailVerificationAttempts {
+	if foundUser.EmailVerificationAttempts >= s.config.MaxAttempts {
 		return status.Error(codes.ResourceExhausted, "maximum verification attempts exceeded")
 	}
 
@@ -77,7 +108,7 @@ func (s *EmailVerificationService) SendVerificationEmail(ctx context.Context, us
 	// Update user with verification token
 	expiresAt := time.Now().Add(EmailVerificationTokenDuration)
 	updatedUser, err := foundUser.Update().
-		SetEmailVerificationToken(token).
+		SetEmailVerificationToken(auth.HashToken(token)).
 		SetEmailVerificationExpiresAt(expiresAt).
 		AddEmailVerificationAttempts(1).
 		Save(ctx)
@@ -111,19 +142,25 @@ func (s *EmailVerificationService) VerifyEmail(ctx context.Context, token string
 	foundUser, err := s.client.User.Query().
 		Where(
 			user.And(
-				user.EmailVerificationTokenEQ(token),
+				user.EmailVerificationTokenEQ(auth.HashToken(token)),
 				user.EmailVerifiedEQ(false),
 			),
 		).
 		Only(ctx)
 
-	if err != nil {
-		if ent.IsNotFound(err) {
-			return status.Error(codes.NotFound, "invalid or expired verification token")
-		}
+	if err != nil && !ent.IsNotFound(err) {
 		return status.Error(codes.Internal, "failed to find user")
 	}
 
+	// Compare even on a miss so unknown tokens take as long as known ones
+	var storedToken string
+	if foundUser != nil {
+		storedToken = foundUser.EmailVerificationToken
+	}
+	if !auth.TokenMatches(token, storedToken) {
+		return status.Error(codes.NotFound, "invalid or expired verification token")
+	}
+
 	// Check if token is expired
 	if foundUser.EmailVerificationExpiresAt != nil && foundUser.EmailVerificationExpiresAt.Before(time.Now()) {
 		return status.Error(codes.DeadlineExceeded, "verification token has expired")
@@ -176,16 +213,13 @@ func (s *EmailVerificationService) ResendVerificationEmail(ctx context.Context,
 		return status.Error(codes.FailedPrecondition, "email is already verified")
 	}
 
-	// Check rate limiting (can only resend once per hour)
-	if foundUser.EmailVerificationExpiresAt != nil {
-		timeUntilNextResend := foundUser.EmailVerificationExpiresAt.Add(-EmailVerificationTokenDuration).Add(1 * time.Hour)
-		if time.Now().Before(timeUntilNextResend) {
-			return status.Error(codes.ResourceExhausted, "please wait before requesting another verification email")
-		}
+	// Check rate limiting (one resend per configured wait)
+	if time.Now().Before(s.nextResendAt(foundUser)) {
+		return status.Error(codes.ResourceExhausted, "please wait before requesting another verification email")
 	}
 
 	// Check verification attempts
-	if foundUser.EmailVerificationAttempts >= MaxEmailVerificationAttempts {
+	if foundUser.EmailVerificationAttempts >= s.config.MaxAttempts {
 		return status.Error(codes.ResourceExhausted, "maximum verification attempts exceeded")
 	}
 
@@ -198,7 +232,7 @@ func (s *EmailVerificationService) ResendVerificationEmail(ctx context.Context,
 	// Update user with new verification token
 	expiresAt := time.Now().Add(EmailVerificationTokenDuration)
 	updatedUser, err := foundUser.Update().
-		SetEmailVerificationToken(token).
+		SetEmailVerificationToken(auth.HashToken(token)).
 		SetEmailVerificationExpiresAt(expiresAt).
 		AddEmailVerificationAttempts(1).
 		Save(ctx)
@@ -239,7 +273,7 @@ func (s *EmailVerificationService) GetVerificationStatus(ctx context.Context, us
 	verificationStatus := &EmailVerificationStatus{
 		EmailVerified: foundUser.EmailVerified,
 		Attempts:      foundUser.EmailVerificationAttempts,
-		MaxAttempts:   MaxEmailVerificationAttempts,
+		MaxAttempts:   s.config.MaxAttempts,
 	}
 
 	if foundUser.EmailVerificationExpiresAt != nil {
@@ -247,14 +281,135 @@ func (s *EmailVerificationService) GetVerificationStatus(ctx context.Context, us
 		verificationStatus.IsExpired = foundUser.EmailVerificationExpiresAt.Before(time.Now())
 	}
 
-	verificationStatus.CanResend = !foundUser.EmailVerified &&
-		foundUser.EmailVerificationAttempts < MaxEmailVerificationAttempts &&
-		(foundUser.EmailVerificationExpiresAt == nil ||
-			time.Now().After(foundUser.EmailVerificationExpiresAt.Add(-EmailVerificationTokenDuration).Add(1*time.Hour)))
+	// Only the resend wait can be counted down; verified users and exhausted
+	// attempts never become resendable by waiting
+	if !foundUser.EmailVerified && foundUser.EmailVerificationAttempts < s.config.MaxAttempts {
+		wait := time.Until(s.nextResendAt(foundUser))
+		verificationStatus.CanResend = wait <= 0
+		if wait > 0 {
+			// Round up so clients never retry a moment too early
+			verificationStatus.SecondsUntilResend = int64((wait + time.Second - 1) / time.Second)
+		}
+	}
 
 	return verificationStatus, nil
 }
 
+// RequestEmailChange stores newEmail as the user's pending email and sends a
+// confirmation link to it. The account keeps its current email, including for
+// login, until ConfirmEmailChange is called with the token.
+func (s *EmailVerificationService) RequestEmailChange(ctx context.Context, userID, newEmail string) error {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid user ID")
+	}
+
+	if err := auth.ValidateEmail(newEmail); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid email: %v", err)
+	}
+	newEmail = strings.ToLower(newEmail)
+
+	foundUser, err := s.client.User.Get(ctx, userUUID)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return status.Error(codes.NotFound, "user not found")
+		}
+		return status.Error(codes.Internal, "failed to get user")
+	}
+
+	if newEmail == foundUser.Email {
+		return status.Error(codes.InvalidArgument, "new email must differ from the current email")
+	}
+
+	taken, err := s.client.User.Query().Where(user.EmailEQ(newEmail)).Exist(ctx)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to check email availability")
+	}
+	if taken {
+		return status.Error(codes.AlreadyExists, "email is already in use")
+	}
+
+	token, err := s.generateVerificationToken()
+	if err != nil {
+		return status.Error(codes.Internal, "failed to generate email change token")
+	}
+
+	expiresAt := time.Now().Add(EmailChangeTokenDuration)
+	updatedUser, err := foundUser.Update().
+		SetPendingEmail(newEmail).
+		SetEmailChangeToken(auth.HashToken(token)).
+		SetEmailChangeExpiresAt(expiresAt).
+		Save(ctx)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to update user")
+	}
+
+	if err := s.emailService.SendEmailChangeConfirmation(ctx, updatedUser, newEmail, token, expiresAt); err != nil {
+		return status.Error(codes.Internal, "failed to send email change confirmation")
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange replaces the user's email with the pending one and
+// notifies the previous address
+func (s *EmailVerificationService) ConfirmEmailChange(ctx context.Context, token string) error {
+	if token == "" {
+		return status.Error(codes.InvalidArgument, "email change token is required")
+	}
+
+	foundUser, err := s.client.User.Query().
+		Where(
+			user.EmailChangeTokenEQ(auth.HashToken(token)),
+			user.PendingEmailNotNil(),
+		).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return status.Error(codes.NotFound, "invalid or expired email change token")
+		}
+		return status.Error(codes.Internal, "failed to find user")
+	}
+
+	if foundUser.EmailChangeExpiresAt != nil && foundUser.EmailChangeExpiresAt.Before(time.Now()) {
+		return status.Error(codes.DeadlineExceeded, "email change token has expired")
+	}
+
+	// The address may have been registered since the change was requested
+	taken, err := s.client.User.Query().Where(user.EmailEQ(*foundUser.PendingEmail)).Exist(ctx)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to check email availability")
+	}
+	if taken {
+		return status.Error(codes.AlreadyExists, "email is already in use")
+	}
+
+	oldEmail := foundUser.Email
+	updatedUser, err := foundUser.Update().
+		SetEmail(*foundUser.PendingEmail).
+		SetEmailVerified(true). // Confirming the link proves ownership of the new address
+		ClearPendingEmail().
+		ClearEmailChangeToken().
+		ClearEmailChangeExpiresAt().
+		Save(ctx)
+	if err != nil {
+		if ent.IsConstraintError(err) {
+			return status.Error(codes.AlreadyExists, "email is already in use")
+		}
+		return status.Error(codes.Internal, "failed to change email")
+	}
+
+	if err := s.emailService.SendEmailChangedNotification(ctx, updatedUser, oldEmail); err != nil {
+		// The change is complete even if the notice fails
+	}
+
+	if err := s.securityLogger.LogSecurityAlert(ctx, updatedUser.ID, fmt.Sprintf("Email changed from %s to %s", oldEmail, updatedUser.Email)); err != nil {
+		// Log error but don't fail the operation
+	}
+
+	return nil
+}
+
 // generateVerificationToken generates a cryptographically secure verification token
 func (s *EmailVerificationService) generateVerificationToken() (string, error) {
 	bytes := make([]byte, EmailVerificationTokenLength)
@@ -272,9 +427,12 @@ type EmailVerificationStatus struct {
 	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
 	IsExpired     bool       `json:"is_expired"`
 	CanResend     bool       `json:"can_resend"`
+	// SecondsUntilResend counts down the resend wait; 0 when CanResend is
+	// true or when waiting would not help
+	SecondsUntilResend int64 `json:"seconds_until_resend"`
 }
 
-// CleanupExpiredTokens removes expired email verification tokens
+// CleanupExpiredTokens removes expired email verification tokens and pending email changes
 // This should be run periodically as a background job
 func (s *EmailVerificationService) CleanupExpiredTokens(ctx context.Context) error {
 	_, err := s.client.User.Update().
@@ -287,6 +445,19 @@ func (s *EmailVerificationService) CleanupExpiredTokens(ctx context.Context) err
 		ClearEmailVerificationToken().
 		ClearEmailVerificationExpiresAt().
 		Save(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.User.Update().
+		Where(
+			user.EmailChangeTokenNotNil(),
+			user.EmailChangeExpiresAtLT(time.Now()),
+		).
+		ClearPendingEmail().
+		ClearEmailChangeToken().
+		ClearEmailChangeExpiresAt().
+		Save(ctx)
 
 	return err
 }
@@ -12,6 +12,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"github.com/gurkanbulca/taskmaster/ent/generated/enttest"
+	"github.com/gurkanbulca/taskmaster/pkg/auth"
 	"github.com/gurkanbulca/taskmaster/pkg/email"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -26,7 +27,7 @@ func TestEmailVerificationService_SendVerificationEmail(t *testing.T) {
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
 
-	service := NewEmailVerificationService(client, mockEmailService, securityLogger)
+	service := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
 
 	// Create test user
 	testUser, err := client.User.Create().
@@ -138,7 +139,7 @@ func TestEmailVerificationService_VerifyEmail(t *testing.T) {
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
 
-	service := NewEmailVerificationService(client, mockEmailService, securityLogger)
+	service := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
 
 	// Create test user with verification token
 	validToken := "valid-verification-token-12345678901234567890"
@@ -149,7 +150,7 @@ func TestEmailVerificationService_VerifyEmail(t *testing.T) {
 		SetUsername("testuser").
 		SetPasswordHash("hash").
 		SetEmailVerified(false).
-		SetEmailVerificationToken(validToken).
+		SetEmailVerificationToken(auth.HashToken(validToken)).
 		SetEmailVerificationExpiresAt(time.Now().Add(24 * time.Hour)).
 		Save(context.Background())
 	require.NoError(t, err)
@@ -160,7 +161,7 @@ func TestEmailVerificationService_VerifyEmail(t *testing.T) {
 		SetUsername("expireduser").
 		SetPasswordHash("hash").
 		SetEmailVerified(false).
-		SetEmailVerificationToken(expiredToken).
+		SetEmailVerificationToken(auth.HashToken(expiredToken)).
 		SetEmailVerificationExpiresAt(time.Now().Add(-1 * time.Hour)).
 		Save(context.Background())
 	require.NoError(t, err)
@@ -227,7 +228,84 @@ func TestEmailVerificationService_VerifyEmail(t *testing.T) {
 	unchangedUser, err := client.User.Get(context.Background(), expiredUser.ID)
 	require.NoError(t, err)
 	assert.False(t, unchangedUser.EmailVerified)
-	assert.Equal(t, expiredToken, unchangedUser.EmailVerificationToken)
+	assert.Equal(t, auth.HashToken(expiredToken), unchangedUser.EmailVerificationToken)
+}
+
+func TestEmailVerificationService_VerifyEmail_Mismatch(t *testing.T) {
+	// Setup
+	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	securityLogger := NewSecurityLogger(NewSecurityService(client))
+	service := NewEmailVerificationService(client, email.NewMockEmailService(), securityLogger, EmailVerificationConfig{})
+
+	validToken := "valid-verification-token-12345678901234567890"
+	_, err := client.User.Create().
+		SetEmail("test@example.com").
+		SetUsername("testuser").
+		SetPasswordHash("hash").
+		SetEmailVerified(false).
+		SetEmailVerificationToken(auth.HashToken(validToken)).
+		SetEmailVerificationExpiresAt(time.Now().Add(24 * time.Hour)).
+		Save(context.Background())
+	require.NoError(t, err)
+
+	// Unknown and near-miss tokens are indistinguishable to the caller
+	for name, token := range map[string]string{
+		"unknown token":   "unknown-verification-token-123456789012345678",
+		"near-miss token": validToken[:len(validToken)-1] + "1",
+		"stored hash":     auth.HashToken(validToken),
+	} {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			err := service.VerifyEmail(context.Background(), token)
+			assert.Less(t, time.Since(start), time.Second)
+
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, codes.NotFound, st.Code())
+			assert.Equal(t, "invalid or expired verification token", st.Message())
+		})
+	}
+}
+
+func TestEmailVerificationService_TokenStoredHashed(t *testing.T) {
+	// Setup
+	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	mockEmailService := email.NewMockEmailService()
+	securityLogger := NewSecurityLogger(NewSecurityService(client))
+	service := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+
+	testUser, err := client.User.Create().
+		SetEmail("test@example.com").
+		SetUsername("testuser").
+		SetPasswordHash("hash").
+		SetEmailVerified(false).
+		Save(context.Background())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, service.SendVerificationEmail(ctx, testUser.ID.String()))
+
+	// The email carries the raw token while only its hash is stored
+	rawToken := mockEmailService.GetLastSentEmail().Data.Token
+	require.NotEmpty(t, rawToken)
+
+	stored, err := client.User.Get(ctx, testUser.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, rawToken, stored.EmailVerificationToken)
+	assert.Equal(t, auth.HashToken(rawToken), stored.EmailVerificationToken)
+
+	// Presenting the stored hash fails, the raw token verifies
+	err = service.VerifyEmail(ctx, stored.EmailVerificationToken)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	require.NoError(t, service.VerifyEmail(ctx, rawToken))
+	verified, err := client.User.Get(ctx, testUser.ID)
+	require.NoError(t, err)
+	assert.True(t, verified.EmailVerified)
 }
 
 func TestEmailVerificationService_ResendVerificationEmail(t *testing.T) {
@@ -239,7 +317,7 @@ func TestEmailVerificationService_ResendVerificationEmail(t *testing.T) {
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
 
-	service := NewEmailVerificationService(client, mockEmailService, securityLogger)
+	service := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
 
 	// Create test user
 	testUser, err := client.User.Create().
@@ -349,6 +427,83 @@ func TestEmailVerificationService_ResendVerificationEmail(t *testing.T) {
 	}
 }
 
+func TestEmailVerificationService_ZeroConfigUsesDefaults(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	mockEmailService := email.NewMockEmailService()
+	securityLogger := NewSecurityLogger(NewSecurityService(client))
+	service := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+	assert.Equal(t, MaxEmailVerificationAttempts, service.config.MaxAttempts)
+	assert.Equal(t, EmailVerificationResendWait, service.config.ResendWait)
+
+	testUser := createTestUser(t, client)
+	require.NoError(t, service.SendVerificationEmail(context.Background(), testUser.ID.String()))
+	assert.Len(t, mockEmailService.GetSentEmails(), 1)
+}
+
+func TestEmailVerificationService_ConfiguredLimits(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	verificationConfig := EmailVerificationConfig{MaxAttempts: 3, ResendWait: time.Minute}
+
+	mockEmailService := email.NewMockEmailService()
+	securityLogger := NewSecurityLogger(NewSecurityService(client))
+	service := NewEmailVerificationService(client, mockEmailService, securityLogger, verificationConfig)
+
+	testUser, err := client.User.Create().
+		SetEmail("test@example.com").
+		SetUsername("testuser").
+		SetPasswordHash("hash").
+		SetEmailVerificationAttempts(1).
+		SetEmailVerificationToken("old-token").
+		Save(context.Background())
+	require.NoError(t, err)
+
+	// issuedAgo backdates the current token
+	issuedAgo := func(d time.Duration) {
+		_, err := testUser.Update().
+			SetEmailVerificationExpiresAt(time.Now().Add(EmailVerificationTokenDuration - d)).
+			Save(context.Background())
+		require.NoError(t, err)
+	}
+
+	// Two minutes is past the configured wait, though well within the default hour
+	issuedAgo(2 * time.Minute)
+	require.NoError(t, service.ResendVerificationEmail(context.Background(), testUser.ID.String()))
+
+	// Straight away is too soon
+	err = service.ResendVerificationEmail(context.Background(), testUser.ID.String())
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "please wait")
+
+	verificationStatus, err := service.GetVerificationStatus(context.Background(), testUser.ID.String())
+	require.NoError(t, err)
+	assert.False(t, verificationStatus.CanResend)
+	assert.Greater(t, verificationStatus.SecondsUntilResend, int64(0))
+	assert.LessOrEqual(t, verificationStatus.SecondsUntilResend, int64(60))
+
+	// The third email uses up the configured attempts
+	issuedAgo(2 * time.Minute)
+	require.NoError(t, service.ResendVerificationEmail(context.Background(), testUser.ID.String()))
+
+	issuedAgo(2 * time.Minute)
+	err = service.ResendVerificationEmail(context.Background(), testUser.ID.String())
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "maximum verification attempts")
+
+	verificationStatus, err = service.GetVerificationStatus(context.Background(), testUser.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, 3, verificationStatus.Attempts)
+	assert.Equal(t, 3, verificationStatus.MaxAttempts)
+	assert.False(t, verificationStatus.CanResend)
+	assert.Zero(t, verificationStatus.SecondsUntilResend, "waiting won't help once attempts are used up")
+	assert.Len(t, mockEmailService.GetSentEmails(), 2)
+}
+
 func TestEmailVerificationService_GetVerificationStatus(t *testing.T) {
 	// Setup
 	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
@@ -358,7 +513,7 @@ func TestEmailVerificationService_GetVerificationStatus(t *testing.T) {
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
 
-	service := NewEmailVerificationService(client, mockEmailService, securityLogger)
+	service := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
 
 	// Create test users with different states
 	verifiedUser, err := client.User.Create().
@@ -376,7 +531,7 @@ func TestEmailVerificationService_GetVerificationStatus(t *testing.T) {
 		SetEmailVerified(false).
 		SetEmailVerificationAttempts(2).
 		SetEmailVerificationToken("token").
-		SetEmailVerificationExpiresAt(time.Now().Add(10 * time.Hour)).
+		SetEmailVerificationExpiresAt(time.Now().Add(EmailVerificationTokenDuration - 30*time.Minute)). // Sent 30 minutes ago
 		Save(context.Background())
 	require.NoError(t, err)
 
@@ -395,6 +550,7 @@ func TestEmailVerificationService_GetVerificationStatus(t *testing.T) {
 		name           string
 		userID         string
 		expectedStatus EmailVerificationStatus
+		throttled      bool // Expect a countdown to the end of the resend wait
 		wantErr        bool
 	}{
 		{
@@ -419,7 +575,8 @@ func TestEmailVerificationService_GetVerificationStatus(t *testing.T) {
 				IsExpired:     false,
 				CanResend:     false, // Can't resend yet (rate limited)
 			},
-			wantErr: false,
+			throttled: true,
+			wantErr:   false,
 		},
 		{
 			name:   "expired token",
@@ -454,6 +611,13 @@ func TestEmailVerificationService_GetVerificationStatus(t *testing.T) {
 				assert.Equal(t, tt.expectedStatus.MaxAttempts, status.MaxAttempts)
 				assert.Equal(t, tt.expectedStatus.IsExpired, status.IsExpired)
 				assert.Equal(t, tt.expectedStatus.CanResend, status.CanResend)
+				if tt.throttled {
+					// The hour-long wait started 30 minutes ago
+					assert.Greater(t, status.SecondsUntilResend, int64(0))
+					assert.LessOrEqual(t, status.SecondsUntilResend, int64(30*60))
+				} else {
+					assert.Zero(t, status.SecondsUntilResend)
+				}
 			}
 		})
 	}
@@ -468,7 +632,7 @@ func TestEmailVerificationService_CleanupExpiredTokens(t *testing.T) {
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
 
-	service := NewEmailVerificationService(client, mockEmailService, securityLogger)
+	service := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
 
 	// Create users with expired and valid tokens
 	expiredUser1, err := client.User.Create().
@@ -520,6 +684,106 @@ func TestEmailVerificationService_CleanupExpiredTokens(t *testing.T) {
 	assert.NotNil(t, updatedValid.EmailVerificationExpiresAt)
 }
 
+func TestEmailVerificationService_EmailChange(t *testing.T) {
+	// Setup
+	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	mockEmailService := email.NewMockEmailService()
+	securityService := NewSecurityService(client)
+	securityLogger := NewSecurityLogger(securityService)
+
+	service := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
+	ctx := context.Background()
+
+	testUser, err := client.User.Create().
+		SetEmail("old@example.com").
+		SetUsername("changer").
+		SetPasswordHash("hash").
+		Save(ctx)
+	require.NoError(t, err)
+
+	_, err = client.User.Create().
+		SetEmail("taken@example.com").
+		SetUsername("other").
+		SetPasswordHash("hash").
+		Save(ctx)
+	require.NoError(t, err)
+
+	t.Run("address already in use", func(t *testing.T) {
+		err := service.RequestEmailChange(ctx, testUser.ID.String(), "Taken@example.com")
+		require.Error(t, err)
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.AlreadyExists, st.Code())
+	})
+
+	t.Run("confirmed change", func(t *testing.T) {
+		mockEmailService.Clear()
+		require.NoError(t, service.RequestEmailChange(ctx, testUser.ID.String(), "New@example.com"))
+
+		// The confirmation goes to the new address and the old one stays in effect
+		confirmation := mockEmailService.GetLastSentEmail()
+		require.NotNil(t, confirmation)
+		assert.Equal(t, "new@example.com", confirmation.To)
+		assert.Equal(t, "email_change", confirmation.Template)
+
+		pending, err := client.User.Get(ctx, testUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "old@example.com", pending.Email)
+		require.NotNil(t, pending.PendingEmail)
+		assert.Equal(t, "new@example.com", *pending.PendingEmail)
+		assert.Equal(t, auth.HashToken(confirmation.Data.Token), pending.EmailChangeToken)
+		require.NotNil(t, pending.EmailChangeExpiresAt)
+		assert.True(t, pending.EmailChangeExpiresAt.Equal(confirmation.Data.ExpiresAt), "the email states the stored expiry")
+
+		require.NoError(t, service.ConfirmEmailChange(ctx, confirmation.Data.Token))
+
+		changed, err := client.User.Get(ctx, testUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", changed.Email)
+		assert.True(t, changed.EmailVerified)
+		assert.Nil(t, changed.PendingEmail)
+		assert.Empty(t, changed.EmailChangeToken)
+
+		// The previous address is told about the change
+		notice := mockEmailService.GetLastSentEmail()
+		assert.Equal(t, "old@example.com", notice.To)
+		assert.Equal(t, "email_changed", notice.Template)
+
+		// The token can't be reused
+		err = service.ConfirmEmailChange(ctx, confirmation.Data.Token)
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.NotFound, st.Code())
+	})
+
+	t.Run("expired change", func(t *testing.T) {
+		mockEmailService.Clear()
+		require.NoError(t, service.RequestEmailChange(ctx, testUser.ID.String(), "later@example.com"))
+		token := mockEmailService.GetLastSentEmail().Data.Token
+
+		_, err := client.User.UpdateOneID(testUser.ID).
+			SetEmailChangeExpiresAt(time.Now().Add(-time.Minute)).
+			Save(ctx)
+		require.NoError(t, err)
+
+		err = service.ConfirmEmailChange(ctx, token)
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.DeadlineExceeded, st.Code())
+
+		unchanged, err := client.User.Get(ctx, testUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", unchanged.Email)
+
+		// Cleanup drops the stale pending change
+		require.NoError(t, service.CleanupExpiredTokens(ctx))
+		cleared, err := client.User.Get(ctx, testUser.ID)
+		require.NoError(t, err)
+		assert.Nil(t, cleared.PendingEmail)
+		assert.Empty(t, cleared.EmailChangeToken)
+	})
+}
+
 func TestEmailVerificationService_TokenGeneration(t *testing.T) {
 	// Setup
 	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
@@ -529,7 +793,7 @@ func TestEmailVerificationService_TokenGeneration(t *testing.T) {
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
 
-	service := NewEmailVerificationService(client, mockEmailService, securityLogger)
+	service := NewEmailVerificationService(client, mockEmailService, securityLogger, EmailVerificationConfig{})
 
 	// Test token generation
 	token1, err := service.generateVerificationToken()
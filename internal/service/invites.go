@@ -0,0 +1,237 @@
+// internal/service/invites.go
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/invite"
+	"github.com/gurkanbulca/taskmaster/ent/generated/user"
+	"github.com/gurkanbulca/taskmaster/internal/middleware"
+	"github.com/gurkanbulca/taskmaster/pkg/auth"
+	"github.com/gurkanbulca/taskmaster/pkg/authz"
+	"github.com/gurkanbulca/taskmaster/pkg/security"
+)
+
+const (
+	// InviteTokenLength is the length of invite tokens in bytes
+	InviteTokenLength = 32
+	// InviteDuration is how long an invite can be accepted
+	InviteDuration = 7 * 24 * time.Hour
+)
+
+// CreateInvite emails someone a link to create an account with the given
+// role (admin only)
+func (s *AuthService) CreateInvite(ctx context.Context, req *authv1.CreateInviteRequest) (*authv1.CreateInviteResponse, error) {
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+	if !authz.Can(userRole, authz.UserManage) {
+		return nil, status.Error(codes.PermissionDenied, "admin access required")
+	}
+
+	adminID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+	adminUUID, err := uuid.Parse(adminID)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if err := auth.ValidateEmail(email); err != nil {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid email: %v", err))
+	}
+
+	role := invite.RoleUser
+	if req.Role != authv1.UserRole_USER_ROLE_UNSPECIFIED {
+		role = invite.Role(convertProtoRoleToString(req.Role))
+		if err := invite.RoleValidator(role); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid role")
+		}
+	}
+
+	exists, err := s.client.User.Query().Where(user.EmailEQ(email)).Exist(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to check user existence")
+	}
+	if exists {
+		return nil, status.Error(codes.AlreadyExists, "email is already in use")
+	}
+
+	token, err := generateInviteToken()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create invite")
+	}
+
+	inv, err := s.client.Invite.Create().
+		SetEmail(email).
+		SetRole(role).
+		SetTokenHash(auth.HashToken(token)).
+		SetInvitedBy(adminUUID).
+		SetExpiresAt(time.Now().Add(InviteDuration)).
+		Save(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to create invite")
+	}
+
+	if s.emailService != nil {
+		if err := s.emailService.SendInviteEmail(ctx, inv, token); err != nil {
+			s.logger.Error("failed to send invite email", "invite_id", inv.ID, "error", err)
+		}
+	}
+
+	if err := s.securityLogger.LogCurrentUserFromContext(ctx, security.EventTypeSecurityAlert,
+		fmt.Sprintf("Invited %s as %s", email, role), security.SeverityMedium); err != nil {
+		// Log error but don't fail
+	}
+
+	return &authv1.CreateInviteResponse{Invite: convertInviteToProto(inv)}, nil
+}
+
+// AcceptInvite creates the invited account and signs it in. The account gets
+// the invite's email, already verified, and its role. Each invite works once.
+func (s *AuthService) AcceptInvite(ctx context.Context, req *authv1.AcceptInviteRequest) (*authv1.LoginResponse, error) {
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "invite token is required")
+	}
+
+	inv, err := s.client.Invite.Query().
+		Where(invite.TokenHashEQ(auth.HashToken(req.Token))).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "invalid or expired invite")
+		}
+		return nil, status.Error(codes.Internal, "failed to get invite")
+	}
+
+	if inv.Used {
+		return nil, status.Error(codes.FailedPrecondition, "invite has already been used")
+	}
+	if time.Now().After(inv.ExpiresAt) {
+		return nil, status.Error(codes.DeadlineExceeded, "invite has expired")
+	}
+
+	username := strings.ToLower(strings.TrimSpace(req.Username))
+	if err := auth.ValidateUsername(username); err != nil {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("invalid username: %v", err))
+	}
+	if req.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "password is required")
+	}
+	if err := s.passwordManager.CheckStrength(req.Password, username, inv.Email, req.FirstName, req.LastName); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	exists, err := s.client.User.Query().
+		Where(user.Or(user.EmailEQ(inv.Email), user.UsernameEQ(username))).
+		Exist(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to check user existence")
+	}
+	if exists {
+		return nil, status.Error(codes.AlreadyExists, "user with this email or username already exists")
+	}
+
+	hashedPassword, err := s.passwordManager.HashPassword(req.Password)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	newUser, err := s.redeemInvite(ctx, inv, username, hashedPassword, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, newUser, newUser.Email, false)
+}
+
+// redeemInvite marks the invite used and creates its account in one
+// transaction, so concurrent accepts of the same invite create one account
+func (s *AuthService) redeemInvite(ctx context.Context, inv *ent.Invite, username, passwordHash string, req *authv1.AcceptInviteRequest) (*ent.User, error) {
+	tx, err := s.client.Tx(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to accept invite")
+	}
+
+	rollback := func() {
+		if rerr := tx.Rollback(); rerr != nil {
+			s.logger.Error("failed to roll back invite acceptance", "error", rerr)
+		}
+	}
+
+	now := time.Now()
+	n, err := tx.Invite.Update().
+		Where(invite.IDEQ(inv.ID), invite.UsedEQ(false)).
+		SetUsed(true).
+		SetUsedAt(now).
+		Save(ctx)
+	if err != nil {
+		rollback()
+		return nil, status.Error(codes.Internal, "failed to accept invite")
+	}
+	if n == 0 {
+		rollback()
+		return nil, status.Error(codes.FailedPrecondition, "invite has already been used")
+	}
+
+	newUser, err := tx.User.Create().
+		SetEmail(inv.Email).
+		SetUsername(username).
+		SetPasswordHash(passwordHash).
+		SetFirstName(req.FirstName).
+		SetLastName(req.LastName).
+		SetRole(user.Role(inv.Role)).
+		SetIsActive(true).
+		SetEmailVerified(true).
+		SetPasswordChangedAt(now).
+		SetEmailNotificationsEnabled(true).
+		SetSecurityNotificationsEnabled(s.securityConfig.EnableSecurityNotifications).
+		SetLocale(middleware.GetLocaleFromContext(ctx)).
+		Save(ctx)
+	if err != nil {
+		rollback()
+		if ent.IsConstraintError(err) {
+			return nil, status.Error(codes.AlreadyExists, "user with this email or username already exists")
+		}
+		return nil, status.Error(codes.Internal, "failed to create user")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, status.Error(codes.Internal, "failed to accept invite")
+	}
+
+	return newUser, nil
+}
+
+// generateInviteToken generates a cryptographically secure invite token
+func generateInviteToken() (string, error) {
+	bytes := make([]byte, InviteTokenLength)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func convertInviteToProto(inv *ent.Invite) *authv1.Invite {
+	return &authv1.Invite{
+		Id:        inv.ID.String(),
+		Email:     inv.Email,
+		Role:      convertRoleToProto(user.Role(inv.Role)),
+		InvitedBy: inv.InvitedBy.String(),
+		Used:      inv.Used,
+		ExpiresAt: timestamppb.New(inv.ExpiresAt),
+		CreatedAt: timestamppb.New(inv.CreatedAt),
+	}
+}
@@ -0,0 +1,120 @@
+// internal/service/invites_test.go
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/invite"
+	"github.com/gurkanbulca/taskmaster/ent/generated/user"
+	"github.com/gurkanbulca/taskmaster/pkg/auth"
+	"github.com/gurkanbulca/taskmaster/pkg/email"
+)
+
+func TestAuthService_Invites(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	admin := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	mockEmailService := email.NewMockEmailService()
+	authService.SetEmailService(mockEmailService)
+	adminCtx := userContext(admin.ID.String(), "admin")
+
+	// createInvite invites the address and returns the emailed token
+	createInvite := func(t *testing.T, address string, role authv1.UserRole) string {
+		resp, err := authService.CreateInvite(adminCtx, &authv1.CreateInviteRequest{Email: address, Role: role})
+		require.NoError(t, err)
+		assert.Equal(t, address, resp.Invite.Email)
+		assert.Equal(t, admin.ID.String(), resp.Invite.InvitedBy)
+
+		sent := mockEmailService.SentEmails[len(mockEmailService.SentEmails)-1]
+		require.Equal(t, "invite", sent.Template)
+		assert.Equal(t, address, sent.To)
+		return sent.Data.Token
+	}
+
+	t.Run("accept valid invite", func(t *testing.T) {
+		token := createInvite(t, "manager@example.com", authv1.UserRole_USER_ROLE_MANAGER)
+
+		resp, err := authService.AcceptInvite(context.Background(), &authv1.AcceptInviteRequest{
+			Token:     token,
+			Username:  "newmanager",
+			Password:  "Invited!Pass2024x",
+			FirstName: "Nora",
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, resp.AccessToken)
+		assert.NotEmpty(t, resp.RefreshToken)
+		assert.Equal(t, "manager@example.com", resp.User.Email)
+		assert.Equal(t, authv1.UserRole_USER_ROLE_MANAGER, resp.User.Role)
+		assert.True(t, resp.User.EmailVerified)
+
+		inv, err := client.Invite.Query().Where(invite.EmailEQ("manager@example.com")).Only(context.Background())
+		require.NoError(t, err)
+		assert.True(t, inv.Used)
+		assert.NotNil(t, inv.UsedAt)
+	})
+
+	t.Run("used invite is rejected", func(t *testing.T) {
+		token := createInvite(t, "twice@example.com", authv1.UserRole_USER_ROLE_UNSPECIFIED)
+
+		_, err := authService.AcceptInvite(context.Background(), &authv1.AcceptInviteRequest{
+			Token: token, Username: "twice", Password: "Invited!Pass2024x",
+		})
+		require.NoError(t, err)
+
+		_, err = authService.AcceptInvite(context.Background(), &authv1.AcceptInviteRequest{
+			Token: token, Username: "twiceagain", Password: "Invited!Pass2024x",
+		})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		count, err := client.User.Query().Where(user.UsernameEQ("twiceagain")).Count(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("expired invite is rejected", func(t *testing.T) {
+		token := "expired-invite-token"
+		client.Invite.Create().
+			SetEmail("late@example.com").
+			SetTokenHash(auth.HashToken(token)).
+			SetInvitedBy(admin.ID).
+			SetExpiresAt(time.Now().Add(-time.Hour)).
+			SaveX(context.Background())
+
+		_, err := authService.AcceptInvite(context.Background(), &authv1.AcceptInviteRequest{
+			Token: token, Username: "late", Password: "Invited!Pass2024x",
+		})
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+		exists, err := client.User.Query().Where(user.EmailEQ("late@example.com")).Exist(context.Background())
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, err := authService.AcceptInvite(context.Background(), &authv1.AcceptInviteRequest{
+			Token: "no-such-token", Username: "nobody", Password: "Invited!Pass2024x",
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("existing email", func(t *testing.T) {
+		_, err := authService.CreateInvite(adminCtx, &authv1.CreateInviteRequest{Email: admin.Email})
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	})
+
+	t.Run("admin only", func(t *testing.T) {
+		_, err := authService.CreateInvite(userContext(admin.ID.String(), "manager"), &authv1.CreateInviteRequest{Email: "someone@example.com"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
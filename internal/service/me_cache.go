@@ -0,0 +1,113 @@
+// internal/service/me_cache.go
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/hook"
+)
+
+// maxMeCacheEntries bounds the GetMe cache; when full, expired entries are
+// dropped and, if that is not enough, the whole cache is cleared
+const maxMeCacheEntries = 10000
+
+// meCache holds recent GetMe responses by user ID. Entries are dropped after
+// the TTL and whenever the user changes; a read racing a write may still
+// cache the old state, but only for the TTL.
+type meCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]meCacheEntry
+	now     func() time.Time
+}
+
+type meCacheEntry struct {
+	resp      *authv1.GetMeResponse
+	expiresAt time.Time
+}
+
+func newMeCache(ttl time.Duration) *meCache {
+	return &meCache{
+		ttl:     ttl,
+		entries: make(map[string]meCacheEntry),
+		now:     time.Now,
+	}
+}
+
+// get returns a copy of the cached response for userID
+func (c *meCache) get(userID string) (*authv1.GetMeResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[userID]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, userID)
+		return nil, false
+	}
+	return proto.Clone(entry.resp).(*authv1.GetMeResponse), true
+}
+
+// set caches a copy of resp for userID
+func (c *meCache) set(userID string, resp *authv1.GetMeResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.entries) >= maxMeCacheEntries {
+		for id, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= maxMeCacheEntries {
+			clear(c.entries)
+		}
+	}
+
+	c.entries[userID] = meCacheEntry{
+		resp:      proto.Clone(resp).(*authv1.GetMeResponse),
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// invalidate drops the entry of userID
+func (c *meCache) invalidate(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, userID)
+}
+
+// invalidateAll drops every entry
+func (c *meCache) invalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
+
+// hook invalidates the entries of users changed through the client, so
+// profile, password, role and status changes show up on the next GetMe
+// however they are made. Bulk updates and deletes clear the whole cache.
+func (c *meCache) hook() ent.Hook {
+	return func(next ent.Mutator) ent.Mutator {
+		return hook.UserFunc(func(ctx context.Context, m *ent.UserMutation) (ent.Value, error) {
+			value, err := next.Mutate(ctx, m)
+
+			if id, ok := m.ID(); ok {
+				c.invalidate(id.String())
+			} else if !m.Op().Is(ent.OpCreate) {
+				c.invalidateAll()
+			}
+
+			return value, err
+		})
+	}
+}
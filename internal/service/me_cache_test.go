@@ -0,0 +1,101 @@
+// internal/service/me_cache_test.go
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/enttest"
+	"github.com/gurkanbulca/taskmaster/ent/generated/user"
+)
+
+func TestAuthService_GetMeCache(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	// A second client on the same database, without the cache's hook, makes
+	// changes the cache cannot see
+	unhooked := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	defer unhooked.Close()
+
+	testUser := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	authService.SetGetMeCacheTTL(time.Minute)
+	ctx := userContext(testUser.ID.String(), "user")
+
+	now := time.Now()
+	authService.meCache.now = func() time.Time { return now }
+
+	getMe := func(t *testing.T) *authv1.GetMeResponse {
+		resp, err := authService.GetMe(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("cached read", func(t *testing.T) {
+		assert.Equal(t, "Test", getMe(t).User.FirstName)
+
+		unhooked.User.UpdateOneID(testUser.ID).SetFirstName("Unseen").ExecX(context.Background())
+		assert.Equal(t, "Test", getMe(t).User.FirstName, "served from the cache")
+
+		now = now.Add(2 * time.Minute)
+		assert.Equal(t, "Unseen", getMe(t).User.FirstName, "expired entries are reloaded")
+	})
+
+	t.Run("profile update invalidates", func(t *testing.T) {
+		getMe(t)
+
+		_, err := authService.UpdateProfile(ctx, &authv1.UpdateProfileRequest{FirstName: "Updated"})
+		require.NoError(t, err)
+		assert.Equal(t, "Updated", getMe(t).User.FirstName)
+	})
+
+	t.Run("sensitive changes invalidate", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			change func()
+			check  func(t *testing.T, resp *authv1.GetMeResponse)
+		}{
+			{
+				name:   "role change",
+				change: func() { client.User.UpdateOneID(testUser.ID).SetRole(user.RoleManager).ExecX(context.Background()) },
+				check: func(t *testing.T, resp *authv1.GetMeResponse) {
+					assert.Equal(t, authv1.UserRole_USER_ROLE_MANAGER, resp.User.Role)
+				},
+			},
+			{
+				name: "password change",
+				change: func() {
+					client.User.UpdateOneID(testUser.ID).SetPasswordChangedAt(time.Now().Add(-72 * time.Hour)).ExecX(context.Background())
+				},
+				check: func(t *testing.T, resp *authv1.GetMeResponse) {
+					assert.Equal(t, int32(3), resp.PasswordAgeDays)
+				},
+			},
+			{
+				name: "deactivation by bulk update",
+				change: func() {
+					client.User.Update().Where(user.IDEQ(testUser.ID)).SetIsActive(false).ExecX(context.Background())
+				},
+				check: func(t *testing.T, resp *authv1.GetMeResponse) {
+					assert.False(t, resp.User.IsActive)
+				},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				getMe(t)
+				tt.change()
+				tt.check(t, getMe(t))
+			})
+		}
+	})
+}
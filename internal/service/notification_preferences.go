@@ -0,0 +1,137 @@
+// internal/service/notification_preferences.go
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/internal/middleware"
+)
+
+// Email categories stored as keys of the user's notification_preferences
+const (
+	notifyTaskAssigned   = "task_assigned"
+	notifyTaskDueSoon    = "task_due_soon"
+	notifySecurityAlerts = "security_alerts"
+	notifyProductUpdates = "product_updates"
+)
+
+// notificationDefaults applies to categories the user has never set.
+// Product updates are opt-in.
+var notificationDefaults = map[string]bool{
+	notifyTaskAssigned:   true,
+	notifyTaskDueSoon:    true,
+	notifySecurityAlerts: true,
+	notifyProductUpdates: false,
+}
+
+// notificationEnabled reports whether u wants emails of the category. The
+// account-wide switches still apply: security alerts need security
+// notifications enabled, every other category needs email notifications.
+func notificationEnabled(u *ent.User, category string) bool {
+	if category == notifySecurityAlerts {
+		if !u.SecurityNotificationsEnabled {
+			return false
+		}
+	} else if !u.EmailNotificationsEnabled {
+		return false
+	}
+
+	return notificationPreference(u, category)
+}
+
+// notificationPreference returns the user's choice for a category, ignoring
+// the account-wide switches
+func notificationPreference(u *ent.User, category string) bool {
+	if enabled, ok := u.NotificationPreferences[category].(bool); ok {
+		return enabled
+	}
+	return notificationDefaults[category]
+}
+
+// GetNotificationPreferences returns the current user's email categories
+func (s *AuthService) GetNotificationPreferences(ctx context.Context, _ *authv1.GetNotificationPreferencesRequest) (*authv1.GetNotificationPreferencesResponse, error) {
+	foundUser, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authv1.GetNotificationPreferencesResponse{
+		Preferences: convertNotificationPreferencesToProto(foundUser),
+	}, nil
+}
+
+// UpdateNotificationPreferences turns email categories on or off for the
+// current user; unset fields are left unchanged
+func (s *AuthService) UpdateNotificationPreferences(ctx context.Context, req *authv1.UpdateNotificationPreferencesRequest) (*authv1.UpdateNotificationPreferencesResponse, error) {
+	foundUser, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep any other keys already stored in the field
+	preferences := make(map[string]interface{}, len(foundUser.NotificationPreferences)+4)
+	for key, value := range foundUser.NotificationPreferences {
+		preferences[key] = value
+	}
+
+	for category, enabled := range map[string]*bool{
+		notifyTaskAssigned:   req.TaskAssigned,
+		notifyTaskDueSoon:    req.TaskDueSoon,
+		notifySecurityAlerts: req.SecurityAlerts,
+		notifyProductUpdates: req.ProductUpdates,
+	} {
+		if enabled != nil {
+			preferences[category] = *enabled
+		}
+	}
+
+	updatedUser, err := foundUser.Update().SetNotificationPreferences(preferences).Save(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to update notification preferences")
+	}
+
+	return &authv1.UpdateNotificationPreferencesResponse{
+		Preferences: convertNotificationPreferencesToProto(updatedUser),
+	}, nil
+}
+
+// currentUser loads the authenticated user
+func (s *AuthService) currentUser(ctx context.Context) (*ent.User, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "invalid user ID")
+	}
+
+	foundUser, err := s.client.User.Get(ctx, userUUID)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+		return nil, status.Error(codes.Internal, "failed to get user")
+	}
+
+	return foundUser, nil
+}
+
+func convertNotificationPreferencesToProto(u *ent.User) *authv1.NotificationPreferences {
+	return &authv1.NotificationPreferences{
+		TaskAssigned:   notificationPreference(u, notifyTaskAssigned),
+		TaskDueSoon:    notificationPreference(u, notifyTaskDueSoon),
+		SecurityAlerts: notificationPreference(u, notifySecurityAlerts),
+		ProductUpdates: notificationPreference(u, notifyProductUpdates),
+	}
+}
@@ -0,0 +1,131 @@
+// internal/service/notification_preferences_test.go
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
+	taskv1 "github.com/gurkanbulca/taskmaster/api/proto/task/v1/generated"
+	"github.com/gurkanbulca/taskmaster/internal/middleware"
+	"github.com/gurkanbulca/taskmaster/internal/repository"
+	"github.com/gurkanbulca/taskmaster/pkg/email"
+)
+
+func TestAuthService_NotificationPreferences(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	testUser := createTestUser(t, client)
+	testUser = testUser.Update().
+		SetNotificationPreferences(map[string]interface{}{"digest": "weekly"}).
+		SaveX(context.Background())
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	ctx := userContext(testUser.ID.String(), "user")
+
+	t.Run("defaults", func(t *testing.T) {
+		resp, err := authService.GetNotificationPreferences(ctx, &authv1.GetNotificationPreferencesRequest{})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&authv1.NotificationPreferences{
+			TaskAssigned:   true,
+			TaskDueSoon:    true,
+			SecurityAlerts: true,
+			ProductUpdates: false,
+		}, resp.Preferences))
+	})
+
+	t.Run("update some categories", func(t *testing.T) {
+		disabled, enabled := false, true
+		resp, err := authService.UpdateNotificationPreferences(ctx, &authv1.UpdateNotificationPreferencesRequest{
+			TaskAssigned:   &disabled,
+			ProductUpdates: &enabled,
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.Preferences.TaskAssigned)
+		assert.True(t, resp.Preferences.ProductUpdates)
+		assert.True(t, resp.Preferences.TaskDueSoon, "unset categories are left unchanged")
+		assert.True(t, resp.Preferences.SecurityAlerts, "unset categories are left unchanged")
+
+		got, err := authService.GetNotificationPreferences(ctx, &authv1.GetNotificationPreferencesRequest{})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(resp.Preferences, got.Preferences))
+
+		// Other keys stored in the field survive
+		updatedUser, err := client.User.Get(ctx, testUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "weekly", updatedUser.NotificationPreferences["digest"])
+		assert.Equal(t, false, updatedUser.NotificationPreferences[notifyTaskAssigned])
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		_, err := authService.GetNotificationPreferences(context.Background(), &authv1.GetNotificationPreferencesRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestNotificationPreferences_SuppressEmails(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	creator := createTestUser(t, client)
+	assignee := NewTestHelpers(t, client).CreateTestUser("assignee@example.com", "assignee", "TestPass123!")
+	mockEmail := email.NewMockEmailService()
+
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	authService.SetEmailService(mockEmail)
+	taskService := NewTaskService(repository.NewEntTaskRepository(client))
+	taskService.SetEmailService(mockEmail)
+
+	disabled := false
+	_, err := authService.UpdateNotificationPreferences(userContext(assignee.ID.String(), "user"), &authv1.UpdateNotificationPreferencesRequest{
+		TaskAssigned:   &disabled,
+		SecurityAlerts: &disabled,
+	})
+	require.NoError(t, err)
+
+	t.Run("task assigned", func(t *testing.T) {
+		mockEmail.Clear()
+
+		_, err := taskService.CreateTask(userContext(creator.ID.String(), "user"), &taskv1.CreateTaskRequest{
+			Title:      "Quiet task",
+			AssignedTo: assignee.ID.String(),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, mockEmail.GetSentEmails())
+	})
+
+	t.Run("account locked", func(t *testing.T) {
+		mockEmail.Clear()
+
+		ctx := context.WithValue(context.Background(), middleware.ContextKeyIPAddress, "127.0.0.1")
+		for i := 0; i < 3; i++ {
+			_, err := authService.Login(ctx, &authv1.LoginRequest{Email: assignee.Email, Password: "WrongPassword123!"})
+			require.Error(t, err)
+		}
+		NewTestHelpers(t, client).AssertUserLocked(assignee.ID)
+		assert.Empty(t, mockEmail.GetSentEmails())
+	})
+
+	t.Run("other users still notified", func(t *testing.T) {
+		mockEmail.Clear()
+
+		_, err := taskService.CreateTask(userContext(assignee.ID.String(), "user"), &taskv1.CreateTaskRequest{
+			Title:      "Loud task",
+			AssignedTo: creator.ID.String(),
+		})
+		require.NoError(t, err)
+
+		sent := mockEmail.GetSentEmails()
+		require.Len(t, sent, 1)
+		assert.Equal(t, "task_assigned", sent[0].Template)
+		assert.Equal(t, creator.Email, sent[0].To)
+	})
+}
@@ -0,0 +1,88 @@
+// internal/service/page_token.go
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidPageToken is returned when a page token cannot be decoded
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// pageCursor identifies the last row of a page for keyset pagination.
+// Rows are ordered by (created_at DESC, id DESC), so the next page starts
+// strictly after this position and is unaffected by concurrent inserts.
+type pageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// encodePageCursor encodes the position of the last returned row into an opaque token
+func encodePageCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodePageCursor decodes a token produced by encodePageCursor
+func decodePageCursor(token string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidPageToken
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+
+	return &pageCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// offsetTokenPrefix marks offset-based page tokens
+const offsetTokenPrefix = "offset:"
+
+// encodeOffsetPageToken encodes the offset of the next page into an opaque token.
+// Offset tokens are used where results can be ordered by arbitrary columns.
+func encodeOffsetPageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(offsetTokenPrefix + strconv.Itoa(offset)))
+}
+
+// decodeOffsetPageToken decodes a token produced by encodeOffsetPageToken.
+// An empty token refers to the first page.
+func decodeOffsetPageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidPageToken
+	}
+
+	value, ok := strings.CutPrefix(string(raw), offsetTokenPrefix)
+	if !ok {
+		return 0, ErrInvalidPageToken
+	}
+
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidPageToken
+	}
+
+	return offset, nil
+}
@@ -23,32 +23,72 @@ import (
 const (
 	// PasswordResetTokenLength is the length of password reset tokens
 	PasswordResetTokenLength = 32
-	// PasswordResetTokenDuration is how long reset tokens are valid
+	// PasswordResetTokenDuration is how long reset tokens are valid by default
 	PasswordResetTokenDuration = 1 * time.Hour
-	// MaxPasswordResetAttempts is the maximum number of reset attempts per day
+	// MaxPasswordResetAttempts is the default maximum number of reset attempts per day
 	MaxPasswordResetAttempts = 5
-	// PasswordResetRateLimit is the minimum time between reset requests
+	// PasswordResetRateLimit is the default minimum time between reset requests
 	PasswordResetRateLimit = 15 * time.Minute
+	// DefaultPasswordHistorySize is how many previous passwords are blocked from reuse
+	DefaultPasswordHistorySize = 5
 )
 
+// PasswordResetConfig limits password reset requests. Zero fields fall back
+// to the package defaults.
+type PasswordResetConfig struct {
+	TokenDuration time.Duration // How long reset tokens are valid
+	MaxAttempts   int           // Reset requests allowed per day
+	RateLimit     time.Duration // Minimum time between reset requests
+}
+
 // PasswordResetService handles password reset logic
 type PasswordResetService struct {
 	client          *ent.Client
 	emailService    email.EmailService
 	passwordManager *auth.PasswordManager
 	securityLogger  *SecurityLogger
+	sessions        *auth.SessionStore
+	historySize     int
+	config          PasswordResetConfig
 }
 
 // NewPasswordResetService creates a new password reset service
-func NewPasswordResetService(client *ent.Client, emailService email.EmailService, passwordManager *auth.PasswordManager, securityLogger *SecurityLogger) *PasswordResetService {
+func NewPasswordResetService(client *ent.Client, emailService email.EmailService, passwordManager *auth.PasswordManager, securityLogger *SecurityLogger, resetConfig PasswordResetConfig) *PasswordResetService {
+	if resetConfig.TokenDuration <= 0 {
+		resetConfig.TokenDuration = PasswordResetTokenDuration
+	}
+	if resetConfig.MaxAttempts <= 0 {
+		resetConfig.MaxAttempts = MaxPasswordResetAttempts
+	}
+	if resetConfig.RateLimit <= 0 {
+		resetConfig.RateLimit = PasswordResetRateLimit
+	}
+
 	return &PasswordResetService{
 		client:          client,
 		emailService:    emailService,
 		passwordManager: passwordManager,
 		securityLogger:  securityLogger,
+		sessions:        auth.NewSessionStore(client),
+		historySize:     DefaultPasswordHistorySize,
+		config:          resetConfig,
 	}
 }
 
+// nextRequestAt returns when the user may request another reset, based on
+// when their current token was issued
+func (s *PasswordResetService) nextRequestAt(u *ent.User) time.Time {
+	if u.PasswordResetExpiresAt == nil {
+		return time.Time{}
+	}
+	return u.PasswordResetExpiresAt.Add(-s.config.TokenDuration).Add(s.config.RateLimit)
+}
+
+// SetPasswordHistorySize sets how many previous passwords are blocked from reuse
+func (s *PasswordResetService) SetPasswordHistorySize(size int) {
+	s.historySize = size
+}
+
 // RequestPasswordReset initiates a password reset process
 func (s *PasswordResetService) RequestPasswordReset(ctx context.Context, email string) error {
 	if email == "" {
@@ -82,10 +122,9 @@ func (s *PasswordResetService) RequestPasswordReset(ctx context.Context, email s
 		return status.Error(codes.Internal, "failed to find user")
 	}
 
-	// Check rate limiting - only allow one request per 15 minutes
+	// Check rate limiting - only allow one request per configured interval
 	if foundUser.PasswordResetExpiresAt != nil {
-		timeUntilNextRequest := foundUser.PasswordResetExpiresAt.Add(-PasswordResetTokenDuration).Add(PasswordResetRateLimit)
-		if time.Now().Before(timeUntilNextRequest) {
+		if time.Now().Before(s.nextRequestAt(foundUser)) {
 			// Log the rate limit violation
 			if err := s.securityLogger.LogFromContext(ctx, foundUser.ID, security.EventTypeSuspiciousActivity,
 				"Password reset request rate limited", security.SeverityMedium); err != nil {
@@ -96,7 +135,7 @@ func (s *PasswordResetService) RequestPasswordReset(ctx context.Context, email s
 	}
 
 	// Check daily attempts (reset attempts counter daily)
-	if foundUser.PasswordResetAttempts >= MaxPasswordResetAttempts {
+	if foundUser.PasswordResetAttempts >= s.config.MaxAttempts {
 		// Check if it's been 24 hours since last attempt
 		if foundUser.PasswordResetExpiresAt != nil && time.Since(*foundUser.PasswordResetExpiresAt) < 24*time.Hour {
 			// Log the attempt limit violation
@@ -120,9 +159,9 @@ func (s *PasswordResetService) RequestPasswordReset(ctx context.Context, email s
 	}
 
 	// Update user with reset token
-	expiresAt := time.Now().Add(PasswordResetTokenDuration)
+	expiresAt := time.Now().Add(s.config.TokenDuration)
 	updatedUser, err := foundUser.Update().
-		SetPasswordResetToken(token).
+		SetPasswordResetToken(auth.HashToken(token)).
 		SetPasswordResetExpiresAt(expiresAt).
 		AddPasswordResetAttempts(1).
 		Save(ctx)
@@ -159,19 +198,25 @@ func (s *PasswordResetService) VerifyPasswordResetToken(ctx context.Context, tok
 	foundUser, err := s.client.User.Query().
 		Where(
 			user.And(
-				user.PasswordResetTokenEQ(token),
+				user.PasswordResetTokenEQ(auth.HashToken(token)),
 				user.IsActiveEQ(true),
 			),
 		).
 		Only(ctx)
 
-	if err != nil {
-		if ent.IsNotFound(err) {
-			return nil, status.Error(codes.NotFound, "invalid or expired reset token")
-		}
+	if err != nil && !ent.IsNotFound(err) {
 		return nil, status.Error(codes.Internal, "failed to find user")
 	}
 
+	// Compare even on a miss so unknown tokens take as long as known ones
+	var storedToken string
+	if foundUser != nil {
+		storedToken = foundUser.PasswordResetToken
+	}
+	if !auth.TokenMatches(token, storedToken) {
+		return nil, status.Error(codes.NotFound, "invalid or expired reset token")
+	}
+
 	// Check if token is expired
 	if foundUser.PasswordResetExpiresAt != nil && foundUser.PasswordResetExpiresAt.Before(time.Now()) {
 		return nil, status.Error(codes.DeadlineExceeded, "reset token has expired")
@@ -205,7 +250,7 @@ func (s *PasswordResetService) ResetPassword(ctx context.Context, token, newPass
 	foundUser, err := s.client.User.Query().
 		Where(
 			user.And(
-				user.PasswordResetTokenEQ(token),
+				user.PasswordResetTokenEQ(auth.HashToken(token)),
 				user.IsActiveEQ(true),
 			),
 		).
@@ -233,25 +278,38 @@ func (s *PasswordResetService) ResetPassword(ctx context.Context, token, newPass
 		return status.Error(codes.DeadlineExceeded, "reset token has expired")
 	}
 
+	if err := s.passwordManager.CheckStrength(newPassword, foundUser.Username, foundUser.Email, foundUser.FirstName, foundUser.LastName); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Reject the current password and any still in the history
+	if err := s.passwordManager.CheckPasswordReuse(newPassword, append([]string{foundUser.PasswordHash}, foundUser.PasswordHistory...)...); err != nil {
+		return status.Error(codes.InvalidArgument, "new password must not match a recently used password")
+	}
+
 	// Hash new password
 	hashedPassword, err := s.passwordManager.HashPassword(newPassword)
 	if err != nil {
 		return status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	// Invalidate all existing sessions
+	if _, err := s.sessions.RevokeAll(ctx, foundUser.ID); err != nil {
+		return status.Error(codes.Internal, "failed to reset password")
+	}
+
 	// Update user with new password and clear reset token
 	now := time.Now()
 	_, err = foundUser.Update().
 		SetPasswordHash(hashedPassword).
+		SetPasswordHistory(auth.UpdatePasswordHistory(foundUser.PasswordHistory, foundUser.PasswordHash, s.historySize)).
 		SetPasswordChangedAt(now).
 		SetPasswordResetAt(now).
 		ClearPasswordResetToken().
 		ClearPasswordResetExpiresAt().
 		SetPasswordResetAttempts(0). // Reset attempts on successful reset
-		ClearRefreshToken().         // Invalidate all existing sessions
-		ClearRefreshTokenExpiresAt().
-		SetFailedLoginAttempts(0). // Reset failed login attempts
-		ClearAccountLockedUntil(). // Unlock account if it was locked
+		SetFailedLoginAttempts(0).   // Reset failed login attempts
+		ClearAccountLockedUntil().   // Unlock account if it was locked
 		Save(ctx)
 
 	if err != nil {
@@ -259,7 +317,7 @@ func (s *PasswordResetService) ResetPassword(ctx context.Context, token, newPass
 	}
 
 	// Send password changed notification email
-	if foundUser.SecurityNotificationsEnabled {
+	if notificationEnabled(foundUser, notifySecurityAlerts) {
 		if err := s.emailService.SendPasswordChangedNotification(ctx, foundUser); err != nil {
 			// Log error but don't fail the operation
 			if err := s.securityLogger.LogFromContext(ctx, foundUser.ID, security.EventTypeSecurityAlert,
@@ -295,7 +353,7 @@ func (s *PasswordResetService) GetPasswordResetStatus(ctx context.Context, userI
 
 	status := &PasswordResetStatus{
 		Attempts:    foundUser.PasswordResetAttempts,
-		MaxAttempts: MaxPasswordResetAttempts,
+		MaxAttempts: s.config.MaxAttempts,
 	}
 
 	if foundUser.PasswordResetExpiresAt != nil {
@@ -306,10 +364,9 @@ func (s *PasswordResetService) GetPasswordResetStatus(ctx context.Context, userI
 
 	// Check if user can request another reset
 	if foundUser.PasswordResetExpiresAt != nil {
-		timeUntilNextRequest := foundUser.PasswordResetExpiresAt.Add(-PasswordResetTokenDuration).Add(PasswordResetRateLimit)
-		status.CanRequest = time.Now().After(timeUntilNextRequest) && foundUser.PasswordResetAttempts < MaxPasswordResetAttempts
+		status.CanRequest = time.Now().After(s.nextRequestAt(foundUser)) && foundUser.PasswordResetAttempts < s.config.MaxAttempts
 	} else {
-		status.CanRequest = foundUser.PasswordResetAttempts < MaxPasswordResetAttempts
+		status.CanRequest = foundUser.PasswordResetAttempts < s.config.MaxAttempts
 	}
 
 	if foundUser.PasswordResetAt != nil {
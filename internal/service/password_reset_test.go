@@ -3,16 +3,21 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
-	"github.com/gurkanbulca/taskmaster/ent/generated/user"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
 	"github.com/gurkanbulca/taskmaster/ent/generated/enttest"
+	"github.com/gurkanbulca/taskmaster/ent/generated/securityevent"
+	"github.com/gurkanbulca/taskmaster/ent/generated/session"
+	"github.com/gurkanbulca/taskmaster/ent/generated/user"
 	"github.com/gurkanbulca/taskmaster/internal/middleware"
 	"github.com/gurkanbulca/taskmaster/pkg/auth"
 	"github.com/gurkanbulca/taskmaster/pkg/email"
@@ -30,7 +35,7 @@ func TestPasswordResetService_RequestPasswordReset(t *testing.T) {
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
 
-	service := NewPasswordResetService(client, mockEmailService, passwordManager, securityLogger)
+	service := NewPasswordResetService(client, mockEmailService, passwordManager, securityLogger, PasswordResetConfig{})
 
 	// Create test user
 	testUser, err := client.User.Create().
@@ -176,6 +181,164 @@ func TestPasswordResetService_RequestPasswordReset(t *testing.T) {
 	}
 }
 
+func TestPasswordResetService_RequestPasswordReset_SecurityLogging(t *testing.T) {
+	// Setup
+	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	mockEmailService := email.NewMockEmailService()
+	securityLogger := NewSecurityLogger(NewSecurityService(client))
+	service := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
+
+	testUser, err := client.User.Create().
+		SetEmail("test@example.com").
+		SetUsername("testuser").
+		SetPasswordHash("hash").
+		SetIsActive(true).
+		Save(context.Background())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	ctx = context.WithValue(ctx, middleware.ContextKeyIPAddress, "203.0.113.9")
+	ctx = context.WithValue(ctx, middleware.ContextKeyUserAgent, "reset-test-agent")
+
+	findEvent := func(description string) *ent.SecurityEvent {
+		event, err := client.SecurityEvent.Query().
+			Where(securityevent.DescriptionEQ(description)).
+			Only(context.Background())
+		require.NoError(t, err)
+		return event
+	}
+
+	t.Run("non-existent email", func(t *testing.T) {
+		// Succeeds so callers can't tell whether the email is registered
+		require.NoError(t, service.RequestPasswordReset(ctx, "Nobody@Example.com"))
+		assert.Empty(t, mockEmailService.GetSentEmails())
+
+		event := findEvent("Password reset attempted for non-existent email: nobody@example.com")
+		assert.Equal(t, securityevent.EventTypeSuspiciousActivity, event.EventType)
+		assert.Equal(t, securityevent.SeverityMedium, event.Severity)
+		assert.Equal(t, uuid.Nil, event.UserID)
+		assert.Equal(t, "203.0.113.9", event.IPAddress)
+		assert.Equal(t, "reset-test-agent", event.UserAgent)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		require.NoError(t, service.RequestPasswordReset(ctx, testUser.Email))
+
+		err := service.RequestPasswordReset(ctx, testUser.Email)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		event := findEvent("Password reset request rate limited")
+		assert.Equal(t, securityevent.EventTypeSuspiciousActivity, event.EventType)
+		assert.Equal(t, testUser.ID, event.UserID)
+		assert.Equal(t, "203.0.113.9", event.IPAddress)
+		assert.Equal(t, "reset-test-agent", event.UserAgent)
+	})
+}
+
+func TestPasswordResetService_CustomLimits(t *testing.T) {
+	// Setup
+	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	mockEmailService := email.NewMockEmailService()
+	securityLogger := NewSecurityLogger(NewSecurityService(client))
+	resetConfig := PasswordResetConfig{
+		TokenDuration: 10 * time.Minute,
+		MaxAttempts:   2,
+		RateLimit:     time.Minute,
+	}
+	service := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, resetConfig)
+
+	testUser, err := client.User.Create().
+		SetEmail("test@example.com").
+		SetUsername("testuser").
+		SetPasswordHash("hash").
+		SetIsActive(true).
+		Save(context.Background())
+	require.NoError(t, err)
+
+	// issuedAgo backdates the current reset token
+	issuedAgo := func(d time.Duration) {
+		_, err := testUser.Update().
+			SetPasswordResetExpiresAt(time.Now().Add(resetConfig.TokenDuration - d)).
+			Save(context.Background())
+		require.NoError(t, err)
+	}
+
+	ctx := context.Background()
+	require.NoError(t, service.RequestPasswordReset(ctx, testUser.Email))
+
+	// Tokens last the configured duration
+	updatedUser, err := client.User.Get(ctx, testUser.ID)
+	require.NoError(t, err)
+	require.NotNil(t, updatedUser.PasswordResetExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(resetConfig.TokenDuration), *updatedUser.PasswordResetExpiresAt, 5*time.Second)
+
+	// A second request inside the configured interval is rate limited
+	err = service.RequestPasswordReset(ctx, testUser.Email)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "please wait")
+
+	// Two minutes later is enough, though well within the default 15 minutes
+	issuedAgo(2 * time.Minute)
+	require.NoError(t, service.RequestPasswordReset(ctx, testUser.Email))
+
+	// The configured cap of two requests per day is enforced
+	issuedAgo(2 * time.Minute)
+	err = service.RequestPasswordReset(ctx, testUser.Email)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "maximum password reset attempts")
+
+	resetStatus, err := service.GetPasswordResetStatus(ctx, testUser.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, 2, resetStatus.Attempts)
+	assert.Equal(t, 2, resetStatus.MaxAttempts)
+	assert.False(t, resetStatus.CanRequest)
+	assert.Len(t, mockEmailService.GetSentEmails(), 2)
+}
+
+func TestPasswordResetService_TokenStoredHashed(t *testing.T) {
+	// Setup
+	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	mockEmailService := email.NewMockEmailService()
+	securityLogger := NewSecurityLogger(NewSecurityService(client))
+	service := NewPasswordResetService(client, mockEmailService, auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
+
+	testUser, err := client.User.Create().
+		SetEmail("test@example.com").
+		SetUsername("testuser").
+		SetPasswordHash("hash").
+		SetIsActive(true).
+		Save(context.Background())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, service.RequestPasswordReset(ctx, testUser.Email))
+
+	// The email carries the raw token while only its hash is stored
+	rawToken := mockEmailService.GetLastSentEmail().Data.Token
+	require.NotEmpty(t, rawToken)
+
+	stored, err := client.User.Get(ctx, testUser.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, rawToken, stored.PasswordResetToken)
+	assert.Equal(t, auth.HashToken(rawToken), stored.PasswordResetToken)
+
+	// The raw token verifies and resets, the stored hash does not
+	_, err = service.VerifyPasswordResetToken(ctx, stored.PasswordResetToken)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	tokenInfo, err := service.VerifyPasswordResetToken(ctx, rawToken)
+	require.NoError(t, err)
+	assert.True(t, tokenInfo.IsValid)
+
+	require.NoError(t, service.ResetPassword(ctx, rawToken, "NewSecurePassword456!"))
+}
+
 func TestPasswordResetService_VerifyPasswordResetToken(t *testing.T) {
 	// Setup
 	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
@@ -186,7 +349,7 @@ func TestPasswordResetService_VerifyPasswordResetToken(t *testing.T) {
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
 
-	service := NewPasswordResetService(client, mockEmailService, passwordManager, securityLogger)
+	service := NewPasswordResetService(client, mockEmailService, passwordManager, securityLogger, PasswordResetConfig{})
 
 	// Create test users with tokens
 	validToken := "valid-reset-token-12345678901234567890123456"
@@ -197,7 +360,7 @@ func TestPasswordResetService_VerifyPasswordResetToken(t *testing.T) {
 		SetUsername("validuser").
 		SetPasswordHash("hash").
 		SetIsActive(true).
-		SetPasswordResetToken(validToken).
+		SetPasswordResetToken(auth.HashToken(validToken)).
 		SetPasswordResetExpiresAt(time.Now().Add(30 * time.Minute)).
 		Save(context.Background())
 	require.NoError(t, err)
@@ -207,7 +370,7 @@ func TestPasswordResetService_VerifyPasswordResetToken(t *testing.T) {
 		SetUsername("expireduser").
 		SetPasswordHash("hash").
 		SetIsActive(true).
-		SetPasswordResetToken(expiredToken).
+		SetPasswordResetToken(auth.HashToken(expiredToken)).
 		SetPasswordResetExpiresAt(time.Now().Add(-1 * time.Hour)).
 		Save(context.Background())
 	require.NoError(t, err)
@@ -271,6 +434,44 @@ func TestPasswordResetService_VerifyPasswordResetToken(t *testing.T) {
 	}
 }
 
+func TestPasswordResetService_VerifyPasswordResetToken_Mismatch(t *testing.T) {
+	// Setup
+	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	securityLogger := NewSecurityLogger(NewSecurityService(client))
+	service := NewPasswordResetService(client, email.NewMockEmailService(), auth.NewPasswordManager(), securityLogger, PasswordResetConfig{})
+
+	validToken := "valid-reset-token-12345678901234567890123456"
+	_, err := client.User.Create().
+		SetEmail("valid@example.com").
+		SetUsername("validuser").
+		SetPasswordHash("hash").
+		SetIsActive(true).
+		SetPasswordResetToken(auth.HashToken(validToken)).
+		SetPasswordResetExpiresAt(time.Now().Add(30 * time.Minute)).
+		Save(context.Background())
+	require.NoError(t, err)
+
+	// Unknown and near-miss tokens are indistinguishable to the caller
+	for name, token := range map[string]string{
+		"unknown token":   "unknown-reset-token-1234567890123456789012345",
+		"near-miss token": validToken[:len(validToken)-1] + "7",
+		"stored hash":     auth.HashToken(validToken),
+	} {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			_, err := service.VerifyPasswordResetToken(context.Background(), token)
+			assert.Less(t, time.Since(start), time.Second)
+
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, codes.NotFound, st.Code())
+			assert.Equal(t, "invalid or expired reset token", st.Message())
+		})
+	}
+}
+
 func TestPasswordResetService_ResetPassword(t *testing.T) {
 	// Setup
 	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
@@ -281,7 +482,7 @@ func TestPasswordResetService_ResetPassword(t *testing.T) {
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
 
-	service := NewPasswordResetService(client, mockEmailService, passwordManager, securityLogger)
+	service := NewPasswordResetService(client, mockEmailService, passwordManager, securityLogger, PasswordResetConfig{})
 
 	// Create test user with reset token
 	validToken := "valid-reset-token-12345678901234567890123456"
@@ -293,17 +494,18 @@ func TestPasswordResetService_ResetPassword(t *testing.T) {
 		SetUsername("testuser").
 		SetPasswordHash(oldPasswordHash).
 		SetIsActive(true).
-		SetPasswordResetToken(validToken).
+		SetPasswordResetToken(auth.HashToken(validToken)).
 		SetPasswordResetExpiresAt(time.Now().Add(30 * time.Minute)).
 		SetPasswordResetAttempts(3).
 		SetFailedLoginAttempts(5).
 		SetAccountLockedUntil(time.Now().Add(1 * time.Hour)).
-		SetRefreshToken("old-refresh-token").
-		SetRefreshTokenExpiresAt(time.Now().Add(24 * time.Hour)).
 		SetSecurityNotificationsEnabled(true).
 		Save(context.Background())
 	require.NoError(t, err)
 
+	_, err = auth.NewSessionStore(client).Create(context.Background(), testUser.ID, "old-refresh-token", "127.0.0.1", "test-agent", time.Now().Add(24*time.Hour))
+	require.NoError(t, err)
+
 	// Add context
 	ctx := context.Background()
 	ctx = context.WithValue(ctx, middleware.ContextKeyIPAddress, "127.0.0.1")
@@ -361,7 +563,7 @@ func TestPasswordResetService_ResetPassword(t *testing.T) {
 					SetUsername("expired").
 					SetPasswordHash("hash").
 					SetIsActive(true).
-					SetPasswordResetToken("expired-token-123").
+					SetPasswordResetToken(auth.HashToken("expired-token-123")).
 					SetPasswordResetExpiresAt(time.Now().Add(-1 * time.Hour)).
 					Save(context.Background())
 			},
@@ -403,9 +605,10 @@ func TestPasswordResetService_ResetPassword(t *testing.T) {
 				assert.Nil(t, updatedUser.PasswordResetExpiresAt)
 				assert.Equal(t, 0, updatedUser.PasswordResetAttempts)
 
-				// Verify session was invalidated
-				assert.Empty(t, updatedUser.RefreshToken)
-				assert.Nil(t, updatedUser.RefreshTokenExpiresAt)
+				// Verify sessions were invalidated
+				sessionCount, err := client.Session.Query().Where(session.UserIDEQ(testUser.ID)).Count(context.Background())
+				require.NoError(t, err)
+				assert.Zero(t, sessionCount)
 
 				// Verify account was unlocked
 				assert.Equal(t, 0, updatedUser.FailedLoginAttempts)
@@ -425,6 +628,62 @@ func TestPasswordResetService_ResetPassword(t *testing.T) {
 	}
 }
 
+func TestPasswordResetService_ResetPassword_History(t *testing.T) {
+	// Setup
+	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	passwordManager := auth.NewPasswordManager()
+	securityLogger := NewSecurityLogger(NewSecurityService(client))
+	service := NewPasswordResetService(client, email.NewMockEmailService(), passwordManager, securityLogger, PasswordResetConfig{})
+	service.SetPasswordHistorySize(2)
+
+	currentHash, err := passwordManager.HashPassword("Current123!")
+	require.NoError(t, err)
+	previousHash, err := passwordManager.HashPassword("Previous123!")
+	require.NoError(t, err)
+	olderHash, err := passwordManager.HashPassword("Older123!")
+	require.NoError(t, err)
+
+	testUser, err := client.User.Create().
+		SetEmail("history@example.com").
+		SetUsername("historyuser").
+		SetPasswordHash(currentHash).
+		SetPasswordHistory([]string{previousHash, olderHash}).
+		SetIsActive(true).
+		Save(context.Background())
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyIPAddress, "127.0.0.1")
+
+	reset := func(newPassword string) error {
+		token := fmt.Sprintf("reset-token-%d", time.Now().UnixNano())
+		_, err := testUser.Update().
+			SetPasswordResetToken(auth.HashToken(token)).
+			SetPasswordResetExpiresAt(time.Now().Add(30 * time.Minute)).
+			Save(context.Background())
+		require.NoError(t, err)
+		return service.ResetPassword(ctx, token, newPassword)
+	}
+
+	for _, p := range []string{"Current123!", "Previous123!", "Older123!"} {
+		err := reset(p)
+		require.Error(t, err, p)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+
+	require.NoError(t, reset("Brand123New!"))
+
+	// The outgoing password joins the history and the oldest entry drops off
+	updatedUser, err := client.User.Get(context.Background(), testUser.ID)
+	require.NoError(t, err)
+	require.Len(t, updatedUser.PasswordHistory, 2)
+	assert.NoError(t, passwordManager.ComparePassword(updatedUser.PasswordHistory[0], "Current123!"))
+	assert.NoError(t, passwordManager.ComparePassword(updatedUser.PasswordHistory[1], "Previous123!"))
+
+	assert.NoError(t, reset("Older123!"))
+}
+
 func TestPasswordResetService_GetPasswordResetStatus(t *testing.T) {
 	// Setup
 	client := enttest.Open(t, "sqlite3", "file:ent?mode=memory&cache=shared&_fk=1")
@@ -435,7 +694,7 @@ func TestPasswordResetService_GetPasswordResetStatus(t *testing.T) {
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
 
-	service := NewPasswordResetService(client, mockEmailService, passwordManager, securityLogger)
+	service := NewPasswordResetService(client, mockEmailService, passwordManager, securityLogger, PasswordResetConfig{})
 
 	// Create test users with different states
 	userWithActiveRequest, err := client.User.Create().
@@ -545,7 +804,7 @@ func TestPasswordResetService_CleanupExpiredTokens(t *testing.T) {
 	securityService := NewSecurityService(client)
 	securityLogger := NewSecurityLogger(securityService)
 
-	service := NewPasswordResetService(client, mockEmailService, passwordManager, securityLogger)
+	service := NewPasswordResetService(client, mockEmailService, passwordManager, securityLogger, PasswordResetConfig{})
 
 	// Create users with expired and valid tokens
 	expiredUser1, err := client.User.Create().
@@ -0,0 +1,159 @@
+// internal/service/security_hooks.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/hook"
+	"github.com/gurkanbulca/taskmaster/ent/generated/securityevent"
+	"github.com/gurkanbulca/taskmaster/ent/generated/user"
+	"github.com/gurkanbulca/taskmaster/internal/middleware"
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
+)
+
+type securityAuditSkipKey struct{}
+
+// withoutSecurityAudit marks ctx so user updates made with it are not audited.
+// Only for changes that are not security relevant, like re-hashing an
+// unchanged password with newer parameters.
+func withoutSecurityAudit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, securityAuditSkipKey{}, true)
+}
+
+// RegisterSecurityHooks installs hooks that record a security event whenever a
+// user's password, role, active status or lockout changes, however the update
+// is made. Events that fail to save outside a transaction go to logger.
+func RegisterSecurityHooks(client *ent.Client, logger logging.Logger) {
+	client.User.Use(securityAuditHook(logger))
+}
+
+// userSecurityEvent is an event derived from a change to one user
+type userSecurityEvent struct {
+	eventType   securityevent.EventType
+	severity    securityevent.Severity
+	description string
+}
+
+func securityAuditHook(logger logging.Logger) ent.Hook {
+	return hook.On(func(next ent.Mutator) ent.Mutator {
+		return hook.UserFunc(func(ctx context.Context, m *ent.UserMutation) (ent.Value, error) {
+			if skip, _ := ctx.Value(securityAuditSkipKey{}).(bool); skip || !touchesSecurityFields(m) {
+				return next.Mutate(ctx, m)
+			}
+
+			// Snapshot the affected users so events are only recorded for
+			// values that actually change
+			ids, err := m.IDs(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("load users for security audit: %w", err)
+			}
+			before, err := m.Client().User.Query().Where(user.IDIn(ids...)).All(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("load users for security audit: %w", err)
+			}
+
+			value, err := next.Mutate(ctx, m)
+			if err != nil {
+				return value, err
+			}
+
+			clientInfo := middleware.GetClientInfoFromContext(ctx)
+			metadata := map[string]interface{}{"source": "user_hook"}
+			if actorID, ok := middleware.GetUserIDFromContext(ctx); ok {
+				metadata["changed_by"] = actorID
+			}
+
+			for _, old := range before {
+				for _, event := range userSecurityEvents(old, m) {
+					create := m.Client().SecurityEvent.Create().
+						SetUserID(old.ID).
+						SetEventType(event.eventType).
+						SetSeverity(event.severity).
+						SetDescription(event.description).
+						SetMetadata(metadata)
+					if clientInfo.IPAddress != "" {
+						create = create.SetIPAddress(clientInfo.IPAddress)
+					}
+					if clientInfo.UserAgent != "" {
+						create = create.SetUserAgent(clientInfo.UserAgent)
+					}
+					if err := create.Exec(ctx); err != nil {
+						// In a transaction the change rolls back with the event.
+						// Otherwise it is already saved, so don't report it as failed.
+						if _, txErr := m.Tx(); txErr == nil {
+							return nil, fmt.Errorf("record security event for user %s: %w", old.ID, err)
+						}
+						logger.Error("failed to record security event",
+							"user_id", old.ID, "event_type", event.eventType, "error", err)
+					}
+				}
+			}
+
+			return value, nil
+		})
+	}, ent.OpUpdate|ent.OpUpdateOne)
+}
+
+// touchesSecurityFields reports whether the mutation sets any audited field
+func touchesSecurityFields(m *ent.UserMutation) bool {
+	_, password := m.PasswordHash()
+	_, role := m.Role()
+	_, active := m.IsActive()
+	_, locked := m.AccountLockedUntil()
+	return password || role || active || locked || m.AccountLockedUntilCleared()
+}
+
+// userSecurityEvents compares a user's previous state with the mutation
+func userSecurityEvents(old *ent.User, m *ent.UserMutation) []userSecurityEvent {
+	var events []userSecurityEvent
+
+	if hash, ok := m.PasswordHash(); ok && hash != old.PasswordHash {
+		events = append(events, userSecurityEvent{
+			eventType:   securityevent.EventTypePasswordChanged,
+			severity:    securityevent.SeverityLow,
+			description: "Password changed",
+		})
+	}
+
+	if role, ok := m.Role(); ok && role != old.Role {
+		events = append(events, userSecurityEvent{
+			eventType:   securityevent.EventTypeSecurityAlert,
+			severity:    securityevent.SeverityHigh,
+			description: fmt.Sprintf("Role changed from %s to %s", old.Role, role),
+		})
+	}
+
+	if active, ok := m.IsActive(); ok && active != old.IsActive {
+		event := userSecurityEvent{
+			eventType:   securityevent.EventTypeSecurityAlert,
+			severity:    securityevent.SeverityMedium,
+			description: "Account deactivated",
+		}
+		if active {
+			event.severity = securityevent.SeverityLow
+			event.description = "Account reactivated"
+		}
+		events = append(events, event)
+	}
+
+	wasLocked := old.AccountLockedUntil != nil && old.AccountLockedUntil.After(time.Now())
+	if lockedUntil, ok := m.AccountLockedUntil(); ok && lockedUntil.After(time.Now()) && !wasLocked {
+		events = append(events, userSecurityEvent{
+			eventType:   securityevent.EventTypeAccountLocked,
+			severity:    securityevent.SeverityHigh,
+			description: fmt.Sprintf("Account locked until %s", lockedUntil.UTC().Format(time.RFC3339)),
+		})
+	}
+	if m.AccountLockedUntilCleared() && wasLocked {
+		events = append(events, userSecurityEvent{
+			eventType:   securityevent.EventTypeAccountUnlocked,
+			severity:    securityevent.SeverityLow,
+			description: "Account unlocked",
+		})
+	}
+
+	return events
+}
@@ -0,0 +1,178 @@
+// internal/service/security_hooks_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/enttest"
+	"github.com/gurkanbulca/taskmaster/ent/generated/securityevent"
+	"github.com/gurkanbulca/taskmaster/ent/generated/user"
+	"github.com/gurkanbulca/taskmaster/internal/middleware"
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
+)
+
+// userEvents returns the security events recorded for a user, oldest first
+func userEvents(t *testing.T, client *ent.Client, u *ent.User) []*ent.SecurityEvent {
+	t.Helper()
+	events, err := client.SecurityEvent.Query().
+		Where(securityevent.UserIDEQ(u.ID)).
+		Order(ent.Asc(securityevent.FieldCreatedAt)).
+		All(context.Background())
+	require.NoError(t, err)
+	return events
+}
+
+func TestSecurityHooks_UserFieldChanges(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		mutate          func(u *ent.User) error
+		wantType        securityevent.EventType
+		wantSeverity    securityevent.Severity
+		wantDescription string
+	}{
+		{
+			name: "password hash",
+			mutate: func(u *ent.User) error {
+				return u.Update().SetPasswordHash("new-hash").Exec(ctx)
+			},
+			wantType:        securityevent.EventTypePasswordChanged,
+			wantSeverity:    securityevent.SeverityLow,
+			wantDescription: "Password changed",
+		},
+		{
+			name: "role",
+			mutate: func(u *ent.User) error {
+				return u.Update().SetRole(user.RoleAdmin).Exec(ctx)
+			},
+			wantType:        securityevent.EventTypeSecurityAlert,
+			wantSeverity:    securityevent.SeverityHigh,
+			wantDescription: "Role changed from user to admin",
+		},
+		{
+			name: "deactivation",
+			mutate: func(u *ent.User) error {
+				return u.Update().SetIsActive(false).Exec(ctx)
+			},
+			wantType:        securityevent.EventTypeSecurityAlert,
+			wantSeverity:    securityevent.SeverityMedium,
+			wantDescription: "Account deactivated",
+		},
+		{
+			name: "lockout",
+			mutate: func(u *ent.User) error {
+				return u.Update().SetAccountLockedUntil(time.Now().Add(time.Hour)).Exec(ctx)
+			},
+			wantType:     securityevent.EventTypeAccountLocked,
+			wantSeverity: securityevent.SeverityHigh,
+		},
+		{
+			name: "bulk update outside the service layer",
+			mutate: func(u *ent.User) error {
+				return client.User.Update().Where(user.ID(u.ID)).SetIsActive(false).Exec(ctx)
+			},
+			wantType:        securityevent.EventTypeSecurityAlert,
+			wantSeverity:    securityevent.SeverityMedium,
+			wantDescription: "Account deactivated",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := NewTestHelpers(t, client).CreateTestUser(fmt.Sprintf("hook%d@example.com", i), fmt.Sprintf("hookuser%d", i), "TestPass123!")
+			require.Empty(t, userEvents(t, client, u))
+
+			require.NoError(t, tt.mutate(u))
+
+			events := userEvents(t, client, u)
+			require.Len(t, events, 1)
+			assert.Equal(t, tt.wantType, events[0].EventType)
+			assert.Equal(t, tt.wantSeverity, events[0].Severity)
+			if tt.wantDescription != "" {
+				assert.Equal(t, tt.wantDescription, events[0].Description)
+			}
+		})
+	}
+}
+
+func TestSecurityHooks_OnlyRecordsActualChanges(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	u := createTestUser(t, client)
+
+	// Setting the same values or unrelated fields records nothing
+	require.NoError(t, u.Update().SetRole(user.RoleUser).SetIsActive(true).Exec(ctx))
+	require.NoError(t, u.Update().SetFirstName("Renamed").ClearAccountLockedUntil().Exec(ctx))
+	assert.Empty(t, userEvents(t, client, u))
+
+	// Unlocking a locked account is recorded with the acting admin
+	require.NoError(t, u.Update().SetAccountLockedUntil(time.Now().Add(time.Hour)).Exec(ctx))
+	adminCtx := context.WithValue(ctx, middleware.ContextKeyUserID, "admin-id")
+	require.NoError(t, u.Update().ClearAccountLockedUntil().Exec(adminCtx))
+
+	events := userEvents(t, client, u)
+	require.Len(t, events, 2)
+	assert.Equal(t, securityevent.EventTypeAccountLocked, events[0].EventType)
+	assert.Equal(t, securityevent.EventTypeAccountUnlocked, events[1].EventType)
+	assert.Equal(t, "admin-id", events[1].Metadata["changed_by"])
+
+	// Opted-out updates are not audited
+	require.NoError(t, u.Update().SetPasswordHash("rehashed").Exec(withoutSecurityAudit(ctx)))
+	assert.Len(t, userEvents(t, client, u), 2)
+}
+
+func TestSecurityHooks_AuditFailure(t *testing.T) {
+	// Setup
+	client := enttest.Open(t, "sqlite3", "file:audit?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	logger := logging.NewCaptureLogger()
+	RegisterSecurityHooks(client, logger)
+	client.SecurityEvent.Use(func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (ent.Value, error) {
+			return nil, errors.New("security event store unavailable")
+		})
+	})
+
+	ctx := context.Background()
+	u := createTestUser(t, client)
+
+	// Outside a transaction the role change is already saved, so it succeeds
+	// and the failure is logged
+	require.NoError(t, u.Update().SetRole(user.RoleManager).Exec(ctx))
+	updated, err := client.User.Get(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.RoleManager, updated.Role)
+
+	entries := logger.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "ERROR", entries[0].Level)
+	assert.Equal(t, "failed to record security event", entries[0].Message)
+
+	// In a transaction the error is returned so both roll back together
+	tx, err := client.Tx(ctx)
+	require.NoError(t, err)
+	err = tx.User.UpdateOneID(u.ID).SetRole(user.RoleAdmin).Exec(ctx)
+	assert.Error(t, err)
+	require.NoError(t, tx.Rollback())
+
+	unchanged, err := client.User.Get(ctx, u.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.RoleManager, unchanged.Role)
+}
@@ -69,16 +69,37 @@ func (sl *SecurityLogger) LogCurrentUserFromContext(ctx context.Context, eventTy
 	return sl.LogFromContext(ctx, userUUID, eventType, description, severity)
 }
 
+// logLoginEvent logs a login attempt with the client details and the email
+// or username it was made with as metadata; userID is uuid.Nil when the
+// attempt did not identify a user
+func (sl *SecurityLogger) logLoginEvent(ctx context.Context, userID uuid.UUID, loginID, eventType, description, severity string) error {
+	clientInfo := middleware.GetClientInfoFromContext(ctx)
+
+	return sl.securityService.LogSecurityEvent(ctx, &LogSecurityEventRequest{
+		UserID:      userID,
+		EventType:   eventType,
+		Description: description,
+		Severity:    severity,
+		IPAddress:   clientInfo.IPAddress,
+		UserAgent:   clientInfo.UserAgent,
+		Metadata: map[string]interface{}{
+			"ip_address": clientInfo.IPAddress,
+			"user_agent": clientInfo.UserAgent,
+			"login_id":   loginID,
+		},
+	})
+}
+
 // Convenience methods for common security events
 
-func (sl *SecurityLogger) LogLoginSuccess(ctx context.Context, userID uuid.UUID) error {
-	return sl.LogFromContext(ctx, userID, security.EventTypeLoginSuccess,
+func (sl *SecurityLogger) LogLoginSuccess(ctx context.Context, userID uuid.UUID, loginID string) error {
+	return sl.logLoginEvent(ctx, userID, loginID, security.EventTypeLoginSuccess,
 		"User successfully logged in", security.SeverityLow)
 }
 
-func (sl *SecurityLogger) LogLoginFailed(ctx context.Context, email, reason string) error {
-	return sl.LogSystemFromContext(ctx, security.EventTypeLoginFailed,
-		"Login failed for "+email+": "+reason, security.SeverityMedium)
+func (sl *SecurityLogger) LogLoginFailed(ctx context.Context, loginID, reason string) error {
+	return sl.logLoginEvent(ctx, uuid.Nil, loginID, security.EventTypeLoginFailed,
+		"Login failed for "+loginID+": "+reason, security.SeverityMedium)
 }
 
 func (sl *SecurityLogger) LogPasswordChanged(ctx context.Context, userID uuid.UUID) error {
@@ -3,16 +3,21 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 
 	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/predicate"
 	"github.com/gurkanbulca/taskmaster/ent/generated/securityevent"
 	"github.com/gurkanbulca/taskmaster/pkg/security"
 )
 
+// ErrSecurityEventNotFound is returned when a bulk operation names an unknown event
+var ErrSecurityEventNotFound = errors.New("security event not found")
+
 // SecurityService handles security event logging and management
 type SecurityService struct {
 	client *ent.Client
@@ -177,6 +182,86 @@ func (s *SecurityService) ResolveSecurityEvent(ctx context.Context, eventID uuid
 	return nil
 }
 
+// BulkResolveSecurityEvents marks the events resolved in one transaction and
+// returns how many were newly resolved. Already resolved events are left as
+// they are; if any ID is unknown, nothing changes.
+func (s *SecurityService) BulkResolveSecurityEvents(ctx context.Context, ids []uuid.UUID) (int, error) {
+	return s.withKnownEvents(ctx, ids, func(tx *ent.Tx) (int, error) {
+		return tx.SecurityEvent.Update().
+			Where(securityevent.IDIn(ids...), securityevent.ResolvedEQ(false)).
+			SetResolved(true).
+			Save(ctx)
+	})
+}
+
+// DeleteSecurityEvents deletes the events in one transaction and returns how
+// many were removed. If any ID is unknown, nothing is deleted.
+func (s *SecurityService) DeleteSecurityEvents(ctx context.Context, ids []uuid.UUID) (int, error) {
+	return s.withKnownEvents(ctx, ids, func(tx *ent.Tx) (int, error) {
+		return tx.SecurityEvent.Delete().
+			Where(securityevent.IDIn(ids...)).
+			Exec(ctx)
+	})
+}
+
+// withKnownEvents runs fn in a transaction after checking that every event in
+// ids exists. ids must not contain duplicates.
+func (s *SecurityService) withKnownEvents(ctx context.Context, ids []uuid.UUID, fn func(tx *ent.Tx) (int, error)) (int, error) {
+	tx, err := s.client.Tx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("starting transaction: %w", err)
+	}
+
+	found, err := tx.SecurityEvent.Query().Where(securityevent.IDIn(ids...)).Count(ctx)
+	if err != nil {
+		return 0, rollbackTx(tx, fmt.Errorf("count security events: %w", err))
+	}
+	if found != len(ids) {
+		return 0, rollbackTx(tx, ErrSecurityEventNotFound)
+	}
+
+	affected, err := fn(tx)
+	if err != nil {
+		return 0, rollbackTx(tx, fmt.Errorf("update security events: %w", err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return affected, nil
+}
+
+// rollbackTx rolls tx back and returns err, annotated with any rollback failure
+func rollbackTx(tx *ent.Tx, err error) error {
+	if rerr := tx.Rollback(); rerr != nil {
+		err = fmt.Errorf("%w: %v", err, rerr)
+	}
+	return err
+}
+
+// PurgeOldSecurityEvents deletes events created more than olderThan ago and
+// returns how many were removed. Unresolved high and critical events are kept
+// when retainUnresolvedHighSeverity is set, so open incidents are not lost.
+func (s *SecurityService) PurgeOldSecurityEvents(ctx context.Context, olderThan time.Duration, retainUnresolvedHighSeverity bool) (int, error) {
+	predicates := []predicate.SecurityEvent{
+		securityevent.CreatedAtLT(time.Now().Add(-olderThan)),
+	}
+	if retainUnresolvedHighSeverity {
+		predicates = append(predicates, securityevent.Not(securityevent.And(
+			securityevent.ResolvedEQ(false),
+			securityevent.SeverityIn(securityevent.SeverityHigh, securityevent.SeverityCritical),
+		)))
+	}
+
+	deleted, err := s.client.SecurityEvent.Delete().Where(predicates...).Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge security events: %w", err)
+	}
+
+	return deleted, nil
+}
+
 // GetSecurityStats returns security statistics
 func (s *SecurityService) GetSecurityStats(ctx context.Context, userID *uuid.UUID) (*SecurityStats, error) {
 	query := s.client.SecurityEvent.Query()
@@ -192,13 +277,13 @@ func (s *SecurityService) GetSecurityStats(ctx context.Context, userID *uuid.UUI
 	}
 
 	// Get unresolved events
-	unresolvedEvents, err := query.Where(securityevent.ResolvedEQ(false)).Count(ctx)
+	unresolvedEvents, err := query.Clone().Where(securityevent.ResolvedEQ(false)).Count(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count unresolved events: %w", err)
 	}
 
 	// Get high/critical severity events
-	highSeverityEvents, err := query.Where(
+	highSeverityEvents, err := query.Clone().Where(
 		securityevent.SeverityIn(
 			securityevent.SeverityHigh,
 			securityevent.SeverityCritical,
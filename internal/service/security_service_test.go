@@ -0,0 +1,63 @@
+// internal/service/security_service_test.go
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gurkanbulca/taskmaster/ent/generated/securityevent"
+)
+
+func TestSecurityService_PurgeOldSecurityEvents(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	testUser := createTestUser(t, client)
+	securityService := NewSecurityService(client)
+
+	seed := func(severity securityevent.Severity, resolved bool, age time.Duration) uuid.UUID {
+		event, err := client.SecurityEvent.Create().
+			SetUserID(testUser.ID).
+			SetEventType(securityevent.EventTypeLoginFailed).
+			SetSeverity(severity).
+			SetDescription("Test event").
+			SetResolved(resolved).
+			SetCreatedAt(time.Now().Add(-age)).
+			Save(ctx)
+		require.NoError(t, err)
+		return event.ID
+	}
+
+	const retention = 30 * 24 * time.Hour
+	staleLow := seed(securityevent.SeverityLow, false, 40*24*time.Hour)
+	staleResolvedHigh := seed(securityevent.SeverityHigh, true, 40*24*time.Hour)
+	staleOpenCritical := seed(securityevent.SeverityCritical, false, 40*24*time.Hour)
+	recent := seed(securityevent.SeverityLow, false, time.Hour)
+
+	remaining := func() []uuid.UUID {
+		ids, err := client.SecurityEvent.Query().IDs(ctx)
+		require.NoError(t, err)
+		return ids
+	}
+
+	// Unresolved critical events survive while retention of them is enabled
+	deleted, err := securityService.PurgeOldSecurityEvents(ctx, retention, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.ElementsMatch(t, []uuid.UUID{staleOpenCritical, recent}, remaining())
+	assert.NotContains(t, remaining(), staleLow)
+	assert.NotContains(t, remaining(), staleResolvedHigh)
+
+	// Without retention every stale event goes
+	deleted, err = securityService.PurgeOldSecurityEvents(ctx, retention, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.ElementsMatch(t, []uuid.UUID{recent}, remaining())
+}
@@ -0,0 +1,175 @@
+// internal/service/task_events.go
+package service
+
+import (
+	"sync"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	taskv1 "github.com/gurkanbulca/taskmaster/api/proto/task/v1/generated"
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/pkg/authz"
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
+)
+
+// defaultTaskEventBuffer is the number of events queued per subscriber
+// before new events are dropped for that subscriber
+const defaultTaskEventBuffer = 64
+
+// TaskChange describes a task mutation published to watchers. The event
+// carries the whole task (its last state for deletions), so clients can
+// upsert or remove their copy by ID without refetching it.
+type TaskChange struct {
+	Event      *taskv1.TaskEvent
+	CreatorID  string
+	AssigneeID string
+}
+
+// TaskSubscription is a single watcher registered with the broker
+type TaskSubscription struct {
+	events  chan *TaskChange
+	filter  func(*TaskChange) bool
+	dropped int
+}
+
+// Events returns the channel of changes; it is closed on Unsubscribe
+func (s *TaskSubscription) Events() <-chan *TaskChange {
+	return s.events
+}
+
+// TaskEventBroker fans task changes out to in-process subscribers.
+// Publishing never blocks: when a subscriber's buffer is full the event is
+// dropped for that subscriber only.
+type TaskEventBroker struct {
+	mu          sync.RWMutex
+	subscribers map[*TaskSubscription]struct{}
+	bufferSize  int
+	logger      logging.Logger
+}
+
+// NewTaskEventBroker creates a new task event broker
+func NewTaskEventBroker(bufferSize int) *TaskEventBroker {
+	if bufferSize <= 0 {
+		bufferSize = defaultTaskEventBuffer
+	}
+
+	return &TaskEventBroker{
+		subscribers: make(map[*TaskSubscription]struct{}),
+		bufferSize:  bufferSize,
+		logger:      logging.Default(),
+	}
+}
+
+// SetLogger replaces the default logger
+func (b *TaskEventBroker) SetLogger(logger logging.Logger) {
+	b.logger = logger
+}
+
+// Subscribe registers a watcher that receives changes accepted by filter.
+// A nil filter accepts every change. Callers must Unsubscribe when done.
+func (b *TaskEventBroker) Subscribe(filter func(*TaskChange) bool) *TaskSubscription {
+	sub := &TaskSubscription{
+		events: make(chan *TaskChange, b.bufferSize),
+		filter: filter,
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
+
+// Unsubscribe removes a watcher and closes its channel
+func (b *TaskEventBroker) Unsubscribe(sub *TaskSubscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.events)
+}
+
+// Publish delivers a change to every matching subscriber without blocking
+func (b *TaskEventBroker) Publish(change *TaskChange) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(change) {
+			continue
+		}
+
+		select {
+		case sub.events <- change:
+		default:
+			sub.dropped++
+			b.logger.Warn("task event subscriber is falling behind", "dropped", sub.dropped)
+		}
+	}
+}
+
+// SubscriberCount returns the number of active subscribers
+func (b *TaskEventBroker) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
+
+// newTaskChange builds a change for a task whose creator and assignee edges are loaded
+func newTaskChange(eventType taskv1.TaskEvent_EventType, task *ent.Task) *TaskChange {
+	change := &TaskChange{
+		Event: &taskv1.TaskEvent{
+			EventType: eventType,
+			Task:      convertEntTaskToProto(task),
+			Timestamp: timestamppb.Now(),
+		},
+	}
+
+	if task.Edges.Creator != nil {
+		change.CreatorID = task.Edges.Creator.ID.String()
+	}
+	if task.Edges.Assignee != nil {
+		change.AssigneeID = task.Edges.Assignee.ID.String()
+	}
+
+	return change
+}
+
+// newWatchTasksFilter returns a filter applying the request criteria and the
+// caller's visibility: admins and managers see every task, other users only
+// tasks they created or are assigned to
+func newWatchTasksFilter(req *taskv1.WatchTasksRequest, userID, userRole string) func(*TaskChange) bool {
+	eventTypes := make(map[taskv1.TaskEvent_EventType]bool, len(req.EventTypes))
+	for _, t := range req.EventTypes {
+		eventTypes[t] = true
+	}
+
+	return func(change *TaskChange) bool {
+		if !authz.Can(userRole, authz.TasksReadAll) &&
+			change.CreatorID != userID && change.AssigneeID != userID {
+			return false
+		}
+
+		if len(eventTypes) > 0 && !eventTypes[change.Event.EventType] {
+			return false
+		}
+
+		task := change.Event.Task
+		if req.AssignedTo != "" && task.AssignedTo != req.AssignedTo && change.AssigneeID != req.AssignedTo {
+			return false
+		}
+
+		if req.CreatorId != "" && change.CreatorID != req.CreatorId {
+			return false
+		}
+
+		if req.Status != taskv1.TaskStatus_TASK_STATUS_UNSPECIFIED && task.Status != req.Status {
+			return false
+		}
+
+		return true
+	}
+}
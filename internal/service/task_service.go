@@ -3,7 +3,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,19 +18,80 @@ import (
 	ent "github.com/gurkanbulca/taskmaster/ent/generated"
 	"github.com/gurkanbulca/taskmaster/internal/middleware"
 	"github.com/gurkanbulca/taskmaster/internal/repository"
+	"github.com/gurkanbulca/taskmaster/pkg/authz"
+	"github.com/gurkanbulca/taskmaster/pkg/email"
+	"github.com/gurkanbulca/taskmaster/pkg/logging"
 )
 
+// allowedTaskSortFields lists the columns ListTasks can sort by
+var allowedTaskSortFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+	"due_date":   true,
+	"priority":   true,
+}
+
+// Window bounds for ListUpcomingTasks, in hours
+const (
+	defaultUpcomingWindowHours = 24
+	maxUpcomingWindowHours     = 24 * 30
+)
+
+// maxBatchSize caps the number of items accepted by batch RPCs
+const maxBatchSize = 100
+
+// defaultIdempotencyKeyTTL is how long a CreateTask idempotency key is honored
+const defaultIdempotencyKeyTTL = 24 * time.Hour
+
 type TaskService struct {
 	taskv1.UnimplementedTaskServiceServer
-	repo *repository.EntTaskRepository
+	repo      *repository.EntTaskRepository
+	comments  *repository.EntCommentRepository
+	templates *repository.EntTaskTemplateRepository
+	events    *TaskEventBroker
+	validator *middleware.EnhancedValidationInterceptor
+	email     email.EmailService
+	logger    logging.Logger
+
+	idempotencyKeyTTL time.Duration
+	templateSharing   bool
 }
 
 func NewTaskService(repo *repository.EntTaskRepository) *TaskService {
 	return &TaskService{
-		repo: repo,
+		repo:      repo,
+		comments:  repository.NewEntCommentRepository(repo.Client()),
+		templates: repository.NewEntTaskTemplateRepository(repo.Client()),
+		events:    NewTaskEventBroker(defaultTaskEventBuffer),
+		validator: middleware.NewEnhancedValidationInterceptor(nil),
+		logger:    logging.Default(),
+
+		idempotencyKeyTTL: defaultIdempotencyKeyTTL,
+		templateSharing:   true,
 	}
 }
 
+// SetIdempotencyKeyTTL sets how long CreateTask idempotency keys are honored
+func (s *TaskService) SetIdempotencyKeyTTL(ttl time.Duration) {
+	s.idempotencyKeyTTL = ttl
+}
+
+// SetLogger replaces the default logger for the service and its event broker
+func (s *TaskService) SetLogger(logger logging.Logger) {
+	s.logger = logger
+	s.events.SetLogger(logger)
+}
+
+// SetValidationConfig sets the limits used to validate batch items
+func (s *TaskService) SetValidationConfig(config *middleware.ValidationConfig) {
+	s.validator = middleware.NewEnhancedValidationInterceptor(config)
+}
+
+// SetEmailService enables assignment notification emails
+func (s *TaskService) SetEmailService(emailService email.EmailService) {
+	s.email = emailService
+}
+
 // CreateTask creates a new task
 func (s *TaskService) CreateTask(ctx context.Context, req *taskv1.CreateTaskRequest) (*taskv1.CreateTaskResponse, error) {
 	// Get user ID from context (set by auth middleware)
@@ -42,49 +105,123 @@ func (s *TaskService) CreateTask(ctx context.Context, req *taskv1.CreateTaskRequ
 		return nil, status.Error(codes.InvalidArgument, "title is required")
 	}
 
-	// Prepare input
-	input := &repository.TaskInput{
-		Title:       req.Title,
-		Description: req.Description,
-		Status:      "pending",
-		Priority:    convertPriorityToString(req.Priority),
-		CreatorID:   userID, // Set the creator
-	}
-
-	// Handle tags - ensure not nil
-	if req.Tags != nil && len(req.Tags) > 0 {
-		input.Tags = req.Tags
-	} else {
-		input.Tags = []string{}
+	// A retried request carrying the same idempotency key gets the original task back
+	key, hasKey := middleware.GetIdempotencyKeyFromContext(ctx)
+	if hasKey && len(key) > middleware.MaxIdempotencyKeyLength {
+		return nil, status.Errorf(codes.InvalidArgument, "idempotency key too long (max %d characters)", middleware.MaxIdempotencyKeyLength)
 	}
 
-	// Initialize metadata
-	input.Metadata = make(map[string]interface{})
+	input := newTaskInput(req, userID)
 
-	if req.AssignedTo != "" {
-		input.AssignedTo = &req.AssignedTo
-		// If assigned_to looks like a UUID, set it as assignee
-		if _, err := uuid.Parse(req.AssignedTo); err == nil {
-			input.AssigneeID = req.AssignedTo
+	// Subtasks require write access to the parent
+	if req.ParentId != "" {
+		userRole, _ := middleware.GetUserRoleFromContext(ctx)
+		parentID, err := s.resolveParentTask(ctx, req.ParentId, userID, userRole)
+		if err != nil {
+			return nil, err
 		}
-	}
-
-	if req.DueDate != nil {
-		dueDate := req.DueDate.AsTime()
-		input.DueDate = &dueDate
+		input.ParentID = &parentID
 	}
 
 	// Create task with creator
-	task, err := s.repo.CreateWithCreator(ctx, input, userID)
+	var (
+		task    *ent.Task
+		created = true
+		err     error
+	)
+	if hasKey {
+		task, created, err = s.repo.CreateIdempotent(ctx, input, userID, key, s.idempotencyKeyTTL)
+	} else {
+		task, err = s.repo.CreateWithCreator(ctx, input, userID)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create task: %v", err)
 	}
 
+	if created {
+		if loaded := s.publishTaskChange(ctx, taskv1.TaskEvent_EVENT_TYPE_CREATED, task.ID); loaded != nil {
+			s.notifyAssignee(ctx, loaded, "", userID)
+		}
+	}
+
 	return &taskv1.CreateTaskResponse{
 		Task: convertEntTaskToProto(task),
 	}, nil
 }
 
+// BatchCreateTasks creates several tasks in one round trip. Invalid items are
+// reported individually and do not prevent the valid ones from being created.
+func (s *TaskService) BatchCreateTasks(ctx context.Context, req *taskv1.BatchCreateTasksRequest) (*taskv1.BatchCreateTasksResponse, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	if len(req.Tasks) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one task is required")
+	}
+	if len(req.Tasks) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "too many tasks (max %d)", maxBatchSize)
+	}
+
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	var (
+		inputs   []*repository.TaskInput
+		itemErrs []*taskv1.BatchItemError
+	)
+
+	for i, item := range req.Tasks {
+		if item == nil {
+			itemErrs = append(itemErrs, &taskv1.BatchItemError{Index: int32(i), Message: "task is required"})
+			continue
+		}
+		if err := s.validator.ValidateCreateTaskRequest(item); err != nil {
+			itemErrs = append(itemErrs, &taskv1.BatchItemError{Index: int32(i), Message: status.Convert(err).Message()})
+			continue
+		}
+		input := newTaskInput(item, userID)
+
+		// Subtasks require write access to the parent, as in CreateTask
+		if item.ParentId != "" {
+			parentID, err := s.resolveParentTask(ctx, item.ParentId, userID, userRole)
+			if err != nil {
+				if status.Code(err) == codes.Internal {
+					return nil, err
+				}
+				itemErrs = append(itemErrs, &taskv1.BatchItemError{Index: int32(i), Message: status.Convert(err).Message()})
+				continue
+			}
+			input.ParentID = &parentID
+		}
+
+		inputs = append(inputs, input)
+	}
+
+	resp := &taskv1.BatchCreateTasksResponse{
+		Errors: itemErrs,
+	}
+
+	if len(inputs) == 0 {
+		return resp, nil
+	}
+
+	tasks, err := s.repo.CreateBatch(ctx, inputs, userID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create tasks: %v", err)
+	}
+
+	resp.Tasks = make([]*taskv1.Task, len(tasks))
+	for i, task := range tasks {
+		resp.Tasks[i] = convertEntTaskToProto(task)
+		if loaded := s.publishTaskChange(ctx, taskv1.TaskEvent_EVENT_TYPE_CREATED, task.ID); loaded != nil {
+			s.notifyAssignee(ctx, loaded, "", userID)
+		}
+	}
+
+	return resp, nil
+}
+
 // GetTask retrieves a task by ID
 func (s *TaskService) GetTask(ctx context.Context, req *taskv1.GetTaskRequest) (*taskv1.GetTaskResponse, error) {
 	// Get user info from context
@@ -110,24 +247,86 @@ func (s *TaskService) GetTask(ctx context.Context, req *taskv1.GetTaskRequest) (
 		return nil, status.Errorf(codes.Internal, "failed to get task: %v", err)
 	}
 
-	// Check permissions: admin can see all, others can only see their own or assigned tasks
-	if userRole != "admin" {
-		canView := false
-		if task.Edges.Creator != nil && task.Edges.Creator.ID.String() == userID {
-			canView = true
-		}
-		if task.Edges.Assignee != nil && task.Edges.Assignee.ID.String() == userID {
-			canView = true
+	// Check permissions
+	if !canAccessTask(task, userID, userRole, authz.TasksReadAll) {
+		return nil, status.Error(codes.PermissionDenied, "you don't have permission to view this task")
+	}
+
+	subtasks, err := s.repo.ListSubtasks(ctx, id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list subtasks: %v", err)
+	}
+
+	resp := &taskv1.GetTaskResponse{
+		Task: convertEntTaskToProto(task),
+	}
+	for _, subtask := range subtasks {
+		resp.Subtasks = append(resp.Subtasks, &taskv1.TaskSummary{
+			Id:     subtask.ID.String(),
+			Title:  subtask.Title,
+			Status: convertStringToStatus(string(subtask.Status)),
+		})
+	}
+
+	return resp, nil
+}
+
+// ListSubtasks returns the direct subtasks of a task the caller can view
+func (s *TaskService) ListSubtasks(ctx context.Context, req *taskv1.ListSubtasksRequest) (*taskv1.ListSubtasksResponse, error) {
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	parentID, err := uuid.Parse(req.ParentId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid parent task ID format")
+	}
+
+	parent, err := s.repo.GetByIDWithCreator(ctx, parentID)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "task not found")
 		}
+		return nil, status.Errorf(codes.Internal, "failed to get task: %v", err)
+	}
+
+	if !canAccessTask(parent, userID, userRole, authz.TasksReadAll) {
+		return nil, status.Error(codes.PermissionDenied, "you don't have permission to view this task")
+	}
+
+	subtasks, err := s.repo.ListSubtasks(ctx, parentID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list subtasks: %v", err)
+	}
+
+	resp := &taskv1.ListSubtasksResponse{}
+	for _, subtask := range subtasks {
+		resp.Tasks = append(resp.Tasks, convertEntTaskToProto(subtask))
+	}
+
+	return resp, nil
+}
+
+// resolveParentTask parses a parent task ID and checks the caller may add
+// subtasks to it
+func (s *TaskService) resolveParentTask(ctx context.Context, rawID, userID, userRole string) (uuid.UUID, error) {
+	parentID, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "invalid parent task ID format")
+	}
 
-		if !canView {
-			return nil, status.Error(codes.PermissionDenied, "you don't have permission to view this task")
+	parent, err := s.repo.GetByIDWithCreator(ctx, parentID)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return uuid.Nil, status.Error(codes.NotFound, "parent task not found")
 		}
+		return uuid.Nil, status.Errorf(codes.Internal, "failed to get parent task: %v", err)
 	}
 
-	return &taskv1.GetTaskResponse{
-		Task: convertEntTaskToProto(task),
-	}, nil
+	if !canAccessTask(parent, userID, userRole, authz.TasksWriteAll) {
+		return uuid.Nil, status.Error(codes.PermissionDenied, "you don't have permission to add subtasks to this task")
+	}
+
+	return parentID, nil
 }
 
 // ListTasks retrieves a list of tasks
@@ -136,27 +335,31 @@ func (s *TaskService) ListTasks(ctx context.Context, req *taskv1.ListTasksReques
 	userID, _ := middleware.GetUserIDFromContext(ctx)
 	userRole, _ := middleware.GetUserRoleFromContext(ctx)
 
-	// Set default page size
-	pageSize := req.PageSize
-	if pageSize <= 0 {
-		pageSize = 10
-	}
-	if pageSize > 100 {
-		pageSize = 100
+	offset, err := decodeOffsetPageToken(req.PageToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid page token")
 	}
 
 	// Build filter
 	filter := repository.ListFilter{
-		Limit:         int(pageSize),
-		Offset:        0,
+		Limit:         listPageSize(req.PageSize),
+		Offset:        offset,
 		WithRelations: true, // Include creator and assignee info
 	}
 
-	// If not admin, only show user's tasks (created or assigned)
-	if userRole != "admin" && userRole != "manager" {
+	// Without read_all, only show user's tasks (created or assigned)
+	if !authz.Can(userRole, authz.TasksReadAll) {
 		filter.UserID = &userID
 	}
 
+	// Soft-deleted tasks are only visible to admins
+	if req.IncludeDeleted {
+		if !authz.Can(userRole, authz.TasksReadDeleted) {
+			return nil, status.Error(codes.PermissionDenied, "only admins can list deleted tasks")
+		}
+		filter.IncludeDeleted = true
+	}
+
 	if req.Status != taskv1.TaskStatus_TASK_STATUS_UNSPECIFIED {
 		status := convertStatusToString(req.Status)
 		filter.Status = &status
@@ -167,22 +370,226 @@ func (s *TaskService) ListTasks(ctx context.Context, req *taskv1.ListTasksReques
 		filter.Priority = &priority
 	}
 
+	// Due date range is inclusive; tasks without a due date never match
+	if req.DueDateFrom != nil {
+		from := req.DueDateFrom.AsTime()
+		filter.DueAfter = &from
+	}
+	if req.DueDateTo != nil {
+		to := req.DueDateTo.AsTime()
+		filter.DueBefore = &to
+	}
+	if filter.DueAfter != nil && filter.DueBefore != nil && filter.DueAfter.After(*filter.DueBefore) {
+		return nil, status.Error(codes.InvalidArgument, "due_date_from must not be after due_date_to")
+	}
+
+	filter.Search = strings.TrimSpace(req.Search)
+
+	// Sorting defaults to newest first, or best match first when searching
+	filter.SortBy = "created_at"
+	if filter.Search != "" {
+		filter.SortBy = "relevance"
+	}
+	if err := applyTaskSort(&filter, req.SortBy, req.SortOrder); err != nil {
+		return nil, err
+	}
+
 	// Get tasks
 	tasks, totalCount, err := s.repo.List(ctx, filter)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to list tasks: %v", err)
 	}
 
-	// Convert to proto
+	protoTasks, nextPageToken := taskPage(tasks, offset, totalCount)
+	return &taskv1.ListTasksResponse{
+		Tasks:         protoTasks,
+		NextPageToken: nextPageToken,
+		TotalCount:    int32(totalCount),
+	}, nil
+}
+
+// ListMyTasks lists the tasks assigned to the caller, whoever created them
+func (s *TaskService) ListMyTasks(ctx context.Context, req *taskv1.ListMyTasksRequest) (*taskv1.ListMyTasksResponse, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	offset, err := decodeOffsetPageToken(req.PageToken)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid page token")
+	}
+
+	// Assignment alone grants access, so no role-based scoping is needed
+	filter := repository.ListFilter{
+		AssigneeID:    &userID,
+		Limit:         listPageSize(req.PageSize),
+		Offset:        offset,
+		WithRelations: true,
+		SortBy:        "created_at",
+	}
+
+	if req.Status != taskv1.TaskStatus_TASK_STATUS_UNSPECIFIED {
+		status := convertStatusToString(req.Status)
+		filter.Status = &status
+	}
+
+	if req.Priority != taskv1.Priority_PRIORITY_UNSPECIFIED {
+		priority := convertPriorityToString(req.Priority)
+		filter.Priority = &priority
+	}
+
+	if err := applyTaskSort(&filter, req.SortBy, req.SortOrder); err != nil {
+		return nil, err
+	}
+
+	tasks, totalCount, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list tasks: %v", err)
+	}
+
+	protoTasks, nextPageToken := taskPage(tasks, offset, totalCount)
+	return &taskv1.ListMyTasksResponse{
+		Tasks:         protoTasks,
+		NextPageToken: nextPageToken,
+		TotalCount:    int32(totalCount),
+	}, nil
+}
+
+// listPageSize applies the default and maximum page size for task listings
+func listPageSize(pageSize int32) int {
+	if pageSize <= 0 {
+		return 10
+	}
+	return int(min(pageSize, 100))
+}
+
+// applyTaskSort validates the requested ordering and sets it on filter,
+// keeping filter.SortBy as the default when none is requested
+func applyTaskSort(filter *repository.ListFilter, sortBy, sortOrder string) error {
+	if sortBy != "" {
+		if !allowedTaskSortFields[sortBy] {
+			return status.Errorf(codes.InvalidArgument, "invalid sort_by %q: must be one of created_at, updated_at, due_date, priority", sortBy)
+		}
+		filter.SortBy = sortBy
+	}
+
+	filter.SortOrder = "desc"
+	switch strings.ToLower(sortOrder) {
+	case "", "desc":
+	case "asc":
+		filter.SortOrder = "asc"
+	default:
+		return status.Errorf(codes.InvalidArgument, "invalid sort_order %q: must be asc or desc", sortOrder)
+	}
+	return nil
+}
+
+// taskPage converts one page of tasks to proto, with a next page token only
+// when more results remain
+func taskPage(tasks []*ent.Task, offset, totalCount int) ([]*taskv1.Task, string) {
 	protoTasks := make([]*taskv1.Task, len(tasks))
 	for i, task := range tasks {
 		protoTasks[i] = convertEntTaskToProto(task)
 	}
 
-	return &taskv1.ListTasksResponse{
-		Tasks:         protoTasks,
-		NextPageToken: "",
-		TotalCount:    int32(totalCount),
+	nextPageToken := ""
+	if nextOffset := offset + len(tasks); len(tasks) > 0 && nextOffset < totalCount {
+		nextPageToken = encodeOffsetPageToken(nextOffset)
+	}
+	return protoTasks, nextPageToken
+}
+
+// GetTaskStatistics returns task counts by status and priority for the caller's tasks
+func (s *TaskService) GetTaskStatistics(ctx context.Context, _ *taskv1.GetTaskStatisticsRequest) (*taskv1.GetTaskStatisticsResponse, error) {
+	// Get user info from context
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+
+	// Statistics are personal: always scoped to the caller, whatever their role
+	stats, err := s.repo.Stats(ctx, repository.ListFilter{UserID: &userID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get task statistics: %v", err)
+	}
+
+	resp := &taskv1.GetTaskStatisticsResponse{
+		TotalTasks:   int32(stats.Total),
+		OverdueTasks: int32(stats.Overdue),
+	}
+
+	// Report every status and priority, including empty buckets
+	for _, st := range []taskv1.TaskStatus{
+		taskv1.TaskStatus_TASK_STATUS_PENDING,
+		taskv1.TaskStatus_TASK_STATUS_IN_PROGRESS,
+		taskv1.TaskStatus_TASK_STATUS_COMPLETED,
+		taskv1.TaskStatus_TASK_STATUS_CANCELLED,
+	} {
+		resp.ByStatus = append(resp.ByStatus, &taskv1.StatusCount{
+			Status: st,
+			Count:  int32(stats.ByStatus[convertStatusToString(st)]),
+		})
+	}
+
+	for _, p := range []taskv1.Priority{
+		taskv1.Priority_PRIORITY_LOW,
+		taskv1.Priority_PRIORITY_MEDIUM,
+		taskv1.Priority_PRIORITY_HIGH,
+		taskv1.Priority_PRIORITY_CRITICAL,
+	} {
+		resp.ByPriority = append(resp.ByPriority, &taskv1.PriorityCount{
+			Priority: p,
+			Count:    int32(stats.ByPriority[convertPriorityToString(p)]),
+		})
+	}
+
+	return resp, nil
+}
+
+// ListUpcomingTasks returns the caller's open tasks due within the requested
+// number of hours, soonest first. Overdue tasks are included and flagged
+// when include_overdue is set; tasks without a due date are never returned.
+func (s *TaskService) ListUpcomingTasks(ctx context.Context, req *taskv1.ListUpcomingTasksRequest) (*taskv1.ListUpcomingTasksResponse, error) {
+	// Get user info from context
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+
+	withinHours := req.WithinHours
+	if withinHours < 0 || withinHours > maxUpcomingWindowHours {
+		return nil, status.Errorf(codes.InvalidArgument, "within_hours must be between 0 and %d", maxUpcomingWindowHours)
+	}
+	if withinHours == 0 {
+		withinHours = defaultUpcomingWindowHours
+	}
+
+	now := time.Now()
+	dueBefore := now.Add(time.Duration(withinHours) * time.Hour)
+
+	// Upcoming tasks are personal: always scoped to the caller, whatever their role
+	filter := repository.ListFilter{
+		UserID:          &userID,
+		DueBefore:       &dueBefore,
+		ExcludeStatuses: []string{"completed", "cancelled"},
+		SortBy:          "due_date",
+		SortOrder:       "asc",
+		WithRelations:   true,
+	}
+	if !req.IncludeOverdue {
+		filter.DueAfter = &now
+	}
+
+	tasks, _, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list upcoming tasks: %v", err)
+	}
+
+	items := make([]*taskv1.UpcomingTask, len(tasks))
+	for i, task := range tasks {
+		items[i] = &taskv1.UpcomingTask{
+			Task:    convertEntTaskToProto(task),
+			Overdue: task.DueDate != nil && task.DueDate.Before(now),
+		}
+	}
+
+	return &taskv1.ListUpcomingTasksResponse{
+		Tasks: items,
 	}, nil
 }
 
@@ -212,20 +619,12 @@ func (s *TaskService) UpdateTask(ctx context.Context, req *taskv1.UpdateTaskRequ
 	}
 
 	// Check permissions
-	canUpdate := userRole == "admin" || userRole == "manager"
-	if !canUpdate && existingTask.Edges.Creator != nil && existingTask.Edges.Creator.ID.String() == userID {
-		canUpdate = true
-	}
-	if !canUpdate && existingTask.Edges.Assignee != nil && existingTask.Edges.Assignee.ID.String() == userID {
-		canUpdate = true
-	}
-
-	if !canUpdate {
+	if !canAccessTask(existingTask, userID, userRole, authz.TasksWriteAll) {
 		return nil, status.Error(codes.PermissionDenied, "you don't have permission to update this task")
 	}
 
 	// Build update input
-	input := &repository.TaskUpdateInput{}
+	input := &repository.TaskUpdateInput{ActorID: parseActorID(userID)}
 
 	if req.Title != "" {
 		input.Title = &req.Title
@@ -255,21 +654,197 @@ func (s *TaskService) UpdateTask(ctx context.Context, req *taskv1.UpdateTaskRequ
 	if len(req.Tags) > 0 {
 		input.Tags = req.Tags
 	}
+	if req.ExpectedVersion > 0 {
+		expectedVersion := int(req.ExpectedVersion)
+		input.ExpectedVersion = &expectedVersion
+	}
+	if req.ParentId != "" {
+		parentID, err := s.resolveParentTask(ctx, req.ParentId, userID, userRole)
+		if err != nil {
+			return nil, err
+		}
+		input.ParentID = &parentID
+	}
 
 	// Update task
 	task, err := s.repo.Update(ctx, id, input)
 	if err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, status.Error(codes.Aborted, "task was modified by someone else; reload it and retry")
+		}
+		if errors.Is(err, repository.ErrTaskCycle) {
+			return nil, status.Error(codes.InvalidArgument, "a task cannot be moved under itself or one of its subtasks")
+		}
 		if ent.IsNotFound(err) {
 			return nil, status.Error(codes.NotFound, "task not found")
 		}
 		return nil, status.Errorf(codes.Internal, "failed to update task: %v", err)
 	}
 
+	// Status-only changes get their own event type so boards can move the
+	// task between columns without treating it as an edit
+	eventType := taskv1.TaskEvent_EVENT_TYPE_UPDATED
+	if isStatusOnlyUpdate(input) && *input.Status != string(existingTask.Status) {
+		eventType = taskv1.TaskEvent_EVENT_TYPE_STATUS_CHANGED
+	}
+	if loaded := s.publishTaskChange(ctx, eventType, task.ID); loaded != nil {
+		previousAssigneeID := ""
+		if existingTask.Edges.Assignee != nil {
+			previousAssigneeID = existingTask.Edges.Assignee.ID.String()
+		}
+		s.notifyAssignee(ctx, loaded, previousAssigneeID, userID)
+	}
+
 	return &taskv1.UpdateTaskResponse{
 		Task: convertEntTaskToProto(task),
 	}, nil
 }
 
+// BatchGetTasks loads several tasks in one query. Tasks that don't exist or
+// that the caller may not view are reported in missing_ids alike, so the
+// response doesn't reveal which IDs belong to other users.
+func (s *TaskService) BatchGetTasks(ctx context.Context, req *taskv1.BatchGetTasksRequest) (*taskv1.BatchGetTasksResponse, error) {
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	ids, err := parseBatchIDs(req.Ids, "task")
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get tasks: %v", err)
+	}
+
+	byID := make(map[uuid.UUID]*ent.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	// Answer in request order
+	resp := &taskv1.BatchGetTasksResponse{
+		Tasks: make([]*taskv1.Task, 0, len(tasks)),
+	}
+	for _, id := range ids {
+		task, ok := byID[id]
+		if !ok || !canAccessTask(task, userID, userRole, authz.TasksReadAll) {
+			resp.MissingIds = append(resp.MissingIds, id.String())
+			continue
+		}
+		resp.Tasks = append(resp.Tasks, convertEntTaskToProto(task))
+	}
+
+	return resp, nil
+}
+
+// BatchUpdateTaskStatus sets the status of several tasks atomically: either
+// every task is updated or none is
+func (s *TaskService) BatchUpdateTaskStatus(ctx context.Context, req *taskv1.BatchUpdateTaskStatusRequest) (*taskv1.BatchUpdateTaskStatusResponse, error) {
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	ids, err := parseBatchIDs(req.Ids, "task")
+	if err != nil {
+		return nil, err
+	}
+	if req.Status == taskv1.TaskStatus_TASK_STATUS_UNSPECIFIED || convertStringToStatus(convertStatusToString(req.Status)) != req.Status {
+		return nil, status.Error(codes.InvalidArgument, "invalid task status")
+	}
+
+	// Check permissions on every task before touching any of them
+	for _, id := range ids {
+		existingTask, err := s.repo.GetByIDWithCreator(ctx, id)
+		if err != nil {
+			if ent.IsNotFound(err) {
+				return nil, status.Errorf(codes.NotFound, "task not found: %s", id)
+			}
+			return nil, status.Errorf(codes.Internal, "failed to get task: %v", err)
+		}
+		if !canAccessTask(existingTask, userID, userRole, authz.TasksWriteAll) {
+			return nil, status.Errorf(codes.PermissionDenied, "you don't have permission to update task %s", id)
+		}
+	}
+
+	if err := s.repo.UpdateStatusBatch(ctx, ids, convertStatusToString(req.Status), parseActorID(userID)); err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update tasks: %v", err)
+	}
+
+	resp := &taskv1.BatchUpdateTaskStatusResponse{
+		Tasks: make([]*taskv1.Task, 0, len(ids)),
+	}
+	for _, id := range ids {
+		task, err := s.repo.GetByIDWithCreator(ctx, id)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get task: %v", err)
+		}
+		resp.Tasks = append(resp.Tasks, convertEntTaskToProto(task))
+		s.events.Publish(newTaskChange(taskv1.TaskEvent_EVENT_TYPE_STATUS_CHANGED, task))
+	}
+
+	return resp, nil
+}
+
+// BatchDeleteTasks soft-deletes several tasks in one transaction and reports
+// a result per ID. As in BatchGetTasks, tasks the caller may not delete are
+// reported as not found, and they don't stop the others from being deleted.
+func (s *TaskService) BatchDeleteTasks(ctx context.Context, req *taskv1.BatchDeleteTasksRequest) (*taskv1.BatchDeleteTasksResponse, error) {
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	ids, err := parseBatchIDs(req.Ids, "task")
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get tasks: %v", err)
+	}
+
+	byID := make(map[uuid.UUID]*ent.Task, len(tasks))
+	for _, task := range tasks {
+		if canAccessTask(task, userID, userRole, authz.TasksWriteAll) {
+			byID[task.ID] = task
+		}
+	}
+
+	allowed := make([]uuid.UUID, 0, len(byID))
+	for _, id := range ids {
+		if _, ok := byID[id]; ok {
+			allowed = append(allowed, id)
+		}
+	}
+
+	deletedIDs, err := s.repo.DeleteBatch(ctx, allowed, parseActorID(userID))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete tasks: %v", err)
+	}
+	deleted := make(map[uuid.UUID]bool, len(deletedIDs))
+	for _, id := range deletedIDs {
+		deleted[id] = true
+	}
+
+	// Answer in request order
+	resp := &taskv1.BatchDeleteTasksResponse{
+		Results: make([]*taskv1.BatchDeleteTaskResult, 0, len(ids)),
+	}
+	for _, id := range ids {
+		result := &taskv1.BatchDeleteTaskResult{Id: id.String(), Deleted: deleted[id]}
+		if result.Deleted {
+			s.events.Publish(newTaskChange(taskv1.TaskEvent_EVENT_TYPE_DELETED, byID[id]))
+		} else {
+			result.Error = "task not found"
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	return resp, nil
+}
+
 // DeleteTask deletes a task
 func (s *TaskService) DeleteTask(ctx context.Context, req *taskv1.DeleteTaskRequest) (*emptypb.Empty, error) {
 	// Get user info from context
@@ -295,52 +870,376 @@ func (s *TaskService) DeleteTask(ctx context.Context, req *taskv1.DeleteTaskRequ
 		return nil, status.Errorf(codes.Internal, "failed to get task: %v", err)
 	}
 
-	// Check permissions: only creator or admin can delete
-	canDelete := userRole == "admin"
-	if !canDelete && existingTask.Edges.Creator != nil && existingTask.Edges.Creator.ID.String() == userID {
-		canDelete = true
-	}
-
-	if !canDelete {
+	// Check permissions
+	if !canAccessTask(existingTask, userID, userRole, authz.TasksWriteAll) {
 		return nil, status.Error(codes.PermissionDenied, "you don't have permission to delete this task")
 	}
 
 	// Delete task
-	if err := s.repo.Delete(ctx, id); err != nil {
+	if err := s.repo.Delete(ctx, id, parseActorID(userID)); err != nil {
 		if ent.IsNotFound(err) {
 			return nil, status.Error(codes.NotFound, "task not found")
 		}
 		return nil, status.Errorf(codes.Internal, "failed to delete task: %v", err)
 	}
 
+	// The task is gone, so publish the snapshot loaded before deletion
+	s.events.Publish(newTaskChange(taskv1.TaskEvent_EVENT_TYPE_DELETED, existingTask))
+
+	return &emptypb.Empty{}, nil
+}
+
+// RestoreTask undoes a soft delete
+func (s *TaskService) RestoreTask(ctx context.Context, req *taskv1.RestoreTaskRequest) (*taskv1.RestoreTaskResponse, error) {
+	// Get user info from context
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	// Parse UUID
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid task ID format")
+	}
+
+	existingTask, err := s.repo.GetByIDIncludingDeleted(ctx, id)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get task: %v", err)
+	}
+
+	// Check permissions
+	if !canAccessTask(existingTask, userID, userRole, authz.TasksWriteAll) {
+		return nil, status.Error(codes.PermissionDenied, "you don't have permission to restore this task")
+	}
+
+	if existingTask.DeletedAt == nil {
+		return nil, status.Error(codes.FailedPrecondition, "task is not deleted")
+	}
+
+	task, err := s.repo.Restore(ctx, id, parseActorID(userID))
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.FailedPrecondition, "task is not deleted")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to restore task: %v", err)
+	}
+
+	s.publishTaskChange(ctx, taskv1.TaskEvent_EVENT_TYPE_UPDATED, task.ID)
+
+	return &taskv1.RestoreTaskResponse{
+		Task: convertEntTaskToProto(task),
+	}, nil
+}
+
+// GetTaskHistory returns a task's activity log, oldest first. The history of
+// a deleted task remains readable so its deletion can be traced.
+func (s *TaskService) GetTaskHistory(ctx context.Context, req *taskv1.GetTaskHistoryRequest) (*taskv1.GetTaskHistoryResponse, error) {
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	id, err := uuid.Parse(req.TaskId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid task ID format")
+	}
+
+	task, err := s.repo.GetByIDIncludingDeleted(ctx, id)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "task not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get task: %v", err)
+	}
+
+	if !canAccessTask(task, userID, userRole, authz.TasksReadAll) {
+		return nil, status.Error(codes.PermissionDenied, "you don't have permission to view this task")
+	}
+
+	activities, err := s.repo.ListActivity(ctx, id)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get task history: %v", err)
+	}
+
+	resp := &taskv1.GetTaskHistoryResponse{}
+	for _, activity := range activities {
+		resp.Activities = append(resp.Activities, convertEntTaskActivityToProto(activity))
+	}
+
+	return resp, nil
+}
+
+// AddComment adds a comment to a task the caller can view
+func (s *TaskService) AddComment(ctx context.Context, req *taskv1.AddCommentRequest) (*taskv1.AddCommentResponse, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	taskID, err := s.resolveCommentTask(ctx, req.TaskId, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	authorID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "invalid user ID")
+	}
+
+	comment, err := s.comments.Create(ctx, taskID, authorID, req.Body)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to add comment: %v", err)
+	}
+
+	return &taskv1.AddCommentResponse{
+		Comment: convertEntCommentToProto(comment),
+	}, nil
+}
+
+// ListComments returns a task's comments in the order they were written
+func (s *TaskService) ListComments(ctx context.Context, req *taskv1.ListCommentsRequest) (*taskv1.ListCommentsResponse, error) {
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	taskID, err := s.resolveCommentTask(ctx, req.TaskId, userID, userRole)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.comments.ListByTask(ctx, taskID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list comments: %v", err)
+	}
+
+	resp := &taskv1.ListCommentsResponse{}
+	for _, comment := range comments {
+		resp.Comments = append(resp.Comments, convertEntCommentToProto(comment))
+	}
+
+	return resp, nil
+}
+
+// DeleteComment deletes a comment; only its author or an admin may do so
+func (s *TaskService) DeleteComment(ctx context.Context, req *taskv1.DeleteCommentRequest) (*emptypb.Empty, error) {
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	id, err := uuid.Parse(req.Id)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid comment ID format")
+	}
+
+	comment, err := s.comments.GetByID(ctx, id)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "comment not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get comment: %v", err)
+	}
+
+	if comment.AuthorID.String() != userID && !authz.Can(userRole, authz.CommentsDeleteAll) {
+		return nil, status.Error(codes.PermissionDenied, "you don't have permission to delete this comment")
+	}
+
+	if err := s.comments.Delete(ctx, id); err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "comment not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to delete comment: %v", err)
+	}
+
 	return &emptypb.Empty{}, nil
 }
 
-// WatchTasks streams task events
+// resolveCommentTask parses a task ID and checks the caller may view the task
+func (s *TaskService) resolveCommentTask(ctx context.Context, rawID, userID, userRole string) (uuid.UUID, error) {
+	taskID, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.InvalidArgument, "invalid task ID format")
+	}
+
+	task, err := s.repo.GetByIDWithCreator(ctx, taskID)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return uuid.Nil, status.Error(codes.NotFound, "task not found")
+		}
+		return uuid.Nil, status.Errorf(codes.Internal, "failed to get task: %v", err)
+	}
+
+	if !canAccessTask(task, userID, userRole, authz.TasksReadAll) {
+		return uuid.Nil, status.Error(codes.PermissionDenied, "you don't have permission to view this task")
+	}
+
+	return taskID, nil
+}
+
+// WatchTasks streams task change events matching the request filters
 func (s *TaskService) WatchTasks(req *taskv1.WatchTasksRequest, stream taskv1.TaskService_WatchTasksServer) error {
-	// This is a simplified implementation
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	ctx := stream.Context()
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	sub := s.events.Subscribe(newWatchTasksFilter(req, userID, userRole))
+	defer s.events.Unsubscribe(sub)
 
 	for {
 		select {
-		case <-stream.Context().Done():
+		case <-ctx.Done():
 			return nil
-		case <-ticker.C:
-			event := &taskv1.TaskEvent{
-				EventType: taskv1.TaskEvent_EVENT_TYPE_UPDATED,
-				Timestamp: timestamppb.Now(),
+		case change, ok := <-sub.Events():
+			if !ok {
+				return nil
 			}
-
-			if err := stream.Send(event); err != nil {
+			if err := stream.Send(change.Event); err != nil {
 				return err
 			}
 		}
 	}
 }
 
+// parseBatchIDs parses and de-duplicates the IDs of a batch request,
+// preserving request order. noun names the IDs in error messages, e.g. "task".
+func parseBatchIDs(rawIDs []string, noun string) ([]uuid.UUID, error) {
+	if len(rawIDs) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "at least one %s ID is required", noun)
+	}
+	if len(rawIDs) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "too many %s IDs (max %d)", noun, maxBatchSize)
+	}
+
+	ids := make([]uuid.UUID, 0, len(rawIDs))
+	seen := make(map[uuid.UUID]bool, len(rawIDs))
+	for _, rawID := range rawIDs {
+		id, err := uuid.Parse(rawID)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid %s ID format: %s", noun, rawID)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// isStatusOnlyUpdate reports whether an update changes nothing but the status
+func isStatusOnlyUpdate(input *repository.TaskUpdateInput) bool {
+	return input.Status != nil &&
+		input.Title == nil &&
+		input.Description == nil &&
+		input.Priority == nil &&
+		input.AssignedTo == nil &&
+		input.AssigneeID == nil &&
+		input.DueDate == nil &&
+		input.Tags == nil &&
+		input.Metadata == nil &&
+		input.ParentID == nil
+}
+
+// publishTaskChange reloads a task with its relations and publishes it to
+// watchers. It returns the reloaded task, or nil if it could not be loaded.
+func (s *TaskService) publishTaskChange(ctx context.Context, eventType taskv1.TaskEvent_EventType, id uuid.UUID) *ent.Task {
+	task, err := s.repo.GetByIDWithCreator(ctx, id)
+	if err != nil {
+		s.logger.Error("failed to load task for event", "task_id", id, "event_type", eventType.String(), "error", err)
+		return nil
+	}
+	s.events.Publish(newTaskChange(eventType, task))
+	return task
+}
+
+// notifyAssignee emails the task's assignee when they are newly assigned by
+// someone else and want task assignment emails. Send failures are only
+// logged so they never fail the task mutation.
+func (s *TaskService) notifyAssignee(ctx context.Context, task *ent.Task, previousAssigneeID, actorID string) {
+	assignee := task.Edges.Assignee
+	if s.email == nil || assignee == nil {
+		return
+	}
+
+	assigneeID := assignee.ID.String()
+	if assigneeID == previousAssigneeID || assigneeID == actorID || !notificationEnabled(assignee, notifyTaskAssigned) {
+		return
+	}
+
+	if err := s.email.SendTaskAssignedEmail(ctx, assignee, task); err != nil {
+		s.logger.Error("failed to send task assigned email", "task_id", task.ID, "error", err)
+	}
+}
+
 // Helper functions
 
+// newTaskInput converts a create request into repository input owned by creatorID
+func newTaskInput(req *taskv1.CreateTaskRequest, creatorID string) *repository.TaskInput {
+	input := &repository.TaskInput{
+		Title:       req.Title,
+		Description: req.Description,
+		Status:      "pending",
+		Priority:    convertPriorityToString(req.Priority),
+		CreatorID:   creatorID,
+	}
+
+	// Handle tags - ensure not nil
+	if len(req.Tags) > 0 {
+		input.Tags = req.Tags
+	} else {
+		input.Tags = []string{}
+	}
+
+	// Initialize metadata
+	input.Metadata = make(map[string]interface{})
+
+	if req.AssignedTo != "" {
+		input.AssignedTo = &req.AssignedTo
+		// If assigned_to looks like a UUID, set it as assignee
+		if _, err := uuid.Parse(req.AssignedTo); err == nil {
+			input.AssigneeID = req.AssignedTo
+		}
+	}
+
+	if req.DueDate != nil {
+		dueDate := req.DueDate.AsTime()
+		input.DueDate = &dueDate
+	}
+
+	return input
+}
+
+// canAccessTask reports whether the caller may read or modify a task: roles
+// granted the permission can access any task, other users only tasks they
+// created or are assigned to. The task must be loaded with its creator and assignee.
+func canAccessTask(task *ent.Task, userID, userRole string, permission authz.Permission) bool {
+	if authz.Can(userRole, permission) {
+		return true
+	}
+	if userID == "" {
+		return false
+	}
+	if task.Edges.Creator != nil && task.Edges.Creator.ID.String() == userID {
+		return true
+	}
+	if task.Edges.Assignee != nil && task.Edges.Assignee.ID.String() == userID {
+		return true
+	}
+	return false
+}
+
+// parseActorID parses the caller's user ID for the activity log, or returns nil
+// if there is none
+func parseActorID(userID string) *uuid.UUID {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
 func convertEntTaskToProto(task *ent.Task) *taskv1.Task {
 	proto := &taskv1.Task{
 		Id:          task.ID.String(),
@@ -351,6 +1250,7 @@ func convertEntTaskToProto(task *ent.Task) *taskv1.Task {
 		CreatedAt:   timestamppb.New(task.CreatedAt),
 		UpdatedAt:   timestamppb.New(task.UpdatedAt),
 		Tags:        task.Tags,
+		Version:     int64(task.Version),
 	}
 
 	if task.AssignedTo != "" {
@@ -361,6 +1261,14 @@ func convertEntTaskToProto(task *ent.Task) *taskv1.Task {
 		proto.DueDate = timestamppb.New(*task.DueDate)
 	}
 
+	if task.DeletedAt != nil {
+		proto.DeletedAt = timestamppb.New(*task.DeletedAt)
+	}
+
+	if task.ParentID != nil {
+		proto.ParentId = task.ParentID.String()
+	}
+
 	if task.Metadata != nil {
 		proto.Metadata = make(map[string]string)
 		for k, v := range task.Metadata {
@@ -371,6 +1279,44 @@ func convertEntTaskToProto(task *ent.Task) *taskv1.Task {
 	return proto
 }
 
+func convertEntTaskActivityToProto(activity *ent.TaskActivity) *taskv1.TaskActivity {
+	proto := &taskv1.TaskActivity{
+		Id:        activity.ID.String(),
+		TaskId:    activity.TaskID.String(),
+		Action:    string(activity.Action),
+		Field:     activity.ChangedField,
+		OldValue:  activity.OldValue,
+		NewValue:  activity.NewValue,
+		CreatedAt: timestamppb.New(activity.CreatedAt),
+	}
+
+	if activity.ActorID != nil {
+		proto.ActorId = activity.ActorID.String()
+	}
+	if activity.Edges.Actor != nil {
+		proto.ActorUsername = activity.Edges.Actor.Username
+	}
+
+	return proto
+}
+
+func convertEntCommentToProto(comment *ent.Comment) *taskv1.Comment {
+	proto := &taskv1.Comment{
+		Id:        comment.ID.String(),
+		TaskId:    comment.TaskID.String(),
+		AuthorId:  comment.AuthorID.String(),
+		Body:      comment.Body,
+		CreatedAt: timestamppb.New(comment.CreatedAt),
+		UpdatedAt: timestamppb.New(comment.UpdatedAt),
+	}
+
+	if comment.Edges.Author != nil {
+		proto.AuthorUsername = comment.Edges.Author.Username
+	}
+
+	return proto
+}
+
 func convertStatusToString(status taskv1.TaskStatus) string {
 	switch status {
 	case taskv1.TaskStatus_TASK_STATUS_PENDING:
@@ -0,0 +1,318 @@
+// internal/service/task_templates.go
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	taskv1 "github.com/gurkanbulca/taskmaster/api/proto/task/v1/generated"
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/internal/middleware"
+	"github.com/gurkanbulca/taskmaster/internal/repository"
+	"github.com/gurkanbulca/taskmaster/pkg/authz"
+)
+
+// SetTemplateSharing controls whether templates marked as shared are visible
+// to every user; when disabled, templates are private to their owner
+func (s *TaskService) SetTemplateSharing(enabled bool) {
+	s.templateSharing = enabled
+}
+
+// CreateTaskTemplate saves a template owned by the caller
+func (s *TaskService) CreateTaskTemplate(ctx context.Context, req *taskv1.CreateTaskTemplateRequest) (*taskv1.CreateTaskTemplateResponse, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	ownerID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "invalid user ID")
+	}
+
+	if strings.TrimSpace(req.Name) == "" || strings.TrimSpace(req.TitlePattern) == "" {
+		return nil, status.Error(codes.InvalidArgument, "name and title pattern are required")
+	}
+	if req.Shared && !s.templateSharing {
+		return nil, status.Error(codes.FailedPrecondition, "template sharing is disabled")
+	}
+
+	priority := "medium"
+	if req.Priority != taskv1.Priority_PRIORITY_UNSPECIFIED {
+		priority = convertPriorityToString(req.Priority)
+	}
+
+	template, err := s.templates.Create(ctx, ownerID, &repository.TaskTemplateInput{
+		Name:         req.Name,
+		TitlePattern: req.TitlePattern,
+		Description:  req.Description,
+		Priority:     priority,
+		Tags:         req.Tags,
+		Shared:       req.Shared,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create template: %v", err)
+	}
+
+	return &taskv1.CreateTaskTemplateResponse{
+		Template: convertEntTaskTemplateToProto(template),
+	}, nil
+}
+
+// GetTaskTemplate returns a template the caller may use
+func (s *TaskService) GetTaskTemplate(ctx context.Context, req *taskv1.GetTaskTemplateRequest) (*taskv1.GetTaskTemplateResponse, error) {
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	template, err := s.getTaskTemplate(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.canUseTemplate(template, userID, userRole) {
+		return nil, status.Error(codes.PermissionDenied, "you don't have permission to view this template")
+	}
+
+	return &taskv1.GetTaskTemplateResponse{
+		Template: convertEntTaskTemplateToProto(template),
+	}, nil
+}
+
+// ListTaskTemplates returns the caller's templates and, when sharing is
+// enabled, the templates other users have shared
+func (s *TaskService) ListTaskTemplates(ctx context.Context, _ *taskv1.ListTaskTemplatesRequest) (*taskv1.ListTaskTemplatesResponse, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+
+	ownerID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "invalid user ID")
+	}
+
+	templates, err := s.templates.List(ctx, ownerID, s.templateSharing)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list templates: %v", err)
+	}
+
+	resp := &taskv1.ListTaskTemplatesResponse{}
+	for _, template := range templates {
+		resp.Templates = append(resp.Templates, convertEntTaskTemplateToProto(template))
+	}
+
+	return resp, nil
+}
+
+// UpdateTaskTemplate changes a template; only its owner or an admin may do so
+func (s *TaskService) UpdateTaskTemplate(ctx context.Context, req *taskv1.UpdateTaskTemplateRequest) (*taskv1.UpdateTaskTemplateResponse, error) {
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	template, err := s.getTaskTemplate(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canManageTemplate(template, userID, userRole) {
+		return nil, status.Error(codes.PermissionDenied, "you don't have permission to update this template")
+	}
+
+	// Empty fields are left unchanged, as in UpdateTask
+	input := &repository.TaskTemplateUpdateInput{}
+	if req.Name != "" {
+		input.Name = &req.Name
+	}
+	if req.TitlePattern != "" {
+		input.TitlePattern = &req.TitlePattern
+	}
+	if req.Description != "" {
+		input.Description = &req.Description
+	}
+	if req.Priority != taskv1.Priority_PRIORITY_UNSPECIFIED {
+		priority := convertPriorityToString(req.Priority)
+		input.Priority = &priority
+	}
+	if len(req.Tags) > 0 {
+		input.Tags = req.Tags
+	}
+	if req.Shared != nil {
+		if *req.Shared && !s.templateSharing {
+			return nil, status.Error(codes.FailedPrecondition, "template sharing is disabled")
+		}
+		input.Shared = req.Shared
+	}
+
+	updated, err := s.templates.Update(ctx, template.ID, input)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "template not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to update template: %v", err)
+	}
+
+	return &taskv1.UpdateTaskTemplateResponse{
+		Template: convertEntTaskTemplateToProto(updated),
+	}, nil
+}
+
+// DeleteTaskTemplate deletes a template; only its owner or an admin may do so
+func (s *TaskService) DeleteTaskTemplate(ctx context.Context, req *taskv1.DeleteTaskTemplateRequest) (*emptypb.Empty, error) {
+	userID, _ := middleware.GetUserIDFromContext(ctx)
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	template, err := s.getTaskTemplate(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canManageTemplate(template, userID, userRole) {
+		return nil, status.Error(codes.PermissionDenied, "you don't have permission to delete this template")
+	}
+
+	if err := s.templates.Delete(ctx, template.ID); err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "template not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to delete template: %v", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// CreateTaskFromTemplate creates a task from a template's defaults. Fields set
+// on the request override the template's, and {placeholders} in the title and
+// description are filled from the request variables; {date} defaults to today.
+func (s *TaskService) CreateTaskFromTemplate(ctx context.Context, req *taskv1.CreateTaskFromTemplateRequest) (*taskv1.CreateTaskFromTemplateResponse, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+
+	template, err := s.getTaskTemplate(ctx, req.TemplateId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.canUseTemplate(template, userID, userRole) {
+		return nil, status.Error(codes.PermissionDenied, "you don't have permission to use this template")
+	}
+
+	createReq := newCreateTaskRequestFromTemplate(template, req, time.Now())
+	if err := s.validator.ValidateCreateTaskRequest(createReq); err != nil {
+		return nil, err
+	}
+
+	created, err := s.CreateTask(ctx, createReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &taskv1.CreateTaskFromTemplateResponse{
+		Task: created.Task,
+	}, nil
+}
+
+// getTaskTemplate parses a template ID and loads the template
+func (s *TaskService) getTaskTemplate(ctx context.Context, rawID string) (*ent.TaskTemplate, error) {
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid template ID format")
+	}
+
+	template, err := s.templates.GetByID(ctx, id)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, status.Error(codes.NotFound, "template not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get template: %v", err)
+	}
+
+	return template, nil
+}
+
+// canUseTemplate reports whether the caller may view and create tasks from a template
+func (s *TaskService) canUseTemplate(template *ent.TaskTemplate, userID, userRole string) bool {
+	if template.Shared && s.templateSharing {
+		return true
+	}
+	return canManageTemplate(template, userID, userRole)
+}
+
+// canManageTemplate reports whether the caller may change or delete a template
+func canManageTemplate(template *ent.TaskTemplate, userID, userRole string) bool {
+	return template.OwnerID.String() == userID || authz.Can(userRole, authz.TemplatesManageAll)
+}
+
+// newCreateTaskRequestFromTemplate merges a template's defaults with the
+// overrides in req
+func newCreateTaskRequestFromTemplate(template *ent.TaskTemplate, req *taskv1.CreateTaskFromTemplateRequest, now time.Time) *taskv1.CreateTaskRequest {
+	createReq := &taskv1.CreateTaskRequest{
+		Title:       template.TitlePattern,
+		Description: template.Description,
+		Priority:    convertStringToPriority(string(template.Priority)),
+		Tags:        append([]string(nil), template.Tags...),
+		AssignedTo:  req.AssignedTo,
+		DueDate:     req.DueDate,
+		ParentId:    req.ParentId,
+	}
+
+	if req.Title != "" {
+		createReq.Title = req.Title
+	}
+	if req.Description != "" {
+		createReq.Description = req.Description
+	}
+	if req.Priority != taskv1.Priority_PRIORITY_UNSPECIFIED {
+		createReq.Priority = req.Priority
+	}
+	if len(req.Tags) > 0 {
+		createReq.Tags = req.Tags
+	}
+
+	replacer := templateReplacer(req.Variables, now)
+	createReq.Title = replacer.Replace(createReq.Title)
+	createReq.Description = replacer.Replace(createReq.Description)
+
+	return createReq
+}
+
+// templateReplacer fills {name} placeholders from variables, with {date}
+// defaulting to now's date
+func templateReplacer(variables map[string]string, now time.Time) *strings.Replacer {
+	values := map[string]string{
+		"date": now.UTC().Format(time.DateOnly),
+	}
+	for name, value := range variables {
+		values[name] = value
+	}
+
+	pairs := make([]string, 0, len(values)*2)
+	for name, value := range values {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+func convertEntTaskTemplateToProto(template *ent.TaskTemplate) *taskv1.TaskTemplate {
+	return &taskv1.TaskTemplate{
+		Id:           template.ID.String(),
+		OwnerId:      template.OwnerID.String(),
+		Name:         template.Name,
+		TitlePattern: template.TitlePattern,
+		Description:  template.Description,
+		Priority:     convertStringToPriority(string(template.Priority)),
+		Tags:         template.Tags,
+		Shared:       template.Shared,
+		CreatedAt:    timestamppb.New(template.CreatedAt),
+		UpdatedAt:    timestamppb.New(template.UpdatedAt),
+	}
+}
@@ -0,0 +1,211 @@
+// internal/service/task_templates_test.go
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	taskv1 "github.com/gurkanbulca/taskmaster/api/proto/task/v1/generated"
+	"github.com/gurkanbulca/taskmaster/internal/repository"
+)
+
+func TestTaskService_CreateTaskFromTemplate(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	owner := createTestUser(t, client)
+	assignee := NewTestHelpers(t, client).CreateTestUser("assignee@example.com", "assignee", "TestPass123!")
+	taskService := NewTaskService(repository.NewEntTaskRepository(client))
+	ctx := userContext(owner.ID.String(), "user")
+
+	created, err := taskService.CreateTaskTemplate(ctx, &taskv1.CreateTaskTemplateRequest{
+		Name:         "Weekly report",
+		TitlePattern: "Weekly report for {team} ({date})",
+		Description:  "Summarize {team}'s progress",
+		Priority:     taskv1.Priority_PRIORITY_HIGH,
+		Tags:         []string{"report", "weekly"},
+	})
+	require.NoError(t, err)
+	template := created.Template
+	assert.Equal(t, owner.ID.String(), template.OwnerId)
+	assert.False(t, template.Shared)
+
+	t.Run("without overrides", func(t *testing.T) {
+		resp, err := taskService.CreateTaskFromTemplate(ctx, &taskv1.CreateTaskFromTemplateRequest{
+			TemplateId: template.Id,
+			Variables:  map[string]string{"team": "Platform"},
+		})
+		require.NoError(t, err)
+
+		today := time.Now().UTC().Format(time.DateOnly)
+		task := resp.Task
+		assert.Equal(t, "Weekly report for Platform ("+today+")", task.Title)
+		assert.Equal(t, "Summarize Platform's progress", task.Description)
+		assert.Equal(t, taskv1.Priority_PRIORITY_HIGH, task.Priority)
+		assert.Equal(t, []string{"report", "weekly"}, task.Tags)
+		assert.Equal(t, taskv1.TaskStatus_TASK_STATUS_PENDING, task.Status)
+
+		// The task is a regular task
+		fetched, err := taskService.GetTask(ctx, &taskv1.GetTaskRequest{Id: task.Id})
+		require.NoError(t, err)
+		assert.Equal(t, task.Title, fetched.Task.Title)
+	})
+
+	t.Run("with overrides", func(t *testing.T) {
+		dueDate := timestamppb.New(time.Now().Add(72 * time.Hour).Truncate(time.Second))
+		resp, err := taskService.CreateTaskFromTemplate(ctx, &taskv1.CreateTaskFromTemplateRequest{
+			TemplateId: template.Id,
+			Title:      "Ad-hoc report for {team}",
+			Priority:   taskv1.Priority_PRIORITY_CRITICAL,
+			Tags:       []string{"urgent"},
+			AssignedTo: assignee.ID.String(),
+			DueDate:    dueDate,
+			Variables:  map[string]string{"team": "Mobile"},
+		})
+		require.NoError(t, err)
+
+		task := resp.Task
+		assert.Equal(t, "Ad-hoc report for Mobile", task.Title)
+		assert.Equal(t, "Summarize Mobile's progress", task.Description, "unset fields keep the template default")
+		assert.Equal(t, taskv1.Priority_PRIORITY_CRITICAL, task.Priority)
+		assert.Equal(t, []string{"urgent"}, task.Tags)
+		assert.Equal(t, assignee.ID.String(), task.AssignedTo)
+		assert.True(t, proto.Equal(dueDate, task.DueDate))
+
+		// The template itself is unchanged
+		got, err := taskService.GetTaskTemplate(ctx, &taskv1.GetTaskTemplateRequest{Id: template.Id})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"report", "weekly"}, got.Template.Tags)
+	})
+
+	t.Run("resolved title is validated", func(t *testing.T) {
+		_, err := taskService.CreateTaskFromTemplate(ctx, &taskv1.CreateTaskFromTemplateRequest{
+			TemplateId: template.Id,
+			Variables:  map[string]string{"team": strings.Repeat("x", 300)},
+		})
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		_, err := taskService.CreateTaskFromTemplate(ctx, &taskv1.CreateTaskFromTemplateRequest{
+			TemplateId: "00000000-0000-0000-0000-000000000000",
+		})
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.NotFound, st.Code())
+	})
+}
+
+func TestTaskService_TaskTemplateAccess(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	owner := createTestUser(t, client)
+	other := NewTestHelpers(t, client).CreateTestUser("other@example.com", "otheruser", "TestPass123!")
+	admin := NewTestHelpers(t, client).CreateTestUser("admin@example.com", "adminuser", "TestPass123!")
+	taskService := NewTaskService(repository.NewEntTaskRepository(client))
+	ownerCtx := userContext(owner.ID.String(), "user")
+	otherCtx := userContext(other.ID.String(), "user")
+	adminCtx := userContext(admin.ID.String(), "admin")
+
+	newTemplate := func(name string, shared bool) *taskv1.TaskTemplate {
+		t.Helper()
+		resp, err := taskService.CreateTaskTemplate(ownerCtx, &taskv1.CreateTaskTemplateRequest{
+			Name:         name,
+			TitlePattern: name,
+			Shared:       shared,
+		})
+		require.NoError(t, err)
+		return resp.Template
+	}
+	private := newTemplate("Private", false)
+	shared := newTemplate("Shared", true)
+
+	codeOf := func(err error) codes.Code {
+		st, _ := status.FromError(err)
+		return st.Code()
+	}
+	useAs := func(ctx context.Context, template *taskv1.TaskTemplate) error {
+		_, err := taskService.CreateTaskFromTemplate(ctx, &taskv1.CreateTaskFromTemplateRequest{TemplateId: template.Id})
+		return err
+	}
+	listNames := func(ctx context.Context) []string {
+		resp, err := taskService.ListTaskTemplates(ctx, &taskv1.ListTaskTemplatesRequest{})
+		require.NoError(t, err)
+		var names []string
+		for _, template := range resp.Templates {
+			names = append(names, template.Name)
+		}
+		return names
+	}
+
+	t.Run("private templates are owner-only", func(t *testing.T) {
+		assert.Equal(t, codes.PermissionDenied, codeOf(useAs(otherCtx, private)))
+		_, err := taskService.GetTaskTemplate(otherCtx, &taskv1.GetTaskTemplateRequest{Id: private.Id})
+		assert.Equal(t, codes.PermissionDenied, codeOf(err))
+
+		assert.NoError(t, useAs(ownerCtx, private))
+		assert.NoError(t, useAs(adminCtx, private))
+	})
+
+	t.Run("shared templates are usable by everyone but managed by the owner", func(t *testing.T) {
+		assert.NoError(t, useAs(otherCtx, shared))
+		assert.Equal(t, []string{"Shared"}, listNames(otherCtx))
+		assert.Equal(t, []string{"Private", "Shared"}, listNames(ownerCtx))
+
+		_, err := taskService.UpdateTaskTemplate(otherCtx, &taskv1.UpdateTaskTemplateRequest{Id: shared.Id, Name: "Mine now"})
+		assert.Equal(t, codes.PermissionDenied, codeOf(err))
+		_, err = taskService.DeleteTaskTemplate(otherCtx, &taskv1.DeleteTaskTemplateRequest{Id: shared.Id})
+		assert.Equal(t, codes.PermissionDenied, codeOf(err))
+	})
+
+	t.Run("owner updates and unshares", func(t *testing.T) {
+		unshare := false
+		resp, err := taskService.UpdateTaskTemplate(ownerCtx, &taskv1.UpdateTaskTemplateRequest{
+			Id:       shared.Id,
+			Priority: taskv1.Priority_PRIORITY_LOW,
+			Shared:   &unshare,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Shared", resp.Template.Name)
+		assert.Equal(t, taskv1.Priority_PRIORITY_LOW, resp.Template.Priority)
+		assert.False(t, resp.Template.Shared)
+
+		assert.Equal(t, codes.PermissionDenied, codeOf(useAs(otherCtx, shared)))
+		assert.Empty(t, listNames(otherCtx))
+	})
+
+	t.Run("sharing disabled", func(t *testing.T) {
+		reshare := true
+		_, err := taskService.UpdateTaskTemplate(ownerCtx, &taskv1.UpdateTaskTemplateRequest{Id: shared.Id, Shared: &reshare})
+		require.NoError(t, err)
+
+		taskService.SetTemplateSharing(false)
+		defer taskService.SetTemplateSharing(true)
+
+		assert.Equal(t, codes.PermissionDenied, codeOf(useAs(otherCtx, shared)))
+		assert.Empty(t, listNames(otherCtx))
+
+		_, err = taskService.CreateTaskTemplate(ownerCtx, &taskv1.CreateTaskTemplateRequest{Name: "New", TitlePattern: "New", Shared: true})
+		assert.Equal(t, codes.FailedPrecondition, codeOf(err))
+	})
+
+	t.Run("admin deletes any template", func(t *testing.T) {
+		_, err := taskService.DeleteTaskTemplate(adminCtx, &taskv1.DeleteTaskTemplateRequest{Id: private.Id})
+		require.NoError(t, err)
+
+		_, err = taskService.GetTaskTemplate(ownerCtx, &taskv1.GetTaskTemplateRequest{Id: private.Id})
+		assert.Equal(t, codes.NotFound, codeOf(err))
+	})
+}
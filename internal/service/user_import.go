@@ -0,0 +1,260 @@
+// internal/service/user_import.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/user"
+	"github.com/gurkanbulca/taskmaster/internal/middleware"
+	"github.com/gurkanbulca/taskmaster/pkg/auth"
+	"github.com/gurkanbulca/taskmaster/pkg/authz"
+	"github.com/gurkanbulca/taskmaster/pkg/security"
+)
+
+// importRow is a validated user spec of a BulkImportUsers request
+type importRow struct {
+	index        int
+	email        string
+	username     string
+	firstName    string
+	lastName     string
+	role         user.Role
+	passwordHash string // Empty when the user sets a password from the invite
+}
+
+// BulkImportUsers creates several accounts at once (admin only). Rows are
+// validated and checked against existing users first; if any row fails,
+// nothing is created and the failures are reported per row. The accounts are
+// created in one transaction, so a conflict at insert time rolls back the
+// whole import. With send_invites, each new user gets a link to set their
+// password.
+func (s *AuthService) BulkImportUsers(ctx context.Context, req *authv1.BulkImportUsersRequest) (*authv1.BulkImportUsersResponse, error) {
+	userRole, _ := middleware.GetUserRoleFromContext(ctx)
+	if !authz.Can(userRole, authz.UserManage) {
+		return nil, status.Error(codes.PermissionDenied, "admin access required")
+	}
+
+	if len(req.Users) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one user is required")
+	}
+	if len(req.Users) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "too many users (max %d)", maxBatchSize)
+	}
+
+	rows, itemErrs := s.prepareUserImport(req)
+	if len(itemErrs) == 0 {
+		var err error
+		itemErrs, err = s.findImportConflicts(ctx, rows)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to check user existence")
+		}
+	}
+	if len(itemErrs) > 0 {
+		return &authv1.BulkImportUsersResponse{Errors: itemErrs}, nil
+	}
+
+	created, itemErr, err := s.createImportedUsers(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	if itemErr != nil {
+		return &authv1.BulkImportUsersResponse{Errors: []*authv1.BatchItemError{itemErr}}, nil
+	}
+
+	if err := s.securityLogger.LogCurrentUserFromContext(ctx, security.EventTypeSecurityAlert,
+		fmt.Sprintf("Imported %d users", len(created)), security.SeverityMedium); err != nil {
+		// Log error but don't fail
+	}
+
+	resp := &authv1.BulkImportUsersResponse{}
+	for _, u := range created {
+		resp.Users = append(resp.Users, s.convertUserToProto(u))
+
+		if req.SendInvites {
+			// The password reset link doubles as the invite
+			if err := s.passwordResetService.RequestPasswordReset(ctx, u.Email); err != nil {
+				s.logger.Error("failed to send invite", "user_id", u.ID, "error", err)
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+// prepareUserImport validates each spec and hashes temporary passwords.
+// Emails and usernames repeated within the batch are reported as errors.
+func (s *AuthService) prepareUserImport(req *authv1.BulkImportUsersRequest) ([]*importRow, []*authv1.BatchItemError) {
+	var (
+		rows      []*importRow
+		itemErrs  []*authv1.BatchItemError
+		emails    = make(map[string]int, len(req.Users))
+		usernames = make(map[string]int, len(req.Users))
+	)
+
+	fail := func(i int, message string) {
+		itemErrs = append(itemErrs, &authv1.BatchItemError{Index: int32(i), Message: message})
+	}
+
+	for i, spec := range req.Users {
+		if spec == nil {
+			fail(i, "user is required")
+			continue
+		}
+
+		email := strings.ToLower(strings.TrimSpace(spec.Email))
+		username := strings.ToLower(strings.TrimSpace(spec.Username))
+		if err := auth.ValidateEmail(email); err != nil {
+			fail(i, fmt.Sprintf("invalid email: %v", err))
+			continue
+		}
+		if err := auth.ValidateUsername(username); err != nil {
+			fail(i, fmt.Sprintf("invalid username: %v", err))
+			continue
+		}
+		if first, ok := emails[email]; ok {
+			fail(i, fmt.Sprintf("email duplicates row %d", first))
+			continue
+		}
+		if first, ok := usernames[username]; ok {
+			fail(i, fmt.Sprintf("username duplicates row %d", first))
+			continue
+		}
+		emails[email] = i
+		usernames[username] = i
+
+		role := user.RoleUser
+		if spec.Role != authv1.UserRole_USER_ROLE_UNSPECIFIED {
+			role = user.Role(convertProtoRoleToString(spec.Role))
+			if err := user.RoleValidator(role); err != nil {
+				fail(i, "invalid role")
+				continue
+			}
+		}
+
+		row := &importRow{
+			index:     i,
+			email:     email,
+			username:  username,
+			firstName: spec.FirstName,
+			lastName:  spec.LastName,
+			role:      role,
+		}
+
+		switch {
+		case spec.TemporaryPassword != "":
+			if err := s.passwordManager.CheckStrength(spec.TemporaryPassword, username, email, spec.FirstName, spec.LastName); err != nil {
+				fail(i, err.Error())
+				continue
+			}
+			hash, err := s.passwordManager.HashPassword(spec.TemporaryPassword)
+			if err != nil {
+				fail(i, err.Error())
+				continue
+			}
+			row.passwordHash = hash
+		case !req.SendInvites:
+			fail(i, "temporary password is required unless invites are sent")
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, itemErrs
+}
+
+// findImportConflicts reports rows whose email or username already belongs
+// to an account
+func (s *AuthService) findImportConflicts(ctx context.Context, rows []*importRow) ([]*authv1.BatchItemError, error) {
+	emails := make([]string, len(rows))
+	usernames := make([]string, len(rows))
+	for i, row := range rows {
+		emails[i] = row.email
+		usernames[i] = row.username
+	}
+
+	existing, err := s.client.User.Query().
+		Where(user.Or(user.EmailIn(emails...), user.UsernameIn(usernames...))).
+		Select(user.FieldEmail, user.FieldUsername).
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	takenEmails := make(map[string]bool, len(existing))
+	takenUsernames := make(map[string]bool, len(existing))
+	for _, u := range existing {
+		takenEmails[u.Email] = true
+		takenUsernames[u.Username] = true
+	}
+
+	var itemErrs []*authv1.BatchItemError
+	for _, row := range rows {
+		switch {
+		case takenEmails[row.email]:
+			itemErrs = append(itemErrs, &authv1.BatchItemError{Index: int32(row.index), Message: "email is already in use"})
+		case takenUsernames[row.username]:
+			itemErrs = append(itemErrs, &authv1.BatchItemError{Index: int32(row.index), Message: "username is already taken"})
+		}
+	}
+	return itemErrs, nil
+}
+
+// createImportedUsers creates the rows in one transaction. A row that hits a
+// uniqueness conflict, e.g. an account registered since the checks ran, rolls
+// back the import and is returned as an item error.
+func (s *AuthService) createImportedUsers(ctx context.Context, rows []*importRow) ([]*ent.User, *authv1.BatchItemError, error) {
+	tx, err := s.client.Tx(ctx)
+	if err != nil {
+		return nil, nil, status.Error(codes.Internal, "failed to import users")
+	}
+
+	rollback := func() {
+		if rerr := tx.Rollback(); rerr != nil {
+			s.logger.Error("failed to roll back user import", "error", rerr)
+		}
+	}
+
+	now := time.Now()
+	created := make([]*ent.User, 0, len(rows))
+	for _, row := range rows {
+		create := tx.User.Create().
+			SetEmail(row.email).
+			SetUsername(row.username).
+			SetPasswordHash(row.passwordHash).
+			SetFirstName(row.firstName).
+			SetLastName(row.lastName).
+			SetRole(row.role).
+			SetIsActive(true).
+			SetEmailVerified(false).
+			SetEmailNotificationsEnabled(true).
+			SetSecurityNotificationsEnabled(s.securityConfig.EnableSecurityNotifications)
+		if row.passwordHash != "" {
+			create = create.SetPasswordChangedAt(now)
+		}
+
+		u, err := create.Save(ctx)
+		if err != nil {
+			rollback()
+			if ent.IsConstraintError(err) {
+				return nil, &authv1.BatchItemError{Index: int32(row.index), Message: "email or username is already in use"}, nil
+			}
+			return nil, nil, status.Error(codes.Internal, "failed to import users")
+		}
+		created = append(created, u)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, status.Error(codes.Internal, "failed to import users")
+	}
+
+	return created, nil, nil
+}
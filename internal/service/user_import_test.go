@@ -0,0 +1,107 @@
+// internal/service/user_import_test.go
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	authv1 "github.com/gurkanbulca/taskmaster/api/proto/auth/v1/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/user"
+)
+
+func TestAuthService_BulkImportUsers(t *testing.T) {
+	// Setup
+	client := setupTestDB(t)
+	defer client.Close()
+
+	existing := createTestUser(t, client)
+	authService := newTestAuthService(client, createTestSecurityConfig())
+	adminCtx := userContext(existing.ID.String(), "admin")
+
+	countUsers := func() int {
+		count, err := client.User.Query().Count(context.Background())
+		require.NoError(t, err)
+		return count
+	}
+
+	t.Run("all valid", func(t *testing.T) {
+		resp, err := authService.BulkImportUsers(adminCtx, &authv1.BulkImportUsersRequest{
+			Users: []*authv1.UserImportSpec{
+				{Email: "Alice@Example.com", Username: "alice", FirstName: "Alice", TemporaryPassword: "Onboard!Pass2024x"},
+				{Email: "bob@example.com", Username: "bob", Role: authv1.UserRole_USER_ROLE_MANAGER, TemporaryPassword: "Onboard!Pass2024y"},
+				{Email: "carol@example.com", Username: "carol"},
+			},
+			SendInvites: true,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Errors)
+		require.Len(t, resp.Users, 3)
+		assert.Equal(t, "alice@example.com", resp.Users[0].Email)
+		assert.Equal(t, authv1.UserRole_USER_ROLE_USER, resp.Users[0].Role)
+		assert.Equal(t, authv1.UserRole_USER_ROLE_MANAGER, resp.Users[1].Role)
+
+		// Temporary passwords work for login
+		_, err = authService.Login(context.Background(), &authv1.LoginRequest{Email: "alice@example.com", Password: "Onboard!Pass2024x"})
+		assert.NoError(t, err)
+
+		// Invited users get a link to set their password
+		carol, err := client.User.Query().Where(user.EmailEQ("carol@example.com")).Only(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, carol.PasswordHash)
+		assert.NotEmpty(t, carol.PasswordResetToken)
+	})
+
+	t.Run("duplicate email rolls back the batch", func(t *testing.T) {
+		before := countUsers()
+
+		resp, err := authService.BulkImportUsers(adminCtx, &authv1.BulkImportUsersRequest{
+			Users: []*authv1.UserImportSpec{
+				{Email: "dave@example.com", Username: "dave", TemporaryPassword: "Onboard!Pass2024z"},
+				{Email: existing.Email, Username: "someoneelse", TemporaryPassword: "Onboard!Pass2024z"},
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Users)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, int32(1), resp.Errors[0].Index)
+		assert.Equal(t, "email is already in use", resp.Errors[0].Message)
+		assert.Equal(t, before, countUsers(), "valid rows are not created either")
+	})
+
+	t.Run("per-row validation errors", func(t *testing.T) {
+		before := countUsers()
+
+		resp, err := authService.BulkImportUsers(adminCtx, &authv1.BulkImportUsersRequest{
+			Users: []*authv1.UserImportSpec{
+				{Email: "erin@example.com", Username: "erin", TemporaryPassword: "Onboard!Pass2024z"},
+				{Email: "ERIN@example.com", Username: "erin2", TemporaryPassword: "Onboard!Pass2024z"},
+				{Email: "not-an-email", Username: "frank", TemporaryPassword: "Onboard!Pass2024z"},
+				{Email: "grace@example.com", Username: "grace"},
+			},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.Users)
+
+		messages := make(map[int32]string)
+		for _, itemErr := range resp.Errors {
+			messages[itemErr.Index] = itemErr.Message
+		}
+		assert.Equal(t, "email duplicates row 0", messages[1])
+		assert.Contains(t, messages[2], "invalid email")
+		assert.Equal(t, "temporary password is required unless invites are sent", messages[3])
+		assert.NotContains(t, messages, int32(0))
+		assert.Equal(t, before, countUsers())
+	})
+
+	t.Run("admin only", func(t *testing.T) {
+		_, err := authService.BulkImportUsers(userContext(existing.ID.String(), "manager"), &authv1.BulkImportUsersRequest{
+			Users: []*authv1.UserImportSpec{{Email: "heidi@example.com", Username: "heidi", TemporaryPassword: "Onboard!Pass2024z"}},
+		})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
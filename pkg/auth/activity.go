@@ -0,0 +1,74 @@
+// pkg/auth/activity.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/session"
+)
+
+// ErrIdleSession is returned for sessions that have made no request for
+// longer than the idle timeout
+var ErrIdleSession = errors.New("session has timed out")
+
+// activityWriteInterval bounds how often a session's activity is written, so
+// busy clients don't cause a write on every request
+const activityWriteInterval = time.Minute
+
+// ActivityTracker records when each session last made an authenticated
+// request and rejects sessions idle for longer than the timeout. Activity on
+// one device does not keep the user's other sessions alive; signing in again
+// starts a fresh session.
+type ActivityTracker struct {
+	client      *ent.Client
+	idleTimeout time.Duration
+	now         func() time.Time
+}
+
+// NewActivityTracker creates an activity tracker; an idleTimeout of 0 only
+// records activity
+func NewActivityTracker(client *ent.Client, idleTimeout time.Duration) *ActivityTracker {
+	return &ActivityTracker{client: client, idleTimeout: idleTimeout, now: time.Now}
+}
+
+// Touch records a request by session sessionID. It fails with ErrIdleSession
+// when the previous one is older than the idle timeout, and with
+// ErrInvalidSession when the session has ended. Sessions without recorded
+// activity count as active.
+func (t *ActivityTracker) Touch(ctx context.Context, sessionID uuid.UUID) error {
+	found, err := t.client.Session.Query().
+		Where(session.ID(sessionID)).
+		Select(session.FieldLastActivityAt).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return ErrInvalidSession
+		}
+		return fmt.Errorf("load activity: %w", err)
+	}
+
+	now := t.now()
+	if t.IsIdle(found.LastActivityAt) {
+		return ErrIdleSession
+	}
+	if found.LastActivityAt != nil && now.Sub(*found.LastActivityAt) < activityWriteInterval {
+		return nil
+	}
+
+	if err := t.client.Session.UpdateOneID(sessionID).SetLastActivityAt(now).Exec(ctx); err != nil {
+		return fmt.Errorf("record activity: %w", err)
+	}
+	return nil
+}
+
+// IsIdle reports whether a session last active at lastActivityAt has been idle
+// for longer than the timeout, without recording anything
+func (t *ActivityTracker) IsIdle(lastActivityAt *time.Time) bool {
+	return t.idleTimeout > 0 && lastActivityAt != nil && t.now().Sub(*lastActivityAt) > t.idleTimeout
+}
@@ -0,0 +1,77 @@
+// pkg/auth/activity_test.go
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gurkanbulca/taskmaster/ent/generated/enttest"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestActivityTracker_Touch(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:activity?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	owner, err := client.User.Create().
+		SetEmail("activity@example.com").
+		SetUsername("activityuser").
+		SetPasswordHash("hash").
+		Save(ctx)
+	require.NoError(t, err)
+	active, err := client.Session.Create().
+		SetUserID(owner.ID).
+		SetRefreshTokenHash("active").
+		SetExpiresAt(time.Now().Add(24 * time.Hour)).
+		Save(ctx)
+	require.NoError(t, err)
+	other, err := client.Session.Create().
+		SetUserID(owner.ID).
+		SetRefreshTokenHash("other").
+		SetExpiresAt(time.Now().Add(24 * time.Hour)).
+		Save(ctx)
+	require.NoError(t, err)
+
+	now := time.Now()
+	tracker := NewActivityTracker(client, time.Hour)
+	tracker.now = func() time.Time { return now }
+
+	lastActivity := func() time.Time {
+		return *client.Session.GetX(ctx, active.ID).LastActivityAt
+	}
+
+	// No recorded activity yet counts as active
+	require.NoError(t, tracker.Touch(ctx, active.ID))
+	assert.WithinDuration(t, now, lastActivity(), time.Second)
+
+	// Requests shortly after each other are not written
+	recorded := now
+	now = now.Add(30 * time.Second)
+	require.NoError(t, tracker.Touch(ctx, active.ID))
+	assert.WithinDuration(t, recorded, lastActivity(), time.Second)
+
+	// Within the timeout the clock restarts
+	now = now.Add(50 * time.Minute)
+	require.NoError(t, tracker.Touch(ctx, active.ID))
+	assert.WithinDuration(t, now, lastActivity(), time.Second)
+
+	// Requests on another device don't keep this one alive
+	require.NoError(t, client.Session.UpdateOneID(other.ID).SetLastActivityAt(now).Exec(ctx))
+	now = now.Add(50 * time.Minute)
+	require.NoError(t, tracker.Touch(ctx, other.ID))
+	now = now.Add(11 * time.Minute)
+	require.NoError(t, tracker.Touch(ctx, other.ID))
+
+	// Past the timeout the session is idle, and stays so
+	assert.ErrorIs(t, tracker.Touch(ctx, active.ID), ErrIdleSession)
+	assert.ErrorIs(t, tracker.Touch(ctx, active.ID), ErrIdleSession)
+
+	assert.ErrorIs(t, tracker.Touch(ctx, uuid.New()), ErrInvalidSession)
+}
@@ -0,0 +1,64 @@
+// pkg/auth/blacklist.go
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/revokedtoken"
+)
+
+// TokenBlacklist tracks revoked JWTs by their ID (jti) claim until they expire
+type TokenBlacklist struct {
+	client *ent.Client
+}
+
+// NewTokenBlacklist creates a new token blacklist
+func NewTokenBlacklist(client *ent.Client) *TokenBlacklist {
+	return &TokenBlacklist{client: client}
+}
+
+// Revoke adds a token ID to the blacklist until expiresAt
+func (b *TokenBlacklist) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return ErrInvalidClaims
+	}
+
+	err := b.client.RevokedToken.Create().
+		SetJti(jti).
+		SetExpiresAt(expiresAt).
+		Exec(ctx)
+	if err != nil {
+		// Revoking the same token twice is not an error
+		if ent.IsConstraintError(err) {
+			return nil
+		}
+		return fmt.Errorf("revoke token: %w", err)
+	}
+
+	return nil
+}
+
+// IsRevoked reports whether the token ID has been blacklisted
+func (b *TokenBlacklist) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	revoked, err := b.client.RevokedToken.Query().
+		Where(revokedtoken.JtiEQ(jti)).
+		Exist(ctx)
+	if err != nil {
+		return false, fmt.Errorf("check revoked token: %w", err)
+	}
+	return revoked, nil
+}
+
+// CleanupExpired removes blacklist entries whose tokens have expired
+func (b *TokenBlacklist) CleanupExpired(ctx context.Context) (int, error) {
+	deleted, err := b.client.RevokedToken.Delete().
+		Where(revokedtoken.ExpiresAtLT(time.Now())).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("cleanup revoked tokens: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,75 @@
+// pkg/auth/blacklist_test.go
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gurkanbulca/taskmaster/ent/generated/enttest"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestTokenBlacklist_RejectsRevokedAccessToken(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:blacklist?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	blacklist := NewTokenBlacklist(client)
+	tm := NewTokenManager("test-access-secret", "test-refresh-secret", 15*time.Minute, 7*24*time.Hour)
+	tm.SetBlacklist(blacklist)
+
+	accessToken, _, _, err := tm.GenerateTokenPair("user-1", "user@example.com", "user", "user")
+	require.NoError(t, err)
+
+	otherToken, _, _, err := tm.GenerateTokenPair("user-1", "user@example.com", "user", "user")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	claims, err := tm.ValidateAccessToken(ctx, accessToken)
+	require.NoError(t, err)
+
+	require.NoError(t, tm.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time))
+
+	// Revoking twice is harmless
+	require.NoError(t, tm.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time))
+
+	_, err = tm.ValidateAccessToken(ctx, accessToken)
+	assert.ErrorIs(t, err, ErrRevokedToken)
+
+	// Other tokens for the same user remain valid
+	_, err = tm.ValidateAccessToken(ctx, otherToken)
+	assert.NoError(t, err)
+
+	// The revocation check runs under the caller's context
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = tm.ValidateAccessToken(cancelled, otherToken)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestTokenBlacklist_CleanupExpired(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:blacklist_cleanup?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	blacklist := NewTokenBlacklist(client)
+	ctx := context.Background()
+
+	require.NoError(t, blacklist.Revoke(ctx, "expired-jti", time.Now().Add(-time.Minute)))
+	require.NoError(t, blacklist.Revoke(ctx, "active-jti", time.Now().Add(time.Hour)))
+
+	deleted, err := blacklist.CleanupExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	revoked, err := blacklist.IsRevoked(ctx, "active-jti")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, err = blacklist.IsRevoked(ctx, "expired-jti")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+}
@@ -0,0 +1,64 @@
+// pkg/auth/captcha_test.go
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecaptchaVerifier_Verify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "test-secret", r.PostForm.Get("secret"))
+
+		switch r.PostForm.Get("response") {
+		case "solved":
+			assert.Equal(t, "203.0.113.10", r.PostForm.Get("remoteip"))
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true})
+		case "likely-human":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "score": 0.9})
+		case "likely-bot":
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "score": 0.1})
+		case "unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "error-codes": []string{"invalid-input-response"}})
+		}
+	}))
+	defer server.Close()
+
+	verifier := NewRecaptchaVerifier("test-secret", server.URL, 0.5)
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "solved", token: "solved"},
+		{name: "score above minimum", token: "likely-human"},
+		{name: "score below minimum", token: "likely-bot", wantErr: ErrInvalidCaptcha},
+		{name: "rejected token", token: "forged", wantErr: ErrInvalidCaptcha},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifier.Verify(context.Background(), tt.token, "203.0.113.10")
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	// Provider failures are not reported as invalid tokens
+	err := verifier.Verify(context.Background(), "unavailable", "")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidCaptcha)
+}
@@ -2,8 +2,10 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -15,26 +17,113 @@ var (
 	ErrExpiredToken      = errors.New("token has expired")
 	ErrInvalidClaims     = errors.New("invalid token claims")
 	ErrInvalidSigningKey = errors.New("invalid signing key")
+	ErrRevokedToken      = errors.New("token has been revoked")
+	ErrTokenNotValidYet  = errors.New("token is not valid yet")
 )
 
+// Default "iss" and "aud" claims, used until SetIssuer and SetAudience are called
+const (
+	DefaultTokenIssuer   = "taskmaster"
+	DefaultTokenAudience = "taskmaster"
+)
+
+// DefaultTokenLeeway is the clock skew tolerated when checking the "exp" and
+// "nbf" claims, until SetLeeway is called
+const DefaultTokenLeeway = 30 * time.Second
+
+// DefaultRefreshThreshold is how close to expiry an access token is reported
+// as expiring soon, until SetRefreshThreshold is called
+const DefaultRefreshThreshold = 2 * time.Minute
+
+// mfaTokenDuration bounds how long a user has to complete the second login step
+const mfaTokenDuration = 5 * time.Minute
+
 // TokenManager manages JWT tokens
 type TokenManager struct {
-	accessSecret    []byte
-	refreshSecret   []byte
-	accessDuration  time.Duration
-	refreshDuration time.Duration
-	issuer          string
+	accessSecret       []byte
+	refreshSecret      []byte
+	accessDuration     time.Duration
+	refreshDuration    time.Duration
+	rememberMeDuration time.Duration
+	issuer             string
+	audience           string
+	leeway             time.Duration
+	refreshThreshold   time.Duration
+	blacklist          *TokenBlacklist
 }
 
 // NewTokenManager creates a new token manager
 func NewTokenManager(accessSecret, refreshSecret string, accessDuration, refreshDuration time.Duration) *TokenManager {
 	return &TokenManager{
-		accessSecret:    []byte(accessSecret),
-		refreshSecret:   []byte(refreshSecret),
-		accessDuration:  accessDuration,
-		refreshDuration: refreshDuration,
-		issuer:          "taskmaster",
+		accessSecret:       []byte(accessSecret),
+		refreshSecret:      []byte(refreshSecret),
+		accessDuration:     accessDuration,
+		refreshDuration:    refreshDuration,
+		rememberMeDuration: refreshDuration,
+		issuer:             DefaultTokenIssuer,
+		audience:           DefaultTokenAudience,
+		leeway:             DefaultTokenLeeway,
+		refreshThreshold:   DefaultRefreshThreshold,
+	}
+}
+
+// SetIssuer sets the "iss" claim of issued tokens; tokens from any other
+// issuer are rejected
+func (tm *TokenManager) SetIssuer(issuer string) {
+	tm.issuer = issuer
+}
+
+// SetAudience sets the "aud" claim of issued tokens; tokens not intended for
+// this audience are rejected
+func (tm *TokenManager) SetAudience(audience string) {
+	tm.audience = audience
+}
+
+// SetLeeway sets how far a token may be past its expiry, or before its
+// not-before time, and still be accepted, to absorb clock differences
+// between services
+func (tm *TokenManager) SetLeeway(leeway time.Duration) {
+	tm.leeway = leeway
+}
+
+// SetRefreshThreshold sets how close to expiry ValidateAndCheckRefresh
+// reports an access token as expiring soon; 0 disables the hint
+func (tm *TokenManager) SetRefreshThreshold(threshold time.Duration) {
+	tm.refreshThreshold = threshold
+}
+
+// SetBlacklist enables revocation checks against the given blacklist
+func (tm *TokenManager) SetBlacklist(blacklist *TokenBlacklist) {
+	tm.blacklist = blacklist
+}
+
+// RevokeToken blacklists a token by its ID until it expires.
+// It is a no-op when no blacklist is configured.
+func (tm *TokenManager) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if tm.blacklist == nil {
+		return nil
+	}
+	return tm.blacklist.Revoke(ctx, jti, expiresAt)
+}
+
+// SetRememberMeDuration sets how long refresh tokens of "remember me"
+// logins are valid; until it is called they get the regular duration
+func (tm *TokenManager) SetRememberMeDuration(duration time.Duration) {
+	tm.rememberMeDuration = duration
+}
+
+// RefreshTokenDuration returns how long refresh tokens are valid
+func (tm *TokenManager) RefreshTokenDuration() time.Duration {
+	return tm.refreshDuration
+}
+
+// RefreshTokenDurationFor returns how long the refresh tokens of a login are
+// valid, depending on whether it opted into "remember me"
+func (tm *TokenManager) RefreshTokenDurationFor(rememberMe bool) time.Duration {
+	if rememberMe {
+		return tm.rememberMeDuration
 	}
+	return tm.refreshDuration
 }
 
 // CustomClaims represents the custom JWT claims
@@ -43,20 +132,32 @@ type CustomClaims struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
-	Type     string `json:"type"` // "access" or "refresh"
+	Type     string `json:"type"` // "access", "refresh" or "mfa"
+	// SessionID is the signed-in device the token was issued to, if any
+	SessionID string `json:"sid,omitempty"`
+	// RememberMe is set on the refresh and MFA tokens of "remember me" logins
+	RememberMe bool `json:"remember_me,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateTokenPair generates both access and refresh tokens
+// GenerateTokenPair generates both access and refresh tokens, not bound to
+// any session
 func (tm *TokenManager) GenerateTokenPair(userID, email, username, role string) (accessToken, refreshToken string, expiresIn int64, err error) {
+	return tm.GenerateTokenPairFor("", userID, email, username, role, false)
+}
+
+// GenerateTokenPairFor generates both tokens of session sessionID, for a
+// login that did or didn't opt into "remember me". The refresh token lasts
+// accordingly and carries the choice, so refreshing it keeps the same lifetime.
+func (tm *TokenManager) GenerateTokenPairFor(sessionID, userID, email, username, role string, rememberMe bool) (accessToken, refreshToken string, expiresIn int64, err error) {
 	// Generate access token
-	accessToken, err = tm.generateToken(userID, email, username, role, "access", tm.accessSecret, tm.accessDuration)
+	accessToken, err = tm.generateToken(sessionID, userID, email, username, role, "access", false, tm.accessSecret, tm.accessDuration)
 	if err != nil {
 		return "", "", 0, fmt.Errorf("generate access token: %w", err)
 	}
 
 	// Generate refresh token
-	refreshToken, err = tm.generateToken(userID, email, username, role, "refresh", tm.refreshSecret, tm.refreshDuration)
+	refreshToken, err = tm.generateToken(sessionID, userID, email, username, role, "refresh", rememberMe, tm.refreshSecret, tm.RefreshTokenDurationFor(rememberMe))
 	if err != nil {
 		return "", "", 0, fmt.Errorf("generate refresh token: %w", err)
 	}
@@ -66,18 +167,21 @@ func (tm *TokenManager) GenerateTokenPair(userID, email, username, role string)
 }
 
 // generateToken creates a JWT token with custom claims
-func (tm *TokenManager) generateToken(userID, email, username, role, tokenType string, secret []byte, duration time.Duration) (string, error) {
+func (tm *TokenManager) generateToken(sessionID, userID, email, username, role, tokenType string, rememberMe bool, secret []byte, duration time.Duration) (string, error) {
 	now := time.Now()
 
 	claims := CustomClaims{
-		UserID:   userID,
-		Email:    email,
-		Username: username,
-		Role:     role,
-		Type:     tokenType,
+		UserID:     userID,
+		Email:      email,
+		Username:   username,
+		Role:       role,
+		Type:       tokenType,
+		SessionID:  sessionID,
+		RememberMe: rememberMe,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        uuid.New().String(),
 			Issuer:    tm.issuer,
+			Audience:  jwt.ClaimStrings{tm.audience},
 			Subject:   userID,
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
@@ -94,27 +198,64 @@ func (tm *TokenManager) generateToken(userID, email, username, role, tokenType s
 	return tokenString, nil
 }
 
+// GenerateMFAToken issues a short-lived token proving the password step of a
+// two-factor login succeeded. It cannot be used as an access token.
+// rememberMe carries the login's "remember me" choice to the second step.
+func (tm *TokenManager) GenerateMFAToken(userID, email, username, role string, rememberMe bool) (string, int64, error) {
+	token, err := tm.generateToken("", userID, email, username, role, "mfa", rememberMe, tm.accessSecret, mfaTokenDuration)
+	if err != nil {
+		return "", 0, fmt.Errorf("generate mfa token: %w", err)
+	}
+	return token, int64(mfaTokenDuration.Seconds()), nil
+}
+
+// ValidateMFAToken validates an MFA token and returns the claims
+func (tm *TokenManager) ValidateMFAToken(ctx context.Context, tokenString string) (*CustomClaims, error) {
+	return tm.validateToken(ctx, tokenString, "mfa", tm.accessSecret)
+}
+
 // ValidateAccessToken validates an access token and returns the claims
-func (tm *TokenManager) ValidateAccessToken(tokenString string) (*CustomClaims, error) {
-	return tm.validateToken(tokenString, "access", tm.accessSecret)
+func (tm *TokenManager) ValidateAccessToken(ctx context.Context, tokenString string) (*CustomClaims, error) {
+	return tm.validateToken(ctx, tokenString, "access", tm.accessSecret)
+}
+
+// ValidateAndCheckRefresh validates an access token like ValidateAccessToken
+// and also reports whether it expires within the refresh threshold, so the
+// client can refresh it before requests start failing
+func (tm *TokenManager) ValidateAndCheckRefresh(ctx context.Context, tokenString string) (*CustomClaims, bool, error) {
+	claims, err := tm.ValidateAccessToken(ctx, tokenString)
+	if err != nil {
+		return nil, false, err
+	}
+
+	expiringSoon := tm.refreshThreshold > 0 && claims.ExpiresAt != nil &&
+		time.Until(claims.ExpiresAt.Time) <= tm.refreshThreshold
+	return claims, expiringSoon, nil
 }
 
 // ValidateRefreshToken validates a refresh token and returns the claims
-func (tm *TokenManager) ValidateRefreshToken(tokenString string) (*CustomClaims, error) {
-	return tm.validateToken(tokenString, "refresh", tm.refreshSecret)
+func (tm *TokenManager) ValidateRefreshToken(ctx context.Context, tokenString string) (*CustomClaims, error) {
+	return tm.validateToken(ctx, tokenString, "refresh", tm.refreshSecret)
 }
 
 // validateToken validates a token and returns the custom claims
-func (tm *TokenManager) validateToken(tokenString, expectedType string, secret []byte) (*CustomClaims, error) {
+func (tm *TokenManager) validateToken(ctx context.Context, tokenString, expectedType string, secret []byte) (*CustomClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Verify signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return secret, nil
-	})
+	}, jwt.WithLeeway(tm.leeway))
 
+	// The parser checks "exp" and "nbf", allowing for the leeway
 	if err != nil {
+		switch {
+		case errors.Is(err, jwt.ErrTokenExpired):
+			return nil, ErrExpiredToken
+		case errors.Is(err, jwt.ErrTokenNotValidYet):
+			return nil, ErrTokenNotValidYet
+		}
 		return nil, fmt.Errorf("parse token: %w", err)
 	}
 
@@ -127,34 +268,47 @@ func (tm *TokenManager) validateToken(tokenString, expectedType string, secret [
 		return nil, ErrInvalidToken
 	}
 
+	// Verify the token was issued by and for this deployment
+	if claims.Issuer != tm.issuer || !slices.Contains(claims.Audience, tm.audience) {
+		return nil, ErrInvalidClaims
+	}
+
 	// Verify token type
 	if claims.Type != expectedType {
 		return nil, fmt.Errorf("invalid token type: expected %s, got %s", expectedType, claims.Type)
 	}
 
-	// Check expiration
-	if claims.ExpiresAt != nil && claims.ExpiresAt.Before(time.Now()) {
-		return nil, ErrExpiredToken
+	// Check revocation
+	if tm.blacklist != nil {
+		revoked, err := tm.blacklist.IsRevoked(ctx, claims.ID)
+		if err != nil {
+			return nil, fmt.Errorf("check token revocation: %w", err)
+		}
+		if revoked {
+			return nil, ErrRevokedToken
+		}
 	}
 
 	return claims, nil
 }
 
 // RefreshAccessToken generates a new access token from a valid refresh token
-func (tm *TokenManager) RefreshAccessToken(refreshToken string) (string, int64, error) {
+func (tm *TokenManager) RefreshAccessToken(ctx context.Context, refreshToken string) (string, int64, error) {
 	// Validate refresh token
-	claims, err := tm.ValidateRefreshToken(refreshToken)
+	claims, err := tm.ValidateRefreshToken(ctx, refreshToken)
 	if err != nil {
 		return "", 0, fmt.Errorf("validate refresh token: %w", err)
 	}
 
 	// Generate new access token
 	accessToken, err := tm.generateToken(
+		claims.SessionID,
 		claims.UserID,
 		claims.Email,
 		claims.Username,
 		claims.Role,
 		"access",
+		false,
 		tm.accessSecret,
 		tm.accessDuration,
 	)
@@ -0,0 +1,196 @@
+// pkg/auth/jwt_test.go
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTokenManager(issuer, audience string) *TokenManager {
+	tm := NewTokenManager("test-access-secret", "test-refresh-secret", 15*time.Minute, 7*24*time.Hour)
+	tm.SetIssuer(issuer)
+	tm.SetAudience(audience)
+	return tm
+}
+
+func TestTokenManager_IssuerAndAudience(t *testing.T) {
+	tm := newTestTokenManager("taskmaster-eu", "taskmaster-eu-api")
+
+	accessToken, refreshToken, _, err := tm.GenerateTokenPair("user-1", "user@example.com", "user", "user")
+	require.NoError(t, err)
+
+	claims, err := tm.ValidateAccessToken(context.Background(), accessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "taskmaster-eu", claims.Issuer)
+	assert.Equal(t, []string{"taskmaster-eu-api"}, []string(claims.Audience))
+
+	tests := []struct {
+		name     string
+		issuer   string
+		audience string
+	}{
+		{name: "wrong issuer", issuer: "taskmaster-us", audience: "taskmaster-eu-api"},
+		{name: "wrong audience", issuer: "taskmaster-eu", audience: "taskmaster-us-api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Same secrets, different deployment
+			other := newTestTokenManager(tt.issuer, tt.audience)
+
+			_, err := other.ValidateAccessToken(context.Background(), accessToken)
+			assert.ErrorIs(t, err, ErrInvalidClaims)
+
+			_, err = other.ValidateRefreshToken(context.Background(), refreshToken)
+			assert.ErrorIs(t, err, ErrInvalidClaims)
+
+			_, _, err = other.RefreshAccessToken(context.Background(), refreshToken)
+			assert.ErrorIs(t, err, ErrInvalidClaims)
+		})
+	}
+}
+
+func TestTokenManager_DefaultIssuerAndAudience(t *testing.T) {
+	tm := NewTokenManager("test-access-secret", "test-refresh-secret", 15*time.Minute, 7*24*time.Hour)
+
+	accessToken, _, _, err := tm.GenerateTokenPair("user-1", "user@example.com", "user", "user")
+	require.NoError(t, err)
+
+	claims, err := tm.ValidateAccessToken(context.Background(), accessToken)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTokenIssuer, claims.Issuer)
+	assert.Equal(t, []string{DefaultTokenAudience}, []string(claims.Audience))
+}
+
+func TestTokenManager_Leeway(t *testing.T) {
+	tests := []struct {
+		name    string
+		expiry  time.Duration
+		wantErr error
+	}{
+		{name: "valid", expiry: time.Minute},
+		{name: "just expired, within leeway", expiry: -10 * time.Second},
+		{name: "expired beyond leeway", expiry: -time.Minute, wantErr: ErrExpiredToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewTokenManager("test-access-secret", "test-refresh-secret", tt.expiry, time.Hour)
+			tm.SetLeeway(30 * time.Second)
+
+			accessToken, _, _, err := tm.GenerateTokenPair("user-1", "user@example.com", "user", "user")
+			require.NoError(t, err)
+
+			_, err = tm.ValidateAccessToken(context.Background(), accessToken)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("not before", func(t *testing.T) {
+		tm := NewTokenManager("test-access-secret", "test-refresh-secret", 15*time.Minute, time.Hour)
+		tm.SetLeeway(30 * time.Second)
+
+		// A token minted by a server whose clock runs ahead of ours
+		signNotBefore := func(notBefore time.Time) string {
+			claims := CustomClaims{
+				UserID: "user-1",
+				Type:   "access",
+				RegisteredClaims: jwt.RegisteredClaims{
+					Issuer:    DefaultTokenIssuer,
+					Audience:  jwt.ClaimStrings{DefaultTokenAudience},
+					IssuedAt:  jwt.NewNumericDate(notBefore),
+					NotBefore: jwt.NewNumericDate(notBefore),
+					ExpiresAt: jwt.NewNumericDate(notBefore.Add(15 * time.Minute)),
+				},
+			}
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-access-secret"))
+			require.NoError(t, err)
+			return token
+		}
+
+		_, err := tm.ValidateAccessToken(context.Background(), signNotBefore(time.Now().Add(10*time.Second)))
+		assert.NoError(t, err)
+
+		_, err = tm.ValidateAccessToken(context.Background(), signNotBefore(time.Now().Add(time.Minute)))
+		assert.ErrorIs(t, err, ErrTokenNotValidYet)
+	})
+}
+
+func TestTokenManager_ValidateAndCheckRefresh(t *testing.T) {
+	tests := []struct {
+		name         string
+		lifetime     time.Duration
+		threshold    time.Duration
+		expiringSoon bool
+	}{
+		{name: "outside threshold", lifetime: 15 * time.Minute, threshold: 2 * time.Minute, expiringSoon: false},
+		{name: "inside threshold", lifetime: time.Minute, threshold: 2 * time.Minute, expiringSoon: true},
+		{name: "expired within leeway", lifetime: -10 * time.Second, threshold: 2 * time.Minute, expiringSoon: true},
+		{name: "hint disabled", lifetime: time.Minute, threshold: 0, expiringSoon: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tm := NewTokenManager("test-access-secret", "test-refresh-secret", tt.lifetime, time.Hour)
+			tm.SetRefreshThreshold(tt.threshold)
+
+			accessToken, _, _, err := tm.GenerateTokenPair("user-1", "user@example.com", "user", "user")
+			require.NoError(t, err)
+
+			claims, expiringSoon, err := tm.ValidateAndCheckRefresh(context.Background(), accessToken)
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", claims.UserID)
+			assert.Equal(t, tt.expiringSoon, expiringSoon)
+		})
+	}
+
+	t.Run("invalid token", func(t *testing.T) {
+		tm := NewTokenManager("test-access-secret", "test-refresh-secret", 15*time.Minute, time.Hour)
+		_, refreshToken, _, err := tm.GenerateTokenPair("user-1", "user@example.com", "user", "user")
+		require.NoError(t, err)
+
+		_, expiringSoon, err := tm.ValidateAndCheckRefresh(context.Background(), refreshToken)
+		assert.Error(t, err)
+		assert.False(t, expiringSoon)
+	})
+}
+
+func TestTokenManager_RememberMe(t *testing.T) {
+	tm := NewTokenManager("test-access-secret", "test-refresh-secret", 15*time.Minute, 24*time.Hour)
+	tm.SetRememberMeDuration(30 * 24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		rememberMe bool
+		wantExpiry time.Duration
+	}{
+		{name: "regular login", rememberMe: false, wantExpiry: 24 * time.Hour},
+		{name: "remember me", rememberMe: true, wantExpiry: 30 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accessToken, refreshToken, _, err := tm.GenerateTokenPairFor("session-1", "user-1", "user@example.com", "user", "user", tt.rememberMe)
+			require.NoError(t, err)
+
+			claims, err := tm.ValidateRefreshToken(context.Background(), refreshToken)
+			require.NoError(t, err)
+			assert.Equal(t, tt.rememberMe, claims.RememberMe)
+			assert.Equal(t, "session-1", claims.SessionID)
+
+			accessClaims, err := tm.ValidateAccessToken(context.Background(), accessToken)
+			require.NoError(t, err)
+			assert.Equal(t, "session-1", accessClaims.SessionID)
+			assert.WithinDuration(t, time.Now().Add(tt.wantExpiry), claims.ExpiresAt.Time, time.Minute)
+		})
+	}
+}
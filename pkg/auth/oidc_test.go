@@ -0,0 +1,142 @@
+// pkg/auth/oidc_test.go
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://accounts.example.com"
+	testClientID = "taskmaster-client"
+)
+
+// newTestJWKS serves the public half of key under kid and counts fetches
+func newTestJWKS(t *testing.T, kid string, key *rsa.PrivateKey) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": kid,
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &fetches
+}
+
+func signTestIDToken(t *testing.T, kid string, key *rsa.PrivateKey, claims OIDCClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validTestClaims() OIDCClaims {
+	return OIDCClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testIssuer,
+			Subject:   "provider-subject-1",
+			Audience:  jwt.ClaimStrings{testClientID},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+		Email:         "oidc@example.com",
+		EmailVerified: true,
+	}
+}
+
+func TestOIDCVerifier_Verify(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server, fetches := newTestJWKS(t, "key-1", key)
+	verifier := NewOIDCVerifier(testIssuer, testClientID, server.URL)
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr bool
+	}{
+		{
+			name:  "valid token",
+			token: func() string { return signTestIDToken(t, "key-1", key, validTestClaims()) },
+		},
+		{
+			name: "wrong audience",
+			token: func() string {
+				claims := validTestClaims()
+				claims.Audience = jwt.ClaimStrings{"another-client"}
+				return signTestIDToken(t, "key-1", key, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				claims := validTestClaims()
+				claims.Issuer = "https://evil.example.com"
+				return signTestIDToken(t, "key-1", key, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			token: func() string {
+				claims := validTestClaims()
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+				return signTestIDToken(t, "key-1", key, claims)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "signed with a different key",
+			token:   func() string { return signTestIDToken(t, "key-1", otherKey, validTestClaims()) },
+			wantErr: true,
+		},
+		{
+			name:    "unknown key ID",
+			token:   func() string { return signTestIDToken(t, "key-2", key, validTestClaims()) },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(context.Background(), tt.token())
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "provider-subject-1", claims.Subject)
+			assert.Equal(t, "oidc@example.com", claims.Email)
+			assert.True(t, claims.EmailVerified)
+		})
+	}
+
+	// Keys are cached, and unknown key IDs don't refetch more than once a minute
+	assert.Equal(t, int32(1), fetches.Load())
+}
@@ -5,15 +5,27 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
 	"unicode"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	ErrWeakPassword = errors.New("password does not meet requirements")
+	ErrWeakPassword   = errors.New("password does not meet requirements")
+	ErrPasswordReused = errors.New("password was used recently")
 )
 
+// Supported password hashing algorithms
+const (
+	HashAlgorithmBcrypt   = "bcrypt"
+	HashAlgorithmArgon2id = "argon2id"
+)
+
+// DefaultBcryptCost is the bcrypt work factor used unless configured otherwise
+const DefaultBcryptCost = 12
+
 // PasswordManager handles password hashing and validation
 type PasswordManager struct {
 	minLength      int
@@ -21,6 +33,10 @@ type PasswordManager struct {
 	requireLower   bool
 	requireNumber  bool
 	requireSpecial bool
+	minScore       int
+	algorithm      string
+	argon2Params   Argon2Params
+	bcryptCost     int
 }
 
 // NewPasswordManager creates a new password manager with default settings
@@ -31,18 +47,94 @@ func NewPasswordManager() *PasswordManager {
 		requireLower:   true,
 		requireNumber:  true,
 		requireSpecial: false,
+		minScore:       DefaultMinPasswordScore,
+		algorithm:      HashAlgorithmBcrypt,
+		argon2Params:   DefaultArgon2Params(),
+		bcryptCost:     DefaultBcryptCost,
 	}
 }
 
-// HashPassword hashes a password using bcrypt
+// ValidateBcryptCost rejects costs outside the range bcrypt accepts
+func ValidateBcryptCost(cost int) error {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return fmt.Errorf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, cost)
+	}
+	return nil
+}
+
+// SetBcryptCost sets the work factor for new bcrypt hashes. Hashes made with
+// another cost keep verifying and are upgraded via NeedsRehash.
+func (pm *PasswordManager) SetBcryptCost(cost int) error {
+	if err := ValidateBcryptCost(cost); err != nil {
+		return err
+	}
+	pm.bcryptCost = cost
+	return nil
+}
+
+// SuggestBcryptCost benchmarks bcrypt on this machine and returns the highest
+// cost whose hash takes no longer than target, never less than bcrypt.MinCost.
+// Each cost step doubles the work, so the loop stops as soon as one is too slow.
+func SuggestBcryptCost(target time.Duration) (int, error) {
+	suggested := bcrypt.MinCost
+	for cost := bcrypt.MinCost; cost <= bcrypt.MaxCost; cost++ {
+		start := time.Now()
+		if _, err := bcrypt.GenerateFromPassword([]byte("benchmark-password"), cost); err != nil {
+			return 0, fmt.Errorf("benchmark bcrypt cost %d: %w", cost, err)
+		}
+		if time.Since(start) > target {
+			break
+		}
+		suggested = cost
+	}
+	return suggested, nil
+}
+
+// SetHashAlgorithm selects the algorithm used for new hashes. Existing hashes
+// of either algorithm keep verifying and are upgraded via NeedsRehash.
+func (pm *PasswordManager) SetHashAlgorithm(algorithm string, params Argon2Params) error {
+	switch algorithm {
+	case HashAlgorithmBcrypt, HashAlgorithmArgon2id:
+	default:
+		return fmt.Errorf("unsupported password hash algorithm %q", algorithm)
+	}
+	pm.algorithm = algorithm
+	pm.argon2Params = params
+	return nil
+}
+
+// SetMinScore sets the minimum strength score accepted by CheckStrength; 0 disables it
+func (pm *PasswordManager) SetMinScore(minScore int) {
+	pm.minScore = minScore
+}
+
+// CheckStrength rejects passwords scoring below the minimum, see ScorePassword
+func (pm *PasswordManager) CheckStrength(password string, userInputs ...string) error {
+	return CheckPasswordStrength(password, pm.minScore, userInputs...)
+}
+
+// HashPassword hashes a password using the configured algorithm
 func (pm *PasswordManager) HashPassword(password string) (string, error) {
 	// Validate password strength
 	if err := pm.ValidatePassword(password); err != nil {
 		return "", err
 	}
 
-	// Generate hash with cost of 12
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	return pm.Rehash(password)
+}
+
+// Rehash hashes an already accepted password with the configured algorithm,
+// skipping validation so existing passwords can be migrated
+func (pm *PasswordManager) Rehash(password string) (string, error) {
+	if pm.algorithm == HashAlgorithmArgon2id {
+		hash, err := hashArgon2id(password, pm.argon2Params)
+		if err != nil {
+			return "", fmt.Errorf("hash password: %w", err)
+		}
+		return hash, nil
+	}
+
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), pm.bcryptCost)
 	if err != nil {
 		return "", fmt.Errorf("hash password: %w", err)
 	}
@@ -50,11 +142,69 @@ func (pm *PasswordManager) HashPassword(password string) (string, error) {
 	return string(hashedBytes), nil
 }
 
-// ComparePassword compares a password with a hash
+// ComparePassword compares a password with a bcrypt or Argon2id hash
 func (pm *PasswordManager) ComparePassword(hashedPassword, password string) error {
+	if strings.HasPrefix(hashedPassword, argon2idPrefix) {
+		return compareArgon2id(hashedPassword, password)
+	}
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 }
 
+// NeedsRehash reports whether a hash was produced with a different algorithm,
+// bcrypt cost or Argon2id parameters than the ones currently configured
+func (pm *PasswordManager) NeedsRehash(hashedPassword string) bool {
+	isArgon2 := strings.HasPrefix(hashedPassword, argon2idPrefix)
+	if pm.algorithm != HashAlgorithmArgon2id {
+		if isArgon2 {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(hashedPassword))
+		return err == nil && cost != pm.bcryptCost
+	}
+	if !isArgon2 {
+		return true
+	}
+
+	params, _, _, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return true
+	}
+	return params.Memory != pm.argon2Params.Memory ||
+		params.Iterations != pm.argon2Params.Iterations ||
+		params.Parallelism != pm.argon2Params.Parallelism ||
+		params.KeyLength != pm.argon2Params.KeyLength
+}
+
+// CheckPasswordReuse returns ErrPasswordReused if password matches any of the hashes
+func (pm *PasswordManager) CheckPasswordReuse(password string, hashes ...string) error {
+	for _, hash := range hashes {
+		if hash != "" && pm.ComparePassword(hash, password) == nil {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// UpdatePasswordHistory puts the outgoing password hash at the front of
+// history and keeps at most size entries. A size of 0 disables history.
+func UpdatePasswordHistory(history []string, previousHash string, size int) []string {
+	if size <= 0 {
+		return []string{}
+	}
+
+	updated := make([]string, 0, size)
+	if previousHash != "" {
+		updated = append(updated, previousHash)
+	}
+	for _, hash := range history {
+		if len(updated) == size {
+			break
+		}
+		updated = append(updated, hash)
+	}
+	return updated
+}
+
 // ValidatePassword checks if a password meets the requirements
 func (pm *PasswordManager) ValidatePassword(password string) error {
 	if len(password) < pm.minLength {
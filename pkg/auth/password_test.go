@@ -0,0 +1,147 @@
+// pkg/auth/password_test.go
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2Params keeps hashing fast in tests
+var testArgon2Params = Argon2Params{Memory: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func newArgon2Manager(t *testing.T, params Argon2Params) *PasswordManager {
+	t.Helper()
+	pm := NewPasswordManager()
+	require.NoError(t, pm.SetHashAlgorithm(HashAlgorithmArgon2id, params))
+	return pm
+}
+
+func TestPasswordManager_Argon2idRoundTrip(t *testing.T) {
+	pm := newArgon2Manager(t, testArgon2Params)
+
+	hash, err := pm.HashPassword("Quartz#Nimbus71")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=8192,t=1,p=1$"))
+
+	assert.NoError(t, pm.ComparePassword(hash, "Quartz#Nimbus71"))
+	assert.Error(t, pm.ComparePassword(hash, "Quartz#Nimbus72"))
+
+	// Salts are random, so the same password hashes differently
+	other, err := pm.HashPassword("Quartz#Nimbus71")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+}
+
+func TestPasswordManager_CrossAlgorithmVerification(t *testing.T) {
+	bcryptManager := NewPasswordManager()
+	argon2Manager := newArgon2Manager(t, testArgon2Params)
+
+	bcryptHash, err := bcryptManager.HashPassword("Quartz#Nimbus71")
+	require.NoError(t, err)
+	argon2Hash, err := argon2Manager.HashPassword("Quartz#Nimbus71")
+	require.NoError(t, err)
+
+	for _, pm := range []*PasswordManager{bcryptManager, argon2Manager} {
+		assert.NoError(t, pm.ComparePassword(bcryptHash, "Quartz#Nimbus71"))
+		assert.NoError(t, pm.ComparePassword(argon2Hash, "Quartz#Nimbus71"))
+		assert.Error(t, pm.ComparePassword(bcryptHash, "wrong"))
+		assert.Error(t, pm.ComparePassword(argon2Hash, "wrong"))
+	}
+
+	assert.ErrorIs(t, argon2Manager.CheckPasswordReuse("Quartz#Nimbus71", bcryptHash), ErrPasswordReused)
+}
+
+func TestPasswordManager_ComparePassword_MalformedArgon2Hash(t *testing.T) {
+	pm := NewPasswordManager()
+
+	for _, hash := range []string{
+		"$argon2id$",
+		"$argon2id$v=19$m=8192,t=1,p=1$c2FsdA",
+		"$argon2id$v=18$m=8192,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=8192,t=1,p=1$!!$a2V5",
+	} {
+		assert.Error(t, pm.ComparePassword(hash, "Quartz#Nimbus71"), hash)
+	}
+}
+
+func TestPasswordManager_NeedsRehash(t *testing.T) {
+	bcryptManager := NewPasswordManager()
+	argon2Manager := newArgon2Manager(t, testArgon2Params)
+
+	bcryptHash, err := bcryptManager.HashPassword("Quartz#Nimbus71")
+	require.NoError(t, err)
+	argon2Hash, err := argon2Manager.HashPassword("Quartz#Nimbus71")
+	require.NoError(t, err)
+
+	stronger := testArgon2Params
+	stronger.Iterations = 2
+	strongerManager := newArgon2Manager(t, stronger)
+
+	cheaperManager := NewPasswordManager()
+	require.NoError(t, cheaperManager.SetBcryptCost(bcrypt.MinCost))
+
+	tests := []struct {
+		name     string
+		manager  *PasswordManager
+		hash     string
+		expected bool
+	}{
+		{name: "bcrypt preferred, bcrypt hash", manager: bcryptManager, hash: bcryptHash, expected: false},
+		{name: "bcrypt preferred, argon2 hash", manager: bcryptManager, hash: argon2Hash, expected: true},
+		{name: "bcrypt preferred, changed cost", manager: cheaperManager, hash: bcryptHash, expected: true},
+		{name: "argon2 preferred, bcrypt hash", manager: argon2Manager, hash: bcryptHash, expected: true},
+		{name: "argon2 preferred, same params", manager: argon2Manager, hash: argon2Hash, expected: false},
+		{name: "argon2 preferred, changed params", manager: strongerManager, hash: argon2Hash, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.manager.NeedsRehash(tt.hash))
+		})
+	}
+}
+
+func TestPasswordManager_SetHashAlgorithm_Unsupported(t *testing.T) {
+	pm := NewPasswordManager()
+	assert.Error(t, pm.SetHashAlgorithm("md5", DefaultArgon2Params()))
+}
+
+func TestPasswordManager_SetBcryptCost(t *testing.T) {
+	pm := NewPasswordManager()
+	require.NoError(t, pm.SetBcryptCost(5))
+
+	hash, err := pm.HashPassword("Quartz#Nimbus71")
+	require.NoError(t, err)
+	cost, err := bcrypt.Cost([]byte(hash))
+	require.NoError(t, err)
+	assert.Equal(t, 5, cost)
+	assert.NoError(t, pm.ComparePassword(hash, "Quartz#Nimbus71"))
+
+	for _, invalid := range []int{0, bcrypt.MinCost - 1, bcrypt.MaxCost + 1} {
+		assert.Error(t, pm.SetBcryptCost(invalid), invalid)
+	}
+
+	// Rejected values leave the configured cost in place
+	hash, err = pm.HashPassword("Quartz#Nimbus71")
+	require.NoError(t, err)
+	cost, err = bcrypt.Cost([]byte(hash))
+	require.NoError(t, err)
+	assert.Equal(t, 5, cost)
+}
+
+func TestSuggestBcryptCost(t *testing.T) {
+	// Nothing is fast enough for a zero budget, so the minimum is suggested
+	cost, err := SuggestBcryptCost(0)
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.MinCost, cost)
+
+	cost, err = SuggestBcryptCost(20 * time.Millisecond)
+	require.NoError(t, err)
+	assert.NoError(t, ValidateBcryptCost(cost))
+}
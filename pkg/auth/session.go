@@ -0,0 +1,164 @@
+// pkg/auth/session.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/session"
+)
+
+var (
+	ErrInvalidSession = errors.New("invalid session")
+	ErrExpiredSession = errors.New("session has expired")
+	// ErrRefreshTokenReused is returned when a refresh token was already
+	// rotated out, e.g. by a concurrent refresh; the session is ended
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+// SessionStore tracks signed-in devices. Each session holds a SHA-256 hash of
+// its current refresh token, so a stolen database does not yield usable tokens.
+type SessionStore struct {
+	client *ent.Client
+	now    func() time.Time
+}
+
+// NewSessionStore creates a new session store
+func NewSessionStore(client *ent.Client) *SessionStore {
+	return &SessionStore{client: client, now: time.Now}
+}
+
+// hashRefreshToken returns the stored form of a refresh token
+func hashRefreshToken(refreshToken string) string {
+	return HashToken(refreshToken)
+}
+
+// Create records session sessionID for userID holding refreshToken. The ID
+// is chosen by the caller, so it can be put in the session's tokens.
+func (s *SessionStore) Create(ctx context.Context, sessionID, userID uuid.UUID, refreshToken, ipAddress, userAgent string, expiresAt time.Time) (*ent.Session, error) {
+	now := s.now()
+	created, err := s.client.Session.Create().
+		SetID(sessionID).
+		SetUserID(userID).
+		SetRefreshTokenHash(hashRefreshToken(refreshToken)).
+		SetIPAddress(ipAddress).
+		SetUserAgent(userAgent).
+		SetExpiresAt(expiresAt).
+		SetLastUsedAt(now).
+		SetLastActivityAt(now).
+		Save(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create session: %w", err)
+	}
+	return created, nil
+}
+
+// Find resolves a refresh token to its session, with the owner loaded.
+// Unknown tokens, including ones already rotated out, and expired sessions fail.
+func (s *SessionStore) Find(ctx context.Context, refreshToken string) (*ent.Session, error) {
+	found, err := s.client.Session.Query().
+		Where(session.RefreshTokenHashEQ(hashRefreshToken(refreshToken))).
+		WithOwner().
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return nil, ErrInvalidSession
+		}
+		return nil, fmt.Errorf("find session: %w", err)
+	}
+
+	if !found.ExpiresAt.After(s.now()) {
+		return nil, ErrExpiredSession
+	}
+
+	return found, nil
+}
+
+// Rotate replaces the session's refresh token oldToken with newToken and
+// records the use. If oldToken is no longer current, someone else already
+// used it: the session is ended, since one of the two holders may have
+// stolen it, and ErrRefreshTokenReused is returned.
+func (s *SessionStore) Rotate(ctx context.Context, sessionID uuid.UUID, oldToken, newToken string, expiresAt time.Time) error {
+	n, err := s.client.Session.Update().
+		Where(session.ID(sessionID), session.RefreshTokenHashEQ(hashRefreshToken(oldToken))).
+		SetRefreshTokenHash(hashRefreshToken(newToken)).
+		SetExpiresAt(expiresAt).
+		SetLastUsedAt(s.now()).
+		Save(ctx)
+	if err != nil {
+		return fmt.Errorf("rotate session: %w", err)
+	}
+	if n == 0 {
+		if err := s.client.Session.DeleteOneID(sessionID).Exec(ctx); err != nil && !ent.IsNotFound(err) {
+			return fmt.Errorf("revoke reused session: %w", err)
+		}
+		return ErrRefreshTokenReused
+	}
+	return nil
+}
+
+// List returns userID's unexpired sessions, most recently used first
+func (s *SessionStore) List(ctx context.Context, userID uuid.UUID) ([]*ent.Session, error) {
+	sessions, err := s.client.Session.Query().
+		Where(session.UserIDEQ(userID), session.ExpiresAtGT(s.now())).
+		Order(ent.Desc(session.FieldLastUsedAt)).
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// Revoke ends a session owned by userID. A session owned by someone else is
+// reported as not found.
+func (s *SessionStore) Revoke(ctx context.Context, userID, sessionID uuid.UUID) error {
+	found, err := s.client.Session.Query().
+		Where(session.ID(sessionID), session.UserIDEQ(userID)).
+		Only(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.client.Session.DeleteOne(found).Exec(ctx); err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeByToken ends the session holding refreshToken, if any
+func (s *SessionStore) RevokeByToken(ctx context.Context, refreshToken string) error {
+	_, err := s.client.Session.Delete().
+		Where(session.RefreshTokenHashEQ(hashRefreshToken(refreshToken))).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeAll ends every session of userID, signing the user out everywhere
+func (s *SessionStore) RevokeAll(ctx context.Context, userID uuid.UUID) (int, error) {
+	deleted, err := s.client.Session.Delete().
+		Where(session.UserIDEQ(userID)).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("revoke sessions: %w", err)
+	}
+	return deleted, nil
+}
+
+// CleanupExpired removes sessions whose refresh token has expired
+func (s *SessionStore) CleanupExpired(ctx context.Context) (int, error) {
+	deleted, err := s.client.Session.Delete().
+		Where(session.ExpiresAtLT(s.now())).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("cleanup sessions: %w", err)
+	}
+	return deleted, nil
+}
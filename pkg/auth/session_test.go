@@ -0,0 +1,72 @@
+// pkg/auth/session_test.go
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gurkanbulca/taskmaster/ent/generated/enttest"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSessionStore_RotateAndCleanup(t *testing.T) {
+	client := enttest.Open(t, "sqlite3", "file:sessions?mode=memory&cache=shared&_fk=1")
+	defer client.Close()
+
+	ctx := context.Background()
+	owner, err := client.User.Create().
+		SetEmail("session@example.com").
+		SetUsername("sessionuser").
+		SetPasswordHash("hash").
+		Save(ctx)
+	require.NoError(t, err)
+
+	store := NewSessionStore(client)
+	active, err := store.Create(ctx, uuid.New(), owner.ID, "refresh-1", "127.0.0.1", "test-agent", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	_, err = store.Create(ctx, uuid.New(), owner.ID, "refresh-expired", "127.0.0.1", "test-agent", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	found, err := store.Find(ctx, "refresh-1")
+	require.NoError(t, err)
+	assert.Equal(t, active.ID, found.ID)
+	assert.Equal(t, owner.ID, found.Edges.Owner.ID)
+
+	_, err = store.Find(ctx, "refresh-expired")
+	assert.ErrorIs(t, err, ErrExpiredSession)
+
+	// After rotation only the new token resolves to the session
+	require.NoError(t, store.Rotate(ctx, active.ID, "refresh-1", "refresh-2", time.Now().Add(time.Hour)))
+	_, err = store.Find(ctx, "refresh-1")
+	assert.ErrorIs(t, err, ErrInvalidSession)
+	found, err = store.Find(ctx, "refresh-2")
+	require.NoError(t, err)
+	assert.Equal(t, active.ID, found.ID)
+
+	// A second rotation of the same token, as by a concurrent refresh, ends
+	// the session instead of forking it
+	err = store.Rotate(ctx, active.ID, "refresh-1", "refresh-3", time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	_, err = store.Find(ctx, "refresh-2")
+	assert.ErrorIs(t, err, ErrInvalidSession)
+	_, err = store.Find(ctx, "refresh-3")
+	assert.ErrorIs(t, err, ErrInvalidSession)
+
+	_, err = store.Create(ctx, uuid.New(), owner.ID, "refresh-4", "127.0.0.1", "test-agent", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	// Expired sessions are hidden from the list and removed by cleanup
+	sessions, err := store.List(ctx, owner.ID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+
+	deleted, err := store.CleanupExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+}
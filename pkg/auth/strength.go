@@ -0,0 +1,250 @@
+// pkg/auth/strength.go
+package auth
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// MaxPasswordScore is the highest score ScorePassword returns
+const MaxPasswordScore = 4
+
+// DefaultMinPasswordScore is the minimum score accepted unless configured otherwise
+const DefaultMinPasswordScore = 2
+
+// commonPasswords are rejected outright, including leetspeak variants and
+// variants with leading or trailing digits and symbols (e.g. "P@ssw0rd1!")
+var commonPasswords = map[string]bool{
+	"password": true, "passw0rd": true, "qwerty": true, "qwertyuiop": true, "letmein": true,
+	"welcome": true, "admin": true, "administrator": true, "iloveyou": true, "monkey": true,
+	"dragon": true, "master": true, "sunshine": true, "princess": true, "football": true,
+	"baseball": true, "superman": true, "batman": true, "trustno": true, "shadow": true,
+	"michael": true, "jennifer": true, "hunter": true, "freedom": true, "whatever": true,
+	"starwars": true, "login": true, "access": true, "secret": true, "changeme": true,
+	"default": true, "computer": true, "internet": true, "hello": true, "charlie": true,
+	"summer": true, "winter": true, "spring": true, "autumn": true, "flower": true,
+	"cheese": true, "pokemon": true, "soccer": true, "hockey": true, "killer": true,
+	"pepper": true, "ginger": true, "matrix": true, "mustang": true, "liverpool": true,
+	"chelsea": true, "arsenal": true, "jordan": true, "harley": true, "ranger": true,
+	"buster": true, "tigger": true, "maggie": true, "daniel": true, "thomas": true,
+	"robert": true, "jessica": true, "ashley": true, "nicole": true, "biteme": true,
+	"zaq1xsw2": true, "1q2w3e4r": true, "qazwsx": true, "abc123": true, "password1": true,
+	"letmein1": true, "welcome1": true, "test": true, "guest": true, "root": true,
+	"user": true, "temp": true, "taskmaster": true,
+}
+
+// keyboardSequences are scanned forwards and backwards for runs like "abc" or "321"
+var keyboardSequences = []string{
+	"abcdefghijklmnopqrstuvwxyz",
+	"01234567890",
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+}
+
+// leetReplacer maps common character substitutions back to letters
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s",
+)
+
+// PasswordStrength is the result of scoring a password
+type PasswordStrength struct {
+	Score    int      // 0 (very weak) to MaxPasswordScore (strong)
+	Warnings []string // Human readable reasons the password is weak
+}
+
+// ScorePassword estimates how hard a password is to guess. Passwords that are
+// common, contain one of the user inputs (username, email, names) or are built
+// from sequences and repeats score low. userInputs shorter than 3 characters
+// are ignored.
+func ScorePassword(password string, userInputs ...string) PasswordStrength {
+	var result PasswordStrength
+	lower := strings.ToLower(password)
+
+	if isCommonPassword(lower) {
+		result.Warnings = append(result.Warnings, "it is a commonly used password")
+	}
+
+	for _, input := range expandUserInputs(userInputs) {
+		if strings.Contains(lower, input) {
+			result.Warnings = append(result.Warnings, "it contains your username or email")
+			break
+		}
+	}
+
+	if len(result.Warnings) > 0 {
+		return result
+	}
+
+	effectiveLength, hasPattern := effectivePasswordLength(lower)
+	if hasPattern {
+		result.Warnings = append(result.Warnings, "it contains sequences or repeated characters")
+	}
+
+	bits := float64(effectiveLength) * math.Log2(float64(charsetSize(password)))
+	switch {
+	case bits < 28:
+		result.Score = 0
+	case bits < 36:
+		result.Score = 1
+	case bits < 50:
+		result.Score = 2
+	case bits < 70:
+		result.Score = 3
+	default:
+		result.Score = 4
+	}
+
+	return result
+}
+
+// CheckPasswordStrength returns an error wrapping ErrWeakPassword when the
+// password scores below minScore
+func CheckPasswordStrength(password string, minScore int, userInputs ...string) error {
+	if minScore <= 0 {
+		return nil
+	}
+
+	strength := ScorePassword(password, userInputs...)
+	if strength.Score >= minScore {
+		return nil
+	}
+
+	if len(strength.Warnings) > 0 {
+		return fmt.Errorf("%w: password is too weak, %s", ErrWeakPassword, strength.Warnings[0])
+	}
+	return fmt.Errorf("%w: password is too weak, use a longer or less predictable password", ErrWeakPassword)
+}
+
+// isCommonPassword checks the password and its de-decorated forms against the dictionary
+func isCommonPassword(lower string) bool {
+	trimmed := strings.TrimFunc(lower, func(r rune) bool {
+		return unicode.IsDigit(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+
+	for _, candidate := range []string{lower, trimmed, leetReplacer.Replace(trimmed)} {
+		if candidate != "" && commonPasswords[candidate] {
+			return true
+		}
+	}
+	return false
+}
+
+// expandUserInputs lowercases inputs and adds the local part of email addresses
+func expandUserInputs(inputs []string) []string {
+	var expanded []string
+	for _, input := range inputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if at := strings.Index(input, "@"); at > 0 {
+			expanded = append(expanded, input[:at])
+		}
+		expanded = append(expanded, input)
+	}
+
+	filtered := expanded[:0]
+	for _, input := range expanded {
+		if len(input) >= 3 {
+			filtered = append(filtered, input)
+		}
+	}
+	return filtered
+}
+
+// effectivePasswordLength counts each run of three or more repeated or
+// sequential characters as a single character
+func effectivePasswordLength(lower string) (int, bool) {
+	runes := []rune(lower)
+	length := 0
+	hasPattern := false
+
+	for i := 0; i < len(runes); {
+		run := patternRunLength(runes[i:])
+		if run >= 3 {
+			hasPattern = true
+			i += run
+		} else {
+			i++
+		}
+		length++
+	}
+
+	return length, hasPattern
+}
+
+// patternRunLength returns the length of the repeat or sequence starting at runes[0]
+func patternRunLength(runes []rune) int {
+	if len(runes) < 2 {
+		return len(runes)
+	}
+
+	// Repeated characters, e.g. "aaa"
+	repeat := 1
+	for repeat < len(runes) && runes[repeat] == runes[0] {
+		repeat++
+	}
+
+	// Sequences, e.g. "abc", "cba", "qwe", "789"
+	longest := repeat
+	for _, seq := range keyboardSequences {
+		for _, s := range []string{seq, reverseString(seq)} {
+			pos := strings.IndexRune(s, runes[0])
+			if pos < 0 {
+				continue
+			}
+			n := 1
+			for n < len(runes) && pos+n < len(s) && rune(s[pos+n]) == runes[n] {
+				n++
+			}
+			if n > longest {
+				longest = n
+			}
+		}
+	}
+
+	return longest
+}
+
+// charsetSize estimates the alphabet the password draws from
+func charsetSize(password string) int {
+	var hasLower, hasUpper, hasDigit, hasOther bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasOther = true
+		}
+	}
+
+	size := 0
+	if hasLower {
+		size += 26
+	}
+	if hasUpper {
+		size += 26
+	}
+	if hasDigit {
+		size += 10
+	}
+	if hasOther {
+		size += 33
+	}
+	if size == 0 {
+		size = 1
+	}
+	return size
+}
+
+func reverseString(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
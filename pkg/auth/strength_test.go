@@ -0,0 +1,67 @@
+// pkg/auth/strength_test.go
+package auth
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScorePassword(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		minScore   int
+		maxScore   int
+	}{
+		// Common passwords, including decorated and leetspeak variants
+		{name: "common", password: "password", maxScore: 0},
+		{name: "common with digit", password: "Password1", maxScore: 0},
+		{name: "common leetspeak", password: "P@ssw0rd!", maxScore: 0},
+		{name: "common with suffix", password: "Welcome2024!", maxScore: 0},
+		{name: "keyboard walk", password: "qwertyuiop", maxScore: 0},
+
+		// Passwords containing user inputs
+		{name: "contains username", password: "JohnDoe#2024x", userInputs: []string{"johndoe", "jd@example.com"}, maxScore: 0},
+		{name: "contains email local part", password: "Xmarycurie77!", userInputs: []string{"mc", "marycurie@example.com"}, maxScore: 0},
+		{name: "short inputs ignored", password: "Zq8#vLm2pR", userInputs: []string{"zq"}, minScore: 3, maxScore: 4},
+
+		// Sequences and repeats
+		{name: "alphabet sequence", password: "Abcdefgh1", maxScore: 1},
+		{name: "digit sequence", password: "12345678", maxScore: 0},
+		{name: "repeats", password: "Aaaaaaaa1111", maxScore: 1},
+
+		// Strong passwords
+		{name: "random mix", password: "Zq8#vLm2pR", minScore: 3, maxScore: 4},
+		{name: "passphrase", password: "correct horse battery staple", minScore: 4, maxScore: 4},
+		{name: "long mixed", password: "Tr0ub4dor&3-Kx9", minScore: 4, maxScore: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strength := ScorePassword(tt.password, tt.userInputs...)
+			assert.GreaterOrEqual(t, strength.Score, tt.minScore)
+			assert.LessOrEqual(t, strength.Score, tt.maxScore)
+			if tt.maxScore == 0 {
+				assert.NotEmpty(t, strength.Warnings)
+			}
+		})
+	}
+}
+
+func TestCheckPasswordStrength(t *testing.T) {
+	err := CheckPasswordStrength("Password1", DefaultMinPasswordScore)
+	assert.True(t, errors.Is(err, ErrWeakPassword))
+	assert.Contains(t, err.Error(), "commonly used")
+
+	err = CheckPasswordStrength("alice-Secure-42", DefaultMinPasswordScore, "alice", "alice@example.com")
+	assert.True(t, errors.Is(err, ErrWeakPassword))
+	assert.Contains(t, err.Error(), "username or email")
+
+	assert.NoError(t, CheckPasswordStrength("Zq8#vLm2pR", DefaultMinPasswordScore, "alice"))
+
+	// A minimum of 0 disables the check
+	assert.NoError(t, CheckPasswordStrength("password", 0))
+}
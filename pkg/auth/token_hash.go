@@ -0,0 +1,31 @@
+// pkg/auth/token_hash.go
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// HashToken returns the stored form of a secret token: single-use tokens such
+// as password reset or email verification links, refresh tokens and API keys.
+// Only the hash is kept, so a database leak does not yield working tokens.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// dummyTokenHash stands in for the stored hash when a lookup finds nothing
+var dummyTokenHash = HashToken("no stored token")
+
+// TokenMatches reports whether token hashes to storedHash using a constant-time
+// comparison. An empty storedHash never matches but costs the same as a real
+// comparison, so callers can pass it on lookup misses to keep timing uniform.
+func TokenMatches(token, storedHash string) bool {
+	want := storedHash
+	if want == "" {
+		want = dummyTokenHash
+	}
+	equal := subtle.ConstantTimeCompare([]byte(HashToken(token)), []byte(want)) == 1
+	return equal && storedHash != ""
+}
@@ -0,0 +1,39 @@
+// pkg/auth/token_hash_test.go
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashToken(t *testing.T) {
+	hash := HashToken("raw-token")
+	assert.Len(t, hash, 64)
+	assert.NotEqual(t, "raw-token", hash)
+	assert.Equal(t, hash, HashToken("raw-token"))
+	assert.NotEqual(t, hash, HashToken("raw-token2"))
+}
+
+func TestTokenMatches(t *testing.T) {
+	stored := HashToken("raw-token")
+
+	tests := []struct {
+		name       string
+		token      string
+		storedHash string
+		want       bool
+	}{
+		{name: "matching token", token: "raw-token", storedHash: stored, want: true},
+		{name: "wrong token", token: "raw-tokem", storedHash: stored, want: false},
+		{name: "hash presented as token", token: stored, storedHash: stored, want: false},
+		{name: "nothing stored", token: "raw-token", storedHash: "", want: false},
+		{name: "nothing stored, dummy preimage", token: "no stored token", storedHash: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, TokenMatches(tt.token, tt.storedHash))
+		})
+	}
+}
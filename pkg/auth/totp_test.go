@@ -0,0 +1,104 @@
+// pkg/auth/totp_test.go
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 Appendix B test secret for SHA1
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPManager_GenerateCode_RFC6238Vectors(t *testing.T) {
+	m := NewTOTPManager("TaskMaster")
+
+	// The RFC lists 8-digit codes; the 6-digit codes are their last six digits
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, v := range vectors {
+		code, err := m.GenerateCode(rfc6238Secret, time.Unix(v.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, v.code, code, "time %d", v.unix)
+	}
+}
+
+func TestTOTPManager_ValidateCode_TimeSkew(t *testing.T) {
+	m := NewTOTPManager("TaskMaster")
+	secret, err := m.GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	code, err := m.GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		at    time.Time
+		valid bool
+	}{
+		{"same period", now, true},
+		{"one period behind", now.Add(-30 * time.Second), true},
+		{"one period ahead", now.Add(30 * time.Second), true},
+		{"two periods behind", now.Add(-60 * time.Second), false},
+		{"two periods ahead", now.Add(60 * time.Second), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.valid, m.ValidateCode(secret, code, tt.at))
+		})
+	}
+
+	assert.False(t, m.ValidateCode(secret, "12345", now), "wrong length")
+	assert.False(t, m.ValidateCode("not-base32!", code, now), "invalid secret")
+}
+
+func TestTOTPManager_ConsumeBackupCode(t *testing.T) {
+	m := NewTOTPManager("TaskMaster")
+
+	codes, hashes, err := m.GenerateBackupCodes(3)
+	require.NoError(t, err)
+	require.Len(t, codes, 3)
+	require.Len(t, hashes, 3)
+
+	remaining, ok := m.ConsumeBackupCode(hashes, codes[1])
+	require.True(t, ok)
+	assert.Len(t, remaining, 2)
+
+	// A consumed code cannot be reused
+	_, ok = m.ConsumeBackupCode(remaining, codes[1])
+	assert.False(t, ok)
+
+	// Codes are matched case-insensitively
+	remaining, ok = m.ConsumeBackupCode(remaining, strings.ToUpper(codes[0]))
+	require.True(t, ok)
+	assert.Len(t, remaining, 1)
+
+	_, ok = m.ConsumeBackupCode(remaining, "0000000000")
+	assert.False(t, ok)
+}
+
+func TestTOTPManager_ProvisioningURI(t *testing.T) {
+	m := NewTOTPManager("TaskMaster")
+	uri := m.ProvisioningURI("JBSWY3DPEHPK3PXP", "user@example.com")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/TaskMaster:user@example.com?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=TaskMaster")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
@@ -0,0 +1,48 @@
+// pkg/authz/authz.go
+package authz
+
+import (
+	"github.com/gurkanbulca/taskmaster/ent/generated/user"
+)
+
+// Permission is a named capability granted to roles, in resource:action form
+type Permission string
+
+// Permissions checked by the services
+const (
+	SecurityEventsReadAll Permission = "security_events:read_all" // View every user's security events and stats
+	SecurityEventsManage  Permission = "security_events:manage"   // Resolve and delete security events
+	AccountUnlock         Permission = "account:unlock"           // Unlock accounts locked after failed logins
+	UserManage            Permission = "user:manage"              // List users, change roles and active status
+	TasksReadAll          Permission = "tasks:read_all"           // View tasks of any user
+	TasksWriteAll         Permission = "tasks:write_all"          // Update, delete and restore tasks of any user
+	TasksReadDeleted      Permission = "tasks:read_deleted"       // List soft-deleted tasks
+	CommentsDeleteAll     Permission = "comments:delete_all"      // Delete comments written by any user
+	TemplatesManageAll    Permission = "templates:manage_all"     // View, use, update and delete any user's task templates
+)
+
+// rolePermissions maps each role to the permissions it grants. Roles not
+// listed here, including the regular user role, only act on their own data.
+var rolePermissions = map[user.Role]map[Permission]bool{
+	user.RoleAdmin: {
+		SecurityEventsReadAll: true,
+		SecurityEventsManage:  true,
+		AccountUnlock:         true,
+		UserManage:            true,
+		TasksReadAll:          true,
+		TasksWriteAll:         true,
+		TasksReadDeleted:      true,
+		CommentsDeleteAll:     true,
+		TemplatesManageAll:    true,
+	},
+	user.RoleManager: {
+		TasksReadAll:  true,
+		TasksWriteAll: true,
+	},
+	user.RoleUser: {},
+}
+
+// Can reports whether the role grants the permission
+func Can(role string, permission Permission) bool {
+	return rolePermissions[user.Role(role)][permission]
+}
@@ -0,0 +1,45 @@
+// pkg/authz/authz_test.go
+package authz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCan_PermissionMatrix(t *testing.T) {
+	allPermissions := []Permission{
+		SecurityEventsReadAll,
+		SecurityEventsManage,
+		AccountUnlock,
+		UserManage,
+		TasksReadAll,
+		TasksWriteAll,
+		TasksReadDeleted,
+		CommentsDeleteAll,
+		TemplatesManageAll,
+	}
+
+	expected := map[string][]Permission{
+		"admin":   allPermissions,
+		"manager": {TasksReadAll, TasksWriteAll},
+		"user":    {},
+		"":        {},
+		"root":    {},
+	}
+
+	for role, granted := range expected {
+		grantedSet := make(map[Permission]bool, len(granted))
+		for _, p := range granted {
+			grantedSet[p] = true
+		}
+
+		for _, permission := range allPermissions {
+			assert.Equal(t, grantedSet[permission], Can(role, permission), "role %q permission %s", role, permission)
+		}
+	}
+}
+
+func TestCan_UnknownPermission(t *testing.T) {
+	assert.False(t, Can("admin", Permission("tasks:launch_rockets")))
+}
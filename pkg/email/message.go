@@ -0,0 +1,193 @@
+// pkg/email/message.go
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// base64LineLength is the line length of base64 encoded attachments
+const base64LineLength = 76
+
+// Attachment is a file sent along with an email
+type Attachment struct {
+	Filename    string
+	ContentType string // Defaults to application/octet-stream
+	Data        []byte
+}
+
+// message is an outgoing email with plain text and HTML alternatives and
+// optional attachments
+type message struct {
+	from        mail.Address
+	to          string
+	bcc         []string // Envelope only, never written as a header
+	subject     string
+	textBody    string
+	htmlBody    string
+	attachments []Attachment
+	date        time.Time
+}
+
+// recipients returns the envelope recipients: the addressee and any BCCs
+func (m *message) recipients() []string {
+	return append([]string{m.to}, m.bcc...)
+}
+
+// bytes renders the message as RFC 5322 text with CRLF line endings, a Date
+// and a Message-ID, as DKIM signers and spam filters expect. Non-ASCII
+// header text is RFC 2047 encoded and the bodies are quoted-printable, so
+// every line stays 7-bit and short. With attachments the body is
+// multipart/mixed, holding the alternatives followed by one part per file.
+func (m *message) bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+
+	mediaType := "multipart/alternative"
+	if len(m.attachments) > 0 {
+		mediaType = "multipart/mixed"
+	}
+
+	headers := []struct{ key, value string }{
+		{"From", m.from.String()},
+		{"To", (&mail.Address{Address: m.to}).String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", m.subject)},
+		{"Date", m.date.Format(time.RFC1123Z)},
+		{"Message-ID", newMessageID(m.from.Address)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", mime.FormatMediaType(mediaType, map[string]string{"boundary": body.Boundary()})},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h.key, h.value)
+	}
+	buf.WriteString("\r\n")
+
+	if len(m.attachments) == 0 {
+		if err := m.writeAlternatives(body); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := m.writeMixed(body); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, fmt.Errorf("close multipart body: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeAlternatives adds the plain text and HTML bodies
+func (m *message) writeAlternatives(body *multipart.Writer) error {
+	if err := writeTextPart(body, "text/plain", m.textBody); err != nil {
+		return err
+	}
+	return writeTextPart(body, "text/html", m.htmlBody)
+}
+
+// writeMixed adds a nested multipart/alternative part with the bodies,
+// followed by the attachments
+func (m *message) writeMixed(body *multipart.Writer) error {
+	var altBuf bytes.Buffer
+	alt := multipart.NewWriter(&altBuf)
+	if err := m.writeAlternatives(alt); err != nil {
+		return err
+	}
+	if err := alt.Close(); err != nil {
+		return fmt.Errorf("close alternative part: %w", err)
+	}
+
+	part, err := body.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": alt.Boundary()})},
+	})
+	if err != nil {
+		return fmt.Errorf("create alternative part: %w", err)
+	}
+	if _, err := part.Write(altBuf.Bytes()); err != nil {
+		return fmt.Errorf("write alternative part: %w", err)
+	}
+
+	for _, a := range m.attachments {
+		if err := writeAttachment(body, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTextPart adds a quoted-printable UTF-8 part; its line breaks become CRLF
+func writeTextPart(body *multipart.Writer, mediaType, content string) error {
+	part, err := body.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(mediaType, map[string]string{"charset": "UTF-8"})},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return fmt.Errorf("create %s part: %w", mediaType, err)
+	}
+
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return fmt.Errorf("write %s part: %w", mediaType, err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("write %s part: %w", mediaType, err)
+	}
+	return nil
+}
+
+// writeAttachment adds a base64 attachment part. The content type is
+// re-formatted and the filename RFC 2231 encoded as needed, so neither can
+// break out of its header.
+func writeAttachment(body *multipart.Writer, a Attachment) error {
+	contentType := "application/octet-stream"
+	if mediaType, params, err := mime.ParseMediaType(a.ContentType); err == nil {
+		contentType = mime.FormatMediaType(mediaType, params)
+	}
+
+	disposition := "attachment"
+	if a.Filename != "" {
+		disposition = mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})
+	}
+
+	part, err := body.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Disposition":       {disposition},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return fmt.Errorf("create attachment part: %w", err)
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	for len(encoded) > 0 {
+		n := min(base64LineLength, len(encoded))
+		if _, err := fmt.Fprintf(part, "%s\r\n", encoded[:n]); err != nil {
+			return fmt.Errorf("write attachment part: %w", err)
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
+
+// newMessageID returns a unique Message-ID in the sender's domain
+func newMessageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+		domain = from[at+1:]
+	}
+
+	random := make([]byte, 16)
+	rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain)
+}
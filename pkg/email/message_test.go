@@ -0,0 +1,162 @@
+// pkg/email/message_test.go
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage_Bytes(t *testing.T) {
+	date := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	msg := &message{
+		from:     mail.Address{Name: "TaskMaster Support", Address: "noreply@example.com"},
+		to:       "user@example.com",
+		subject:  "Willkommen bei TaskMaster, Jürgen",
+		textBody: "Hallo Jürgen,\nwillkommen!\n",
+		htmlBody: "<p>Hallo Jürgen,</p>\n<p>" + strings.Repeat("lang ", 100) + "</p>\n",
+		date:     date,
+	}
+
+	raw, err := msg.bytes()
+	require.NoError(t, err)
+
+	t.Run("CRLF line endings", func(t *testing.T) {
+		assert.NotContains(t, strings.ReplaceAll(string(raw), "\r\n", ""), "\n", "no bare LF")
+		assert.NotContains(t, strings.ReplaceAll(string(raw), "\r\n", ""), "\r", "no bare CR")
+		for _, line := range strings.Split(string(raw), "\r\n") {
+			assert.LessOrEqual(t, len(line), 998, "RFC 5322 line length limit")
+			for _, c := range line {
+				require.Less(t, c, rune(128), "7-bit only: %q", line)
+			}
+		}
+	})
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	t.Run("headers", func(t *testing.T) {
+		sentAt, err := parsed.Header.Date()
+		require.NoError(t, err)
+		assert.True(t, date.Equal(sentAt))
+
+		messageID := parsed.Header.Get("Message-ID")
+		assert.True(t, strings.HasPrefix(messageID, "<"))
+		assert.True(t, strings.HasSuffix(messageID, "@example.com>"))
+
+		from, err := parsed.Header.AddressList("From")
+		require.NoError(t, err)
+		assert.Equal(t, []*mail.Address{{Name: "TaskMaster Support", Address: "noreply@example.com"}}, from)
+
+		// Non-ASCII subjects are RFC 2047 encoded
+		assert.NotEqual(t, msg.subject, parsed.Header.Get("Subject"))
+		subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+		require.NoError(t, err)
+		assert.Equal(t, msg.subject, subject)
+
+		assert.Equal(t, "1.0", parsed.Header.Get("MIME-Version"))
+	})
+
+	t.Run("alternative parts", func(t *testing.T) {
+		mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/alternative", mediaType)
+
+		// The multipart reader decodes quoted-printable parts
+		parts := multipart.NewReader(parsed.Body, params["boundary"])
+		var bodies []string
+		for {
+			part, err := parts.NextPart()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			content, err := io.ReadAll(part)
+			require.NoError(t, err)
+			bodies = append(bodies, string(content))
+		}
+
+		require.Len(t, bodies, 2)
+		assert.Equal(t, "Hallo Jürgen,\r\nwillkommen!\r\n", bodies[0])
+		assert.Contains(t, bodies[1], "<p>Hallo Jürgen,</p>")
+		assert.Contains(t, bodies[1], strings.Repeat("lang ", 100))
+	})
+
+	t.Run("ASCII subjects are left as is", func(t *testing.T) {
+		plain := *msg
+		plain.subject = "Welcome to TaskMaster"
+		raw, err := plain.bytes()
+		require.NoError(t, err)
+		assert.Contains(t, string(raw), "\r\nSubject: Welcome to TaskMaster\r\n")
+	})
+
+	t.Run("attachments", func(t *testing.T) {
+		withFile := *msg
+		withFile.attachments = []Attachment{
+			{Filename: "Rechnung März.pdf", ContentType: "application/pdf", Data: bytes.Repeat([]byte{0, 0xff, 'x'}, 100)},
+		}
+		raw, err := withFile.bytes()
+		require.NoError(t, err)
+		for _, line := range strings.Split(string(raw), "\r\n") {
+			assert.LessOrEqual(t, len(line), 998, "RFC 5322 line length limit")
+		}
+
+		parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+		require.NoError(t, err)
+		mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/mixed", mediaType)
+
+		parts := multipart.NewReader(parsed.Body, params["boundary"])
+
+		// The bodies come first, as a nested multipart/alternative part
+		alt, err := parts.NextPart()
+		require.NoError(t, err)
+		altType, altParams, err := mime.ParseMediaType(alt.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/alternative", altType)
+		altParts := multipart.NewReader(alt, altParams["boundary"])
+		for _, want := range []string{"text/plain", "text/html"} {
+			part, err := altParts.NextPart()
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(part.Header.Get("Content-Type"), want))
+		}
+		_, err = altParts.NextPart()
+		assert.Equal(t, io.EOF, err)
+
+		file, err := parts.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, "application/pdf", file.Header.Get("Content-Type"))
+		assert.Equal(t, "base64", file.Header.Get("Content-Transfer-Encoding"))
+		disposition, dispParams, err := mime.ParseMediaType(file.Header.Get("Content-Disposition"))
+		require.NoError(t, err)
+		assert.Equal(t, "attachment", disposition)
+		assert.Equal(t, "Rechnung März.pdf", dispParams["filename"])
+		encoded, err := io.ReadAll(file)
+		require.NoError(t, err)
+		data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+		require.NoError(t, err)
+		assert.Equal(t, withFile.attachments[0].Data, data)
+
+		_, err = parts.NextPart()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("BCC is envelope only", func(t *testing.T) {
+		bcc := *msg
+		bcc.bcc = []string{"audit@example.com"}
+		raw, err := bcc.bytes()
+		require.NoError(t, err)
+		assert.NotContains(t, string(raw), "audit@example.com")
+		assert.Equal(t, []string{"user@example.com", "audit@example.com"}, bcc.recipients())
+	})
+}
@@ -0,0 +1,118 @@
+// pkg/email/preview.go
+package email
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/ent/generated/task"
+)
+
+var (
+	// ErrUnknownTemplate is returned for names that are not a built-in template
+	ErrUnknownTemplate = errors.New("unknown email template")
+	// ErrUnknownSampleField is returned for preview sample data Preview can't use
+	ErrUnknownSampleField = errors.New("unknown sample field")
+)
+
+// RenderedEmail is a template rendered as it would be sent
+type RenderedEmail struct {
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// Previewer renders templates without sending them
+type Previewer interface {
+	Preview(name, locale string, sample map[string]string) (*RenderedEmail, error)
+}
+
+// TemplateNames returns the names of the built-in templates, sorted
+func TemplateNames() []string {
+	templates := NewTemplates().byName()
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Preview renders a template, including any overrides and translations, as
+// it would be sent, with a made-up user, task and token. Sample entries
+// replace parts of that: first_name, last_name, email, username, token,
+// alert_message, new_email, role, task_title and task_description.
+func (s *SMTPEmailService) Preview(name, locale string, sample map[string]string) (*RenderedEmail, error) {
+	u := &ent.User{
+		ID:        uuid.Nil,
+		Email:     "jane.doe@example.com",
+		Username:  "janedoe",
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Locale:    locale,
+	}
+	now := time.Now()
+	dueDate := now.Add(72 * time.Hour)
+	t := &ent.Task{
+		ID:          uuid.Nil,
+		Title:       "Prepare quarterly report",
+		Description: "Collect the numbers and draft the summary.",
+		Priority:    task.PriorityHigh,
+		DueDate:     &dueDate,
+	}
+	t.Edges.Creator = &ent.User{Username: "johnsmith"}
+	token := "sample-token"
+	alertMessage := "New login from Chrome on Linux"
+	newEmail := "jane.new@example.com"
+	role := "user"
+
+	for key, value := range sample {
+		switch key {
+		case "first_name":
+			u.FirstName = value
+		case "last_name":
+			u.LastName = value
+		case "email":
+			u.Email = value
+		case "username":
+			u.Username = value
+		case "token":
+			token = value
+		case "alert_message":
+			alertMessage = value
+		case "new_email":
+			newEmail = value
+		case "role":
+			role = value
+		case "task_title":
+			t.Title = value
+		case "task_description":
+			t.Description = value
+		default:
+			return nil, fmt.Errorf("%w %q", ErrUnknownSampleField, key)
+		}
+	}
+
+	data := s.buildEmailData(u, token, now.Add(24*time.Hour))
+	data.VerificationURL = fmt.Sprintf("%s/verify-email?token=%s", s.config.BaseURL, token)
+	data.ResetURL = fmt.Sprintf("%s/reset-password?token=%s", s.config.BaseURL, token)
+	data.ConfirmURL = fmt.Sprintf("%s/confirm-email-change?token=%s", s.config.BaseURL, token)
+	data.InviteURL = fmt.Sprintf("%s/accept-invite?token=%s", s.config.BaseURL, token)
+	data.Task = t
+	data.TaskURL = fmt.Sprintf("%s/tasks/%s", s.config.BaseURL, t.ID)
+	data.AlertMessage = alertMessage
+	data.OccurredAt = now
+	data.NewEmail = newEmail
+	data.Role = role
+
+	subject, textBody, htmlBody, err := s.renderTemplate(name, data)
+	if err != nil {
+		return nil, err
+	}
+	return &RenderedEmail{Subject: subject, HTMLBody: htmlBody, TextBody: textBody}, nil
+}
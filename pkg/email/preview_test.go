@@ -0,0 +1,48 @@
+// pkg/email/preview_test.go
+package email
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPEmailService_Preview(t *testing.T) {
+	svc := newTestSMTPService(t, &fakeSender{}, 1)
+	svc.config.BaseURL = "https://app.example.com"
+
+	t.Run("renders every built-in template", func(t *testing.T) {
+		for _, name := range TemplateNames() {
+			t.Run(name, func(t *testing.T) {
+				rendered, err := svc.Preview(name, "", nil)
+				require.NoError(t, err)
+
+				assert.Contains(t, rendered.Subject+rendered.HTMLBody+rendered.TextBody, "TaskMaster", "AppName is substituted")
+				for _, part := range []string{rendered.Subject, rendered.HTMLBody, rendered.TextBody} {
+					assert.NotEmpty(t, part)
+					assert.NotContains(t, part, "{{")
+					assert.NotContains(t, part, "<no value>")
+				}
+			})
+		}
+	})
+
+	t.Run("sample data is substituted", func(t *testing.T) {
+		rendered, err := svc.Preview("verification", "", map[string]string{"first_name": "Ada", "token": "abc123"})
+		require.NoError(t, err)
+		assert.Contains(t, rendered.TextBody, "Ada")
+		assert.Contains(t, rendered.TextBody, "https://app.example.com/verify-email?token=abc123")
+		assert.Contains(t, rendered.HTMLBody, "Ada")
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		_, err := svc.Preview("newsletter", "", nil)
+		assert.ErrorIs(t, err, ErrUnknownTemplate)
+	})
+
+	t.Run("unknown sample field", func(t *testing.T) {
+		_, err := svc.Preview("welcome", "", map[string]string{"favourite_colour": "blue"})
+		assert.ErrorIs(t, err, ErrUnknownSampleField)
+	})
+}
@@ -0,0 +1,240 @@
+// pkg/email/queue.go
+package email
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+)
+
+var (
+	// ErrQueueFull is returned by Enqueue when the buffer has no free slot
+	ErrQueueFull = errors.New("email queue is full")
+	// ErrQueueClosed is returned by Enqueue after Shutdown has been called
+	ErrQueueClosed = errors.New("email queue is closed")
+)
+
+// QueueConfig holds email queue configuration
+type QueueConfig struct {
+	Workers    int           // Number of background senders
+	BufferSize int           // Jobs that can wait before Enqueue reports ErrQueueFull
+	JobTimeout time.Duration // Upper bound for a single job, including retries
+}
+
+// DefaultQueueConfig returns default email queue configuration
+func DefaultQueueConfig() *QueueConfig {
+	return &QueueConfig{
+		Workers:    4,
+		BufferSize: 100,
+		JobTimeout: 2 * time.Minute,
+	}
+}
+
+// Job is a unit of email work processed by the queue
+type Job struct {
+	Name string                          // Used in logs, e.g. "verification"
+	Ctx  context.Context                 // Carries request values; its cancellation is ignored
+	Send func(ctx context.Context) error // Performs the send
+}
+
+// Queue is a buffered worker pool that sends emails in the background
+type Queue struct {
+	config *QueueConfig
+	jobs   chan Job
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewQueue creates a queue and starts its workers
+func NewQueue(config *QueueConfig) *Queue {
+	if config == nil {
+		config = DefaultQueueConfig()
+	}
+
+	q := &Queue{
+		config: config,
+		jobs:   make(chan Job, config.BufferSize),
+	}
+
+	workers := config.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.worker()
+	}
+
+	return q
+}
+
+// Enqueue schedules a job without blocking
+func (q *Queue) Enqueue(job Job) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Shutdown stops accepting jobs and waits for queued jobs to finish or ctx to end
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// worker processes jobs until the queue is closed and drained
+func (q *Queue) worker() {
+	defer q.wg.Done()
+
+	for job := range q.jobs {
+		q.run(job)
+	}
+}
+
+// run executes a single job detached from the enqueuing request's lifetime
+func (q *Queue) run(job Job) {
+	parent := context.Background()
+	if job.Ctx != nil {
+		parent = context.WithoutCancel(job.Ctx)
+	}
+
+	ctx := parent
+	if q.config.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, q.config.JobTimeout)
+		defer cancel()
+	}
+
+	if err := job.Send(ctx); err != nil {
+		log.Printf("Failed to send %s email: %v", job.Name, err)
+	}
+}
+
+// QueuedEmailService implements EmailService by handing every send to a
+// Queue. When the queue is full or closed the email is sent synchronously
+// so it is never silently lost.
+type QueuedEmailService struct {
+	next  EmailService
+	queue *Queue
+}
+
+// NewQueuedEmailService wraps an EmailService so sends happen in the background
+func NewQueuedEmailService(next EmailService, queue *Queue) *QueuedEmailService {
+	return &QueuedEmailService{
+		next:  next,
+		queue: queue,
+	}
+}
+
+// SendVerificationEmail enqueues an email verification email
+func (s *QueuedEmailService) SendVerificationEmail(ctx context.Context, user *ent.User, token string) error {
+	return s.dispatch(ctx, "verification", func(ctx context.Context) error {
+		return s.next.SendVerificationEmail(ctx, user, token)
+	})
+}
+
+// SendPasswordResetEmail enqueues a password reset email
+func (s *QueuedEmailService) SendPasswordResetEmail(ctx context.Context, user *ent.User, token string) error {
+	return s.dispatch(ctx, "password_reset", func(ctx context.Context) error {
+		return s.next.SendPasswordResetEmail(ctx, user, token)
+	})
+}
+
+// SendWelcomeEmail enqueues a welcome email
+func (s *QueuedEmailService) SendWelcomeEmail(ctx context.Context, user *ent.User) error {
+	return s.dispatch(ctx, "welcome", func(ctx context.Context) error {
+		return s.next.SendWelcomeEmail(ctx, user)
+	})
+}
+
+// SendPasswordChangedNotification enqueues a password changed notification
+func (s *QueuedEmailService) SendPasswordChangedNotification(ctx context.Context, user *ent.User) error {
+	return s.dispatch(ctx, "password_changed", func(ctx context.Context) error {
+		return s.next.SendPasswordChangedNotification(ctx, user)
+	})
+}
+
+// SendTaskAssignedEmail enqueues a task assigned email
+func (s *QueuedEmailService) SendTaskAssignedEmail(ctx context.Context, user *ent.User, task *ent.Task) error {
+	return s.dispatch(ctx, "task_assigned", func(ctx context.Context) error {
+		return s.next.SendTaskAssignedEmail(ctx, user, task)
+	})
+}
+
+// SendSecurityAlertEmail enqueues a security alert email
+func (s *QueuedEmailService) SendSecurityAlertEmail(ctx context.Context, user *ent.User, message string) error {
+	return s.dispatch(ctx, "security_alert", func(ctx context.Context) error {
+		return s.next.SendSecurityAlertEmail(ctx, user, message)
+	})
+}
+
+// SendAccountLockedEmail enqueues an account locked email
+func (s *QueuedEmailService) SendAccountLockedEmail(ctx context.Context, user *ent.User, lockedUntil time.Time) error {
+	return s.dispatch(ctx, "account_locked", func(ctx context.Context) error {
+		return s.next.SendAccountLockedEmail(ctx, user, lockedUntil)
+	})
+}
+
+// SendEmailChangeConfirmation enqueues an email change confirmation
+func (s *QueuedEmailService) SendEmailChangeConfirmation(ctx context.Context, user *ent.User, newEmail, token string, expiresAt time.Time) error {
+	return s.dispatch(ctx, "email_change", func(ctx context.Context) error {
+		return s.next.SendEmailChangeConfirmation(ctx, user, newEmail, token, expiresAt)
+	})
+}
+
+// SendEmailChangedNotification enqueues an email changed notification
+func (s *QueuedEmailService) SendEmailChangedNotification(ctx context.Context, user *ent.User, oldEmail string) error {
+	return s.dispatch(ctx, "email_changed", func(ctx context.Context) error {
+		return s.next.SendEmailChangedNotification(ctx, user, oldEmail)
+	})
+}
+
+// SendInviteEmail enqueues an invite email
+func (s *QueuedEmailService) SendInviteEmail(ctx context.Context, invite *ent.Invite, token string) error {
+	return s.dispatch(ctx, "invite", func(ctx context.Context) error {
+		return s.next.SendInviteEmail(ctx, invite, token)
+	})
+}
+
+// dispatch enqueues a send, falling back to sending inline when the queue can't take it
+func (s *QueuedEmailService) dispatch(ctx context.Context, name string, send func(ctx context.Context) error) error {
+	err := s.queue.Enqueue(Job{Name: name, Ctx: ctx, Send: send})
+	if err == nil {
+		return nil
+	}
+
+	log.Printf("Sending %s email inline: %v", name, err)
+	return send(ctx)
+}
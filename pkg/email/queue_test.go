@@ -0,0 +1,134 @@
+// pkg/email/queue_test.go
+package email
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+)
+
+func TestQueue_ProcessesJobs(t *testing.T) {
+	queue := NewQueue(&QueueConfig{Workers: 2, BufferSize: 10, JobTimeout: time.Second})
+
+	var processed atomic.Int32
+	done := make(chan struct{}, 5)
+	for i := 0; i < 5; i++ {
+		err := queue.Enqueue(Job{Name: "test", Send: func(ctx context.Context) error {
+			processed.Add(1)
+			done <- struct{}{}
+			return nil
+		}})
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 5; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for jobs")
+		}
+	}
+	assert.Equal(t, int32(5), processed.Load())
+
+	require.NoError(t, queue.Shutdown(context.Background()))
+}
+
+func TestQueue_ShutdownDrainsQueue(t *testing.T) {
+	queue := NewQueue(&QueueConfig{Workers: 1, BufferSize: 10})
+
+	var processed atomic.Int32
+	for i := 0; i < 5; i++ {
+		require.NoError(t, queue.Enqueue(Job{Name: "slow", Send: func(ctx context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			processed.Add(1)
+			return nil
+		}}))
+	}
+
+	require.NoError(t, queue.Shutdown(context.Background()))
+	assert.Equal(t, int32(5), processed.Load())
+
+	assert.ErrorIs(t, queue.Enqueue(Job{Name: "late", Send: func(ctx context.Context) error { return nil }}), ErrQueueClosed)
+}
+
+func TestQueue_ShutdownHonoursContext(t *testing.T) {
+	queue := NewQueue(&QueueConfig{Workers: 1, BufferSize: 1})
+
+	release := make(chan struct{})
+	require.NoError(t, queue.Enqueue(Job{Name: "blocked", Send: func(ctx context.Context) error {
+		<-release
+		return nil
+	}}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, queue.Shutdown(ctx), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, queue.Shutdown(context.Background()))
+}
+
+func TestQueue_EnqueueWhenFull(t *testing.T) {
+	queue := NewQueue(&QueueConfig{Workers: 1, BufferSize: 1})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	block := Job{Name: "blocked", Send: func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	}}
+
+	// One job occupies the worker, the next fills the buffer
+	require.NoError(t, queue.Enqueue(block))
+	<-started
+	require.NoError(t, queue.Enqueue(block))
+	assert.ErrorIs(t, queue.Enqueue(block), ErrQueueFull)
+
+	close(release)
+	require.NoError(t, queue.Shutdown(context.Background()))
+}
+
+func TestQueue_JobOutlivesRequestContext(t *testing.T) {
+	queue := NewQueue(&QueueConfig{Workers: 1, BufferSize: 1})
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	var ctxErr atomic.Value
+	require.NoError(t, queue.Enqueue(Job{Name: "detached", Ctx: reqCtx, Send: func(ctx context.Context) error {
+		time.Sleep(5 * time.Millisecond)
+		ctxErr.Store(ctx.Err() == nil)
+		return nil
+	}}))
+	cancel()
+
+	require.NoError(t, queue.Shutdown(context.Background()))
+	assert.Equal(t, true, ctxErr.Load())
+}
+
+func TestQueuedEmailService(t *testing.T) {
+	mock := NewMockEmailService()
+	queue := NewQueue(&QueueConfig{Workers: 1, BufferSize: 10})
+	svc := NewQueuedEmailService(mock, queue)
+
+	user := &ent.User{Email: "user@example.com"}
+	require.NoError(t, svc.SendVerificationEmail(context.Background(), user, "token"))
+	require.NoError(t, svc.SendWelcomeEmail(context.Background(), user))
+
+	require.NoError(t, queue.Shutdown(context.Background()))
+	require.Len(t, mock.GetSentEmails(), 2)
+	assert.Equal(t, "verification", mock.GetSentEmails()[0].Template)
+	assert.Equal(t, "welcome", mock.GetSentEmails()[1].Template)
+
+	// Once the queue is closed, sends fall back to inline delivery
+	require.NoError(t, svc.SendPasswordChangedNotification(context.Background(), user))
+	assert.Equal(t, "password_changed", mock.GetLastSentEmail().Template)
+}
@@ -0,0 +1,150 @@
+// pkg/email/sender.go
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"syscall"
+	"time"
+)
+
+// Default retry settings used when Config leaves them unset
+const (
+	defaultSendMaxAttempts    = 3
+	defaultSendRetryBaseDelay = 1 * time.Second
+	defaultSendRetryMaxDelay  = 30 * time.Second
+)
+
+// Sender delivers a fully built message to an SMTP server
+type Sender interface {
+	SendMail(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+	// Verify connects and authenticates without sending anything
+	Verify(ctx context.Context, addr string, auth smtp.Auth) error
+}
+
+// smtpSender sends mail with net/smtp
+type smtpSender struct {
+	rootCAs *x509.CertPool // Trusted for STARTTLS in Verify; nil uses the system roots
+}
+
+// SendMail implements Sender
+func (smtpSender) SendMail(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	return smtp.SendMail(addr, auth, from, to, msg)
+}
+
+// Verify implements Sender
+func (s smtpSender) Verify(ctx context.Context, addr string, auth smtp.Auth) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("dial SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	host, _, _ := net.SplitHostPort(addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("dial SMTP server: %w", err)
+	}
+	defer client.Close()
+
+	// Same steps as smtp.SendMail, so the check passes when sending would
+	if err := client.Hello("localhost"); err != nil {
+		return fmt.Errorf("SMTP hello failed: %w", err)
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host, RootCAs: s.rootCAs}); err != nil {
+			return fmt.Errorf("SMTP STARTTLS failed: %w", err)
+		}
+	}
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(auth); err != nil {
+				return fmt.Errorf("SMTP authentication failed: %w", err)
+			}
+		}
+	}
+
+	if err := client.Quit(); err != nil {
+		return fmt.Errorf("SMTP quit failed: %w", err)
+	}
+	return nil
+}
+
+// sendWithRetry sends a message, retrying transient failures with exponential
+// backoff until the attempts are exhausted or ctx is done
+func (s *SMTPEmailService) sendWithRetry(ctx context.Context, addr string, to []string, msg []byte) error {
+	maxAttempts := s.config.SendMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultSendMaxAttempts
+	}
+	delay := s.config.SendRetryBaseDelay
+	if delay <= 0 {
+		delay = defaultSendRetryBaseDelay
+	}
+	maxDelay := s.config.SendRetryMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultSendRetryMaxDelay
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = s.sender.SendMail(addr, s.auth, s.config.FromEmail, to, msg)
+		if err == nil {
+			return nil
+		}
+
+		if !isTransientSendError(err) {
+			return err
+		}
+		if attempt >= maxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		log.Printf("Transient SMTP failure (attempt %d/%d), retrying in %v: %v", attempt, maxAttempts, delay, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (last error: %v)", ctx.Err(), err)
+		case <-timer.C:
+		}
+
+		delay *= 2
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+}
+
+// isTransientSendError reports whether a send failure is worth retrying:
+// network errors and 4xx SMTP replies are, while 5xx replies (including
+// authentication failures) and other errors are permanent
+func isTransientSendError(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
@@ -0,0 +1,280 @@
+// pkg/email/sender_test.go
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+)
+
+// fakeSender fails with the queued errors before succeeding and records the
+// last envelope and message
+type fakeSender struct {
+	errs  []error
+	calls int
+	to    []string
+	msg   []byte
+}
+
+func (f *fakeSender) SendMail(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	f.calls++
+	f.to = to
+	f.msg = msg
+	if len(f.errs) == 0 {
+		return nil
+	}
+	err := f.errs[0]
+	f.errs = f.errs[1:]
+	return err
+}
+
+func (f *fakeSender) Verify(ctx context.Context, addr string, auth smtp.Auth) error {
+	return nil
+}
+
+func newTestSMTPService(t *testing.T, sender Sender, maxAttempts int) *SMTPEmailService {
+	svc, err := NewSMTPEmailService(&Config{
+		SMTPHost:           "localhost",
+		SMTPPort:           2525,
+		FromEmail:          "noreply@example.com",
+		FromName:           "TaskMaster",
+		AppName:            "TaskMaster",
+		SendMaxAttempts:    maxAttempts,
+		SendRetryBaseDelay: time.Millisecond,
+		SendRetryMaxDelay:  5 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	svc.SetSender(sender)
+	return svc
+}
+
+func transientNetError() error {
+	return &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+}
+
+func TestSMTPEmailService_RetriesTransientFailures(t *testing.T) {
+	tests := []struct {
+		name        string
+		errs        []error
+		maxAttempts int
+		wantErr     bool
+		wantCalls   int
+	}{
+		{
+			name:        "fails twice then succeeds",
+			errs:        []error{transientNetError(), &textproto.Error{Code: 421, Msg: "try again later"}},
+			maxAttempts: 3,
+			wantCalls:   3,
+		},
+		{
+			name:        "gives up after max attempts",
+			errs:        []error{transientNetError(), transientNetError(), transientNetError()},
+			maxAttempts: 2,
+			wantErr:     true,
+			wantCalls:   2,
+		},
+		{
+			name:        "authentication failure is not retried",
+			errs:        []error{&textproto.Error{Code: 535, Msg: "authentication failed"}},
+			maxAttempts: 3,
+			wantErr:     true,
+			wantCalls:   1,
+		},
+		{
+			name:        "unknown error is not retried",
+			errs:        []error{errors.New("unencrypted connection")},
+			maxAttempts: 3,
+			wantErr:     true,
+			wantCalls:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &fakeSender{errs: tt.errs}
+			svc := newTestSMTPService(t, sender, tt.maxAttempts)
+
+			err := svc.SendWelcomeEmail(context.Background(), &ent.User{Email: "user@example.com", FirstName: "Test"})
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCalls, sender.calls)
+		})
+	}
+}
+
+func TestSMTPEmailService_RetryRespectsContext(t *testing.T) {
+	sender := &fakeSender{errs: []error{transientNetError(), transientNetError()}}
+	svc := newTestSMTPService(t, sender, 5)
+	svc.config.SendRetryBaseDelay = time.Hour
+	svc.config.SendRetryMaxDelay = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := svc.SendWelcomeEmail(ctx, &ent.User{Email: "user@example.com", FirstName: "Test"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, sender.calls)
+}
+
+func TestIsTransientSendError(t *testing.T) {
+	assert.True(t, isTransientSendError(transientNetError()))
+	assert.True(t, isTransientSendError(&textproto.Error{Code: 450, Msg: "mailbox busy"}))
+	assert.False(t, isTransientSendError(&textproto.Error{Code: 550, Msg: "no such user"}))
+	assert.False(t, isTransientSendError(errors.New("permanent")))
+}
+
+func TestSMTPEmailService_SendTemplateWithBCCAndAttachment(t *testing.T) {
+	sender := &fakeSender{}
+	svc := newTestSMTPService(t, sender, 1)
+	svc.config.BCC = []string{"audit@example.com"}
+
+	err := svc.SendTemplate(context.Background(), "user@example.com", "welcome",
+		&EmailData{User: &ent.User{Email: "user@example.com", FirstName: "Test"}},
+		Attachment{Filename: "invoice.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"user@example.com", "audit@example.com"}, sender.to, "BCC is in the envelope")
+	assert.NotContains(t, string(sender.msg), "audit@example.com", "BCC is not in the message")
+	assert.Contains(t, string(sender.msg), "Content-Type: multipart/mixed")
+	assert.Contains(t, string(sender.msg), `filename=invoice.pdf`)
+}
+
+func TestSMTPEmailService_TokenEmailsSkipBCC(t *testing.T) {
+	sender := &fakeSender{}
+	svc := newTestSMTPService(t, sender, 1)
+	svc.config.BCC = []string{"audit@example.com"}
+
+	user := &ent.User{Email: "user@example.com", FirstName: "Test"}
+	require.NoError(t, svc.SendPasswordResetEmail(context.Background(), user, "reset-token"))
+	assert.Equal(t, []string{"user@example.com"}, sender.to)
+
+	require.NoError(t, svc.SendTemplate(context.Background(), "user@example.com", "verification",
+		&EmailData{User: user, Token: "verify-token"}))
+	assert.Equal(t, []string{"user@example.com"}, sender.to)
+
+	// Whatever the template, a token or a link built from one skips the BCC
+	require.NoError(t, svc.SendTemplate(context.Background(), "user@example.com", "security_alert",
+		&EmailData{User: user, AlertMessage: "Confirm it was you", ResetURL: "https://example.com/reset?token=t"}))
+	assert.Equal(t, []string{"user@example.com"}, sender.to)
+
+	// Emails without one are still copied
+	require.NoError(t, svc.SendTemplate(context.Background(), "user@example.com", "security_alert",
+		&EmailData{User: user, AlertMessage: "New sign-in"}))
+	assert.ElementsMatch(t, []string{"user@example.com", "audit@example.com"}, sender.to)
+}
+
+// fakeSMTPServer answers one SMTP session, advertising STARTTLS when
+// tlsConfig is set and AUTH PLAIN, and records the commands it receives
+func fakeSMTPServer(t *testing.T, tlsConfig *tls.Config) (addr string, commands <-chan []string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	done := make(chan []string, 1)
+	go func() {
+		var seen []string
+		defer func() { done <- seen }()
+
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 fake ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb := strings.ToUpper(strings.Fields(line)[0])
+			seen = append(seen, verb)
+
+			switch verb {
+			case "EHLO":
+				tp.PrintfLine("250-fake")
+				if tlsConfig != nil {
+					if _, ok := conn.(*tls.Conn); !ok {
+						tp.PrintfLine("250-STARTTLS")
+					}
+				}
+				tp.PrintfLine("250 AUTH PLAIN")
+			case "STARTTLS":
+				tp.PrintfLine("220 ready")
+				conn = tls.Server(conn, tlsConfig)
+				tp = textproto.NewConn(conn)
+			case "AUTH":
+				tp.PrintfLine("235 authenticated")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				return
+			default:
+				tp.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), done
+}
+
+func TestSMTPSender_Verify(t *testing.T) {
+	// Reuse httptest's certificate, which is valid for 127.0.0.1
+	ts := httptest.NewTLSServer(nil)
+	defer ts.Close()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(ts.Certificate())
+
+	tests := []struct {
+		name         string
+		tlsConfig    *tls.Config
+		auth         bool
+		wantCommands []string
+	}{
+		{
+			name:         "upgrades to TLS before authenticating",
+			tlsConfig:    ts.TLS,
+			auth:         true,
+			wantCommands: []string{"EHLO", "STARTTLS", "EHLO", "AUTH", "QUIT"},
+		},
+		{
+			name:         "no credentials skip AUTH",
+			tlsConfig:    ts.TLS,
+			wantCommands: []string{"EHLO", "STARTTLS", "EHLO", "QUIT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, commands := fakeSMTPServer(t, tt.tlsConfig)
+
+			var auth smtp.Auth
+			if tt.auth {
+				auth = smtp.PlainAuth("", "user", "secret", "127.0.0.1")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			require.NoError(t, smtpSender{rootCAs: rootCAs}.Verify(ctx, addr, auth))
+			assert.Equal(t, tt.wantCommands, <-commands)
+		})
+	}
+}
@@ -14,6 +14,12 @@ type EmailService interface {
 	SendPasswordResetEmail(ctx context.Context, user *ent.User, token string) error
 	SendWelcomeEmail(ctx context.Context, user *ent.User) error
 	SendPasswordChangedNotification(ctx context.Context, user *ent.User) error
+	SendTaskAssignedEmail(ctx context.Context, user *ent.User, task *ent.Task) error
+	SendSecurityAlertEmail(ctx context.Context, user *ent.User, message string) error
+	SendAccountLockedEmail(ctx context.Context, user *ent.User, lockedUntil time.Time) error
+	SendEmailChangeConfirmation(ctx context.Context, user *ent.User, newEmail, token string, expiresAt time.Time) error
+	SendEmailChangedNotification(ctx context.Context, user *ent.User, oldEmail string) error
+	SendInviteEmail(ctx context.Context, invite *ent.Invite, token string) error
 }
 
 // EmailTemplate represents an email template
@@ -33,6 +39,15 @@ type EmailData struct {
 	BaseURL         string
 	VerificationURL string
 	ResetURL        string
+	Task            *ent.Task
+	TaskURL         string
+	AlertMessage    string
+	OccurredAt      time.Time
+	NewEmail        string
+	ConfirmURL      string
+	InviteURL       string
+	Role            string
+	Locale          string // Selects the template translation; empty means English
 }
 
 // Config holds email service configuration
@@ -46,6 +61,13 @@ type Config struct {
 	BaseURL      string
 	AppName      string
 	SupportEmail string
+	TemplateDir  string   // Optional directory of template overrides, see LoadTemplates
+	BCC          []string // Addresses blind-copied on emails without a token, e.g. a monitoring inbox
+
+	// Delivery retry settings; zero values fall back to defaults
+	SendMaxAttempts    int           // Total attempts per email, including the first
+	SendRetryBaseDelay time.Duration // Delay before the first retry, doubled after each attempt
+	SendRetryMaxDelay  time.Duration // Upper bound for the retry delay
 }
 
 // Templates holds all email templates
@@ -56,6 +78,10 @@ type Templates struct {
 	PasswordChanged EmailTemplate
 	AccountLocked   EmailTemplate
 	SecurityAlert   EmailTemplate
+	TaskAssigned    EmailTemplate
+	EmailChange     EmailTemplate
+	EmailChanged    EmailTemplate
+	Invite          EmailTemplate
 }
 
 // NewTemplates creates default email templates
@@ -320,6 +346,365 @@ For your security, we recommend:
 Best regards,
 The {{.AppName}} Team
 
+If you have any questions, please contact us at {{.SupportEmail}}`,
+		},
+
+		AccountLocked: EmailTemplate{
+			Subject: "Your {{.AppName}} account has been locked",
+			HTMLBody: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Account Locked</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { text-align: center; margin-bottom: 30px; }
+        .alert { background-color: #f8d7da; border: 1px solid #f5c6cb; padding: 15px; border-radius: 5px; margin: 20px 0; }
+        .footer { margin-top: 30px; padding-top: 20px; border-top: 1px solid #eee; font-size: 14px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Account Temporarily Locked</h1>
+        </div>
+        
+        <p>Hi {{.User.FirstName}},</p>
+        
+        <p>Your {{.AppName}} account has been temporarily locked after too many failed sign-in attempts.</p>
+        
+        <div class="alert">
+            <strong>Locked until:</strong> {{.ExpiresAt.Format "January 2, 2006 at 3:04 PM MST"}}. You can sign in again after this time.
+        </div>
+        
+        <p>If these attempts weren't you, someone may be trying to access your account. We recommend resetting your password once the lock expires and enabling two-factor authentication.</p>
+        
+        <div class="footer">
+            <p>Best regards,<br>The {{.AppName}} Team</p>
+            <p>If you need help, please contact us at <a href="mailto:{{.SupportEmail}}">{{.SupportEmail}}</a></p>
+        </div>
+    </div>
+</body>
+</html>`,
+			TextBody: `Account Temporarily Locked
+
+Hi {{.User.FirstName}},
+
+Your {{.AppName}} account has been temporarily locked after too many failed sign-in attempts.
+
+Locked until: {{.ExpiresAt.Format "January 2, 2006 at 3:04 PM MST"}}. You can sign in again after this time.
+
+If these attempts weren't you, someone may be trying to access your account. We recommend resetting your password once the lock expires and enabling two-factor authentication.
+
+Best regards,
+The {{.AppName}} Team
+
+If you need help, please contact us at {{.SupportEmail}}`,
+		},
+
+		SecurityAlert: EmailTemplate{
+			Subject: "Security alert for your {{.AppName}} account",
+			HTMLBody: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Security Alert</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { text-align: center; margin-bottom: 30px; }
+        .alert { background-color: #fff3cd; border: 1px solid #ffeaa7; padding: 15px; border-radius: 5px; margin: 20px 0; }
+        .footer { margin-top: 30px; padding-top: 20px; border-top: 1px solid #eee; font-size: 14px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Security Alert</h1>
+        </div>
+        
+        <p>Hi {{.User.FirstName}},</p>
+        
+        <p>We noticed security-related activity on your {{.AppName}} account:</p>
+        
+        <div class="alert">
+            <p>{{.AlertMessage}}</p>
+            <p><strong>When:</strong> {{.OccurredAt.Format "January 2, 2006 at 3:04 PM MST"}}</p>
+        </div>
+        
+        <p>If this was you, no action is needed. Otherwise, please change your password and contact our support team at <a href="mailto:{{.SupportEmail}}">{{.SupportEmail}}</a>.</p>
+        
+        <div class="footer">
+            <p>Best regards,<br>The {{.AppName}} Team</p>
+            <p>You can turn off security emails in your profile settings.</p>
+        </div>
+    </div>
+</body>
+</html>`,
+			TextBody: `Security Alert
+
+Hi {{.User.FirstName}},
+
+We noticed security-related activity on your {{.AppName}} account:
+
+{{.AlertMessage}}
+When: {{.OccurredAt.Format "January 2, 2006 at 3:04 PM MST"}}
+
+If this was you, no action is needed. Otherwise, please change your password and contact our support team at {{.SupportEmail}}.
+
+Best regards,
+The {{.AppName}} Team
+
+You can turn off security emails in your profile settings.`,
+		},
+
+		TaskAssigned: EmailTemplate{
+			Subject: "[{{.AppName}}] You've been assigned: {{.Task.Title}}",
+			HTMLBody: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Task Assigned</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { text-align: center; margin-bottom: 30px; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #007bff; color: white; text-decoration: none; border-radius: 5px; }
+        .task { margin: 20px 0; padding: 15px; background-color: #f8f9fa; border-radius: 5px; }
+        .footer { margin-top: 30px; padding-top: 20px; border-top: 1px solid #eee; font-size: 14px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>New Task Assigned</h1>
+        </div>
+        
+        <p>Hi {{.User.FirstName}},</p>
+        
+        <p>A task has been assigned to you in {{.AppName}}.</p>
+        
+        <div class="task">
+            <h3>{{.Task.Title}}</h3>
+            {{if .Task.Description}}<p>{{.Task.Description}}</p>{{end}}
+            <p><strong>Priority:</strong> {{.Task.Priority}}</p>
+            {{if .Task.DueDate}}<p><strong>Due:</strong> {{.Task.DueDate.Format "January 2, 2006 at 3:04 PM"}}</p>{{end}}
+            {{if .Task.Edges.Creator}}<p><strong>Created by:</strong> {{.Task.Edges.Creator.Username}}</p>{{end}}
+        </div>
+        
+        <p style="text-align: center; margin: 30px 0;">
+            <a href="{{.TaskURL}}" class="button">View Task</a>
+        </p>
+        
+        <div class="footer">
+            <p>Best regards,<br>The {{.AppName}} Team</p>
+            <p>You can turn off these emails in your profile settings. Questions? Contact us at <a href="mailto:{{.SupportEmail}}">{{.SupportEmail}}</a></p>
+        </div>
+    </div>
+</body>
+</html>`,
+			TextBody: `New Task Assigned
+
+Hi {{.User.FirstName}},
+
+A task has been assigned to you in {{.AppName}}.
+
+Title: {{.Task.Title}}
+{{if .Task.Description}}Description: {{.Task.Description}}
+{{end}}Priority: {{.Task.Priority}}
+{{if .Task.DueDate}}Due: {{.Task.DueDate.Format "January 2, 2006 at 3:04 PM"}}
+{{end}}{{if .Task.Edges.Creator}}Created by: {{.Task.Edges.Creator.Username}}
+{{end}}
+View the task: {{.TaskURL}}
+
+Best regards,
+The {{.AppName}} Team
+
+You can turn off these emails in your profile settings. Questions? Contact us at {{.SupportEmail}}`,
+		},
+
+		EmailChange: EmailTemplate{
+			Subject: "Confirm your new {{.AppName}} email address",
+			HTMLBody: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Confirm Email Change</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { text-align: center; margin-bottom: 30px; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #007bff; color: white; text-decoration: none; border-radius: 5px; }
+        .footer { margin-top: 30px; padding-top: 20px; border-top: 1px solid #eee; font-size: 14px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Confirm Your New Email</h1>
+        </div>
+        
+        <p>Hi {{.User.FirstName}},</p>
+        
+        <p>We received a request to change the email address on your {{.AppName}} account to {{.NewEmail}}. Please confirm this address by clicking the button below:</p>
+        
+        <p style="text-align: center; margin: 30px 0;">
+            <a href="{{.ConfirmURL}}" class="button">Confirm Email Address</a>
+        </p>
+        
+        <p>If the button doesn't work, you can copy and paste this link into your browser:</p>
+        <p><a href="{{.ConfirmURL}}">{{.ConfirmURL}}</a></p>
+        
+        <p>This link will expire on {{.ExpiresAt.Format "January 2, 2006 at 3:04 PM"}}. Until then you can keep signing in with your current email address.</p>
+        
+        <p>If you didn't request this change, you can safely ignore this email.</p>
+        
+        <div class="footer">
+            <p>Best regards,<br>The {{.AppName}} Team</p>
+            <p>If you have any questions, please contact us at <a href="mailto:{{.SupportEmail}}">{{.SupportEmail}}</a></p>
+        </div>
+    </div>
+</body>
+</html>`,
+			TextBody: `Confirm Your New Email
+
+Hi {{.User.FirstName}},
+
+We received a request to change the email address on your {{.AppName}} account to {{.NewEmail}}. Please confirm this address by visiting this link:
+
+{{.ConfirmURL}}
+
+This link will expire on {{.ExpiresAt.Format "January 2, 2006 at 3:04 PM"}}. Until then you can keep signing in with your current email address.
+
+If you didn't request this change, you can safely ignore this email.
+
+Best regards,
+The {{.AppName}} Team
+
+If you have any questions, please contact us at {{.SupportEmail}}`,
+		},
+
+		EmailChanged: EmailTemplate{
+			Subject: "Your {{.AppName}} email address has been changed",
+			HTMLBody: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Email Changed</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { text-align: center; margin-bottom: 30px; }
+        .alert { background-color: #fff3cd; border: 1px solid #ffeaa7; padding: 15px; border-radius: 5px; margin: 20px 0; }
+        .footer { margin-top: 30px; padding-top: 20px; border-top: 1px solid #eee; font-size: 14px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Email Address Changed</h1>
+        </div>
+        
+        <p>Hi {{.User.FirstName}},</p>
+        
+        <p>The email address on your {{.AppName}} account has been changed to {{.NewEmail}}. You'll need to use the new address to sign in from now on.</p>
+        
+        <div class="alert">
+            <strong>Security Notice:</strong> If you didn't make this change, please contact our support team immediately at <a href="mailto:{{.SupportEmail}}">{{.SupportEmail}}</a>.
+        </div>
+        
+        <div class="footer">
+            <p>Best regards,<br>The {{.AppName}} Team</p>
+            <p>If you have any questions, please contact us at <a href="mailto:{{.SupportEmail}}">{{.SupportEmail}}</a></p>
+        </div>
+    </div>
+</body>
+</html>`,
+			TextBody: `Email Address Changed
+
+Hi {{.User.FirstName}},
+
+The email address on your {{.AppName}} account has been changed to {{.NewEmail}}. You'll need to use the new address to sign in from now on.
+
+Security Notice: If you didn't make this change, please contact our support team immediately at {{.SupportEmail}}.
+
+Best regards,
+The {{.AppName}} Team
+
+If you have any questions, please contact us at {{.SupportEmail}}`,
+		},
+
+		Invite: EmailTemplate{
+			Subject: "You're invited to join {{.AppName}}",
+			HTMLBody: `
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>You're Invited</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { text-align: center; margin-bottom: 30px; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #007bff; color: white; text-decoration: none; border-radius: 5px; }
+        .footer { margin-top: 30px; padding-top: 20px; border-top: 1px solid #eee; font-size: 14px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>You're Invited to {{.AppName}}</h1>
+        </div>
+        
+        <p>Hi,</p>
+        
+        <p>You've been invited to join {{.AppName}} as {{.Role}}. Click the button below to choose a username and password and set up your account:</p>
+        
+        <p style="text-align: center; margin: 30px 0;">
+            <a href="{{.InviteURL}}" class="button">Accept Invite</a>
+        </p>
+        
+        <p>If the button doesn't work, you can copy and paste this link into your browser:</p>
+        <p><a href="{{.InviteURL}}">{{.InviteURL}}</a></p>
+        
+        <p>This invite will expire on {{.ExpiresAt.Format "January 2, 2006 at 3:04 PM"}}.</p>
+        
+        <p>If you weren't expecting this invite, you can safely ignore this email.</p>
+        
+        <div class="footer">
+            <p>Best regards,<br>The {{.AppName}} Team</p>
+            <p>If you have any questions, please contact us at <a href="mailto:{{.SupportEmail}}">{{.SupportEmail}}</a></p>
+        </div>
+    </div>
+</body>
+</html>`,
+			TextBody: `You're Invited to {{.AppName}}
+
+Hi,
+
+You've been invited to join {{.AppName}} as {{.Role}}. Visit this link to choose a username and password and set up your account:
+
+{{.InviteURL}}
+
+This invite will expire on {{.ExpiresAt.Format "January 2, 2006 at 3:04 PM"}}.
+
+If you weren't expecting this invite, you can safely ignore this email.
+
+Best regards,
+The {{.AppName}} Team
+
 If you have any questions, please contact us at {{.SupportEmail}}`,
 		},
 	}
@@ -4,32 +4,60 @@ package email
 import (
 	"bytes"
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"fmt"
+	"net/mail"
 	"net/smtp"
-	"text/template"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+	"github.com/gurkanbulca/taskmaster/pkg/i18n"
+	"github.com/gurkanbulca/taskmaster/pkg/tracing"
 )
 
 // SMTPEmailService implements EmailService using SMTP
 type SMTPEmailService struct {
 	config    *Config
-	templates *Templates
+	templates map[string]map[string]*compiledTemplate // Locale, then template name
 	auth      smtp.Auth
+	sender    Sender
 }
 
-// NewSMTPEmailService creates a new SMTP email service
-func NewSMTPEmailService(config *Config) *SMTPEmailService {
-	auth := smtp.PlainAuth("", config.SMTPUsername, config.SMTPPassword, config.SMTPHost)
+// NewSMTPEmailService creates a new SMTP email service. Templates, including
+// any overrides and translations in config.TemplateDir, are parsed here so
+// errors fail fast.
+func NewSMTPEmailService(config *Config) (*SMTPEmailService, error) {
+	localized, err := LoadLocalizedTemplates(config.TemplateDir)
+	if err != nil {
+		return nil, fmt.Errorf("load email templates: %w", err)
+	}
+
+	compiled := make(map[string]map[string]*compiledTemplate, len(localized))
+	for locale, templates := range localized {
+		compiled[locale], err = templates.compile()
+		if err != nil {
+			return nil, fmt.Errorf("parse %s email templates: %w", locale, err)
+		}
+	}
+
+	// Relays that accept mail without credentials get no AUTH
+	var auth smtp.Auth
+	if config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", config.SMTPUsername, config.SMTPPassword, config.SMTPHost)
+	}
 
 	return &SMTPEmailService{
 		config:    config,
-		templates: NewTemplates(),
+		templates: compiled,
 		auth:      auth,
-	}
+		sender:    smtpSender{},
+	}, nil
+}
+
+// SetSender replaces the transport used to deliver messages
+func (s *SMTPEmailService) SetSender(sender Sender) {
+	s.sender = sender
 }
 
 // SendVerificationEmail sends an email verification email
@@ -37,7 +65,7 @@ func (s *SMTPEmailService) SendVerificationEmail(ctx context.Context, user *ent.
 	data := s.buildEmailData(user, token, time.Now().Add(24*time.Hour))
 	data.VerificationURL = fmt.Sprintf("%s/verify-email?token=%s", s.config.BaseURL, token)
 
-	return s.sendEmail(ctx, user.Email, s.templates.Verification, data)
+	return s.sendEmail(ctx, user.Email, "verification", data)
 }
 
 // SendPasswordResetEmail sends a password reset email
@@ -45,26 +73,78 @@ func (s *SMTPEmailService) SendPasswordResetEmail(ctx context.Context, user *ent
 	data := s.buildEmailData(user, token, time.Now().Add(1*time.Hour))
 	data.ResetURL = fmt.Sprintf("%s/reset-password?token=%s", s.config.BaseURL, token)
 
-	return s.sendEmail(ctx, user.Email, s.templates.PasswordReset, data)
+	return s.sendEmail(ctx, user.Email, "password_reset", data)
 }
 
 // SendWelcomeEmail sends a welcome email after email verification
 func (s *SMTPEmailService) SendWelcomeEmail(ctx context.Context, user *ent.User) error {
 	data := s.buildEmailData(user, "", time.Time{})
 
-	return s.sendEmail(ctx, user.Email, s.templates.Welcome, data)
+	return s.sendEmail(ctx, user.Email, "welcome", data)
 }
 
 // SendPasswordChangedNotification sends a notification when password is changed
 func (s *SMTPEmailService) SendPasswordChangedNotification(ctx context.Context, user *ent.User) error {
 	data := s.buildEmailData(user, "", time.Time{})
 
-	return s.sendEmail(ctx, user.Email, s.templates.PasswordChanged, data)
+	return s.sendEmail(ctx, user.Email, "password_changed", data)
+}
+
+// SendTaskAssignedEmail notifies a user that a task was assigned to them
+func (s *SMTPEmailService) SendTaskAssignedEmail(ctx context.Context, user *ent.User, task *ent.Task) error {
+	data := s.buildEmailData(user, "", time.Time{})
+	data.Task = task
+	data.TaskURL = fmt.Sprintf("%s/tasks/%s", s.config.BaseURL, task.ID)
+
+	return s.sendEmail(ctx, user.Email, "task_assigned", data)
+}
+
+// SendSecurityAlertEmail notifies a user about security-relevant account activity
+func (s *SMTPEmailService) SendSecurityAlertEmail(ctx context.Context, user *ent.User, message string) error {
+	data := s.buildEmailData(user, "", time.Time{})
+	data.AlertMessage = message
+	data.OccurredAt = time.Now()
+
+	return s.sendEmail(ctx, user.Email, "security_alert", data)
+}
+
+// SendAccountLockedEmail notifies a user that their account was locked
+func (s *SMTPEmailService) SendAccountLockedEmail(ctx context.Context, user *ent.User, lockedUntil time.Time) error {
+	data := s.buildEmailData(user, "", lockedUntil)
+
+	return s.sendEmail(ctx, user.Email, "account_locked", data)
+}
+
+// SendEmailChangeConfirmation asks the user to confirm a new email address.
+// It is sent to the new address, not the one currently on the account.
+func (s *SMTPEmailService) SendEmailChangeConfirmation(ctx context.Context, user *ent.User, newEmail, token string, expiresAt time.Time) error {
+	data := s.buildEmailData(user, token, expiresAt)
+	data.NewEmail = newEmail
+	data.ConfirmURL = fmt.Sprintf("%s/confirm-email-change?token=%s", s.config.BaseURL, token)
+
+	return s.sendEmail(ctx, newEmail, "email_change", data)
+}
+
+// SendEmailChangedNotification tells the previous address that the account email changed
+func (s *SMTPEmailService) SendEmailChangedNotification(ctx context.Context, user *ent.User, oldEmail string) error {
+	data := s.buildEmailData(user, "", time.Time{})
+	data.NewEmail = user.Email
+
+	return s.sendEmail(ctx, oldEmail, "email_changed", data)
+}
+
+// SendInviteEmail sends an invite link to someone who doesn't have an account yet
+func (s *SMTPEmailService) SendInviteEmail(ctx context.Context, invite *ent.Invite, token string) error {
+	data := s.buildEmailData(nil, token, invite.ExpiresAt)
+	data.InviteURL = fmt.Sprintf("%s/accept-invite?token=%s", s.config.BaseURL, token)
+	data.Role = string(invite.Role)
+
+	return s.sendEmail(ctx, invite.Email, "invite", data)
 }
 
 // buildEmailData creates EmailData for template rendering
 func (s *SMTPEmailService) buildEmailData(user *ent.User, token string, expiresAt time.Time) *EmailData {
-	return &EmailData{
+	data := &EmailData{
 		User:         user,
 		Token:        token,
 		ExpiresAt:    expiresAt,
@@ -72,58 +152,72 @@ func (s *SMTPEmailService) buildEmailData(user *ent.User, token string, expiresA
 		AppName:      s.config.AppName,
 		BaseURL:      s.config.BaseURL,
 	}
+	if user != nil {
+		data.Locale = user.Locale
+	}
+	return data
 }
 
-// sendEmail sends an email using SMTP
-func (s *SMTPEmailService) sendEmail(ctx context.Context, to string, template EmailTemplate, data *EmailData) error {
-	// Render subject
-	subjectTmpl, err := s.parseTemplate(template.Subject)
-	if err != nil {
-		return fmt.Errorf("parse subject template: %w", err)
+// SendTemplate renders a named template, e.g. "security_alert", and sends it
+// with optional attachments. It is the lower-level path under the
+// EmailService methods for notifications that need more, such as an invoice
+// attachment. Unset AppName, BaseURL and SupportEmail come from the config.
+func (s *SMTPEmailService) SendTemplate(ctx context.Context, to, templateName string, data *EmailData, attachments ...Attachment) error {
+	if data.AppName == "" {
+		data.AppName = s.config.AppName
 	}
-
-	var subjectBuf bytes.Buffer
-	if err := subjectTmpl.Execute(&subjectBuf, data); err != nil {
-		return fmt.Errorf("execute subject template: %w", err)
+	if data.BaseURL == "" {
+		data.BaseURL = s.config.BaseURL
 	}
-
-	// Render HTML body
-	htmlTmpl, err := s.parseTemplate(template.HTMLBody)
-	if err != nil {
-		return fmt.Errorf("parse HTML template: %w", err)
+	if data.SupportEmail == "" {
+		data.SupportEmail = s.config.SupportEmail
 	}
+	return s.sendEmail(ctx, to, templateName, data, attachments...)
+}
 
-	var htmlBuf bytes.Buffer
-	if err := htmlTmpl.Execute(&htmlBuf, data); err != nil {
-		return fmt.Errorf("execute HTML template: %w", err)
-	}
+// carriesToken reports whether data holds a single-use token, directly or in
+// one of the links built from it. Such an email would let anyone on the BCC
+// list take over the account, so it only goes to the addressee.
+func (data *EmailData) carriesToken() bool {
+	return data.Token != "" || data.VerificationURL != "" || data.ResetURL != "" ||
+		data.ConfirmURL != "" || data.InviteURL != ""
+}
+
+// sendEmail sends an email using SMTP, to the configured BCC addresses too
+// unless it carries a token
+func (s *SMTPEmailService) sendEmail(ctx context.Context, to string, templateName string, data *EmailData, attachments ...Attachment) (err error) {
+	ctx, span := tracing.Start(ctx, "SMTPEmailService.sendEmail", attribute.String("email.template", templateName))
+	defer func() { tracing.End(span, err) }()
 
-	// Render text body
-	textTmpl, err := s.parseTemplate(template.TextBody)
+	subject, textBody, htmlBody, err := s.renderTemplate(templateName, data)
 	if err != nil {
-		return fmt.Errorf("parse text template: %w", err)
+		return err
 	}
 
-	var textBuf bytes.Buffer
-	if err := textTmpl.Execute(&textBuf, data); err != nil {
-		return fmt.Errorf("execute text template: %w", err)
+	var bcc []string
+	if !data.carriesToken() {
+		bcc = s.config.BCC
 	}
 
 	// Create MIME message
-	boundary := s.generateBoundary()
-	message := s.buildMIMEMessage(
-		s.config.FromEmail,
-		s.config.FromName,
-		to,
-		subjectBuf.String(),
-		textBuf.String(),
-		htmlBuf.String(),
-		boundary,
-	)
-
-	// Send email
+	msg := &message{
+		from:        mail.Address{Name: s.config.FromName, Address: s.config.FromEmail},
+		to:          to,
+		bcc:         bcc,
+		subject:     subject,
+		textBody:    textBody,
+		htmlBody:    htmlBody,
+		attachments: attachments,
+		date:        time.Now(),
+	}
+	raw, err := msg.bytes()
+	if err != nil {
+		return fmt.Errorf("build message: %w", err)
+	}
+
+	// Send email, retrying transient failures
 	addr := fmt.Sprintf("%s:%d", s.config.SMTPHost, s.config.SMTPPort)
-	err = smtp.SendMail(addr, s.auth, s.config.FromEmail, []string{to}, message)
+	err = s.sendWithRetry(ctx, addr, msg.recipients(), raw)
 	if err != nil {
 		return fmt.Errorf("send email: %w", err)
 	}
@@ -131,59 +225,49 @@ func (s *SMTPEmailService) sendEmail(ctx context.Context, to string, template Em
 	return nil
 }
 
-// parseTemplate parses a template string
-func (s *SMTPEmailService) parseTemplate(templateStr string) (*template.Template, error) {
-	return template.New("email").Parse(templateStr)
-}
-
-// generateBoundary generates a random boundary for MIME messages
-func (s *SMTPEmailService) generateBoundary() string {
-	bytes := make([]byte, 16)
-	rand.Read(bytes)
-	return hex.EncodeToString(bytes)
-}
-
-// buildMIMEMessage builds a MIME email message with both text and HTML parts
-func (s *SMTPEmailService) buildMIMEMessage(from, fromName, to, subject, textBody, htmlBody, boundary string) []byte {
-	message := fmt.Sprintf(`From: %s <%s>
-To: %s
-Subject: %s
-MIME-Version: 1.0
-Content-Type: multipart/alternative; boundary="%s"
-
---%s
-Content-Type: text/plain; charset=UTF-8
-Content-Transfer-Encoding: 7bit
+// renderTemplate renders the subject, text body and HTML body of a named
+// template in data.Locale, falling back to English when the locale has no
+// templates. The HTML body uses html/template so user-controlled fields are escaped.
+func (s *SMTPEmailService) renderTemplate(name string, data *EmailData) (subject, textBody, htmlBody string, err error) {
+	tmpl, ok := s.templatesFor(data.Locale)[name]
+	if !ok {
+		return "", "", "", fmt.Errorf("%w %q", ErrUnknownTemplate, name)
+	}
 
-%s
+	var subjectBuf bytes.Buffer
+	if err := tmpl.subject.Execute(&subjectBuf, data); err != nil {
+		return "", "", "", fmt.Errorf("execute subject template: %w", err)
+	}
 
---%s
-Content-Type: text/html; charset=UTF-8
-Content-Transfer-Encoding: 7bit
+	var htmlBuf bytes.Buffer
+	if err := tmpl.html.Execute(&htmlBuf, data); err != nil {
+		return "", "", "", fmt.Errorf("execute HTML template: %w", err)
+	}
 
-%s
+	var textBuf bytes.Buffer
+	if err := tmpl.text.Execute(&textBuf, data); err != nil {
+		return "", "", "", fmt.Errorf("execute text template: %w", err)
+	}
 
---%s--
-`, fromName, from, to, subject, boundary, boundary, textBody, boundary, htmlBody, boundary)
+	return subjectBuf.String(), textBuf.String(), htmlBuf.String(), nil
+}
 
-	return []byte(message)
+// templatesFor returns the templates of the closest configured locale, e.g.
+// "de" for "de-AT", or the English templates
+func (s *SMTPEmailService) templatesFor(locale string) map[string]*compiledTemplate {
+	for _, candidate := range i18n.Candidates(locale) {
+		if templates, ok := s.templates[candidate]; ok {
+			return templates
+		}
+	}
+	return s.templates[i18n.DefaultLocale]
 }
 
-// TestConnection tests the SMTP connection
+// TestConnection checks that the SMTP server is reachable and accepts our
+// credentials. It gives up when ctx is done, so it can back a health check.
 func (s *SMTPEmailService) TestConnection(ctx context.Context) error {
 	addr := fmt.Sprintf("%s:%d", s.config.SMTPHost, s.config.SMTPPort)
-
-	client, err := smtp.Dial(addr)
-	if err != nil {
-		return fmt.Errorf("dial SMTP server: %w", err)
-	}
-	defer client.Close()
-
-	if err := client.Auth(s.auth); err != nil {
-		return fmt.Errorf("SMTP authentication failed: %w", err)
-	}
-
-	return nil
+	return s.sender.Verify(ctx, addr, s.auth)
 }
 
 // MockEmailService implements EmailService for testing
@@ -260,6 +344,93 @@ func (m *MockEmailService) SendPasswordChangedNotification(ctx context.Context,
 	return nil
 }
 
+// SendTaskAssignedEmail mock implementation
+func (m *MockEmailService) SendTaskAssignedEmail(ctx context.Context, user *ent.User, task *ent.Task) error {
+	m.SentEmails = append(m.SentEmails, SentEmail{
+		To:       user.Email,
+		Template: "task_assigned",
+		Data: &EmailData{
+			User: user,
+			Task: task,
+		},
+		SentAt: time.Now(),
+	})
+	return nil
+}
+
+// SendSecurityAlertEmail mock implementation
+func (m *MockEmailService) SendSecurityAlertEmail(ctx context.Context, user *ent.User, message string) error {
+	m.SentEmails = append(m.SentEmails, SentEmail{
+		To:       user.Email,
+		Template: "security_alert",
+		Data: &EmailData{
+			User:         user,
+			AlertMessage: message,
+		},
+		SentAt: time.Now(),
+	})
+	return nil
+}
+
+// SendAccountLockedEmail mock implementation
+func (m *MockEmailService) SendAccountLockedEmail(ctx context.Context, user *ent.User, lockedUntil time.Time) error {
+	m.SentEmails = append(m.SentEmails, SentEmail{
+		To:       user.Email,
+		Template: "account_locked",
+		Data: &EmailData{
+			User:      user,
+			ExpiresAt: lockedUntil,
+		},
+		SentAt: time.Now(),
+	})
+	return nil
+}
+
+// SendEmailChangeConfirmation mock implementation
+func (m *MockEmailService) SendEmailChangeConfirmation(ctx context.Context, user *ent.User, newEmail, token string, expiresAt time.Time) error {
+	m.SentEmails = append(m.SentEmails, SentEmail{
+		To:       newEmail,
+		Template: "email_change",
+		Data: &EmailData{
+			User:      user,
+			Token:     token,
+			ExpiresAt: expiresAt,
+			NewEmail:  newEmail,
+		},
+		SentAt: time.Now(),
+	})
+	return nil
+}
+
+// SendEmailChangedNotification mock implementation
+func (m *MockEmailService) SendEmailChangedNotification(ctx context.Context, user *ent.User, oldEmail string) error {
+	m.SentEmails = append(m.SentEmails, SentEmail{
+		To:       oldEmail,
+		Template: "email_changed",
+		Data: &EmailData{
+			User:     user,
+			NewEmail: user.Email,
+		},
+		SentAt: time.Now(),
+	})
+	return nil
+}
+
+// SendInviteEmail mock implementation
+func (m *MockEmailService) SendInviteEmail(ctx context.Context, invite *ent.Invite, token string) error {
+	m.SentEmails = append(m.SentEmails, SentEmail{
+		To:       invite.Email,
+		Template: "invite",
+		Data: &EmailData{
+			Token:     token,
+			ExpiresAt: invite.ExpiresAt,
+			Role:      string(invite.Role),
+		},
+		SentAt: time.Now(),
+	})
+	return nil
+}
+
 // GetSentEmails returns all sent emails (for testing)
 func (m *MockEmailService) GetSentEmails() []SentEmail {
 	return m.SentEmails
@@ -0,0 +1,55 @@
+// pkg/email/smtp_test.go
+package email
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+)
+
+func TestSMTPEmailService_RenderTemplateEscapesHTML(t *testing.T) {
+	const maliciousName = `<script>alert("x")</script>`
+
+	svc := newTestSMTPService(t, &fakeSender{}, 1)
+
+	data := svc.buildEmailData(&ent.User{Email: "user@example.com", FirstName: maliciousName}, "token", time.Now().Add(time.Hour))
+	data.VerificationURL = "https://example.com/verify-email?token=token"
+	data.ResetURL = "https://example.com/reset-password?token=token"
+	data.Task = &ent.Task{ID: uuid.New(), Title: "Task", Priority: "high"}
+	data.TaskURL = "https://example.com/tasks/1"
+	data.NewEmail = "new@example.com"
+	data.ConfirmURL = "https://example.com/confirm-email-change?token=token"
+
+	for _, name := range []string{"verification", "password_reset", "welcome", "password_changed", "task_assigned", "account_locked", "security_alert", "email_change", "email_changed"} {
+		t.Run(name, func(t *testing.T) {
+			_, textBody, htmlBody, err := svc.renderTemplate(name, data)
+			require.NoError(t, err)
+
+			assert.NotContains(t, htmlBody, "<script>")
+			assert.Contains(t, htmlBody, "&lt;script&gt;")
+
+			// Plain text is not interpreted as markup, so it keeps the name as is
+			assert.Contains(t, textBody, maliciousName)
+		})
+	}
+}
+
+func TestSMTPEmailService_RenderInviteTemplate(t *testing.T) {
+	svc := newTestSMTPService(t, &fakeSender{}, 1)
+
+	data := svc.buildEmailData(nil, "token", time.Now().Add(time.Hour))
+	data.InviteURL = "https://example.com/accept-invite?token=token"
+	data.Role = "manager"
+
+	subject, textBody, htmlBody, err := svc.renderTemplate("invite", data)
+	require.NoError(t, err)
+	assert.Equal(t, "You're invited to join TaskMaster", subject)
+	assert.Contains(t, textBody, data.InviteURL)
+	assert.Contains(t, textBody, "as manager")
+	assert.Contains(t, htmlBody, `href="https://example.com/accept-invite?token=token"`)
+}
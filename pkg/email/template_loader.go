@@ -0,0 +1,151 @@
+// pkg/email/template_loader.go
+package email
+
+import (
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	texttemplate "text/template"
+
+	"github.com/gurkanbulca/taskmaster/pkg/i18n"
+)
+
+// compiledTemplate holds the parsed form of an EmailTemplate
+type compiledTemplate struct {
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+}
+
+// compile parses the subject, HTML body and text body of a template
+func (t EmailTemplate) compile() (*compiledTemplate, error) {
+	subject, err := texttemplate.New("subject").Parse(t.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("parse subject template: %w", err)
+	}
+
+	html, err := htmltemplate.New("html").Parse(t.HTMLBody)
+	if err != nil {
+		return nil, fmt.Errorf("parse HTML template: %w", err)
+	}
+
+	text, err := texttemplate.New("text").Parse(t.TextBody)
+	if err != nil {
+		return nil, fmt.Errorf("parse text template: %w", err)
+	}
+
+	return &compiledTemplate{subject: subject, html: html, text: text}, nil
+}
+
+// byName maps override file base names to the templates they replace
+func (t *Templates) byName() map[string]*EmailTemplate {
+	return map[string]*EmailTemplate{
+		"verification":     &t.Verification,
+		"password_reset":   &t.PasswordReset,
+		"welcome":          &t.Welcome,
+		"password_changed": &t.PasswordChanged,
+		"account_locked":   &t.AccountLocked,
+		"security_alert":   &t.SecurityAlert,
+		"task_assigned":    &t.TaskAssigned,
+		"email_change":     &t.EmailChange,
+		"email_changed":    &t.EmailChanged,
+		"invite":           &t.Invite,
+	}
+}
+
+// LoadTemplates returns the default templates with any overrides found in dir
+// applied. For a template named e.g. "verification", the files
+// verification.subject, verification.html and verification.txt each replace
+// the matching part; missing files keep the built-in default.
+func LoadTemplates(dir string) (*Templates, error) {
+	templates := NewTemplates()
+	if dir == "" {
+		return templates, nil
+	}
+
+	if err := templates.applyOverrides(dir); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// LoadLocalizedTemplates returns the templates of LoadTemplates under
+// i18n.DefaultLocale, plus one set per locale subdirectory of dir (e.g.
+// dir/de/welcome.subject). Locale files are layered over the English
+// templates, so a locale only needs the files it translates.
+func LoadLocalizedTemplates(dir string) (map[string]*Templates, error) {
+	base, err := LoadTemplates(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	localized := map[string]*Templates{i18n.DefaultLocale: base}
+	if dir == "" {
+		return localized, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read template dir: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		locale := i18n.Normalize(entry.Name())
+		if locale == "" {
+			return nil, fmt.Errorf("template dir %q is not a locale tag", entry.Name())
+		}
+
+		templates := *base
+		if err := templates.applyOverrides(filepath.Join(dir, entry.Name())); err != nil {
+			return nil, fmt.Errorf("%s: %w", locale, err)
+		}
+		localized[locale] = &templates
+	}
+
+	return localized, nil
+}
+
+// applyOverrides replaces template parts with the matching files in dir
+func (t *Templates) applyOverrides(dir string) error {
+	for name, tmpl := range t.byName() {
+		overrides := []struct {
+			ext    string
+			target *string
+		}{
+			{".subject", &tmpl.Subject},
+			{".html", &tmpl.HTMLBody},
+			{".txt", &tmpl.TextBody},
+		}
+
+		for _, o := range overrides {
+			content, err := os.ReadFile(filepath.Join(dir, name+o.ext))
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("read %s%s template: %w", name, o.ext, err)
+			}
+			*o.target = string(content)
+		}
+	}
+	return nil
+}
+
+// compile parses every template up front, keyed by template name
+func (t *Templates) compile() (map[string]*compiledTemplate, error) {
+	compiled := make(map[string]*compiledTemplate)
+	for name, tmpl := range t.byName() {
+		c, err := tmpl.compile()
+		if err != nil {
+			return nil, fmt.Errorf("%s template: %w", name, err)
+		}
+		compiled[name] = c
+	}
+	return compiled, nil
+}
@@ -0,0 +1,117 @@
+// pkg/email/template_loader_test.go
+package email
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ent "github.com/gurkanbulca/taskmaster/ent/generated"
+)
+
+func writeTemplateFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+}
+
+func TestLoadTemplates_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, dir, "verification.subject", "Confirm your {{.AppName}} address")
+	writeTemplateFile(t, dir, "verification.html", "<p>Hello {{.User.FirstName}}, click <a href=\"{{.VerificationURL}}\">here</a></p>")
+	writeTemplateFile(t, dir, "welcome.txt", "Welcome aboard, {{.User.FirstName}}!")
+
+	templates, err := LoadTemplates(dir)
+	require.NoError(t, err)
+
+	defaults := NewTemplates()
+
+	// Provided files replace their part of the template
+	assert.Equal(t, "Confirm your {{.AppName}} address", templates.Verification.Subject)
+	assert.Contains(t, templates.Verification.HTMLBody, "click <a href")
+	assert.Equal(t, "Welcome aboard, {{.User.FirstName}}!", templates.Welcome.TextBody)
+
+	// Missing files keep the defaults
+	assert.Equal(t, defaults.Verification.TextBody, templates.Verification.TextBody)
+	assert.Equal(t, defaults.Welcome.Subject, templates.Welcome.Subject)
+	assert.Equal(t, defaults.PasswordReset, templates.PasswordReset)
+}
+
+func TestLoadTemplates_EmptyDirUsesDefaults(t *testing.T) {
+	templates, err := LoadTemplates("")
+	require.NoError(t, err)
+	assert.Equal(t, NewTemplates(), templates)
+}
+
+func TestNewSMTPEmailService_TemplateOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, dir, "welcome.subject", "Hi from {{.AppName}}")
+	writeTemplateFile(t, dir, "welcome.html", "<h1>Welcome {{.User.FirstName}}</h1>")
+
+	svc, err := NewSMTPEmailService(&Config{AppName: "Acme", TemplateDir: dir})
+	require.NoError(t, err)
+
+	data := svc.buildEmailData(&ent.User{FirstName: "Ada"}, "", time.Time{})
+	subject, textBody, htmlBody, err := svc.renderTemplate("welcome", data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hi from Acme", subject)
+	assert.Equal(t, "<h1>Welcome Ada</h1>", htmlBody)
+	assert.Contains(t, textBody, "Welcome to Acme!")
+}
+
+func TestNewSMTPEmailService_InvalidOverrideFailsFast(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, dir, "password_reset.html", "<p>{{.ResetURL</p>")
+
+	_, err := NewSMTPEmailService(&Config{TemplateDir: dir})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "password_reset template")
+}
+
+func TestNewSMTPEmailService_LocalizedTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplateFile(t, dir, "welcome.subject", "Hi from {{.AppName}}")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "de"), 0o755))
+	writeTemplateFile(t, filepath.Join(dir, "de"), "welcome.subject", "Willkommen bei {{.AppName}}")
+	writeTemplateFile(t, filepath.Join(dir, "de"), "welcome.txt", "Hallo {{.User.FirstName}}!")
+
+	svc, err := NewSMTPEmailService(&Config{AppName: "Acme", TemplateDir: dir})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		locale      string
+		wantSubject string
+		wantText    string
+	}{
+		{name: "translated", locale: "de", wantSubject: "Willkommen bei Acme", wantText: "Hallo Ada!"},
+		{name: "region falls back to language", locale: "de-AT", wantSubject: "Willkommen bei Acme", wantText: "Hallo Ada!"},
+		{name: "missing locale falls back to English", locale: "fr", wantSubject: "Hi from Acme", wantText: "Welcome to Acme!"},
+		{name: "no locale", locale: "", wantSubject: "Hi from Acme", wantText: "Welcome to Acme!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := svc.buildEmailData(&ent.User{FirstName: "Ada", Locale: tt.locale}, "", time.Time{})
+			subject, textBody, htmlBody, err := svc.renderTemplate("welcome", data)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantSubject, subject)
+			assert.Contains(t, textBody, tt.wantText)
+			// Files a locale doesn't provide keep the English template
+			assert.Contains(t, htmlBody, "Welcome to Acme!")
+		})
+	}
+}
+
+func TestLoadLocalizedTemplates_InvalidLocaleDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "not a locale"), 0o755))
+
+	_, err := LoadLocalizedTemplates(dir)
+	assert.Error(t, err)
+}
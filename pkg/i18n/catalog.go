@@ -0,0 +1,65 @@
+// pkg/i18n/catalog.go
+package i18n
+
+// catalog maps English error messages to their translations, keyed by
+// locale. Only messages users commonly see are listed; anything else is
+// returned in English.
+var catalog = map[string]map[string]string{
+	"de": {
+		"user not authenticated":                          "Benutzer nicht authentifiziert",
+		"missing authorization header":                    "Authorization-Header fehlt",
+		"invalid token":                                   "Ungültiges Token",
+		"invalid credentials":                             "Ungültige Anmeldedaten",
+		"account is deactivated":                          "Konto ist deaktiviert",
+		"admin access required":                           "Administratorzugriff erforderlich",
+		"user not found":                                  "Benutzer nicht gefunden",
+		"task not found":                                  "Aufgabe nicht gefunden",
+		"template not found":                              "Vorlage nicht gefunden",
+		"comment not found":                               "Kommentar nicht gefunden",
+		"invalid task ID format":                          "Ungültiges Format der Aufgaben-ID",
+		"you don't have permission to view this task":     "Sie haben keine Berechtigung, diese Aufgabe anzuzeigen",
+		"email is already in use":                         "E-Mail-Adresse wird bereits verwendet",
+		"incorrect password":                              "Falsches Passwort",
+		"request timed out":                               "Zeitüberschreitung der Anfrage",
+		"too many requests, please try again later":       "Zu viele Anfragen, bitte versuchen Sie es später erneut",
+		"too many login attempts, please try again later": "Zu viele Anmeldeversuche, bitte versuchen Sie es später erneut",
+		"internal server error":                           "Interner Serverfehler",
+	},
+	"es": {
+		"user not authenticated":                          "Usuario no autenticado",
+		"missing authorization header":                    "Falta la cabecera de autorización",
+		"invalid token":                                   "Token no válido",
+		"invalid credentials":                             "Credenciales no válidas",
+		"account is deactivated":                          "La cuenta está desactivada",
+		"admin access required":                           "Se requiere acceso de administrador",
+		"user not found":                                  "Usuario no encontrado",
+		"task not found":                                  "Tarea no encontrada",
+		"template not found":                              "Plantilla no encontrada",
+		"comment not found":                               "Comentario no encontrado",
+		"invalid task ID format":                          "Formato de ID de tarea no válido",
+		"you don't have permission to view this task":     "No tienes permiso para ver esta tarea",
+		"email is already in use":                         "El correo electrónico ya está en uso",
+		"incorrect password":                              "Contraseña incorrecta",
+		"request timed out":                               "La solicitud ha excedido el tiempo de espera",
+		"too many requests, please try again later":       "Demasiadas solicitudes, inténtalo de nuevo más tarde",
+		"too many login attempts, please try again later": "Demasiados intentos de inicio de sesión, inténtalo de nuevo más tarde",
+		"internal server error":                           "Error interno del servidor",
+	},
+}
+
+// Translate returns message in the first of the preferred locales that has a
+// translation, trying each tag's parents too ("de-at" falls back to "de").
+// Without a match the English message is returned unchanged.
+func Translate(message string, preferred ...string) string {
+	for _, tag := range preferred {
+		for _, candidate := range Candidates(tag) {
+			if candidate == DefaultLocale {
+				return message
+			}
+			if translated, ok := catalog[candidate][message]; ok {
+				return translated
+			}
+		}
+	}
+	return message
+}
@@ -0,0 +1,60 @@
+// pkg/i18n/i18n_test.go
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []string
+	}{
+		{name: "empty", header: "", want: []string{}},
+		{name: "single", header: "de-DE", want: []string{"de-de"}},
+		{name: "ordered by weight", header: "en;q=0.5, es-MX, de;q=0.8", want: []string{"es-mx", "de", "en"}},
+		{name: "equal weights keep order", header: "fr, de", want: []string{"fr", "de"}},
+		{name: "drops wildcard and q=0", header: "*, it;q=0, pt_BR;q=0.3", want: []string{"pt-br"}},
+		{name: "drops malformed", header: "<script>, de;q=abc, es", want: []string{"es"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseAcceptLanguage(tt.header))
+		})
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	assert.Equal(t, []string{"zh-hant-tw", "zh-hant", "zh"}, Candidates("zh-Hant-TW"))
+	assert.Equal(t, []string{"de"}, Candidates("DE"))
+	assert.Nil(t, Candidates(""))
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name      string
+		preferred []string
+		want      string
+	}{
+		{name: "exact locale", preferred: []string{"de"}, want: "Aufgabe nicht gefunden"},
+		{name: "region falls back to language", preferred: []string{"es-MX"}, want: "Tarea no encontrada"},
+		{name: "first supported locale wins", preferred: []string{"fr", "es", "de"}, want: "Tarea no encontrada"},
+		{name: "english preferred over later locales", preferred: []string{"en-GB", "de"}, want: "task not found"},
+		{name: "unsupported locale", preferred: []string{"fr"}, want: "task not found"},
+		{name: "no preference", want: "task not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Translate("task not found", tt.preferred...))
+		})
+	}
+
+	t.Run("message not in catalog", func(t *testing.T) {
+		assert.Equal(t, "page size cannot exceed 100", Translate("page size cannot exceed 100", "de"))
+	})
+}
@@ -0,0 +1,97 @@
+// pkg/i18n/locale.go
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLocale is used when a user or request has no supported locale
+const DefaultLocale = "en"
+
+// MaxLocaleLength bounds the locale tags accepted from clients
+const MaxLocaleLength = 35
+
+// Normalize lower-cases a BCP 47 tag and uses "-" as the subtag separator,
+// so "pt_BR" and "pt-BR" both become "pt-br". Malformed tags yield "".
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if tag == "" || len(tag) > MaxLocaleLength {
+		return ""
+	}
+
+	for _, subtag := range strings.Split(tag, "-") {
+		if subtag == "" || len(subtag) > 8 {
+			return ""
+		}
+		for _, r := range subtag {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+				return ""
+			}
+		}
+	}
+	return tag
+}
+
+// Candidates returns the tag followed by its less specific parents, e.g.
+// "pt-br" gives ["pt-br", "pt"]. Lookups try them in order before falling
+// back to DefaultLocale.
+func Candidates(tag string) []string {
+	tag = Normalize(tag)
+	if tag == "" {
+		return nil
+	}
+
+	candidates := []string{tag}
+	for {
+		i := strings.LastIndex(tag, "-")
+		if i < 0 {
+			return candidates
+		}
+		tag = tag[:i]
+		candidates = append(candidates, tag)
+	}
+}
+
+// ParseAcceptLanguage returns the normalized tags of an Accept-Language
+// header, most preferred first. Wildcards, malformed tags and tags with q=0
+// are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = Normalize(tag)
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, weighted{tag: tag, q: q})
+	}
+
+	// Stable so equally weighted tags keep the client's order
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	tags := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		tags = append(tags, r.tag)
+	}
+	return tags
+}
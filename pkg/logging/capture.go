@@ -0,0 +1,48 @@
+// pkg/logging/capture.go
+package logging
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Entry is a log entry recorded by CaptureLogger
+type Entry struct {
+	Level   string
+	Message string
+	Fields  map[string]any
+}
+
+// CaptureLogger records entries in memory for assertions in tests
+type CaptureLogger struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewCaptureLogger creates an empty capturing logger
+func NewCaptureLogger() *CaptureLogger {
+	return &CaptureLogger{}
+}
+
+func (c *CaptureLogger) Debug(msg string, args ...any) { c.record("DEBUG", msg, args) }
+func (c *CaptureLogger) Info(msg string, args ...any)  { c.record("INFO", msg, args) }
+func (c *CaptureLogger) Warn(msg string, args ...any)  { c.record("WARN", msg, args) }
+func (c *CaptureLogger) Error(msg string, args ...any) { c.record("ERROR", msg, args) }
+
+// Entries returns a copy of the recorded entries
+func (c *CaptureLogger) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Entry(nil), c.entries...)
+}
+
+func (c *CaptureLogger) record(level, msg string, args []any) {
+	fields := make(map[string]any, len(args)/2)
+	for i := 0; i+1 < len(args); i += 2 {
+		fields[fmt.Sprint(args[i])] = args[i+1]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = append(c.entries, Entry{Level: level, Message: msg, Fields: fields})
+}
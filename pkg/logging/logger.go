@@ -0,0 +1,27 @@
+// pkg/logging/logger.go
+package logging
+
+import (
+	"io"
+	"log/slog"
+)
+
+// Logger writes structured log entries. Args are alternating key/value pairs,
+// e.g. logger.Error("send email", "user_id", id, "error", err).
+// *slog.Logger satisfies this interface.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// NewJSONLogger returns a Logger that writes one JSON object per entry to w
+func NewJSONLogger(w io.Writer, level slog.Level) Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+// Default returns the process-wide slog logger, used until one is injected
+func Default() Logger {
+	return slog.Default()
+}
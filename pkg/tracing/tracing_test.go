@@ -0,0 +1,50 @@
+// pkg/tracing/tracing_test.go
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartEnd_ChildSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, parent := Start(context.Background(), "rpc")
+	_, ok := Start(ctx, "EntTaskRepository.GetByID")
+	End(ok, nil)
+	_, failed := Start(ctx, "SMTPEmailService.sendEmail", attribute.String("email.template", "welcome"))
+	End(failed, errors.New("connection refused"))
+	End(parent, nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+
+	byName := make(map[string]tracetest.SpanStub, len(spans))
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+
+	parentID := byName["rpc"].SpanContext.SpanID()
+	assert.Equal(t, parentID, byName["EntTaskRepository.GetByID"].Parent.SpanID())
+	assert.Equal(t, otelcodes.Unset, byName["EntTaskRepository.GetByID"].Status.Code)
+
+	email := byName["SMTPEmailService.sendEmail"]
+	assert.Equal(t, parentID, email.Parent.SpanID())
+	assert.Equal(t, otelcodes.Error, email.Status.Code)
+	assert.Contains(t, email.Attributes, attribute.String("email.template", "welcome"))
+	require.Len(t, email.Events, 1, "error should be recorded as an event")
+}